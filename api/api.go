@@ -0,0 +1,54 @@
+package api
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/null-like/movie-backend/movie"
+	_movieDelivery "github.com/null-like/movie-backend/movie/delivery"
+	"github.com/null-like/movie-backend/openapi"
+	"github.com/null-like/movie-backend/poster"
+	_posterDelivery "github.com/null-like/movie-backend/poster/delivery"
+	"github.com/null-like/movie-backend/tv"
+	_tvDelivery "github.com/null-like/movie-backend/tv/delivery"
+	"github.com/null-like/movie-backend/user"
+	_userDelivery "github.com/null-like/movie-backend/user/delivery"
+	"github.com/sirupsen/logrus"
+)
+
+type Usecases struct {
+	Movie  movie.Usecase
+	Tv     tv.Usecase
+	User   user.Usecase
+	Poster poster.Usecase
+}
+
+// Register mounts every handler on the /v1 and /v2 groups. Document has to
+// describe each route it adds.
+func Register(v1 *echo.Group, v2 *echo.Group, u Usecases, logger *logrus.Logger) {
+	_movieDelivery.NewMovieHandler(v1, u.Movie)
+	_movieDelivery.NewMovieHandlerV2(v2, u.Movie)
+
+	_tvDelivery.NewTvHandler(v1, u.Tv)
+	_tvDelivery.NewTvHandlerV2(v2, u.Tv)
+
+	_userDelivery.NewUserHandler(v1, u.User, logger)
+	_userDelivery.NewUserHandlerV2(v2, u.User, logger)
+
+	_posterDelivery.NewPosterHandler(v1, u.Poster)
+	_posterDelivery.NewPosterHandler(v2, u.Poster)
+}
+
+// Document describes the routes of Register, with /v1 marked deprecated.
+func Document() *openapi.Document {
+	doc := openapi.New("movie-backend", "2.0", "Movie and TV catalogue with user ratings, watchlists, playlists and banners. /v1 is deprecated in favour of /v2.")
+
+	doc.Add("/v1", openapi.Deprecated(_movieDelivery.Operations())...)
+	doc.Add("/v1", openapi.Deprecated(_tvDelivery.Operations())...)
+	doc.Add("/v1", openapi.Deprecated(_userDelivery.Operations())...)
+	doc.Add("/v1", openapi.Deprecated(_posterDelivery.Operations())...)
+
+	doc.Add("/v2", _movieDelivery.OperationsV2()...)
+	doc.Add("/v2", _tvDelivery.OperationsV2()...)
+	doc.Add("/v2", _userDelivery.OperationsV2()...)
+	doc.Add("/v2", _posterDelivery.Operations()...)
+	return doc
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/null-like/movie-backend/openapi"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"testing"
+)
+
+func routes() []*echo.Route {
+	e := echo.New()
+	Register(e.Group("/v1"), e.Group("/v2"), Usecases{}, logrus.New())
+	return e.Routes()
+}
+
+func TestDocumentCoversRoutes(t *testing.T) {
+	doc := Document()
+	for _, r := range routes() {
+		if !doc.Has(r.Method, r.Path) {
+			t.Errorf("%s %s is registered but missing from the OpenAPI document", r.Method, r.Path)
+		}
+	}
+}
+
+func TestDocumentHasNoStaleRoutes(t *testing.T) {
+	registered := map[string]bool{}
+	for _, r := range routes() {
+		registered[r.Method+" "+openapi.Path(r.Path)] = true
+	}
+
+	for path, ops := range Document().Paths {
+		for method := range ops {
+			if !registered[strings.ToUpper(method)+" "+path] {
+				t.Errorf("%s %s is documented but not registered", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
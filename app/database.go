@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/viper"
+
+	"github.com/null-like/movie-backend/migrations"
+	"github.com/null-like/movie-backend/movie"
+	_movieRepo "github.com/null-like/movie-backend/movie/repository"
+	"github.com/null-like/movie-backend/replica"
+	"github.com/null-like/movie-backend/tv"
+	_tvRepo "github.com/null-like/movie-backend/tv/repository"
+	"github.com/null-like/movie-backend/user"
+	_userRepo "github.com/null-like/movie-backend/user/repository"
+)
+
+// databaseDialect reads database.driver, which is MariaDB unless it says
+// sqlite or postgres.
+func databaseDialect() migrations.Dialect {
+	viper.SetDefault("database.driver", string(migrations.MariaDB))
+	dialect := migrations.Dialect(viper.GetString("database.driver"))
+	switch dialect {
+	case migrations.MariaDB, migrations.SQLite, migrations.Postgres:
+		return dialect
+	}
+	log.Errorf("unknown database.driver %q", dialect)
+	os.Exit(1)
+	return ""
+}
+
+// initSQLiteConnection opens the database file at database.path for local
+// development, creating it when needed. SQLite takes one writer at a time, so
+// the pool keeps a single connection instead of failing writes as busy. The
+// driver needs cgo.
+func initSQLiteConnection() *sql.DB {
+	viper.SetDefault("database.path", filepath.Join("data", "movie.db"))
+	path := viper.GetString("database.path")
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	DB, err := sql.Open("sqlite3", path+"?_busy_timeout=5000")
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+	DB.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 1*time.Second)
+	defer cancel()
+	err = DB.PingContext(ctx)
+	if err != nil {
+		log.Error(err)
+		DB.Close()
+		os.Exit(1)
+	}
+
+	// The tv repository's queries run on SQLite as they are, qualified with
+	// the schema every SQLite database calls main.
+	schemaMap = map[string]string{"movie": "main", "user": "main"}
+	return DB
+}
+
+// initPostgresConnection connects to the PostgreSQL database at database.dsn.
+// Every table lives in the one schema named by database.schema, which
+// migrate creates when it is missing.
+func initPostgresConnection() *sql.DB {
+	DB, err := sql.Open("postgres", viper.GetString("database.dsn"))
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 1*time.Second)
+	defer cancel()
+	err = DB.PingContext(ctx)
+	if err != nil {
+		log.Error(err)
+		DB.Close()
+		os.Exit(1)
+	}
+
+	viper.SetDefault("database.schema", "public")
+	schema := viper.GetString("database.schema")
+	schemaMap = map[string]string{"movie": schema, "user": schema}
+	return DB
+}
+
+// initReplicas opens the read replicas listed in database.replicas, DSNs for
+// the primary's driver, and checks their health every
+// database.replica_check_seconds. Without replicas every query goes to the
+// primary as before.
+func initReplicas() {
+	dsns := viper.GetStringSlice("database.replicas")
+	if len(dsns) == 0 {
+		return
+	}
+	if dialect == migrations.SQLite {
+		log.Warn("database.replicas is ignored with the sqlite driver")
+		return
+	}
+
+	for _, dsn := range dsns {
+		conn, err := openReplica(dsn)
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		replicas = append(replicas, conn)
+	}
+
+	viper.SetDefault("database.replica_check_seconds", 5)
+	router = replica.NewRouter(log, replicas)
+	go router.Run(context.Background(), time.Duration(viper.GetInt("database.replica_check_seconds"))*time.Second)
+}
+
+// openReplica opens a replica with the primary's driver. A replica that is
+// down only makes its reads fall back to the primary, so it isn't pinged here.
+// MariaDB replicas can use the mysql+tcp network to go through the primary's
+// SSH tunnel.
+func openReplica(dsn string) (*sql.DB, error) {
+	if dialect == migrations.Postgres {
+		return sql.Open("postgres", dsn)
+	}
+
+	conf, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	conf.ParseTime = true
+	return sql.Open("mysql", conf.FormatDSN())
+}
+
+func newMovieRepository() movie.Repository {
+	primary := movieRepositoryOn(db)
+	if router == nil {
+		return primary
+	}
+
+	var rs []movie.Repository
+	for _, conn := range replicas {
+		rs = append(rs, movieRepositoryOn(conn))
+	}
+	return _movieRepo.NewReplicaMovieRepository(router, primary, rs)
+}
+
+func movieRepositoryOn(conn *sql.DB) movie.Repository {
+	switch dialect {
+	case migrations.SQLite:
+		return _movieRepo.NewSQLiteMovieRepository(log, conn)
+	case migrations.Postgres:
+		return _movieRepo.NewPostgresMovieRepository(log, conn, schemaMap)
+	}
+	return _movieRepo.NewMariaDBMovieRepository(log, conn, schemaMap)
+}
+
+func newUserRepository() user.Repository {
+	primary := userRepositoryOn(db)
+	if router == nil {
+		return primary
+	}
+
+	var rs []user.Repository
+	for _, conn := range replicas {
+		rs = append(rs, userRepositoryOn(conn))
+	}
+	return _userRepo.NewReplicaUserRepository(router, primary, rs)
+}
+
+func userRepositoryOn(conn *sql.DB) user.Repository {
+	switch dialect {
+	case migrations.SQLite:
+		return _userRepo.NewSQLiteUserRepository(log, conn)
+	case migrations.Postgres:
+		return _userRepo.NewPostgresUserRepository(log, conn, schemaMap)
+	}
+	return _userRepo.NewMariaDBUserRepository(log, conn, schemaMap)
+}
+
+// newTvRepository reads series with the MariaDB repository, whose
+// schema-qualified queries run on every driver.
+func newTvRepository() tv.Repository {
+	primary := _tvRepo.NewMariaDBTvRepository(log, db, schemaMap)
+	if router == nil {
+		return primary
+	}
+
+	var rs []tv.Repository
+	for _, conn := range replicas {
+		rs = append(rs, _tvRepo.NewMariaDBTvRepository(log, conn, schemaMap))
+	}
+	return _tvRepo.NewReplicaTvRepository(router, primary, rs)
+}
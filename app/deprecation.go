@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+)
+
+// Used when api.v1_deprecated / api.v1_sunset are missing from config.json.
+var (
+	defaultV1Deprecated = time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)
+	defaultV1Sunset     = time.Date(2027, time.May, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// deprecated marks every response of a group as deprecated since the given
+// time and due to be removed at sunset, pointing clients at its successor.
+func deprecated(since, sunset time.Time, successor string) echo.MiddlewareFunc {
+	deprecation := fmt.Sprintf("@%d", since.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+	link := fmt.Sprintf("<%s>; rel=\"successor-version\"", successor)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+			header.Set("Deprecation", deprecation)
+			header.Set("Sunset", sunsetDate)
+			header.Add("Link", link)
+			return next(c)
+		}
+	}
+}
+
+// v1Window reads the /v1 deprecation and sunset dates (YYYY-MM-DD).
+func v1Window() (time.Time, time.Time) {
+	return configDate("api.v1_deprecated", defaultV1Deprecated), configDate("api.v1_sunset", defaultV1Sunset)
+}
+
+func configDate(key string, fallback time.Time) time.Time {
+	v := viper.GetString(key)
+	if v == "" {
+		return fallback
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		log.Errorf("%s: %v, using %s", key, err, fallback.Format("2006-01-02"))
+		return fallback
+	}
+	return t
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	_movieImporter "github.com/null-like/movie-backend/movie/importer"
+)
+
+// importCatalog handles `import movies -dir <path>`, loading TMDB exports
+// (movies_metadata, keywords, credits as .csv or .json) into the catalog.
+func importCatalog(args []string) {
+	if len(args) == 0 || args[0] != "movies" {
+		fmt.Fprintln(os.Stderr, "usage: import movies [-dir path] [-batch size]")
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("import movies", flag.ExitOnError)
+	dir := flags.String("dir", ".", "directory holding movies_metadata, keywords and credits exports")
+	batch := flags.Int("batch", _movieImporter.DefaultBatchSize, "rows written per transaction")
+	flags.Parse(args[1:])
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	mr := newMovieRepository()
+	im := _movieImporter.NewImporter(log, mr, *dir, *batch, os.Stdout)
+	err := im.ImportMovies(ctx)
+	if err != nil {
+		log.Error(err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintf(os.Stderr, "progress was saved to %s; rerun the same command to resume\n", _movieImporter.CheckpointFile)
+		os.Exit(1)
+	}
+}
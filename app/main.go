@@ -11,24 +11,47 @@ import (
 	"github.com/spf13/viper"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
+	"google.golang.org/grpc"
 	"net"
 
-	_movieDelivery "github.com/null-like/movie-backend/movie/delivery"
-	_movieRepo "github.com/null-like/movie-backend/movie/repository"
+	_movieRpc "github.com/null-like/movie-backend/movie/rpc"
 	_movieUsecase "github.com/null-like/movie-backend/movie/usecase"
 
-	_userDelivery "github.com/null-like/movie-backend/user/delivery"
-	_userRepo "github.com/null-like/movie-backend/user/repository"
+	_tvUsecase "github.com/null-like/movie-backend/tv/usecase"
+
+	_blobStorage "github.com/null-like/movie-backend/blob/storage"
+
+	"github.com/null-like/movie-backend/poster"
+	_posterCache "github.com/null-like/movie-backend/poster/cache"
+	_posterOrigin "github.com/null-like/movie-backend/poster/origin"
+	_posterUsecase "github.com/null-like/movie-backend/poster/usecase"
+
+	"github.com/null-like/movie-backend/api"
+	"github.com/null-like/movie-backend/auth"
+	"github.com/null-like/movie-backend/graph"
+	"github.com/null-like/movie-backend/migrations"
+	"github.com/null-like/movie-backend/openapi"
+	"github.com/null-like/movie-backend/problem"
+	"github.com/null-like/movie-backend/replica"
+	"github.com/null-like/movie-backend/request"
+	"github.com/null-like/movie-backend/rpc"
+
+	_userRpc "github.com/null-like/movie-backend/user/rpc"
 	_userUsecase "github.com/null-like/movie-backend/user/usecase"
 
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
 var log *logrus.Logger
 var db *sql.DB
+var dialect migrations.Dialect
 var schemaMap map[string]string
+var replicas []*sql.DB
+var router *replica.Router
 var env string
 
 type ViaSSHDialer struct {
@@ -43,7 +66,15 @@ func init() {
 	initLogger()
 	initConfig()
 	env = os.Args[1]
-	db = initDBConnection()
+	dialect = databaseDialect()
+	switch dialect {
+	case migrations.SQLite:
+		db = initSQLiteConnection()
+	case migrations.Postgres:
+		db = initPostgresConnection()
+	default:
+		db = initDBConnection()
+	}
 }
 
 func initLogger() {
@@ -135,28 +166,110 @@ func initDBConnection() *sql.DB {
 }
 
 func main() {
+	command := "serve"
+	if len(os.Args) > 2 {
+		command = os.Args[2]
+	}
+
+	switch command {
+	case "serve":
+		serve()
+	case "migrate":
+		migrate()
+	case "import":
+		importCatalog(os.Args[3:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q (expected serve, migrate or import)\n", command)
+		os.Exit(2)
+	}
+}
+
+func serve() {
+	initReplicas()
+
 	e := echo.New()
+	e.HTTPErrorHandler = problem.HTTPErrorHandler(log)
+	e.Validator = request.Validator{}
+	e.Use(replica.Middleware)
+	tokens := auth.Tokens(viper.GetStringMapString("auth.tokens"))
+	e.Use(tokens.Middleware)
 	if env == "development" {
 		e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 			Skipper:          nil,
 			AllowOrigins:     []string{"*"},
-			AllowMethods:     []string{http.MethodHead, http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete},
+			AllowMethods:     []string{http.MethodHead, http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
 			AllowHeaders:     []string{"X-Requested-With", "Content-Type", "Authorization"},
 			AllowCredentials: false,
-			ExposeHeaders:    nil,
+			ExposeHeaders:    []string{"Deprecation", "Sunset", "Link"},
 			MaxAge:           0,
 		}))
 	}
 
-	v1 := e.Group("/v1")
+	since, sunset := v1Window()
+	v1 := e.Group("/v1", deprecated(since, sunset, "/v2"))
+	v2 := e.Group("/v2")
 
-	mr := _movieRepo.NewMariaDBMovieRepository(log, db, schemaMap)
+	mr := newMovieRepository()
 	mu := _movieUsecase.NewMovieUsecase(log, mr)
-	_movieDelivery.NewMovieHandler(v1, mu)
 
-	ur := _userRepo.NewMariaDBUserRepository(log, db, schemaMap)
-	uu := _userUsecase.NewUserUsecase(log, ur)
-	_userDelivery.NewUserHandler(v1, uu, log)
+	tr := newTvRepository()
+	tu := _tvUsecase.NewTvUsecase(log, tr)
+
+	storageDir := viper.GetString("storage.dir")
+	if storageDir == "" {
+		storageDir = "data"
+	}
+	bs := _blobStorage.NewLocalStorage(log, storageDir)
+
+	ur := newUserRepository()
+	uu := _userUsecase.NewUserUsecase(log, ur, mr, tr, bs)
+
+	pc, err := _posterCache.NewDiskCache(log, filepath.Join(storageDir, "poster-cache"), viper.GetInt64("poster.cache_bytes"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	pu := _posterUsecase.NewPosterUsecase(log, posterOrigin(), pc)
+
+	api.Register(v1, v2, api.Usecases{Movie: mu, Tv: tu, User: uu, Poster: pu}, log)
+	openapi.Serve(e, api.Document())
+	graph.NewGraphHandler(e.Group("/graphql"), mu, uu, graphLimits(), log)
+
+	if address := viper.GetString("grpc.address"); address != "" {
+		gs := rpc.NewServer(log, tokens)
+		_movieRpc.NewMovieServer(gs, mu)
+		_userRpc.NewUserServer(gs, uu)
+		go serveGRPC(gs, address)
+	}
 
 	log.Fatal(e.Start(viper.GetString(`server.address`)))
 }
+
+// serveGRPC runs the gRPC server for internal consumers beside the HTTP one.
+func serveGRPC(s *grpc.Server, address string) {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Fatal(s.Serve(lis))
+}
+
+// graphLimits reads the GraphQL query limits, keeping them on when they are
+// not configured.
+func graphLimits() graph.Limits {
+	viper.SetDefault("graphql.max_depth", 8)
+	viper.SetDefault("graphql.max_complexity", 1000)
+	return graph.Limits{
+		Depth:      viper.GetInt("graphql.max_depth"),
+		Complexity: viper.GetInt("graphql.max_complexity"),
+	}
+}
+
+// posterOrigin reads posters over HTTP when poster.origin is a URL and from
+// that directory otherwise.
+func posterOrigin() poster.Origin {
+	origin := viper.GetString("poster.origin")
+	if strings.HasPrefix(origin, "http://") || strings.HasPrefix(origin, "https://") {
+		return _posterOrigin.NewHTTPOrigin(origin)
+	}
+	return _posterOrigin.NewDirOrigin(origin)
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/null-like/movie-backend/migrations"
+)
+
+func migrate() {
+	err := migrations.Apply(context.Background(), log, db, dialect, schemaMap["movie"])
+	if err != nil {
+		log.Error(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"github.com/labstack/echo/v4"
+	"github.com/null-like/movie-backend/domain/apperror"
+	"strings"
+)
+
+var (
+	ErrMissingToken = apperror.Unauthorized("missing bearer token")
+	ErrInvalidToken = apperror.Unauthorized("invalid token")
+)
+
+// Tokens are the bearer tokens of the services allowed to call us, keyed by
+// service name. The HTTP middleware and the gRPC interceptor both check them
+// with Authenticate, so a token is accepted or rejected the same way on
+// either transport.
+type Tokens map[string]string
+
+// Service returns the service an Authorization header belongs to.
+func (t Tokens) Service(authorization string) (string, error) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", ErrMissingToken
+	}
+
+	// Every token is compared so the time taken doesn't tell which service's
+	// token was close.
+	service := ""
+	for name, want := range t {
+		if want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+			service = name
+		}
+	}
+	if service == "" {
+		return "", ErrInvalidToken
+	}
+	return service, nil
+}
+
+type serviceKey struct{}
+
+// Authenticate checks an Authorization header and returns ctx carrying the
+// service it belongs to.
+func (t Tokens) Authenticate(ctx context.Context, authorization string) (context.Context, error) {
+	service, err := t.Service(authorization)
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, serviceKey{}, service), nil
+}
+
+// ServiceFrom is the name of the calling service, empty when the request
+// carried no token.
+func ServiceFrom(ctx context.Context) string {
+	service, _ := ctx.Value(serviceKey{}).(string)
+	return service
+}
+
+// Middleware authenticates HTTP requests that carry an Authorization header.
+// The HTTP API is public, so requests without one go through anonymously.
+func (t Tokens) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		authorization := c.Request().Header.Get(echo.HeaderAuthorization)
+		if authorization == "" {
+			return next(c)
+		}
+		ctx, err := t.Authenticate(c.Request().Context(), authorization)
+		if err != nil {
+			return err
+		}
+		c.SetRequest(c.Request().WithContext(ctx))
+		return next(c)
+	}
+}
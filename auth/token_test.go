@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	tokens := Tokens{"billing": "secret", "disabled": ""}
+	for _, tc := range []struct {
+		name          string
+		authorization string
+		wantService   string
+		wantErr       error
+	}{
+		{"anonymous", "", "", nil},
+		{"service", "Bearer secret", "billing", nil},
+		{"lower case scheme", "bearer secret", "billing", nil},
+		{"wrong token", "Bearer guess", "", ErrInvalidToken},
+		{"empty token", "Bearer ", "", ErrMissingToken},
+		{"other scheme", "Basic secret", "", ErrMissingToken},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.authorization != "" {
+				req.Header.Set(echo.HeaderAuthorization, tc.authorization)
+			}
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			service := ""
+			err := tokens.Middleware(func(c echo.Context) error {
+				service = ServiceFrom(c.Request().Context())
+				return nil
+			})(c)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+			if service != tc.wantService {
+				t.Errorf("got service %q, want %q", service, tc.wantService)
+			}
+		})
+	}
+}
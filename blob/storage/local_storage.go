@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/null-like/movie-backend/blob"
+	"github.com/sirupsen/logrus"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type localStorage struct {
+	logger *logrus.Logger
+	dir    string
+}
+
+// NewLocalStorage stores blobs as files under dir, one file per key. The
+// content type is derived from the key's extension when reading back.
+func NewLocalStorage(l *logrus.Logger, dir string) blob.Storage {
+	return &localStorage{
+		logger: l,
+		dir:    dir,
+	}
+}
+
+// path maps a key onto a file under the storage directory, refusing keys
+// that would escape it.
+func (s *localStorage) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return "", blob.ErrInvalidKey
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes to a temporary file first so readers never see a partial blob.
+func (s *localStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(name), 0o755)
+	if err != nil {
+		s.logger.Error(err)
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		s.logger.Error(err)
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		s.logger.Error(err)
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), name)
+	if err != nil {
+		s.logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (s *localStorage) Get(ctx context.Context, key string) (io.ReadCloser, blob.Info, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, blob.Info{}, err
+	}
+
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, blob.Info{}, blob.ErrNotFound
+	}
+	if err != nil {
+		s.logger.Error(err)
+		return nil, blob.Info{}, err
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		s.logger.Error(err)
+		return nil, blob.Info{}, err
+	}
+
+	info := blob.Info{
+		Key:         key,
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+		Size:        stat.Size(),
+		ModTime:     stat.ModTime(),
+	}
+	return f, info, nil
+}
+
+func (s *localStorage) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		s.logger.Error(err)
+		return err
+	}
+	return nil
+}
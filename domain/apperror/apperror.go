@@ -0,0 +1,130 @@
+package apperror
+
+import (
+	"errors"
+	"strings"
+)
+
+// Kind says what went wrong in terms a client can act on.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindTooLarge
+	KindUnsupported
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not-found"
+	case KindConflict:
+		return "conflict"
+	case KindValidation:
+		return "validation"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindForbidden:
+		return "forbidden"
+	case KindTooLarge:
+		return "too-large"
+	case KindUnsupported:
+		return "unsupported"
+	default:
+		return "internal"
+	}
+}
+
+// Error is an error of a known kind. Domain packages declare their sentinel
+// errors with the constructors below, so errors.Is keeps working on them and
+// the delivery layer can tell a missing row from a broken database.
+type Error struct {
+	Kind    Kind
+	Message string
+	Fields  []FieldError
+}
+
+// FieldError is one request field that failed validation.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+func NotFound(message string) *Error {
+	return New(KindNotFound, message)
+}
+
+// Invalid is a validation error listing every field that failed.
+func Invalid(fields ...FieldError) *Error {
+	reasons := make([]string, len(fields))
+	for i, f := range fields {
+		reasons[i] = f.Field + " " + f.Message
+	}
+	return &Error{
+		Kind:    KindValidation,
+		Message: "invalid request: " + strings.Join(reasons, "; "),
+		Fields:  fields,
+	}
+}
+
+func Conflict(message string) *Error {
+	return New(KindConflict, message)
+}
+
+func Validation(message string) *Error {
+	return New(KindValidation, message)
+}
+
+func Unauthorized(message string) *Error {
+	return New(KindUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(KindForbidden, message)
+}
+
+func TooLarge(message string) *Error {
+	return New(KindTooLarge, message)
+}
+
+func Unsupported(message string) *Error {
+	return New(KindUnsupported, message)
+}
+
+// Internal is for failures the client can't fix; its message is not shown
+// to them.
+func Internal(message string) *Error {
+	return New(KindInternal, message)
+}
+
+// FieldsOf returns the failed fields of a validation error, if it has any.
+func FieldsOf(err error) []FieldError {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Fields
+	}
+	return nil
+}
+
+// KindOf finds the kind of the first *Error in err's chain. Errors that carry
+// no kind are internal.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindInternal
+}
@@ -0,0 +1,64 @@
+package movie
+
+type Person struct {
+	Id          int
+	Name        string
+	Gender      int
+	ProfilePath string
+}
+
+type Cast struct {
+	CreditId    string
+	PersonId    int
+	Name        string
+	Gender      int
+	ProfilePath string
+	Character   string
+	Order       int
+}
+
+type Crew struct {
+	CreditId    string
+	PersonId    int
+	Name        string
+	Gender      int
+	ProfilePath string
+	Job         string
+	Department  string
+}
+
+type Credits struct {
+	MovieId int
+	Cast    []Cast
+	Crew    []Crew
+}
+
+// Search roles accepted by FindMoviesByPersonName.
+const (
+	RoleActor    = "actor"
+	RoleDirector = "director"
+)
+
+type CastCredit struct {
+	MovieId     int
+	Title       string
+	Poster      string
+	ReleaseDate string
+	Character   string
+	Order       int
+}
+
+type CrewCredit struct {
+	MovieId     int
+	Title       string
+	Poster      string
+	ReleaseDate string
+	Job         string
+	Department  string
+}
+
+type PersonDetail struct {
+	Person
+	Cast []CastCredit
+	Crew []CrewCredit
+}
@@ -0,0 +1,11 @@
+package movie
+
+type Keyword struct {
+	Id   int
+	Name string
+}
+
+type MovieKeywords struct {
+	MovieId  int
+	Keywords []Keyword
+}
@@ -1,5 +1,15 @@
 package movie
 
+import "github.com/null-like/movie-backend/domain/apperror"
+
+const MediaType = "movie"
+
+var (
+	ErrMovieNotFound   = apperror.NotFound("movie not found")
+	ErrCompanyNotFound = apperror.NotFound("production company not found")
+	ErrPersonNotFound  = apperror.NotFound("person not found")
+)
+
 type Movie struct {
 	Id                  int
 	Adult               bool
@@ -15,6 +25,8 @@ type Movie struct {
 	Tagline             string
 	Rating              float32
 	Votes               int
+	Cast                []Cast
+	Crew                []Crew
 }
 
 type Genre struct {
@@ -27,3 +39,16 @@ type ProductionCompany struct {
 	Name    string
 	Country string
 }
+
+type GenreWithCount struct {
+	Id         int
+	Name       string
+	MovieCount int
+}
+
+type ProductionCompanyWithCount struct {
+	Id         int
+	Name       string
+	Country    string
+	MovieCount int
+}
@@ -0,0 +1,35 @@
+package movie
+
+import (
+	"github.com/null-like/movie-backend/domain/apperror"
+	"time"
+)
+
+// OriginalPosterSize serves the poster as the origin has it.
+const OriginalPosterSize = "original"
+
+// PosterWidths are the size variants a poster can be requested in, by name,
+// following the names TMDB uses for the same widths.
+var PosterWidths = map[string]int{
+	"w92":  92,
+	"w154": 154,
+	"w185": 185,
+	"w342": 342,
+	"w500": 500,
+	"w780": 780,
+}
+
+var (
+	ErrPosterNotFound    = apperror.NotFound("poster not found")
+	ErrInvalidPosterPath = apperror.Validation("invalid poster path")
+	ErrInvalidPosterSize = apperror.Validation("unknown poster size")
+)
+
+// PosterImage is a poster in one size variant. ETag is a strong validator
+// derived from Data.
+type PosterImage struct {
+	Data        []byte
+	ContentType string
+	ETag        string
+	ModTime     time.Time
+}
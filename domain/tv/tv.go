@@ -0,0 +1,54 @@
+package tv
+
+import "github.com/null-like/movie-backend/domain/apperror"
+
+const MediaType = "tv"
+
+var (
+	ErrSeriesNotFound  = apperror.NotFound("series not found")
+	ErrSeasonNotFound  = apperror.NotFound("season not found")
+	ErrEpisodeNotFound = apperror.NotFound("episode not found")
+)
+
+type Series struct {
+	Id               int
+	Adult            bool
+	Title            string
+	Language         string
+	Overview         string
+	Poster           string
+	FirstAirDate     string
+	LastAirDate      string
+	Status           string
+	NumberOfSeasons  int
+	NumberOfEpisodes int
+	Rating           float32
+	Votes            int
+	Seasons          []Season
+}
+
+type Season struct {
+	Id           int
+	SeriesId     int
+	SeasonNumber int
+	Title        string
+	Overview     string
+	Poster       string
+	AirDate      string
+	EpisodeCount int
+	Episodes     []Episode
+}
+
+type Episode struct {
+	Id            int
+	SeriesId      int
+	SeasonNumber  int
+	EpisodeNumber int
+	Title         string
+	Overview      string
+	Still         string
+	AirDate       string
+	Runtime       int
+	Rating        float32
+	Votes         int
+}
@@ -0,0 +1,58 @@
+package user
+
+import "github.com/null-like/movie-backend/domain/apperror"
+
+// Activity kinds. Ratings are the reviews in this service, so a rating event
+// covers both.
+const (
+	ActivityRating            = "rating"
+	ActivityFavorite          = "favorite"
+	ActivityPlaylistItem      = "playlist_item"
+	ActivityPlaylistPublished = "playlist_published"
+)
+
+// Activity visibility settings. Followers sees public and followers-only
+// activity; private activity never leaves the user.
+const (
+	ActivityPublic    = "public"
+	ActivityFollowers = "followers"
+	ActivityPrivate   = "private"
+)
+
+var (
+	ErrCannotFollowSelf          = apperror.Validation("users can't follow themselves")
+	ErrInvalidActivityVisibility = apperror.Validation("activity visibility must be public, followers or private")
+	ErrActivityHidden            = apperror.Forbidden("this user's activity is hidden")
+)
+
+// ActivityEvent is one entry in a feed. Fields that don't apply to the kind
+// are zero: Rating is only set for ratings, PlaylistId for playlist events.
+type ActivityEvent struct {
+	Id           int
+	UserId       int
+	Nickname     string
+	Kind         string
+	MediaId      int
+	Type         string
+	Rating       int
+	PlaylistId   int
+	PlaylistName string
+	CreatedAt    string
+}
+
+// Feed is a page of activity, newest first. NextBefore is passed back as
+// before to get the next page and is 0 on the last one.
+type Feed struct {
+	Events     []ActivityEvent
+	NextBefore int
+}
+
+type FollowUser struct {
+	Id         int
+	Nickname   string
+	FollowedAt string
+}
+
+func ValidActivityVisibility(v string) bool {
+	return v == ActivityPublic || v == ActivityFollowers || v == ActivityPrivate
+}
@@ -0,0 +1,28 @@
+package user
+
+import (
+	"fmt"
+	"github.com/null-like/movie-backend/domain/apperror"
+)
+
+// AvatarSizes are the square thumbnails made from every uploaded avatar, in
+// pixels.
+var AvatarSizes = []int{64, 128, 256}
+
+const (
+	MaxAvatarBytes     = 5 << 20
+	MaxAvatarDimension = 4096
+)
+
+var (
+	ErrAvatarTooLarge    = apperror.TooLarge("avatar must be at most 5MB and 4096x4096")
+	ErrUnsupportedImage  = apperror.Unsupported("avatar must be a JPEG, PNG or GIF image")
+	ErrAvatarNotFound    = apperror.NotFound("avatar not found")
+	ErrInvalidAvatarSize = apperror.Validation("unknown avatar size")
+)
+
+// AvatarKey is where the thumbnail of an avatar is kept in blob storage.
+// name is the file part of Profile.Avatar.
+func AvatarKey(userId int, size int, name string) string {
+	return fmt.Sprintf("avatars/%d/%d/%s", userId, size, name)
+}
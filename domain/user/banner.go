@@ -1,9 +1,52 @@
 package user
 
+import "github.com/null-like/movie-backend/domain/apperror"
+
+const (
+	AudienceAll      = "all"
+	AudienceLoggedIn = "logged-in"
+	AudienceRank     = "rank"
+)
+
+// BannerTimeLayout is the format of Banner.StartAt and Banner.EndAt.
+const BannerTimeLayout = "2006-01-02 15:04:05"
+
+var (
+	ErrInvalidAudience = apperror.Validation("audience must be all, logged-in or rank")
+	ErrMissingRank     = apperror.Validation("rank audience needs a target rank")
+	ErrInvalidSchedule = apperror.Validation("banner must start before it ends")
+	ErrInvalidLocale   = apperror.Validation("invalid locale")
+)
+
+// Banner is a home banner. StartAt and EndAt are optional; an empty value
+// leaves that side of the schedule open. An empty Locale shows the banner
+// in every locale. Banners sharing a non-empty Slot are variants of one
+// experiment and only one of them is shown to a viewer, picked by Weight.
 type Banner struct {
-	Id      int
-	MovieId int
-	Title   string
-	Type    string
-	Comment string
+	Id         int
+	MovieId    int
+	Title      string
+	Type       string
+	Comment    string
+	StartAt    string
+	EndAt      string
+	Priority   int
+	Audience   string
+	TargetRank string
+	Locale     string
+	Slot       string
+	Weight     int
+}
+
+// ShownTo reports whether a viewer matches the banner's audience. userId is 0
+// and rank empty for anonymous viewers.
+func (b Banner) ShownTo(userId int, rank string) bool {
+	switch b.Audience {
+	case AudienceLoggedIn:
+		return userId != 0
+	case AudienceRank:
+		return userId != 0 && rank == b.TargetRank
+	default:
+		return true
+	}
 }
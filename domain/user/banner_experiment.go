@@ -0,0 +1,65 @@
+package user
+
+import (
+	"fmt"
+	"github.com/null-like/movie-backend/domain/apperror"
+)
+
+const (
+	BannerEventImpression = "impression"
+	BannerEventClick      = "click"
+)
+
+var (
+	ErrBannerNotFound     = apperror.NotFound("banner not found")
+	ErrInvalidWeight      = apperror.Validation("banner weight must not be negative")
+	ErrInvalidBannerEvent = apperror.Validation("banner event must be impression or click")
+	ErrMissingVisitor     = apperror.Validation("user_id or a valid visitor cookie is required")
+	ErrInvalidSlot        = apperror.Validation("slot must be up to 32 letters, digits, - or _")
+)
+
+// BannerVariantStats is one variant's row in an experiment report. The first
+// variant of a slot is the control; the z-test fields compare the others
+// against it and are zero for the control itself.
+type BannerVariantStats struct {
+	BannerId    int
+	Title       string
+	Weight      int
+	Impressions int
+	Clicks      int
+	CTR         float64
+	ZScore      float64
+	PValue      float64
+	Significant bool
+}
+
+type BannerReport struct {
+	Slot     string
+	Variants []BannerVariantStats
+}
+
+// BannerSubject is the key a variant assignment is stored under: the user for
+// logged in viewers, otherwise the anonymous visitor cookie.
+func BannerSubject(userId int, visitorId string) (string, error) {
+	if userId != 0 {
+		return fmt.Sprintf("user:%d", userId), nil
+	}
+	if !validVisitorId(visitorId) {
+		return "", ErrMissingVisitor
+	}
+	return "anon:" + visitorId, nil
+}
+
+// validVisitorId accepts the hex ids the handler hands out in the visitor
+// cookie, so a forged cookie can't smuggle anything else into a query.
+func validVisitorId(id string) bool {
+	if id == "" || len(id) > 58 {
+		return false
+	}
+	for _, c := range id {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
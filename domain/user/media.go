@@ -0,0 +1,8 @@
+package user
+
+import "github.com/null-like/movie-backend/domain/apperror"
+
+var (
+	ErrUnknownMediaType = apperror.Validation("unknown media type")
+	ErrMediaNotFound    = apperror.NotFound("media does not exist")
+)
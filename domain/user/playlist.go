@@ -1,8 +1,102 @@
 package user
 
+import "github.com/null-like/movie-backend/domain/apperror"
+
+const (
+	VisibilityPrivate  = "private"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPublic   = "public"
+)
+
+const (
+	PlaylistRoleEditor = "editor"
+	PlaylistRoleViewer = "viewer"
+)
+
+// Actions recorded in PlaylistChange.
+const (
+	PlaylistActionAdd    = "add"
+	PlaylistActionRemove = "remove"
+	PlaylistActionMove   = "move"
+)
+
+var (
+	ErrPlaylistNotFound  = apperror.NotFound("playlist not found")
+	ErrNotPlaylistOwner  = apperror.Forbidden("only the owner can change this playlist")
+	ErrInvalidVisibility = apperror.Validation("visibility must be private, unlisted or public")
+
+	ErrPlaylistItemNotFound = apperror.NotFound("playlist item not found")
+	ErrPlaylistItemExists   = apperror.Conflict("this title is already in the playlist")
+	ErrInvalidPosition      = apperror.Validation("position is out of range")
+
+	ErrNotPlaylistEditor       = apperror.Forbidden("only the owner or an editor can change this playlist")
+	ErrInvalidPlaylistRole     = apperror.Validation("role must be editor or viewer")
+	ErrPlaylistMemberNotFound  = apperror.NotFound("user is not a member of this playlist")
+	ErrPlaylistVersionConflict = apperror.Conflict("playlist was changed by someone else, reload and try again")
+
+	ErrInvalidShareSlug = apperror.Validation("invalid share link")
+)
+
 type Playlist struct {
-	Id       int
-	Name     string
-	Playlist string
-	Type     string
+	Id         int
+	UserId     int
+	Name       string
+	Type       string
+	Visibility string
+	Version    int
+	ShareSlug  string
+	ViewCount  int
+	ForkedFrom int
+	Items      []PlaylistItem
+}
+
+type PlaylistItem struct {
+	Id         int
+	PlaylistId int
+	MediaId    int
+	Type       string
+	Position   int
+	Note       string
+	AddedBy    int
+	AddedAt    string
+}
+
+type PlaylistMember struct {
+	PlaylistId int
+	UserId     int
+	Nickname   string
+	Role       string
+	InvitedBy  int
+	AddedAt    string
+}
+
+type PlaylistChange struct {
+	Id         int
+	PlaylistId int
+	UserId     int
+	Action     string
+	ItemId     int
+	MediaId    int
+	Type       string
+	Version    int
+	CreatedAt  string
+}
+
+func ValidVisibility(visibility string) bool {
+	switch visibility {
+	case VisibilityPrivate, VisibilityUnlisted, VisibilityPublic:
+		return true
+	default:
+		return false
+	}
+}
+
+func ValidPlaylistRole(role string) bool {
+	return role == PlaylistRoleEditor || role == PlaylistRoleViewer
+}
+
+// VisibleTo reports whether userId may read the playlist. Unlisted playlists
+// are readable by anyone who knows their id but are left out of listings.
+func (p Playlist) VisibleTo(userId int) bool {
+	return p.Visibility != VisibilityPrivate || p.UserId == userId
 }
@@ -0,0 +1,39 @@
+package user
+
+import "github.com/null-like/movie-backend/domain/apperror"
+
+// MaxBioLength is the longest bio a user can set, in characters.
+const MaxBioLength = 500
+
+var ErrBioTooLong = apperror.Validation("bio is longer than 500 characters")
+
+// Profile is the public view of a user. PlaylistCount only counts public
+// playlists and WatchedRuntime is the total minutes of movies watched,
+// rewatches included. Avatar is empty or "<user id>/<file>", served in each
+// of AvatarSizes at /avatar/<size>/<user id>/<file>.
+type Profile struct {
+	Id                 int
+	Nickname           string
+	Avatar             string
+	Bio                string
+	JoinDate           string
+	RatingCount        int
+	FavoriteCount      int
+	PlaylistCount      int
+	RatingDistribution []RatingBucket
+	FavoriteGenres     []GenreAffinity
+	WatchedRuntime     int
+}
+
+type RatingBucket struct {
+	Rating int
+	Count  int
+}
+
+// GenreAffinity is a genre among the movies a user rated.
+type GenreAffinity struct {
+	Id            int
+	Name          string
+	RatedCount    int
+	AverageRating float64
+}
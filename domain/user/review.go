@@ -1,5 +1,9 @@
 package user
 
+import "github.com/null-like/movie-backend/domain/apperror"
+
+var ErrRatingNotFound = apperror.NotFound("title is not rated")
+
 type Rate struct {
 	Id        int
 	Rating    int
@@ -0,0 +1,28 @@
+package user
+
+import (
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	tvDomain "github.com/null-like/movie-backend/domain/tv"
+)
+
+// SharedPlaylist is the read-only view served for a share link, with every
+// item resolved to its catalog entry.
+type SharedPlaylist struct {
+	Id            int
+	Name          string
+	Type          string
+	OwnerId       int
+	OwnerNickname string
+	ViewCount     int
+	ForkedFrom    int
+	Items         []SharedPlaylistItem
+}
+
+type SharedPlaylistItem struct {
+	Position int
+	Note     string
+	AddedAt  string
+	Type     string
+	Movie    *movieDomain.Movie `json:",omitempty"`
+	Series   *tvDomain.Series   `json:",omitempty"`
+}
@@ -1,5 +1,13 @@
 package user
 
+import "github.com/null-like/movie-backend/domain/apperror"
+
+var (
+	ErrUserNotFound       = apperror.NotFound("user not found")
+	ErrEmailTaken         = apperror.Conflict("email is already registered")
+	ErrInvalidCredentials = apperror.Unauthorized("wrong email or password")
+)
+
 type User struct {
 	Id       int
 	Email    string
@@ -0,0 +1,37 @@
+package user
+
+import "github.com/null-like/movie-backend/domain/apperror"
+
+const (
+	WatchStateWant    = "want"
+	WatchStateWatched = "watched"
+)
+
+// WatchTimeLayout is the format of watch dates.
+const WatchTimeLayout = "2006-01-02 15:04:05"
+
+var (
+	ErrInvalidWatchState  = apperror.Validation("state must be want or watched")
+	ErrInvalidWatchDate   = apperror.Validation("invalid watched_at date")
+	ErrWatchEntryNotFound = apperror.NotFound("title is not on the watchlist")
+)
+
+// WatchEntry is a title on a user's watchlist. WatchCount is the number of
+// times it has been watched, so RewatchCount is one less once watched.
+type WatchEntry struct {
+	MediaId       int
+	Type          string
+	State         string
+	WatchCount    int
+	RewatchCount  int
+	LastWatchedAt string
+	AddedAt       string
+}
+
+// WatchEvent is one viewing in a user's watch history.
+type WatchEvent struct {
+	Id        int
+	MediaId   int
+	Type      string
+	WatchedAt string
+}
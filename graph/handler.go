@@ -0,0 +1,122 @@
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"github.com/graph-gophers/graphql-go"
+	gqlErrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/labstack/echo/v4"
+	"github.com/null-like/movie-backend/domain/apperror"
+	"github.com/null-like/movie-backend/movie"
+	"github.com/null-like/movie-backend/request"
+	"github.com/null-like/movie-backend/user"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// visitorCookie is the cookie the REST API gives anonymous viewers, read here
+// so banners stay the same across both.
+const visitorCookie = "visitor_id"
+
+type graphHandler struct {
+	schema       *graphql.Schema
+	limiter      *limiter
+	movieUsecase movie.Usecase
+	userUsecase  user.Usecase
+	logger       *logrus.Logger
+}
+
+// GraphRequest is a GraphQL query posted as JSON.
+type GraphRequest struct {
+	Query         string                 `json:"query" validate:"notblank"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func NewGraphHandler(g *echo.Group, mu movie.Usecase, uu user.Usecase, limits Limits, logger *logrus.Logger) {
+	limiter, err := newLimiter(schemaSDL, limits)
+	if err != nil {
+		panic(err)
+	}
+
+	root := &rootResolver{movieUsecase: mu, userUsecase: uu}
+	handler := &graphHandler{
+		schema:       graphql.MustParseSchema(schemaSDL, root),
+		limiter:      limiter,
+		movieUsecase: mu,
+		userUsecase:  uu,
+		logger:       logger,
+	}
+	g.POST("", handler.Query)
+}
+
+// Query runs a query with loaders of its own, so batching and caching never
+// span requests. Like any GraphQL server it answers 200 with the errors in
+// the body once the request itself is well formed.
+func (h *graphHandler) Query(c echo.Context) error {
+	var req GraphRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.limiter.check(req.Query, req.OperationName, req.Variables); err != nil {
+		return c.JSON(http.StatusOK, &graphql.Response{Errors: []*gqlErrors.QueryError{{
+			Message:    err.Message,
+			Extensions: map[string]interface{}{"code": err.Limit},
+		}}})
+	}
+
+	visitor := ""
+	if cookie, err := c.Cookie(visitorCookie); err == nil {
+		visitor = cookie.Value
+	}
+	ctx := withVisitor(c.Request().Context(), visitor)
+	ctx = withLoaders(ctx, newLoaders(h.movieUsecase, h.userUsecase))
+
+	res := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	for _, err := range res.Errors {
+		h.describe(err)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// describe tags a resolver error with its kind, as the problem type does for
+// REST, and hides the message of internal ones after logging it.
+func (h *graphHandler) describe(err *gqlErrors.QueryError) {
+	if err.ResolverError == nil {
+		return
+	}
+
+	kind := apperror.KindOf(err.ResolverError)
+	if err.Extensions == nil {
+		err.Extensions = map[string]interface{}{}
+	}
+	err.Extensions["code"] = kind.String()
+	if fields := apperror.FieldsOf(err.ResolverError); len(fields) > 0 {
+		params := make([]map[string]string, len(fields))
+		for i, f := range fields {
+			params[i] = map[string]string{"name": f.Field, "reason": f.Message}
+		}
+		err.Extensions["invalid-params"] = params
+	}
+
+	if kind == apperror.KindInternal {
+		h.logger.WithField("path", err.Path).Error(err.ResolverError)
+		err.Message = http.StatusText(http.StatusInternalServerError)
+	}
+}
+
+type visitorKey struct{}
+
+func withVisitor(ctx context.Context, visitor string) context.Context {
+	return context.WithValue(ctx, visitorKey{}, visitor)
+}
+
+// visitorFrom is the anonymous viewer's id, empty when they have none yet.
+func visitorFrom(ctx context.Context) string {
+	visitor, _ := ctx.Value(visitorKey{}).(string)
+	return visitor
+}
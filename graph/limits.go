@@ -0,0 +1,136 @@
+package graph
+
+import (
+	"fmt"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"strings"
+)
+
+// defaultListSize is the length assumed for a list field without a limit
+// argument, like a user's favorites.
+const defaultListSize = 20
+
+// Limits bound the queries the endpoint runs. Zero leaves a limit off.
+//
+// Depth counts nested fields. Complexity estimates the cost of a query: every
+// field costs 1 and a list field multiplies the cost of its selection by the
+// number of items it may return, so asking for the genres of each favorite of
+// each playlist item adds up quickly even within the depth limit.
+// Introspection is answered from the schema and counts towards neither.
+type Limits struct {
+	Depth      int
+	Complexity int
+}
+
+// LimitError is a query rejected for exceeding a limit.
+type LimitError struct {
+	Limit   string
+	Message string
+}
+
+func (e *LimitError) Error() string {
+	return e.Message
+}
+
+type limiter struct {
+	schema *ast.Schema
+	limits Limits
+}
+
+func newLimiter(sdl string, limits Limits) (*limiter, error) {
+	schema, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: sdl})
+	if err != nil {
+		return nil, err
+	}
+	return &limiter{schema: schema, limits: limits}, nil
+}
+
+// check fails when the operation goes over a limit. Queries that don't parse
+// or validate pass, leaving the executor to report why.
+func (l *limiter) check(query, operationName string, variables map[string]interface{}) *LimitError {
+	doc, errs := gqlparser.LoadQuery(l.schema, query)
+	if len(errs) > 0 {
+		return nil
+	}
+	op := doc.Operations.ForName(operationName)
+	if op == nil {
+		return nil
+	}
+
+	if d := depth(op.SelectionSet); l.limits.Depth > 0 && d > l.limits.Depth {
+		return &LimitError{Limit: "depth", Message: fmt.Sprintf("query depth %d exceeds the limit of %d", d, l.limits.Depth)}
+	}
+	if c := complexity(op.SelectionSet, variables); l.limits.Complexity > 0 && c > l.limits.Complexity {
+		return &LimitError{Limit: "complexity", Message: fmt.Sprintf("query complexity %d exceeds the limit of %d", c, l.limits.Complexity)}
+	}
+	return nil
+}
+
+func depth(selections ast.SelectionSet) int {
+	deepest := 0
+	for _, selection := range selections {
+		d := 0
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			d = 1 + depth(s.SelectionSet)
+		case *ast.InlineFragment:
+			d = depth(s.SelectionSet)
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				d = depth(s.Definition.SelectionSet)
+			}
+		}
+		if d > deepest {
+			deepest = d
+		}
+	}
+	return deepest
+}
+
+func complexity(selections ast.SelectionSet, variables map[string]interface{}) int {
+	total := 0
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			cost := complexity(s.SelectionSet, variables)
+			if s.Definition != nil && s.Definition.Type.Elem != nil {
+				cost *= listSize(s, variables)
+			}
+			total += 1 + cost
+		case *ast.InlineFragment:
+			total += complexity(s.SelectionSet, variables)
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				total += complexity(s.Definition.SelectionSet, variables)
+			}
+		}
+	}
+	return total
+}
+
+// listSize is how many items a list field may return: its limit, the number
+// of ids it is given or defaultListSize.
+func listSize(field *ast.Field, variables map[string]interface{}) int {
+	args := field.ArgumentMap(variables)
+	if ids, ok := args["ids"].([]interface{}); ok {
+		return len(ids)
+	}
+	size := defaultListSize
+	switch limit := args["limit"].(type) {
+	case int64:
+		size = int(limit)
+	case float64:
+		size = int(limit)
+	}
+	if size < 0 {
+		return 0
+	}
+	return size
+}
@@ -0,0 +1,133 @@
+package graph
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"github.com/null-like/movie-backend/problem"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLimiterDepth(t *testing.T) {
+	l, err := newLimiter(schemaSDL, Limits{Depth: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{"under", `{ movie(id: 1) { genres { id } } }`, false},
+		{"at", `{ movie(id: 1) { genres { movies { id } } } }`, false},
+		{"over", `{ movie(id: 1) { genres { movies { genres { id } } } } }`, true},
+		{"over through a fragment", `
+			query { movie(id: 1) { ...deep } }
+			fragment deep on Movie { genres { movies { genres { id } } } }
+			`, true},
+		{"introspection", `{ movie(id: 1) { genres { movies { id __typename } } } __schema { types { fields { type { name } } } } }`, false},
+		{"invalid", `{ movie(id: 1) { genres { movies { genres { nope } } } } }`, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := l.check(tc.query, "", nil)
+			if (err != nil) != tc.want {
+				t.Fatalf("got %v, want rejected %v", err, tc.want)
+			}
+			if err != nil && err.Limit != "depth" {
+				t.Errorf("got limit %q, want depth", err.Limit)
+			}
+		})
+	}
+}
+
+func TestLimiterComplexity(t *testing.T) {
+	l, err := newLimiter(schemaSDL, Limits{Complexity: 101})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := func(n int) map[string]interface{} {
+		list := make([]interface{}, n)
+		for i := range list {
+			list[i] = float64(i + 1)
+		}
+		return map[string]interface{}{"ids": list}
+	}
+	const byIds = `query($ids: [Int!]!) { movies(ids: $ids) { id title } }`
+	const byLimit = `query($limit: Int) { genres { movies(limit: $limit) { id } } }`
+
+	for _, tc := range []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		want      bool
+	}{
+		// 1 for movies and 2 for each of the ids.
+		{"ids under", byIds, ids(49), false},
+		{"ids at", byIds, ids(50), false},
+		{"ids over", byIds, ids(51), true},
+		// 1 for genres and, for each of the 20 genres assumed, 1 for movies
+		// and 1 for each of the movies.
+		{"limit at", byLimit, map[string]interface{}{"limit": float64(4)}, false},
+		{"limit over", byLimit, map[string]interface{}{"limit": float64(5)}, true},
+		{"inline limit over", `{ genres { movies(limit: 5) { id } } }`, nil, true},
+		{"default limit over", `{ genres { movies { id } } }`, nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := l.check(tc.query, "", tc.variables)
+			if (err != nil) != tc.want {
+				t.Fatalf("got %v, want rejected %v", err, tc.want)
+			}
+			if err != nil && err.Limit != "complexity" {
+				t.Errorf("got limit %q, want complexity", err.Limit)
+			}
+		})
+	}
+}
+
+func TestLimitsOff(t *testing.T) {
+	l, err := newLimiter(schemaSDL, Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.check(`{ genres { movies(limit: 1000) { genres { movies { genres { id } } } } } }`, "", nil); err != nil {
+		t.Errorf("got %v with the limits off", err)
+	}
+}
+
+func TestQueryOverLimit(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	e := echo.New()
+	e.HTTPErrorHandler = problem.HTTPErrorHandler(logger)
+	NewGraphHandler(e.Group("/graphql"), nil, nil, Limits{Depth: 2}, logger)
+
+	body := `{"query": "{ movie(id: 1) { genres { id } } }"}`
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body)
+	}
+
+	var res struct {
+		Data   interface{}
+		Errors []struct {
+			Message    string
+			Extensions map[string]interface{}
+		}
+	}
+	err := json.Unmarshal(rec.Body.Bytes(), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Data != nil || len(res.Errors) != 1 || res.Errors[0].Extensions["code"] != "depth" {
+		t.Errorf("got %s, want a depth error and no data", rec.Body)
+	}
+}
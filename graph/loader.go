@@ -0,0 +1,137 @@
+package graph
+
+import (
+	"context"
+	"github.com/graph-gophers/dataloader/v7"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	userDomain "github.com/null-like/movie-backend/domain/user"
+	"github.com/null-like/movie-backend/movie"
+	"github.com/null-like/movie-backend/user"
+	"time"
+)
+
+// batchWait is how long a loader collects keys before running its batch.
+// Sibling fields resolve concurrently, so a short wait is enough.
+const batchWait = 2 * time.Millisecond
+
+// loaders batch and cache the lookups of one request. Each batch is one
+// usecase call taking every key collected, so a list of users or playlists
+// costs the same few queries as a single one.
+type loaders struct {
+	movies        *dataloader.Loader[int, movieDomain.Movie]
+	profiles      *dataloader.Loader[int, userDomain.Profile]
+	favorites     *dataloader.Loader[int, []userDomain.Favorite]
+	ratings       *dataloader.Loader[int, []userDomain.Rate]
+	playlistItems *dataloader.Loader[playlistKey, []userDomain.PlaylistItem]
+}
+
+// playlistKey is a playlist as seen by a viewer, who needs to be able to read
+// it for its items to load.
+type playlistKey struct {
+	viewerId   int
+	playlistId int
+}
+
+type loadersKey struct{}
+
+func newLoaders(mu movie.Usecase, uu user.Usecase) *loaders {
+	return &loaders{
+		movies: dataloader.NewBatchedLoader(func(ctx context.Context, ids []int) []*dataloader.Result[movieDomain.Movie] {
+			results := make([]*dataloader.Result[movieDomain.Movie], len(ids))
+			movies, err := mu.GetMovies(ctx, ids)
+			if err != nil {
+				for i := range results {
+					results[i] = &dataloader.Result[movieDomain.Movie]{Error: err}
+				}
+				return results
+			}
+
+			byId := make(map[int]movieDomain.Movie, len(movies))
+			for _, m := range movies {
+				byId[m.Id] = m
+			}
+			for i, id := range ids {
+				m, ok := byId[id]
+				if !ok {
+					results[i] = &dataloader.Result[movieDomain.Movie]{Error: movieDomain.ErrMovieNotFound}
+					continue
+				}
+				results[i] = &dataloader.Result[movieDomain.Movie]{Data: m}
+			}
+			return results
+		}, dataloader.WithWait[int, movieDomain.Movie](batchWait)),
+
+		profiles: byIds(func(ctx context.Context, ids []int) (map[int]userDomain.Profile, error) {
+			profiles, err := uu.GetProfiles(ctx, ids)
+			byId := make(map[int]userDomain.Profile, len(profiles))
+			for _, p := range profiles {
+				byId[p.Id] = p
+			}
+			return byId, err
+		}, userDomain.ErrUserNotFound),
+		favorites: byIds(uu.GetFavoritesByUserIds, nil),
+		ratings:   byIds(uu.GetRatingListsByUserIds, nil),
+
+		playlistItems: dataloader.NewBatchedLoader(func(ctx context.Context, keys []playlistKey) []*dataloader.Result[[]userDomain.PlaylistItem] {
+			// What a playlist holds depends on who asks, so the keys are
+			// fetched in one call per viewer.
+			byViewer := map[int][]int{}
+			for _, key := range keys {
+				byViewer[key.viewerId] = append(byViewer[key.viewerId], key.playlistId)
+			}
+			items := map[playlistKey][]userDomain.PlaylistItem{}
+			errs := map[int]error{}
+			for viewerId, playlistIds := range byViewer {
+				byPlaylist, err := uu.GetPlaylistItemsByPlaylistIds(ctx, viewerId, playlistIds)
+				errs[viewerId] = err
+				for playlistId, list := range byPlaylist {
+					items[playlistKey{viewerId: viewerId, playlistId: playlistId}] = list
+				}
+			}
+
+			results := make([]*dataloader.Result[[]userDomain.PlaylistItem], len(keys))
+			for i, key := range keys {
+				list, ok := items[key]
+				switch {
+				case errs[key.viewerId] != nil:
+					results[i] = &dataloader.Result[[]userDomain.PlaylistItem]{Error: errs[key.viewerId]}
+				case !ok:
+					results[i] = &dataloader.Result[[]userDomain.PlaylistItem]{Error: userDomain.ErrPlaylistNotFound}
+				default:
+					results[i] = &dataloader.Result[[]userDomain.PlaylistItem]{Data: list}
+				}
+			}
+			return results
+		}, dataloader.WithWait[playlistKey, []userDomain.PlaylistItem](batchWait)),
+	}
+}
+
+// byIds makes a loader that fetches every id of a batch in one call. An id
+// the result leaves out fails with notFound, or loads the zero value when
+// notFound is nil, as for a user without favorites.
+func byIds[V any](fetch func(ctx context.Context, ids []int) (map[int]V, error), notFound error) *dataloader.Loader[int, V] {
+	return dataloader.NewBatchedLoader(func(ctx context.Context, ids []int) []*dataloader.Result[V] {
+		results := make([]*dataloader.Result[V], len(ids))
+		values, err := fetch(ctx, ids)
+		for i, id := range ids {
+			v, ok := values[id]
+			switch {
+			case err != nil:
+				results[i] = &dataloader.Result[V]{Error: err}
+			case !ok && notFound != nil:
+				results[i] = &dataloader.Result[V]{Error: notFound}
+			default:
+				results[i] = &dataloader.Result[V]{Data: v}
+			}
+		}
+		return results
+	}, dataloader.WithWait[int, V](batchWait))
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,392 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"github.com/null-like/movie-backend/domain/apperror"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	userDomain "github.com/null-like/movie-backend/domain/user"
+	"github.com/null-like/movie-backend/movie"
+	"github.com/null-like/movie-backend/user"
+)
+
+// maxListSize caps the limit argument of paged fields, as in the REST API.
+const maxListSize = 100
+
+// rootResolver resolves Query. GraphQL Ints are int32, hence the conversions
+// around the usecases.
+type rootResolver struct {
+	movieUsecase movie.Usecase
+	userUsecase  user.Usecase
+}
+
+func (r *rootResolver) Movie(ctx context.Context, args struct{ Id int32 }) (*movieResolver, error) {
+	return r.loadMovie(ctx, int(args.Id), movieDomain.MediaType)
+}
+
+func (r *rootResolver) Movies(ctx context.Context, args struct{ Ids []int32 }) ([]*movieResolver, error) {
+	if len(args.Ids) > maxListSize {
+		return nil, apperror.Invalid(apperror.FieldError{Field: "ids", Message: "must have at most 100 ids"})
+	}
+
+	var movies []*movieResolver
+	for _, id := range args.Ids {
+		m, err := r.loadMovie(ctx, int(id), movieDomain.MediaType)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			movies = append(movies, m)
+		}
+	}
+	return movies, nil
+}
+
+func (r *rootResolver) Genres(ctx context.Context) ([]*genreResolver, error) {
+	genres, err := r.movieUsecase.GetGenres(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*genreResolver, len(genres))
+	for i, g := range genres {
+		count := int32(g.MovieCount)
+		resolvers[i] = &genreResolver{root: r, genre: movieDomain.Genre{Id: g.Id, Name: g.Name}, movieCount: &count}
+	}
+	return resolvers, nil
+}
+
+func (r *rootResolver) User(ctx context.Context, args struct{ Id int32 }) (*userResolver, error) {
+	return r.loadUser(ctx, int(args.Id))
+}
+
+// loadUser resolves a user reference, nil when the user no longer exists.
+func (r *rootResolver) loadUser(ctx context.Context, id int) (*userResolver, error) {
+	profile, err := loadersFrom(ctx).profiles.Load(ctx, id)()
+	if errors.Is(err, userDomain.ErrUserNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &userResolver{root: r, profile: profile}, nil
+}
+
+// loadMovie resolves a movie reference, nil when the title is a series or the
+// movie no longer exists.
+func (r *rootResolver) loadMovie(ctx context.Context, id int, mediaType string) (*movieResolver, error) {
+	if mediaType != movieDomain.MediaType {
+		return nil, nil
+	}
+
+	m, err := loadersFrom(ctx).movies.Load(ctx, id)()
+	if errors.Is(err, movieDomain.ErrMovieNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &movieResolver{root: r, movie: m}, nil
+}
+
+func (r *rootResolver) Playlist(ctx context.Context, args struct {
+	Id       int32
+	ViewerId *int32
+}) (*playlistResolver, error) {
+	viewerId := 0
+	if args.ViewerId != nil {
+		viewerId = int(*args.ViewerId)
+	}
+
+	playlist, err := r.userUsecase.GetPlaylist(ctx, viewerId, int(args.Id))
+	if errors.Is(err, userDomain.ErrPlaylistNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &playlistResolver{root: r, viewerId: viewerId, playlist: playlist}, nil
+}
+
+func (r *rootResolver) PublicPlaylists(ctx context.Context) ([]*playlistResolver, error) {
+	playlists, err := r.userUsecase.GetPublicPlaylists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.playlists(0, playlists), nil
+}
+
+func (r *rootResolver) playlists(viewerId int, playlists []userDomain.Playlist) []*playlistResolver {
+	resolvers := make([]*playlistResolver, len(playlists))
+	for i, p := range playlists {
+		resolvers[i] = &playlistResolver{root: r, viewerId: viewerId, playlist: p}
+	}
+	return resolvers
+}
+
+func (r *rootResolver) Banners(ctx context.Context, args struct {
+	UserId *int32
+	Locale *string
+}) ([]*bannerResolver, error) {
+	userId, locale := 0, ""
+	if args.UserId != nil {
+		userId = int(*args.UserId)
+	}
+	if args.Locale != nil {
+		locale = *args.Locale
+	}
+
+	banners, err := r.userUsecase.GetActiveBanners(ctx, userId, visitorFrom(ctx), locale)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*bannerResolver, len(banners))
+	for i, b := range banners {
+		resolvers[i] = &bannerResolver{root: r, banner: b}
+	}
+	return resolvers, nil
+}
+
+type movieResolver struct {
+	root  *rootResolver
+	movie movieDomain.Movie
+}
+
+func (m *movieResolver) Id() int32            { return int32(m.movie.Id) }
+func (m *movieResolver) Title() string        { return m.movie.Title }
+func (m *movieResolver) Overview() string     { return m.movie.Overview }
+func (m *movieResolver) Poster() string       { return m.movie.Poster }
+func (m *movieResolver) ReleaseDate() string  { return m.movie.ReleaseDate }
+func (m *movieResolver) Runtime() int32       { return int32(m.movie.Runtime) }
+func (m *movieResolver) Language() string     { return m.movie.Language }
+func (m *movieResolver) Tagline() string      { return m.movie.Tagline }
+func (m *movieResolver) Adult() bool          { return m.movie.Adult }
+func (m *movieResolver) VoteAverage() float64 { return float64(m.movie.Rating) }
+func (m *movieResolver) VoteCount() int32     { return int32(m.movie.Votes) }
+
+// Genres goes through the movie loader, as movies listed by genre are read
+// without theirs.
+func (m *movieResolver) Genres(ctx context.Context) ([]*genreResolver, error) {
+	movie, err := loadersFrom(ctx).movies.Load(ctx, m.movie.Id)()
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*genreResolver, len(movie.Genres))
+	for i, g := range movie.Genres {
+		resolvers[i] = &genreResolver{root: m.root, genre: g}
+	}
+	return resolvers, nil
+}
+
+func (m *movieResolver) IsFavorite(ctx context.Context, args struct{ UserId int32 }) (bool, error) {
+	favorites, err := loadersFrom(ctx).favorites.Load(ctx, int(args.UserId))()
+	if err != nil {
+		return false, err
+	}
+	for _, f := range favorites {
+		if f.Id == m.movie.Id && f.Type == movieDomain.MediaType {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *movieResolver) UserRating(ctx context.Context, args struct{ UserId int32 }) (*int32, error) {
+	ratings, err := loadersFrom(ctx).ratings.Load(ctx, int(args.UserId))()
+	if err != nil {
+		return nil, err
+	}
+	for _, rating := range ratings {
+		if rating.Id == m.movie.Id && rating.Type == movieDomain.MediaType {
+			value := int32(rating.Rating)
+			return &value, nil
+		}
+	}
+	return nil, nil
+}
+
+type genreResolver struct {
+	root       *rootResolver
+	genre      movieDomain.Genre
+	movieCount *int32
+}
+
+func (g *genreResolver) Id() int32          { return int32(g.genre.Id) }
+func (g *genreResolver) Name() string       { return g.genre.Name }
+func (g *genreResolver) MovieCount() *int32 { return g.movieCount }
+
+func (g *genreResolver) Movies(ctx context.Context, args struct {
+	Offset int32
+	Limit  int32
+}) ([]*movieResolver, error) {
+	var fields []apperror.FieldError
+	if args.Offset < 0 {
+		fields = append(fields, apperror.FieldError{Field: "offset", Message: "must be at least 0"})
+	}
+	if args.Limit < 0 || args.Limit > maxListSize {
+		fields = append(fields, apperror.FieldError{Field: "limit", Message: "must be between 0 and 100"})
+	}
+	if len(fields) > 0 {
+		return nil, apperror.Invalid(fields...)
+	}
+
+	movies, err := g.root.movieUsecase.GetMoviesByGenre(ctx, g.genre.Id, int(args.Offset), int(args.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*movieResolver, len(movies))
+	for i, m := range movies {
+		resolvers[i] = &movieResolver{root: g.root, movie: m}
+	}
+	return resolvers, nil
+}
+
+type userResolver struct {
+	root    *rootResolver
+	profile userDomain.Profile
+}
+
+func (u *userResolver) Id() int32            { return int32(u.profile.Id) }
+func (u *userResolver) Nickname() string     { return u.profile.Nickname }
+func (u *userResolver) Avatar() string       { return u.profile.Avatar }
+func (u *userResolver) Bio() string          { return u.profile.Bio }
+func (u *userResolver) JoinDate() string     { return u.profile.JoinDate }
+func (u *userResolver) RatingCount() int32   { return int32(u.profile.RatingCount) }
+func (u *userResolver) FavoriteCount() int32 { return int32(u.profile.FavoriteCount) }
+
+func (u *userResolver) Favorites(ctx context.Context) ([]*favoriteResolver, error) {
+	favorites, err := loadersFrom(ctx).favorites.Load(ctx, u.profile.Id)()
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*favoriteResolver, len(favorites))
+	for i, f := range favorites {
+		resolvers[i] = &favoriteResolver{root: u.root, favorite: f}
+	}
+	return resolvers, nil
+}
+
+func (u *userResolver) Ratings(ctx context.Context) ([]*ratingResolver, error) {
+	ratings, err := loadersFrom(ctx).ratings.Load(ctx, u.profile.Id)()
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*ratingResolver, len(ratings))
+	for i, rating := range ratings {
+		resolvers[i] = &ratingResolver{root: u.root, rating: rating}
+	}
+	return resolvers, nil
+}
+
+// Playlists are the ones viewerId may see, only public ones for an anonymous
+// viewer.
+func (u *userResolver) Playlists(ctx context.Context, args struct{ ViewerId *int32 }) ([]*playlistResolver, error) {
+	viewerId := 0
+	if args.ViewerId != nil {
+		viewerId = int(*args.ViewerId)
+	}
+
+	playlists, err := u.root.userUsecase.GetUserPlaylists(ctx, viewerId, u.profile.Id)
+	if err != nil {
+		return nil, err
+	}
+	return u.root.playlists(viewerId, playlists), nil
+}
+
+type favoriteResolver struct {
+	root     *rootResolver
+	favorite userDomain.Favorite
+}
+
+func (f *favoriteResolver) MediaId() int32 { return int32(f.favorite.Id) }
+func (f *favoriteResolver) Type() string   { return f.favorite.Type }
+
+func (f *favoriteResolver) Movie(ctx context.Context) (*movieResolver, error) {
+	return f.root.loadMovie(ctx, f.favorite.Id, f.favorite.Type)
+}
+
+type ratingResolver struct {
+	root   *rootResolver
+	rating userDomain.Rate
+}
+
+func (r *ratingResolver) MediaId() int32  { return int32(r.rating.Id) }
+func (r *ratingResolver) Type() string    { return r.rating.Type }
+func (r *ratingResolver) Rating() int32   { return int32(r.rating.Rating) }
+func (r *ratingResolver) RatedAt() string { return r.rating.ApplyDate }
+
+func (r *ratingResolver) Movie(ctx context.Context) (*movieResolver, error) {
+	return r.root.loadMovie(ctx, r.rating.Id, r.rating.Type)
+}
+
+type playlistResolver struct {
+	root     *rootResolver
+	viewerId int
+	playlist userDomain.Playlist
+}
+
+func (p *playlistResolver) Id() int32          { return int32(p.playlist.Id) }
+func (p *playlistResolver) Name() string       { return p.playlist.Name }
+func (p *playlistResolver) Type() string       { return p.playlist.Type }
+func (p *playlistResolver) Visibility() string { return p.playlist.Visibility }
+func (p *playlistResolver) Version() int32     { return int32(p.playlist.Version) }
+
+func (p *playlistResolver) Owner(ctx context.Context) (*userResolver, error) {
+	return p.root.loadUser(ctx, p.playlist.UserId)
+}
+
+// Items are already there for a playlist read on its own and loaded for one
+// in a list.
+func (p *playlistResolver) Items(ctx context.Context) ([]*playlistItemResolver, error) {
+	items := p.playlist.Items
+	if items == nil {
+		var err error
+		items, err = loadersFrom(ctx).playlistItems.Load(ctx, playlistKey{viewerId: p.viewerId, playlistId: p.playlist.Id})()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resolvers := make([]*playlistItemResolver, len(items))
+	for i, item := range items {
+		resolvers[i] = &playlistItemResolver{root: p.root, item: item}
+	}
+	return resolvers, nil
+}
+
+type playlistItemResolver struct {
+	root *rootResolver
+	item userDomain.PlaylistItem
+}
+
+func (i *playlistItemResolver) Id() int32       { return int32(i.item.Id) }
+func (i *playlistItemResolver) Position() int32 { return int32(i.item.Position) }
+func (i *playlistItemResolver) MediaId() int32  { return int32(i.item.MediaId) }
+func (i *playlistItemResolver) Type() string    { return i.item.Type }
+func (i *playlistItemResolver) Note() string    { return i.item.Note }
+func (i *playlistItemResolver) AddedAt() string { return i.item.AddedAt }
+
+func (i *playlistItemResolver) Movie(ctx context.Context) (*movieResolver, error) {
+	return i.root.loadMovie(ctx, i.item.MediaId, i.item.Type)
+}
+
+type bannerResolver struct {
+	root   *rootResolver
+	banner userDomain.Banner
+}
+
+func (b *bannerResolver) Id() int32       { return int32(b.banner.Id) }
+func (b *bannerResolver) Title() string   { return b.banner.Title }
+func (b *bannerResolver) Type() string    { return b.banner.Type }
+func (b *bannerResolver) Comment() string { return b.banner.Comment }
+func (b *bannerResolver) Priority() int32 { return int32(b.banner.Priority) }
+func (b *bannerResolver) Slot() string    { return b.banner.Slot }
+
+func (b *bannerResolver) Movie(ctx context.Context) (*movieResolver, error) {
+	return b.root.loadMovie(ctx, b.banner.MovieId, b.banner.Type)
+}
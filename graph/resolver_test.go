@@ -0,0 +1,240 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	userDomain "github.com/null-like/movie-backend/domain/user"
+	"github.com/null-like/movie-backend/movie"
+	_movieRepo "github.com/null-like/movie-backend/movie/repository"
+	_movieUsecase "github.com/null-like/movie-backend/movie/usecase"
+	"github.com/null-like/movie-backend/problem"
+	"github.com/null-like/movie-backend/user"
+	_userRepo "github.com/null-like/movie-backend/user/repository"
+	_userUsecase "github.com/null-like/movie-backend/user/usecase"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// serveGraph runs the endpoint on memory repositories, with the user usecase
+// passed through wrap when it is given.
+func serveGraph(t *testing.T, wrap func(user.Usecase) user.Usecase) (*echo.Echo, user.Repository, movie.Repository) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	mr := _movieRepo.NewMemoryMovieRepository()
+	ur := _userRepo.NewMemoryUserRepository(mr)
+
+	uu := _userUsecase.NewUserUsecase(logger, ur, mr, nil, nil)
+	if wrap != nil {
+		uu = wrap(uu)
+	}
+
+	e := echo.New()
+	e.HTTPErrorHandler = problem.HTTPErrorHandler(logger)
+	NewGraphHandler(e.Group("/graphql"), _movieUsecase.NewMovieUsecase(logger, mr), uu, Limits{}, logger)
+	return e, ur, mr
+}
+
+// runQuery posts query and decodes the data of the response into data.
+func runQuery(t *testing.T, e *echo.Echo, query string, data interface{}) {
+	t.Helper()
+	body, err := json.Marshal(GraphRequest{Query: query})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var res struct {
+		Data   json.RawMessage
+		Errors []interface{}
+	}
+	err = json.Unmarshal(rec.Body.Bytes(), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || len(res.Errors) > 0 {
+		t.Fatalf("%s: got status %d: %s", query, rec.Code, rec.Body)
+	}
+	err = json.Unmarshal(res.Data, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUserPlaylistsVisibility(t *testing.T) {
+	e, ur, _ := serveGraph(t, nil)
+	ctx := context.Background()
+	err := ur.InsertUser(ctx, userDomain.User{Email: "a@example.com", Password: "hash", Nickname: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, _, _, _, _, err := ur.FindIdAndPasswdByEmail(ctx, "a@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, visibility := range []string{userDomain.VisibilityPublic, userDomain.VisibilityUnlisted, userDomain.VisibilityPrivate} {
+		id, err := ur.InsertPlaylist(ctx, owner, visibility, movieDomain.MediaType, visibility)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ur.InsertPlaylistItem(ctx, id, 0, owner, 10, movieDomain.MediaType, visibility+" note")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		args string
+		want []string
+	}{
+		{"anonymous", "", []string{userDomain.VisibilityPublic}},
+		{"someone else", "(viewerId: " + strconv.Itoa(owner+1) + ")", []string{userDomain.VisibilityPublic}},
+		{"owner", "(viewerId: " + strconv.Itoa(owner) + ")", []string{
+			userDomain.VisibilityPrivate, userDomain.VisibilityPublic, userDomain.VisibilityUnlisted,
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var data struct {
+				User struct {
+					Playlists []struct {
+						Visibility string
+						Items      []struct{ Note string }
+					}
+				}
+			}
+			runQuery(t, e, `{ user(id: `+strconv.Itoa(owner)+`) { playlists`+tc.args+` { visibility items { note } } } }`, &data)
+
+			var got []string
+			for _, p := range data.User.Playlists {
+				got = append(got, p.Visibility)
+				if len(p.Items) != 1 || p.Items[0].Note != p.Visibility+" note" {
+					t.Errorf("%s playlist: got items %v", p.Visibility, p.Items)
+				}
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+				t.Errorf("got playlists %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// countingUsecase counts the batch calls the loaders make.
+type countingUsecase struct {
+	user.Usecase
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (c *countingUsecase) count(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[name]++
+}
+
+func (c *countingUsecase) GetProfiles(ctx context.Context, ids []int) ([]userDomain.Profile, error) {
+	c.count("GetProfiles")
+	return c.Usecase.GetProfiles(ctx, ids)
+}
+
+func (c *countingUsecase) GetFavoritesByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Favorite, error) {
+	c.count("GetFavoritesByUserIds")
+	return c.Usecase.GetFavoritesByUserIds(ctx, userIds)
+}
+
+func (c *countingUsecase) GetRatingListsByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Rate, error) {
+	c.count("GetRatingListsByUserIds")
+	return c.Usecase.GetRatingListsByUserIds(ctx, userIds)
+}
+
+func (c *countingUsecase) GetPlaylistItemsByPlaylistIds(ctx context.Context, requesterId int, playlistIds []int) (map[int][]userDomain.PlaylistItem, error) {
+	c.count("GetPlaylistItemsByPlaylistIds")
+	return c.Usecase.GetPlaylistItemsByPlaylistIds(ctx, requesterId, playlistIds)
+}
+
+func TestLoadersBatch(t *testing.T) {
+	counter := &countingUsecase{calls: map[string]int{}}
+	e, ur, mr := serveGraph(t, func(uu user.Usecase) user.Usecase {
+		counter.Usecase = uu
+		return counter
+	})
+
+	ctx := context.Background()
+	err := mr.UpsertMovies(ctx, []movieDomain.Movie{{Id: 10, Title: "Heat"}, {Id: 20, Title: "Ronin"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const users = 3
+	for i := 0; i < users; i++ {
+		email := strconv.Itoa(i) + "@example.com"
+		err = ur.InsertUser(ctx, userDomain.User{Email: email, Password: "hash", Nickname: "user" + strconv.Itoa(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, _, _, _, _, err := ur.FindIdAndPasswdByEmail(ctx, email)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mediaId := 10 * (1 + i%2)
+		for _, err := range []error{
+			ur.InsertFavorite(ctx, id, mediaId, movieDomain.MediaType),
+			ur.InsertRating(ctx, id, mediaId, 7, movieDomain.MediaType),
+		} {
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		playlistId, err := ur.InsertPlaylist(ctx, id, "List", movieDomain.MediaType, userDomain.VisibilityPublic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ur.InsertPlaylistItem(ctx, playlistId, 0, id, mediaId, movieDomain.MediaType, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var data struct {
+		PublicPlaylists []struct {
+			Items []struct{ Movie struct{ Title string } }
+			Owner struct {
+				Nickname  string
+				Favorites []struct{ Movie struct{ Title string } }
+				Ratings   []struct{ Rating int }
+			}
+		}
+	}
+	runQuery(t, e, `{ publicPlaylists {
+		items { movie { title } }
+		owner { nickname favorites { movie { title } } ratings { rating } }
+	} }`, &data)
+
+	if len(data.PublicPlaylists) != users {
+		t.Fatalf("got %d playlists, want %d", len(data.PublicPlaylists), users)
+	}
+	for _, p := range data.PublicPlaylists {
+		if p.Owner.Nickname == "" || len(p.Owner.Favorites) != 1 || p.Owner.Favorites[0].Movie.Title == "" ||
+			len(p.Owner.Ratings) != 1 || len(p.Items) != 1 || p.Items[0].Movie.Title == "" {
+			t.Errorf("incomplete playlist %+v", p)
+		}
+	}
+	for _, name := range []string{"GetProfiles", "GetFavoritesByUserIds", "GetRatingListsByUserIds", "GetPlaylistItemsByPlaylistIds"} {
+		if counter.calls[name] != 1 {
+			t.Errorf("%s: got %d calls, want 1", name, counter.calls[name])
+		}
+	}
+}
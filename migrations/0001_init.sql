@@ -0,0 +1,61 @@
+CREATE TABLE IF NOT EXISTS User (
+	id          INT          NOT NULL AUTO_INCREMENT,
+	email       VARCHAR(255) NOT NULL,
+	password    CHAR(64)     NOT NULL,
+	nickname    VARCHAR(50)  NOT NULL,
+	rank        VARCHAR(20)  NOT NULL,
+	signup_date DATETIME     NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY uq_user_email (email)
+);
+
+CREATE TABLE IF NOT EXISTS Movie (
+	id                   INT          NOT NULL,
+	adult                BOOLEAN      NOT NULL DEFAULT FALSE,
+	genres               TEXT,
+	title                VARCHAR(255) NOT NULL,
+	language             VARCHAR(10)  NOT NULL DEFAULT '',
+	overview             TEXT,
+	poster               VARCHAR(255) NOT NULL DEFAULT '',
+	production_companies TEXT,
+	release_date         VARCHAR(10)  NOT NULL DEFAULT '',
+	revenue              BIGINT       NOT NULL DEFAULT 0,
+	runtime              INT          NOT NULL DEFAULT 0,
+	tagline              VARCHAR(255) NOT NULL DEFAULT '',
+	rating               FLOAT        NOT NULL DEFAULT 0,
+	votes                INT          NOT NULL DEFAULT 0,
+	PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS Favorite (
+	user_id  INT        NOT NULL,
+	movie_id INT        NOT NULL,
+	type     VARCHAR(8) NOT NULL,
+	PRIMARY KEY (user_id, movie_id, type)
+);
+
+CREATE TABLE IF NOT EXISTS Rate (
+	user_id    INT        NOT NULL,
+	movie_id   INT        NOT NULL,
+	rating     INT        NOT NULL,
+	type       VARCHAR(8) NOT NULL,
+	apply_date DATETIME   NOT NULL,
+	PRIMARY KEY (user_id, movie_id, type)
+);
+
+CREATE TABLE IF NOT EXISTS Playlist (
+	id   INT          NOT NULL AUTO_INCREMENT,
+	name VARCHAR(255) NOT NULL,
+	list TEXT,
+	type VARCHAR(8)   NOT NULL DEFAULT 'movie',
+	PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS Banner (
+	id       INT          NOT NULL AUTO_INCREMENT,
+	movie_id INT          NOT NULL,
+	title    VARCHAR(255) NOT NULL,
+	type     VARCHAR(8)   NOT NULL,
+	comment  VARCHAR(255) NOT NULL DEFAULT '',
+	PRIMARY KEY (id)
+);
@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS Series (
+	id                 INT          NOT NULL,
+	adult              BOOLEAN      NOT NULL DEFAULT FALSE,
+	title              VARCHAR(255) NOT NULL,
+	language           VARCHAR(10)  NOT NULL DEFAULT '',
+	overview           TEXT,
+	poster             VARCHAR(255) NOT NULL DEFAULT '',
+	first_air_date     VARCHAR(10)  NOT NULL DEFAULT '',
+	last_air_date      VARCHAR(10)  NOT NULL DEFAULT '',
+	status             VARCHAR(32)  NOT NULL DEFAULT '',
+	number_of_seasons  INT          NOT NULL DEFAULT 0,
+	number_of_episodes INT          NOT NULL DEFAULT 0,
+	rating             FLOAT        NOT NULL DEFAULT 0,
+	votes              INT          NOT NULL DEFAULT 0,
+	PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS Season (
+	id            INT          NOT NULL,
+	series_id     INT          NOT NULL,
+	season_number INT          NOT NULL,
+	title         VARCHAR(255) NOT NULL,
+	overview      TEXT,
+	poster        VARCHAR(255) NOT NULL DEFAULT '',
+	air_date      VARCHAR(10)  NOT NULL DEFAULT '',
+	episode_count INT          NOT NULL DEFAULT 0,
+	PRIMARY KEY (id),
+	UNIQUE KEY uq_season_number (series_id, season_number)
+);
+
+CREATE TABLE IF NOT EXISTS Episode (
+	id             INT          NOT NULL,
+	series_id      INT          NOT NULL,
+	season_number  INT          NOT NULL,
+	episode_number INT          NOT NULL,
+	title          VARCHAR(255) NOT NULL,
+	overview       TEXT,
+	still          VARCHAR(255) NOT NULL DEFAULT '',
+	air_date       VARCHAR(10)  NOT NULL DEFAULT '',
+	runtime        INT          NOT NULL DEFAULT 0,
+	rating         FLOAT        NOT NULL DEFAULT 0,
+	votes          INT          NOT NULL DEFAULT 0,
+	PRIMARY KEY (id),
+	UNIQUE KEY uq_episode_number (series_id, season_number, episode_number)
+);
@@ -0,0 +1,70 @@
+CREATE TABLE IF NOT EXISTS Genre (
+	id   INT          NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS ProductionCompany (
+	id      INT          NOT NULL,
+	name    VARCHAR(255) NOT NULL,
+	country VARCHAR(8)   NOT NULL DEFAULT '',
+	PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS MovieGenre (
+	movie_id INT NOT NULL,
+	genre_id INT NOT NULL,
+	PRIMARY KEY (movie_id, genre_id),
+	KEY idx_movie_genre_genre (genre_id)
+);
+
+CREATE TABLE IF NOT EXISTS MovieProductionCompany (
+	movie_id   INT NOT NULL,
+	company_id INT NOT NULL,
+	PRIMARY KEY (movie_id, company_id),
+	KEY idx_movie_company_company (company_id)
+);
+
+CREATE TABLE IF NOT EXISTS Keyword (
+	id   INT          NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS MovieKeyword (
+	movie_id   INT NOT NULL,
+	keyword_id INT NOT NULL,
+	PRIMARY KEY (movie_id, keyword_id),
+	KEY idx_movie_keyword_keyword (keyword_id)
+);
+
+CREATE TABLE IF NOT EXISTS Person (
+	id           INT          NOT NULL,
+	name         VARCHAR(255) NOT NULL,
+	gender       INT          NOT NULL DEFAULT 0,
+	profile_path VARCHAR(255) NOT NULL DEFAULT '',
+	PRIMARY KEY (id),
+	KEY idx_person_name (name)
+);
+
+CREATE TABLE IF NOT EXISTS MovieCast (
+	credit_id      VARCHAR(32)  NOT NULL,
+	movie_id       INT          NOT NULL,
+	person_id      INT          NOT NULL,
+	character_name VARCHAR(512) NOT NULL DEFAULT '',
+	cast_order     INT          NOT NULL DEFAULT 0,
+	PRIMARY KEY (credit_id),
+	KEY idx_movie_cast_movie (movie_id),
+	KEY idx_movie_cast_person (person_id)
+);
+
+CREATE TABLE IF NOT EXISTS MovieCrew (
+	credit_id  VARCHAR(32)  NOT NULL,
+	movie_id   INT          NOT NULL,
+	person_id  INT          NOT NULL,
+	job        VARCHAR(255) NOT NULL DEFAULT '',
+	department VARCHAR(255) NOT NULL DEFAULT '',
+	PRIMARY KEY (credit_id),
+	KEY idx_movie_crew_movie (movie_id),
+	KEY idx_movie_crew_person (person_id)
+);
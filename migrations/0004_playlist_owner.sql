@@ -0,0 +1,9 @@
+-- Playlists created before ownership existed keep user_id 0: they stay public
+-- and can no longer be changed through the API.
+ALTER TABLE Playlist
+	ADD COLUMN user_id    INT        NOT NULL DEFAULT 0 AFTER id,
+	ADD COLUMN visibility VARCHAR(8) NOT NULL DEFAULT 'public';
+
+CREATE INDEX idx_playlist_user ON Playlist (user_id);
+
+ALTER TABLE Playlist ALTER COLUMN visibility SET DEFAULT 'private';
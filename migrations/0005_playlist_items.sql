@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS PlaylistItem (
+	id          INT          NOT NULL AUTO_INCREMENT,
+	playlist_id INT          NOT NULL,
+	media_id    INT          NOT NULL,
+	type        VARCHAR(8)   NOT NULL,
+	position    INT          NOT NULL,
+	note        VARCHAR(512) NOT NULL DEFAULT '',
+	added_at    DATETIME     NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY uq_playlist_item_media (playlist_id, media_id, type),
+	KEY idx_playlist_item_position (playlist_id, position)
+);
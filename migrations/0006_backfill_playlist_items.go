@@ -0,0 +1,139 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	goMigrations["0006_backfill_playlist_items.go"] = backfillPlaylistItems
+}
+
+type legacyPlaylistEntry struct {
+	Id   int
+	Type string
+}
+
+// backfillPlaylistItems copies every Playlist.list string into PlaylistItem
+// rows, keeping the order of the list. A list holding an entry that isn't a
+// media id fails the migration. An entry already in the playlist is skipped
+// and counted, and positions stay contiguous over the entries kept.
+func backfillPlaylistItems(ctx context.Context, logger *logrus.Logger, conn *sql.Conn, dialect Dialect) error {
+	rows, err := conn.QueryContext(ctx, `SELECT id, COALESCE(list, ''), type FROM Playlist`)
+	if err != nil {
+		return err
+	}
+
+	lists := map[int][]legacyPlaylistEntry{}
+	for rows.Next() {
+		var id int
+		var list, mediaType string
+		err = rows.Scan(&id, &list, &mediaType)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		lists[id], err = parseLegacyPlaylist(list, mediaType)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("playlist %d: %w", id, err)
+		}
+	}
+	err = rows.Close()
+	if err != nil {
+		return err
+	}
+
+	insert := `
+		INSERT IGNORE INTO PlaylistItem (playlist_id, media_id, type, position, note, added_at)
+		VALUES (?, ?, ?, ?, '', now())
+		`
+	switch dialect {
+	case SQLite:
+		insert = `
+		INSERT OR IGNORE INTO PlaylistItem (playlist_id, media_id, type, position, note, added_at)
+		VALUES (?, ?, ?, ?, '', CURRENT_TIMESTAMP)
+		`
+	case Postgres:
+		insert = `
+		INSERT INTO PlaylistItem (playlist_id, media_id, type, position, note, added_at)
+		VALUES ($1, $2, $3, $4, '', now())
+		ON CONFLICT DO NOTHING
+		`
+	}
+
+	var copied, skipped int
+	for playlistId, entries := range lists {
+		position := 0
+		for _, entry := range entries {
+			result, err := conn.ExecContext(ctx, insert, playlistId, entry.Id, entry.Type, position+1)
+			if err != nil {
+				return err
+			}
+			n, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				logger.Warnf("playlist %d: skipped duplicate %s %d", playlistId, entry.Type, entry.Id)
+				skipped++
+				continue
+			}
+			position++
+			copied++
+		}
+	}
+	logger.Infof("copied %d playlist entries, skipped %d duplicates", copied, skipped)
+	return nil
+}
+
+// parseLegacyPlaylist understands the shapes the list column was written in:
+// a JSON array of ids, a JSON array of {"id", "type"} objects, or ids
+// separated by commas or whitespace. Anything else is an error.
+func parseLegacyPlaylist(list string, mediaType string) ([]legacyPlaylistEntry, error) {
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return nil, nil
+	}
+
+	var entries []legacyPlaylistEntry
+	if strings.HasPrefix(list, "[") {
+		var raw []json.RawMessage
+		if json.Unmarshal([]byte(list), &raw) == nil {
+			for _, item := range raw {
+				var id int
+				if json.Unmarshal(item, &id) == nil && id > 0 {
+					entries = append(entries, legacyPlaylistEntry{Id: id, Type: mediaType})
+					continue
+				}
+				var entry legacyPlaylistEntry
+				if json.Unmarshal(item, &entry) != nil || entry.Id <= 0 {
+					return nil, fmt.Errorf("can't parse entry %s", item)
+				}
+				if entry.Type == "" {
+					entry.Type = mediaType
+				}
+				entries = append(entries, entry)
+			}
+			return entries, nil
+		}
+	}
+
+	fields := strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '[' || r == ']'
+	})
+	for _, field := range fields {
+		id, err := strconv.Atoi(strings.Trim(field, `"'`))
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("can't parse entry %q", field)
+		}
+		entries = append(entries, legacyPlaylistEntry{Id: id, Type: mediaType})
+	}
+	return entries, nil
+}
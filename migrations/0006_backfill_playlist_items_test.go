@@ -0,0 +1,169 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/sirupsen/logrus"
+)
+
+// legacyPlaylists applies the migrations and gives Playlist its list column
+// back, as if 0006 hadn't run yet.
+func legacyPlaylists(t *testing.T, lists ...string) (*sql.DB, *sql.Conn) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db")+"?_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	logger := logrus.New()
+	logger.SetOutput(&bytes.Buffer{})
+	ctx := context.Background()
+	err = Apply(ctx, logger, db, SQLite, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.ExecContext(ctx, `ALTER TABLE Playlist ADD COLUMN list TEXT`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, list := range lists {
+		_, err = db.ExecContext(ctx, `INSERT INTO Playlist (id, user_id, name, type, list) VALUES (?, 1, 'List', 'movie', ?)`, i+1, list)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return db, conn
+}
+
+func playlistItems(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query(`SELECT playlist_id, position, type, media_id FROM PlaylistItem ORDER BY playlist_id, position`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var playlistId, position, mediaId int
+		var mediaType string
+		err = rows.Scan(&playlistId, &position, &mediaType, &mediaId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, fmt.Sprintf("%d.%d %s %d", playlistId, position, mediaType, mediaId))
+	}
+	return items
+}
+
+func TestBackfillPlaylistItems(t *testing.T) {
+	db, conn := legacyPlaylists(t, `[10, 20, 10]`, `[{"id": 30, "type": "tv"}, {"id": 40}]`, `50, 60 50`, ``)
+
+	var log bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&log)
+	err := backfillPlaylistItems(context.Background(), logger, conn, SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"1.1 movie 10", "1.2 movie 20", "2.1 tv 30", "2.2 movie 40", "3.1 movie 50", "3.2 movie 60"}
+	if got := playlistItems(t, db); !reflect.DeepEqual(got, want) {
+		t.Errorf("got items %q, want %q", got, want)
+	}
+	if !strings.Contains(log.String(), "copied 6 playlist entries, skipped 2 duplicates") {
+		t.Errorf("log doesn't count the duplicates:\n%s", log.String())
+	}
+}
+
+func TestBackfillPlaylistItemsFailsOnUnparsedEntry(t *testing.T) {
+	for _, list := range []string{`[10, "twenty"]`, `[10, {"type": "tv"}]`, `10, twenty`, `10, -1`} {
+		t.Run(list, func(t *testing.T) {
+			db, conn := legacyPlaylists(t, list)
+			err := backfillPlaylistItems(context.Background(), logrus.New(), conn, SQLite)
+			if err == nil {
+				t.Errorf("got items %q, want an error", playlistItems(t, db))
+			}
+		})
+	}
+}
+
+func TestDropPlaylistList(t *testing.T) {
+	ctx := context.Background()
+	logger := logrus.New()
+	logger.SetOutput(&bytes.Buffer{})
+
+	t.Run("after the backfill", func(t *testing.T) {
+		db, conn := legacyPlaylists(t, `[10, 20]`)
+		err := backfillPlaylistItems(ctx, logger, conn, SQLite)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = dropPlaylistList(ctx, logger, conn, SQLite)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = db.ExecContext(ctx, `SELECT list FROM Playlist`)
+		if err == nil {
+			t.Error("the list column is still there")
+		}
+	})
+
+	t.Run("entry missing", func(t *testing.T) {
+		db, conn := legacyPlaylists(t, `[10, 20]`)
+		err := backfillPlaylistItems(ctx, logger, conn, SQLite)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = db.ExecContext(ctx, `DELETE FROM PlaylistItem WHERE media_id = 20`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = dropPlaylistList(ctx, logger, conn, SQLite)
+		if err == nil {
+			t.Error("got the column dropped, want an error")
+		}
+		_, err = db.ExecContext(ctx, `SELECT list FROM Playlist`)
+		if err != nil {
+			t.Errorf("the list column is gone: %v", err)
+		}
+	})
+
+	t.Run("playlist changed since", func(t *testing.T) {
+		db, conn := legacyPlaylists(t, `[10, 20]`)
+		_, err := db.ExecContext(ctx, `UPDATE Playlist SET version = 2`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = dropPlaylistList(ctx, logger, conn, SQLite)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("dropped by 0007", func(t *testing.T) {
+		db, conn := legacyPlaylists(t, `[10, 20]`)
+		_, err := db.ExecContext(ctx, `INSERT INTO SchemaMigration (version, applied_at) VALUES ('0007_drop_playlist_list.sql', CURRENT_TIMESTAMP)`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = dropPlaylistList(ctx, logger, conn, SQLite)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
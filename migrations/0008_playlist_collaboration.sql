@@ -0,0 +1,30 @@
+ALTER TABLE Playlist ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+ALTER TABLE PlaylistItem ADD COLUMN added_by INT NOT NULL DEFAULT 0;
+
+UPDATE PlaylistItem
+SET added_by = (SELECT user_id FROM Playlist WHERE Playlist.id = PlaylistItem.playlist_id);
+
+CREATE TABLE IF NOT EXISTS PlaylistMember (
+	playlist_id INT        NOT NULL,
+	user_id     INT        NOT NULL,
+	role        VARCHAR(8) NOT NULL,
+	invited_by  INT        NOT NULL,
+	added_at    DATETIME   NOT NULL,
+	PRIMARY KEY (playlist_id, user_id),
+	KEY idx_playlist_member_user (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS PlaylistChange (
+	id          INT        NOT NULL AUTO_INCREMENT,
+	playlist_id INT        NOT NULL,
+	user_id     INT        NOT NULL,
+	action      VARCHAR(8) NOT NULL,
+	item_id     INT        NOT NULL,
+	media_id    INT        NOT NULL,
+	type        VARCHAR(8) NOT NULL,
+	version     INT        NOT NULL,
+	created_at  DATETIME   NOT NULL,
+	PRIMARY KEY (id),
+	KEY idx_playlist_change_playlist (playlist_id, id)
+);
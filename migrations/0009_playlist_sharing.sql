@@ -0,0 +1,6 @@
+ALTER TABLE Playlist
+	ADD COLUMN share_slug  VARCHAR(32) NULL,
+	ADD COLUMN view_count  INT         NOT NULL DEFAULT 0,
+	ADD COLUMN forked_from INT         NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX uq_playlist_share_slug ON Playlist (share_slug);
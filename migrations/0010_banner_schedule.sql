@@ -0,0 +1,9 @@
+ALTER TABLE Banner
+	ADD COLUMN start_at    DATETIME    NULL,
+	ADD COLUMN end_at      DATETIME    NULL,
+	ADD COLUMN priority    INT         NOT NULL DEFAULT 0,
+	ADD COLUMN audience    VARCHAR(16) NOT NULL DEFAULT 'all',
+	ADD COLUMN target_rank VARCHAR(20) NOT NULL DEFAULT '',
+	ADD COLUMN locale      VARCHAR(16) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_banner_schedule ON Banner (start_at, end_at);
@@ -0,0 +1,23 @@
+ALTER TABLE Banner
+	ADD COLUMN slot   VARCHAR(32) NOT NULL DEFAULT '',
+	ADD COLUMN weight INT         NOT NULL DEFAULT 1;
+
+CREATE INDEX idx_banner_slot ON Banner (slot);
+
+CREATE TABLE IF NOT EXISTS BannerAssignment (
+	slot        VARCHAR(32) NOT NULL,
+	subject     VARCHAR(64) NOT NULL,
+	banner_id   INT         NOT NULL,
+	assigned_at DATETIME    NOT NULL,
+	PRIMARY KEY (slot, subject)
+);
+
+CREATE TABLE IF NOT EXISTS BannerEvent (
+	id         INT         NOT NULL AUTO_INCREMENT,
+	banner_id  INT         NOT NULL,
+	subject    VARCHAR(64) NOT NULL,
+	kind       VARCHAR(16) NOT NULL,
+	created_at DATETIME    NOT NULL,
+	PRIMARY KEY (id),
+	KEY idx_banner_event_banner (banner_id, kind)
+);
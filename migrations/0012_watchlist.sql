@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS Watchlist (
+	user_id         INT        NOT NULL,
+	media_id        INT        NOT NULL,
+	type            VARCHAR(8) NOT NULL,
+	state           VARCHAR(8) NOT NULL,
+	watch_count     INT        NOT NULL DEFAULT 0,
+	last_watched_at DATETIME   NULL,
+	added_at        DATETIME   NOT NULL,
+	PRIMARY KEY (user_id, media_id, type),
+	KEY idx_watchlist_state (user_id, state, added_at)
+);
+
+CREATE TABLE IF NOT EXISTS WatchHistory (
+	id         INT        NOT NULL AUTO_INCREMENT,
+	user_id    INT        NOT NULL,
+	media_id   INT        NOT NULL,
+	type       VARCHAR(8) NOT NULL,
+	watched_at DATETIME   NOT NULL,
+	PRIMARY KEY (id),
+	KEY idx_watch_history_user (user_id, watched_at)
+);
@@ -0,0 +1,22 @@
+ALTER TABLE User ADD COLUMN activity_visibility VARCHAR(16) NOT NULL DEFAULT 'public';
+
+CREATE TABLE IF NOT EXISTS Follow (
+	follower_id INT      NOT NULL,
+	followee_id INT      NOT NULL,
+	created_at  DATETIME NOT NULL,
+	PRIMARY KEY (follower_id, followee_id),
+	KEY idx_follow_followee (followee_id)
+);
+
+CREATE TABLE IF NOT EXISTS ActivityEvent (
+	id          INT         NOT NULL AUTO_INCREMENT,
+	user_id     INT         NOT NULL,
+	kind        VARCHAR(24) NOT NULL,
+	media_id    INT         NOT NULL DEFAULT 0,
+	type        VARCHAR(8)  NOT NULL DEFAULT '',
+	rating      INT         NOT NULL DEFAULT 0,
+	playlist_id INT         NOT NULL DEFAULT 0,
+	created_at  DATETIME    NOT NULL,
+	PRIMARY KEY (id),
+	KEY idx_activity_user (user_id, id)
+);
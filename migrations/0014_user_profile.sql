@@ -0,0 +1,3 @@
+ALTER TABLE User
+	ADD COLUMN avatar VARCHAR(255) NOT NULL DEFAULT '',
+	ADD COLUMN bio    VARCHAR(500) NOT NULL DEFAULT '';
@@ -0,0 +1,108 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/null-like/movie-backend/movie/importer"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	goMigrations["0015_backfill_movie_catalog.go"] = backfillMovieCatalog
+}
+
+// backfillMovieCatalog copies the legacy Movie.genres and
+// Movie.production_companies values into the Genre, ProductionCompany,
+// MovieGenre and MovieProductionCompany tables the repositories read. Values
+// that don't parse are skipped; rows already there are kept.
+func backfillMovieCatalog(ctx context.Context, _ *logrus.Logger, conn *sql.Conn, dialect Dialect) error {
+	rows, err := conn.QueryContext(ctx, `
+		SELECT id, COALESCE(genres, ''), COALESCE(production_companies, '')
+		FROM Movie
+		WHERE COALESCE(genres, '') <> '' OR COALESCE(production_companies, '') <> ''
+		`)
+	if err != nil {
+		return err
+	}
+
+	type legacyCatalog struct {
+		genres    string
+		companies string
+	}
+	movies := map[int]legacyCatalog{}
+	for rows.Next() {
+		var id int
+		var c legacyCatalog
+		err = rows.Scan(&id, &c.genres, &c.companies)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		movies[id] = c
+	}
+	err = rows.Close()
+	if err != nil {
+		return err
+	}
+
+	insertGenre := insertIgnore(dialect, "Genre", "id", "name")
+	insertMovieGenre := insertIgnore(dialect, "MovieGenre", "movie_id", "genre_id")
+	insertCompany := insertIgnore(dialect, "ProductionCompany", "id", "name", "country")
+	insertMovieCompany := insertIgnore(dialect, "MovieProductionCompany", "movie_id", "company_id")
+
+	for movieId, c := range movies {
+		genres, err := importer.ParseGenres(c.genres)
+		if err == nil {
+			for _, g := range genres {
+				_, err = conn.ExecContext(ctx, insertGenre, g.Id, g.Name)
+				if err != nil {
+					return err
+				}
+				_, err = conn.ExecContext(ctx, insertMovieGenre, movieId, g.Id)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		companies, err := importer.ParseProductionCompanies(c.companies)
+		if err == nil {
+			for _, pc := range companies {
+				_, err = conn.ExecContext(ctx, insertCompany, pc.Id, pc.Name, pc.Country)
+				if err != nil {
+					return err
+				}
+				_, err = conn.ExecContext(ctx, insertMovieCompany, movieId, pc.Id)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// insertIgnore builds an insert of one row into table that does nothing when
+// the row's key is already there.
+func insertIgnore(dialect Dialect, table string, columns ...string) string {
+	placeholders := make([]string, len(columns))
+	for i := range columns {
+		placeholders[i] = "?"
+		if dialect == Postgres {
+			placeholders[i] = fmt.Sprintf("$%d", i+1)
+		}
+	}
+	values := fmt.Sprintf("%s (%s) VALUES (%s)", table, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+
+	switch dialect {
+	case SQLite:
+		return "INSERT OR IGNORE INTO " + values
+	case Postgres:
+		return "INSERT INTO " + values + " ON CONFLICT DO NOTHING"
+	default:
+		return "INSERT IGNORE INTO " + values
+	}
+}
@@ -0,0 +1,85 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/sirupsen/logrus"
+)
+
+func TestBackfillMovieCatalog(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db")+"?_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	ctx := context.Background()
+	err = Apply(ctx, logger, db, SQLite, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO Movie (id, title, genres, production_companies) VALUES
+		(1, 'Toy Story', '[{''id'': 16, ''name'': ''Animation''}, {''id'': 35, ''name'': ''Comedy''}]',
+			'[{''name'': ''Pixar Animation Studios'', ''id'': 3}]'),
+		(2, 'Jumanji', '[{"id": 12, "name": "Adventure"}, {"id": 16, "name": "Animation"}]',
+			'[{"id": 559, "name": "TriStar Pictures", "logo_path": null}]'),
+		(3, 'Heat', 'not a list', NULL),
+		(4, 'Sabrina', NULL, '')
+		`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.ExecContext(ctx, `INSERT INTO MovieGenre (movie_id, genre_id) VALUES (1, 16)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = backfillMovieCatalog(ctx, logger, conn, SQLite)
+	conn.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		query string
+		want  []string
+	}{
+		{`SELECT id || ' ' || name FROM Genre ORDER BY id`, []string{"12 Adventure", "16 Animation", "35 Comedy"}},
+		{`SELECT movie_id || ' ' || genre_id FROM MovieGenre ORDER BY movie_id, genre_id`, []string{"1 16", "1 35", "2 12", "2 16"}},
+		{`SELECT id || ' ' || name FROM ProductionCompany ORDER BY id`, []string{"3 Pixar Animation Studios", "559 TriStar Pictures"}},
+		{`SELECT movie_id || ' ' || company_id FROM MovieProductionCompany ORDER BY movie_id`, []string{"1 3", "2 559"}},
+	} {
+		rows, err := db.QueryContext(ctx, tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for rows.Next() {
+			var s string
+			err = rows.Scan(&s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, s)
+		}
+		rows.Close()
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %q, want %q", tc.query, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	goMigrations["0016_drop_playlist_list.go"] = dropPlaylistList
+}
+
+// dropPlaylistList drops the Playlist.list column once every entry of it is
+// found in PlaylistItem. Playlists changed since the backfill are left out of
+// the check, their items are the users' own. A missing entry fails the
+// migration and keeps the column.
+//
+// Databases that applied the former 0007_drop_playlist_list.sql have no list
+// column left to drop.
+func dropPlaylistList(ctx context.Context, logger *logrus.Logger, conn *sql.Conn, dialect Dialect) error {
+	var dropped bool
+	err := conn.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM SchemaMigration WHERE version = '0007_drop_playlist_list.sql')
+		`).Scan(&dropped)
+	if err != nil {
+		return err
+	}
+	if dropped {
+		return nil
+	}
+
+	rows, err := conn.QueryContext(ctx, `SELECT id, COALESCE(list, ''), type FROM Playlist WHERE version = 1`)
+	if err != nil {
+		return err
+	}
+	lists := map[int][]legacyPlaylistEntry{}
+	for rows.Next() {
+		var id int
+		var list, mediaType string
+		err = rows.Scan(&id, &list, &mediaType)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		lists[id], err = parseLegacyPlaylist(list, mediaType)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("playlist %d: %w", id, err)
+		}
+	}
+	err = rows.Close()
+	if err != nil {
+		return err
+	}
+
+	find := `SELECT EXISTS(SELECT 1 FROM PlaylistItem WHERE playlist_id = ? AND media_id = ? AND type = ?)`
+	if dialect == Postgres {
+		find = `SELECT EXISTS(SELECT 1 FROM PlaylistItem WHERE playlist_id = $1 AND media_id = $2 AND type = $3)`
+	}
+	checked := 0
+	for playlistId, entries := range lists {
+		for _, entry := range entries {
+			var found bool
+			err = conn.QueryRowContext(ctx, find, playlistId, entry.Id, entry.Type).Scan(&found)
+			if err != nil {
+				return err
+			}
+			if !found {
+				return fmt.Errorf("playlist %d: %s %d is missing from PlaylistItem, keeping the list column", playlistId, entry.Type, entry.Id)
+			}
+			checked++
+		}
+	}
+	logger.Infof("found all %d playlist entries in PlaylistItem, dropping the list column", checked)
+
+	_, err = conn.ExecContext(ctx, `ALTER TABLE Playlist DROP COLUMN list`)
+	return err
+}
@@ -0,0 +1,107 @@
+package migrations
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Dialect names the database the migrations are applied to. They are written
+// for MariaDB and rewritten statement by statement for the others.
+type Dialect string
+
+const (
+	MariaDB  Dialect = "mariadb"
+	SQLite   Dialect = "sqlite"
+	Postgres Dialect = "postgres"
+)
+
+var (
+	createTable   = regexp.MustCompile(`^CREATE TABLE IF NOT EXISTS ("?\w+"?)`)
+	alterTable    = regexp.MustCompile(`^ALTER TABLE ("?\w+"?)\s+`)
+	autoIncrement = regexp.MustCompile(`\bINT(\s+NOT NULL)\s+AUTO_INCREMENT`)
+	uniqueKey     = regexp.MustCompile(`\bUNIQUE KEY (\w+) \(`)
+	tableKey      = regexp.MustCompile(`,\s*\n\s*KEY (\w+) (\([^)]*\))`)
+	addColumn     = regexp.MustCompile(`,\s*\n\s*ADD COLUMN\b`)
+	afterColumn   = regexp.MustCompile(`\s+AFTER \w+`)
+	columnDefault = regexp.MustCompile(`^ALTER TABLE \w+ ALTER COLUMN \w+ SET DEFAULT`)
+	datetime      = regexp.MustCompile(`\bDATETIME\b`)
+	userTable     = regexp.MustCompile(`\bUser\b`)
+)
+
+// rewrite turns MariaDB statements into the ones doing the same in d.
+func (d Dialect) rewrite(statements []string) []string {
+	var rewrite func(string) []string
+	switch d {
+	case SQLite:
+		rewrite = sqliteStatements
+	case Postgres:
+		rewrite = postgresStatements
+	default:
+		return statements
+	}
+
+	var rewritten []string
+	for _, statement := range statements {
+		rewritten = append(rewritten, rewrite(statement)...)
+	}
+	return rewritten
+}
+
+// sqliteStatements rewrites one statement for SQLite. An INTEGER primary key
+// is SQLite's auto increment, indexes can't be declared inside CREATE TABLE,
+// and ALTER TABLE takes a single ADD COLUMN without a position. SQLite can't
+// change a column default either; the repositories always name the columns
+// whose default changed, so those statements are dropped.
+func sqliteStatements(statement string) []string {
+	if columnDefault.MatchString(statement) {
+		return nil
+	}
+
+	if createTable.MatchString(statement) {
+		return createTableStatements(autoIncrement.ReplaceAllString(statement, "INTEGER$1"))
+	}
+
+	if m := alterTable.FindStringSubmatch(statement); m != nil && strings.Contains(statement, "ADD COLUMN") {
+		var statements []string
+		for i, column := range addColumn.Split(strings.TrimPrefix(statement, m[0]), -1) {
+			if i > 0 {
+				column = "ADD COLUMN" + column
+			}
+			statements = append(statements, "ALTER TABLE "+m[1]+" "+afterColumn.ReplaceAllString(strings.TrimSpace(column), ""))
+		}
+		return statements
+	}
+
+	return []string{statement}
+}
+
+// postgresStatements rewrites one statement for PostgreSQL. Auto increment
+// columns become SERIAL, DATETIME is TIMESTAMP, indexes are created after
+// the table and columns can't be positioned. User is a reserved word and is
+// quoted, which makes the table name case sensitive.
+func postgresStatements(statement string) []string {
+	statement = datetime.ReplaceAllString(statement, "TIMESTAMP")
+	statement = userTable.ReplaceAllString(statement, `"User"`)
+
+	if createTable.MatchString(statement) {
+		return createTableStatements(autoIncrement.ReplaceAllString(statement, "SERIAL$1"))
+	}
+
+	if alterTable.MatchString(statement) {
+		statement = afterColumn.ReplaceAllString(statement, "")
+	}
+	return []string{statement}
+}
+
+// createTableStatements moves the keys declared inside a CREATE TABLE into
+// constraints and CREATE INDEX statements of their own.
+func createTableStatements(statement string) []string {
+	table := createTable.FindStringSubmatch(statement)[1]
+	statement = uniqueKey.ReplaceAllString(statement, "CONSTRAINT $1 UNIQUE (")
+
+	var indexes []string
+	for _, key := range tableKey.FindAllStringSubmatch(statement, -1) {
+		indexes = append(indexes, "CREATE INDEX IF NOT EXISTS "+key[1]+" ON "+table+" "+key[2])
+	}
+	return append([]string{tableKey.ReplaceAllString(statement, "")}, indexes...)
+}
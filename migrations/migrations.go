@@ -0,0 +1,140 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// goMigrations holds data migrations that can't be expressed in SQL, keyed by
+// version. They are registered from init functions in files named like the
+// version and run in order together with the .sql files, and report what they
+// did to logger.
+var goMigrations = map[string]func(ctx context.Context, logger *logrus.Logger, conn *sql.Conn, dialect Dialect) error{}
+
+// Apply runs every embedded migration that is not yet recorded in the
+// SchemaMigration table of schema, in file name order. The migrations are
+// written for MariaDB and rewritten for dialect; PostgreSQL creates schema
+// when it is missing and SQLite has no schemas and ignores it.
+func Apply(ctx context.Context, logger *logrus.Logger, db *sql.DB, dialect Dialect, schema string) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	switch dialect {
+	case MariaDB:
+		_, err = conn.ExecContext(ctx, fmt.Sprintf("USE %s", schema))
+	case Postgres:
+		_, err = conn.ExecContext(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", schema))
+		if err == nil {
+			_, err = conn.ExecContext(ctx, fmt.Sprintf("SET search_path TO %s", schema))
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range dialect.rewrite([]string{`
+		CREATE TABLE IF NOT EXISTS SchemaMigration (
+			version    VARCHAR(255) NOT NULL,
+			applied_at DATETIME     NOT NULL,
+			PRIMARY KEY (version)
+		)
+	`}) {
+		_, err = conn.ExecContext(ctx, statement)
+		if err != nil {
+			return err
+		}
+	}
+
+	names, err := fs.Glob(files, "*.sql")
+	if err != nil {
+		return err
+	}
+	for name := range goMigrations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var applied bool
+		err = conn.QueryRowContext(ctx, fmt.Sprintf(`
+			SELECT EXISTS(SELECT 1 FROM SchemaMigration WHERE version = '%s')
+			`,
+			name,
+		)).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if applied {
+			continue
+		}
+
+		if migrate, ok := goMigrations[name]; ok {
+			err = migrate(ctx, logger, conn, dialect)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		} else {
+			body, err := files.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			for _, statement := range dialect.rewrite(Statements(string(body))) {
+				logger.Debug(statement)
+				_, err = conn.ExecContext(ctx, statement)
+				if err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+			}
+		}
+
+		_, err = conn.ExecContext(ctx, fmt.Sprintf(`
+			INSERT INTO SchemaMigration (version, applied_at) VALUES ('%s', CURRENT_TIMESTAMP)
+			`,
+			name,
+		))
+		if err != nil {
+			return err
+		}
+		logger.Infof("applied migration %s", name)
+	}
+
+	return nil
+}
+
+// Statements splits a migration file into its individual statements. A
+// statement ends with a semicolon at the end of a line.
+func Statements(body string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(strings.TrimSpace(line), ";") {
+			statement := strings.TrimSuffix(strings.TrimSpace(current.String()), ";")
+			if statement != "" {
+				statements = append(statements, statement)
+			}
+			current.Reset()
+		}
+	}
+	if statement := strings.TrimSpace(current.String()); statement != "" {
+		statements = append(statements, statement)
+	}
+	return statements
+}
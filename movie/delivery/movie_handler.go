@@ -2,38 +2,160 @@ package delivery
 
 import (
 	"github.com/labstack/echo/v4"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
 	"github.com/null-like/movie-backend/movie"
+	"github.com/null-like/movie-backend/request"
 	"net/http"
-	"strconv"
+	"strings"
 )
 
 type movieHandler struct {
 	Usecase movie.Usecase
 }
 
-type ResponseError struct {
-	Message string `json:"message"`
-}
-
 func NewMovieHandler(g *echo.Group, u movie.Usecase) {
 	handler := &movieHandler{
 		Usecase: u,
 	}
 	g.GET("/movie/movie-info", handler.GetMovieInfo)
+	g.GET("/genres", handler.GetGenres)
+	g.GET("/genres/:id/movies", handler.GetGenreMovies)
+	g.GET("/companies", handler.GetCompanies)
+	g.GET("/companies/:id/movies", handler.GetCompanyMovies)
+	g.GET("/people/search", handler.SearchPeople)
+	g.GET("/people/:id", handler.GetPersonDetail)
+	g.GET("/movie/search-by-person", handler.SearchMoviesByPerson)
+}
+
+const defaultPageSize = 20
+
+type CompanyMovies struct {
+	Company movieDomain.ProductionCompanyWithCount
+	Movies  []movieDomain.Movie
 }
 
 func (h *movieHandler) GetMovieInfo(c echo.Context) error {
 	ctx := c.Request().Context()
-	pathParam := c.Param("movie_id")
-	movieId, err := strconv.Atoi(pathParam)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, ResponseError{Message: err.Error()})
+	var req MovieRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
 	}
 
-	movieInfo, err := h.Usecase.GetMovieInfo(ctx, movieId)
+	movieInfo, err := h.Usecase.GetMovieInfo(ctx, req.MovieId)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ResponseError{Message: err.Error()})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, movieInfo)
 }
+
+func (h *movieHandler) GetGenres(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	genres, err := h.Usecase.GetGenres(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, genres)
+}
+
+func (h *movieHandler) GetGenreMovies(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req GenreMoviesRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	offset, limit := req.page()
+	movies, err := h.Usecase.GetMoviesByGenre(ctx, req.Id, offset, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, movies)
+}
+
+func (h *movieHandler) GetCompanies(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req Page
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	offset, limit := req.page()
+	companies, err := h.Usecase.GetCompanies(ctx, offset, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, companies)
+}
+
+func (h *movieHandler) GetCompanyMovies(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req CompanyMoviesRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	offset, limit := req.page()
+	company, movies, err := h.Usecase.GetMoviesByCompany(ctx, req.Id, offset, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, CompanyMovies{Company: company, Movies: movies})
+}
+
+func (h *movieHandler) GetPersonDetail(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req IdPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	detail, err := h.Usecase.GetPersonDetail(ctx, req.Id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, detail)
+}
+
+func (h *movieHandler) SearchPeople(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req PeopleSearchRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	_, limit := Page{Limit: req.Limit}.page()
+	people, err := h.Usecase.SearchPeople(ctx, strings.TrimSpace(req.Name), limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, people)
+}
+
+func (h *movieHandler) SearchMoviesByPerson(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req MoviesByPersonRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	role := req.Role
+	if role == "" {
+		role = movieDomain.RoleActor
+	}
+
+	offset, limit := req.page()
+	movies, err := h.Usecase.SearchMoviesByPerson(ctx, strings.TrimSpace(req.Name), role, offset, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, movies)
+}
@@ -0,0 +1,22 @@
+package delivery
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/null-like/movie-backend/movie"
+)
+
+// NewMovieHandlerV2 registers the /v2 movie routes. The catalogue was already
+// read-only, so only the movie URLs move; the handlers are shared with /v1.
+func NewMovieHandlerV2(g *echo.Group, u movie.Usecase) {
+	handler := &movieHandler{
+		Usecase: u,
+	}
+	g.GET("/movies/by-person", handler.SearchMoviesByPerson)
+	g.GET("/movies/:movie_id", handler.GetMovieInfo)
+	g.GET("/genres", handler.GetGenres)
+	g.GET("/genres/:id/movies", handler.GetGenreMovies)
+	g.GET("/companies", handler.GetCompanies)
+	g.GET("/companies/:id/movies", handler.GetCompanyMovies)
+	g.GET("/people/search", handler.SearchPeople)
+	g.GET("/people/:id", handler.GetPersonDetail)
+}
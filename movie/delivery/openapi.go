@@ -0,0 +1,34 @@
+package delivery
+
+import (
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	"github.com/null-like/movie-backend/openapi"
+)
+
+// Operations documents the routes of NewMovieHandler.
+func Operations() []openapi.Operation {
+	return append([]openapi.Operation{
+		{Method: "GET", Path: "/movie/movie-info", Tag: "movies", Summary: "Get a movie with its cast and crew", Request: MovieRequest{}, Response: movieDomain.Movie{}},
+		{Method: "GET", Path: "/movie/search-by-person", Tag: "movies", Summary: "Search movies by an actor or director", Request: MoviesByPersonRequest{}, Response: []movieDomain.Movie{}},
+	}, catalogue()...)
+}
+
+// OperationsV2 documents the routes of NewMovieHandlerV2.
+func OperationsV2() []openapi.Operation {
+	return append([]openapi.Operation{
+		{Method: "GET", Path: "/movies/:movie_id", Tag: "movies", Summary: "Get a movie with its cast and crew", Request: MovieRequest{}, Response: movieDomain.Movie{}},
+		{Method: "GET", Path: "/movies/by-person", Tag: "movies", Summary: "Search movies by an actor or director", Request: MoviesByPersonRequest{}, Response: []movieDomain.Movie{}},
+	}, catalogue()...)
+}
+
+// catalogue documents the routes that kept their /v1 paths in /v2.
+func catalogue() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: "GET", Path: "/genres", Tag: "movies", Summary: "List genres with their movie counts", Response: []movieDomain.GenreWithCount{}},
+		{Method: "GET", Path: "/genres/:id/movies", Tag: "movies", Summary: "List the movies of a genre", Request: GenreMoviesRequest{}, Response: []movieDomain.Movie{}},
+		{Method: "GET", Path: "/companies", Tag: "movies", Summary: "List production companies", Request: Page{}, Response: []movieDomain.ProductionCompanyWithCount{}},
+		{Method: "GET", Path: "/companies/:id/movies", Tag: "movies", Summary: "Get a production company and its movies", Request: CompanyMoviesRequest{}, Response: CompanyMovies{}},
+		{Method: "GET", Path: "/people/search", Tag: "people", Summary: "Search people by name", Request: PeopleSearchRequest{}, Response: []movieDomain.Person{}},
+		{Method: "GET", Path: "/people/:id", Tag: "people", Summary: "Get a person with their credits", Request: IdPath{}, Response: movieDomain.PersonDetail{}},
+	}
+}
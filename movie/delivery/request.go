@@ -0,0 +1,49 @@
+package delivery
+
+// Ids are read from the path under /v2 and from the query under /v1, so the
+// fields carry both tags.
+
+// Page is the offset and limit of a list request.
+type Page struct {
+	Offset int `query:"offset" validate:"gte=0"`
+	Limit  int `query:"limit" validate:"gte=0,lte=100"`
+}
+
+// page returns the offset and limit, defaulting the limit when unset.
+func (p Page) page() (int, int) {
+	if p.Limit == 0 {
+		return p.Offset, defaultPageSize
+	}
+	return p.Offset, p.Limit
+}
+
+type MovieRequest struct {
+	MovieId int `param:"movie_id" query:"movie_id" validate:"required,gt=0"`
+}
+
+type IdPath struct {
+	Id int `param:"id" validate:"required,gt=0"`
+}
+
+type GenreMoviesRequest struct {
+	IdPath
+	Page
+}
+
+type CompanyMoviesRequest struct {
+	IdPath
+	Page
+}
+
+type PeopleSearchRequest struct {
+	Name  string `query:"name" validate:"notblank,max=255"`
+	Limit int    `query:"limit" validate:"gte=0,lte=100"`
+}
+
+// MoviesByPersonRequest searches an actor's movies unless Role says
+// otherwise.
+type MoviesByPersonRequest struct {
+	Page
+	Name string `query:"name" validate:"notblank,max=255"`
+	Role string `query:"role" validate:"omitempty,oneof=actor director"`
+}
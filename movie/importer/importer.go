@@ -0,0 +1,285 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	"github.com/null-like/movie-backend/movie"
+	"github.com/sirupsen/logrus"
+)
+
+// CheckpointFile is written into the import directory after every batch so an
+// interrupted import resumes where it stopped. It is removed once every
+// export has been imported.
+const CheckpointFile = ".import-checkpoint.json"
+
+const DefaultBatchSize = 500
+
+type Importer struct {
+	logger    *logrus.Logger
+	movieRepo movie.Repository
+	dir       string
+	batchSize int
+	progress  io.Writer
+}
+
+type checkpoint struct {
+	Files map[string]*fileCheckpoint `json:"files"`
+}
+
+type fileCheckpoint struct {
+	Rows int  `json:"rows"`
+	Done bool `json:"done"`
+}
+
+// step imports one export file. add parses a record into the pending batch,
+// flush writes the pending batch and empties it.
+type step struct {
+	name     string
+	required bool
+	add      func(rec record) error
+	pending  func() int
+	flush    func(ctx context.Context) error
+}
+
+func NewImporter(l *logrus.Logger, r movie.Repository, dir string, batchSize int, progress io.Writer) *Importer {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &Importer{
+		logger:    l,
+		movieRepo: r,
+		dir:       dir,
+		batchSize: batchSize,
+		progress:  progress,
+	}
+}
+
+// ImportMovies imports movies_metadata, then keywords and credits, from the
+// import directory. Each file may be a .csv (Kaggle dump) or .json export.
+func (im *Importer) ImportMovies(ctx context.Context) error {
+	state, err := im.loadCheckpoint()
+	if err != nil {
+		return err
+	}
+
+	var movies []movieDomain.Movie
+	movieIndex := map[int]int{}
+	var keywords []movieDomain.MovieKeywords
+	keywordIndex := map[int]int{}
+	var credits []movieDomain.Credits
+	creditIndex := map[int]int{}
+
+	steps := []step{
+		{
+			name:     "movies_metadata",
+			required: true,
+			add: func(rec record) error {
+				m, err := parseMovie(rec)
+				if err != nil {
+					return err
+				}
+				if i, ok := movieIndex[m.Id]; ok {
+					movies[i] = m
+					return nil
+				}
+				movieIndex[m.Id] = len(movies)
+				movies = append(movies, m)
+				return nil
+			},
+			pending: func() int { return len(movies) },
+			flush: func(ctx context.Context) error {
+				err := im.movieRepo.UpsertMovies(ctx, movies)
+				movies, movieIndex = nil, map[int]int{}
+				return err
+			},
+		},
+		{
+			name: "keywords",
+			add: func(rec record) error {
+				mk, err := parseKeywords(rec)
+				if err != nil {
+					return err
+				}
+				if i, ok := keywordIndex[mk.MovieId]; ok {
+					keywords[i] = mk
+					return nil
+				}
+				keywordIndex[mk.MovieId] = len(keywords)
+				keywords = append(keywords, mk)
+				return nil
+			},
+			pending: func() int { return len(keywords) },
+			flush: func(ctx context.Context) error {
+				err := im.movieRepo.UpsertKeywords(ctx, keywords)
+				keywords, keywordIndex = nil, map[int]int{}
+				return err
+			},
+		},
+		{
+			name: "credits",
+			add: func(rec record) error {
+				c, err := parseCredits(rec)
+				if err != nil {
+					return err
+				}
+				if i, ok := creditIndex[c.MovieId]; ok {
+					credits[i] = c
+					return nil
+				}
+				creditIndex[c.MovieId] = len(credits)
+				credits = append(credits, c)
+				return nil
+			},
+			pending: func() int { return len(credits) },
+			flush: func(ctx context.Context) error {
+				err := im.movieRepo.UpsertCredits(ctx, credits)
+				credits, creditIndex = nil, map[int]int{}
+				return err
+			},
+		},
+	}
+
+	for _, s := range steps {
+		err = im.runStep(ctx, s, state)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = os.Remove(filepath.Join(im.dir, CheckpointFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (im *Importer) runStep(ctx context.Context, s step, state *checkpoint) error {
+	fileState, ok := state.Files[s.name]
+	if !ok {
+		fileState = &fileCheckpoint{}
+		state.Files[s.name] = fileState
+	}
+	if fileState.Done {
+		fmt.Fprintf(im.progress, "%s: already imported, skipping\n", s.name)
+		return nil
+	}
+
+	reader, path, size, err := openExport(im.dir, s.name)
+	if errors.Is(err, os.ErrNotExist) {
+		if s.required {
+			return fmt.Errorf("%s.csv or %s.json not found in %s", s.name, s.name, im.dir)
+		}
+		fmt.Fprintf(im.progress, "%s: not found, skipping\n", s.name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	rows, skipped := 0, 0
+	if fileState.Rows > 0 {
+		fmt.Fprintf(im.progress, "%s: resuming after row %d\n", path, fileState.Rows)
+	}
+	for rows < fileState.Rows {
+		_, err = reader.Next()
+		if err != nil {
+			return fmt.Errorf("%s: skipping to row %d: %w", path, fileState.Rows, err)
+		}
+		rows++
+	}
+
+	started := time.Now()
+	commit := func() error {
+		err := s.flush(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: batch ending at row %d: %w", path, rows, err)
+		}
+		fileState.Rows = rows
+		err = im.saveCheckpoint(state)
+		if err != nil {
+			return err
+		}
+		percent := 100.0
+		if size > 0 {
+			percent = float64(reader.Offset()) * 100 / float64(size)
+		}
+		fmt.Fprintf(im.progress, "%s: %d rows (%.1f%%), %d skipped, %s elapsed\n",
+			path, rows, percent, skipped, time.Since(started).Round(time.Second))
+		return nil
+	}
+
+	for {
+		rec, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%s: row %d: %w", path, rows+1, err)
+		}
+		rows++
+
+		err = s.add(rec)
+		if err != nil {
+			skipped++
+			im.logger.Warnf("%s: skipping row %d: %v", path, rows, err)
+		}
+
+		if s.pending() >= im.batchSize {
+			err = commit()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = commit()
+	if err != nil {
+		return err
+	}
+	fileState.Done = true
+	return im.saveCheckpoint(state)
+}
+
+func (im *Importer) loadCheckpoint() (*checkpoint, error) {
+	state := &checkpoint{Files: map[string]*fileCheckpoint{}}
+
+	data, err := os.ReadFile(filepath.Join(im.dir, CheckpointFile))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", CheckpointFile, err)
+	}
+	if state.Files == nil {
+		state.Files = map[string]*fileCheckpoint{}
+	}
+	return state, nil
+}
+
+func (im *Importer) saveCheckpoint(state *checkpoint) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(im.dir, CheckpointFile)
+	err = os.WriteFile(path+".tmp", data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
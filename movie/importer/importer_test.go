@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	_movieRepo "github.com/null-like/movie-backend/movie/repository"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPyLiteralToJSON(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty list", `[]`, `[]`},
+		{"genres", `[{'id': 16, 'name': 'Animation'}]`, `[{"id": 16, "name": "Animation"}]`},
+		{"apostrophe in double quotes", `[{'name': "Schindler's List"}]`, `[{"name": "Schindler's List"}]`},
+		{"escaped apostrophe", `[{'name': 'Schindler\'s List'}]`, `[{"name": "Schindler's List"}]`},
+		{"double quote in single quotes", `[{'name': 'The "Best" Years'}]`, `[{"name": "The \"Best\" Years"}]`},
+		{"constants", `[{'logo': None, 'adult': True, 'video': False}]`, `[{"logo": null, "adult": true, "video": false}]`},
+		{"numbers", `[{'id': -1, 'popularity': 2.5e-3}]`, `[{"id": -1, "popularity": 0.0025}]`},
+		{"nested lists", `[[1, [2, 'a']], {'ids': [3, None]}]`, `[[1, [2, "a"]], {"ids": [3, null]}]`},
+		{"json", `[{"id": 16, "logo": null, "adult": false}]`, `[{"id": 16, "logo": null, "adult": false}]`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := pyLiteralToJSON(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want interface{}
+			err = json.Unmarshal(data, &got)
+			if err != nil {
+				t.Fatalf("%s is not JSON: %v", data, err)
+			}
+			err = json.Unmarshal([]byte(tc.want), &want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %s, want %s", data, tc.want)
+			}
+		})
+	}
+
+	for _, in := range []string{`[{'name': 'unterminated}]`, `[{'id': nan}]`} {
+		if data, err := pyLiteralToJSON(in); err == nil {
+			t.Errorf("%s: got %s, want an error", in, data)
+		}
+	}
+}
+
+func TestImportMoviesResumes(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "movies_metadata.csv"), `id,title,genres
+1,Toy Story,"[{'id': 16, 'name': 'Animation'}]"
+2,Jumanji,[]
+3,Heat,"[{'id': 80, 'name': 'Crime'}]"
+4,Sabrina,[]
+`)
+	writeFile(t, filepath.Join(dir, CheckpointFile), `{"files": {"movies_metadata": {"rows": 2}}}`)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	mr := _movieRepo.NewMemoryMovieRepository()
+	var progress bytes.Buffer
+	err := NewImporter(logger, mr, dir, 1, &progress).ImportMovies(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, id := range []int{1, 2} {
+		_, err = mr.ReadMovieById(ctx, id)
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("movie %d: got error %v, want it skipped", id, err)
+		}
+	}
+	for _, id := range []int{3, 4} {
+		_, err = mr.ReadMovieById(ctx, id)
+		if err != nil {
+			t.Errorf("movie %d: %v", id, err)
+		}
+	}
+	if !strings.Contains(progress.String(), "resuming after row 2") {
+		t.Errorf("progress doesn't mention the resume:\n%s", progress.String())
+	}
+	if _, err = os.Stat(filepath.Join(dir, CheckpointFile)); !os.IsNotExist(err) {
+		t.Errorf("checkpoint: got %v, want it removed", err)
+	}
+}
+
+func TestImportMoviesSkipsFinishedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "movies_metadata.csv"), "id,title\n1,Toy Story\n")
+	writeFile(t, filepath.Join(dir, CheckpointFile), `{"files": {"movies_metadata": {"rows": 1, "done": true}}}`)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	mr := _movieRepo.NewMemoryMovieRepository()
+	err := NewImporter(logger, mr, dir, 0, io.Discard).ImportMovies(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = mr.ReadMovieById(context.Background(), 1)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("got error %v, want the finished file skipped", err)
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,153 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type recordReader interface {
+	// Next returns the next record, or io.EOF once the file is exhausted.
+	Next() (record, error)
+	// Offset is the number of bytes consumed so far, used for progress.
+	Offset() int64
+	Close() error
+}
+
+// openExport opens dir/name.csv or dir/name.json, whichever exists. It returns
+// os.ErrNotExist when neither does.
+func openExport(dir string, name string) (recordReader, string, int64, error) {
+	for _, ext := range []string{".csv", ".json"} {
+		path := filepath.Join(dir, name+ext)
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, "", 0, err
+		}
+
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, "", 0, err
+		}
+
+		var reader recordReader
+		if ext == ".csv" {
+			reader, err = newCSVReader(f)
+		} else {
+			reader, err = newJSONReader(f)
+		}
+		if err != nil {
+			f.Close()
+			return nil, "", 0, fmt.Errorf("%s: %w", path, err)
+		}
+		return reader, path, info.Size(), nil
+	}
+	return nil, "", 0, os.ErrNotExist
+}
+
+type csvReader struct {
+	file   *os.File
+	reader *csv.Reader
+	header []string
+}
+
+func newCSVReader(f *os.File) (*csvReader, error) {
+	reader := csv.NewReader(f)
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+
+	return &csvReader{file: f, reader: reader, header: header}, nil
+}
+
+func (r *csvReader) Next() (record, error) {
+	fields, err := r.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	rec := make(record, len(r.header))
+	for i, name := range r.header {
+		if i < len(fields) {
+			rec[name] = fields[i]
+		}
+	}
+	return rec, nil
+}
+
+func (r *csvReader) Offset() int64 {
+	return r.reader.InputOffset()
+}
+
+func (r *csvReader) Close() error {
+	return r.file.Close()
+}
+
+// jsonReader accepts either a single JSON array of objects or a stream of
+// objects, one after another (JSON lines).
+type jsonReader struct {
+	file    *os.File
+	decoder *json.Decoder
+	array   bool
+}
+
+func newJSONReader(f *os.File) (*jsonReader, error) {
+	var first [1]byte
+	for {
+		_, err := f.Read(first[:])
+		if err != nil {
+			return nil, err
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(first[0])) {
+			break
+		}
+	}
+	_, err := f.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &jsonReader{file: f, decoder: json.NewDecoder(f), array: first[0] == '['}
+	if reader.array {
+		_, err = reader.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return reader, nil
+}
+
+func (r *jsonReader) Next() (record, error) {
+	if r.array && !r.decoder.More() {
+		return nil, io.EOF
+	}
+
+	var rec record
+	err := r.decoder.Decode(&rec)
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+func (r *jsonReader) Offset() int64 {
+	return r.decoder.InputOffset()
+}
+
+func (r *jsonReader) Close() error {
+	return r.file.Close()
+}
@@ -0,0 +1,352 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+)
+
+// record is one row of a TMDB export. CSV rows hold every value as a string,
+// JSON rows hold typed values; the accessors below accept both.
+type record map[string]interface{}
+
+func (r record) str(key string) string {
+	switch v := r[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
+
+func (r record) float(key string) (float64, error) {
+	switch v := r[key].(type) {
+	case float64:
+		return v, nil
+	case string:
+		if v == "" {
+			return 0, nil
+		}
+		return strconv.ParseFloat(v, 64)
+	case nil:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("%s: unexpected value %v", key, v)
+	}
+}
+
+func (r record) int(key string) (int, error) {
+	f, err := r.float(key)
+	return int(f), err
+}
+
+func (r record) bool(key string) bool {
+	switch v := r[key].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	default:
+		return false
+	}
+}
+
+// list returns a nested list of objects such as genres or cast. The Kaggle CSV
+// dumps store these as python reprs, which are converted to JSON first.
+func (r record) list(key string) ([]record, error) {
+	var raw []interface{}
+	switch v := r[key].(type) {
+	case []interface{}:
+		raw = v
+	case string:
+		v = strings.TrimSpace(v)
+		if v == "" || v == "[]" {
+			return nil, nil
+		}
+		data, err := pyLiteralToJSON(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		err = json.Unmarshal(data, &raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%s: unexpected value %v", key, v)
+	}
+
+	list := make([]record, 0, len(raw))
+	for _, item := range raw {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: unexpected item %v", key, item)
+		}
+		list = append(list, record(m))
+	}
+	return list, nil
+}
+
+func parseMovie(r record) (movieDomain.Movie, error) {
+	var m movieDomain.Movie
+	var err error
+
+	m.Id, err = r.int("id")
+	if err != nil || m.Id <= 0 {
+		return m, fmt.Errorf("invalid id %q", r.str("id"))
+	}
+
+	m.Adult = r.bool("adult")
+	m.Title = r.str("title")
+	m.Language = r.str("original_language")
+	m.Overview = r.str("overview")
+	m.Poster = r.str("poster_path")
+	m.ReleaseDate = r.str("release_date")
+	m.Tagline = r.str("tagline")
+	m.Revenue, _ = r.int("revenue")
+	m.Runtime, _ = r.int("runtime")
+	m.Votes, _ = r.int("vote_count")
+	rating, _ := r.float("vote_average")
+	m.Rating = float32(rating)
+
+	m.Genres, err = parseGenres(r)
+	if err != nil {
+		return m, err
+	}
+	m.ProductionCompanies, err = parseProductionCompanies(r)
+	if err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
+
+func parseGenres(r record) ([]movieDomain.Genre, error) {
+	list, err := r.list("genres")
+	if err != nil {
+		return nil, err
+	}
+	var genres []movieDomain.Genre
+	for _, g := range list {
+		id, err := g.int("id")
+		if err != nil {
+			return nil, err
+		}
+		genres = append(genres, movieDomain.Genre{Id: id, Name: g.str("name")})
+	}
+	return genres, nil
+}
+
+func parseProductionCompanies(r record) ([]movieDomain.ProductionCompany, error) {
+	list, err := r.list("production_companies")
+	if err != nil {
+		return nil, err
+	}
+	var companies []movieDomain.ProductionCompany
+	for _, c := range list {
+		id, err := c.int("id")
+		if err != nil {
+			return nil, err
+		}
+		companies = append(companies, movieDomain.ProductionCompany{
+			Id:      id,
+			Name:    c.str("name"),
+			Country: c.str("origin_country"),
+		})
+	}
+	return companies, nil
+}
+
+// ParseGenres parses a genres value as TMDB exports write it, a JSON list or
+// the python repr of the CSV dumps.
+func ParseGenres(s string) ([]movieDomain.Genre, error) {
+	return parseGenres(record{"genres": s})
+}
+
+// ParseProductionCompanies parses a production_companies value as TMDB
+// exports write it, a JSON list or the python repr of the CSV dumps.
+func ParseProductionCompanies(s string) ([]movieDomain.ProductionCompany, error) {
+	return parseProductionCompanies(record{"production_companies": s})
+}
+
+func parseKeywords(r record) (movieDomain.MovieKeywords, error) {
+	var mk movieDomain.MovieKeywords
+	var err error
+
+	mk.MovieId, err = r.int("id")
+	if err != nil || mk.MovieId <= 0 {
+		return mk, fmt.Errorf("invalid id %q", r.str("id"))
+	}
+
+	keywords, err := r.list("keywords")
+	if err != nil {
+		return mk, err
+	}
+	for _, k := range keywords {
+		id, err := k.int("id")
+		if err != nil {
+			return mk, err
+		}
+		mk.Keywords = append(mk.Keywords, movieDomain.Keyword{Id: id, Name: k.str("name")})
+	}
+
+	return mk, nil
+}
+
+func parseCredits(r record) (movieDomain.Credits, error) {
+	var c movieDomain.Credits
+	var err error
+
+	c.MovieId, err = r.int("id")
+	if err != nil || c.MovieId <= 0 {
+		return c, fmt.Errorf("invalid id %q", r.str("id"))
+	}
+
+	cast, err := r.list("cast")
+	if err != nil {
+		return c, err
+	}
+	for _, item := range cast {
+		personId, err := item.int("id")
+		if err != nil {
+			return c, err
+		}
+		gender, _ := item.int("gender")
+		order, _ := item.int("order")
+		c.Cast = append(c.Cast, movieDomain.Cast{
+			CreditId:    item.str("credit_id"),
+			PersonId:    personId,
+			Name:        item.str("name"),
+			Gender:      gender,
+			ProfilePath: item.str("profile_path"),
+			Character:   item.str("character"),
+			Order:       order,
+		})
+	}
+
+	crew, err := r.list("crew")
+	if err != nil {
+		return c, err
+	}
+	for _, item := range crew {
+		personId, err := item.int("id")
+		if err != nil {
+			return c, err
+		}
+		gender, _ := item.int("gender")
+		c.Crew = append(c.Crew, movieDomain.Crew{
+			CreditId:    item.str("credit_id"),
+			PersonId:    personId,
+			Name:        item.str("name"),
+			Gender:      gender,
+			ProfilePath: item.str("profile_path"),
+			Job:         item.str("job"),
+			Department:  item.str("department"),
+		})
+	}
+
+	return c, nil
+}
+
+// pyLiteralToJSON converts a python repr such as
+// "[{'id': 16, 'name': 'Animation', 'logo': None}]" into JSON. JSON passes
+// through unchanged.
+func pyLiteralToJSON(s string) ([]byte, error) {
+	var b bytes.Buffer
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\'' || c == '"':
+			str, n, err := readPyString(s[i:])
+			if err != nil {
+				return nil, err
+			}
+			enc, _ := json.Marshal(str)
+			b.Write(enc)
+			i += n
+		case c >= '0' && c <= '9' || c == '-':
+			j := i + 1
+			for j < len(s) && strings.IndexByte("0123456789.eE+-", s[j]) >= 0 {
+				j++
+			}
+			b.WriteString(s[i:j])
+			i = j
+		case c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+			j := i
+			for j < len(s) && (s[j] >= 'A' && s[j] <= 'Z' || s[j] >= 'a' && s[j] <= 'z') {
+				j++
+			}
+			switch s[i:j] {
+			case "None", "null":
+				b.WriteString("null")
+			case "True", "true":
+				b.WriteString("true")
+			case "False", "false":
+				b.WriteString("false")
+			default:
+				return nil, fmt.Errorf("unexpected identifier %q", s[i:j])
+			}
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// readPyString decodes the quoted python string at the start of s and
+// returns it together with the number of bytes consumed.
+func readPyString(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); {
+		c := s[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\' && i+1 < len(s):
+			e := s[i+1]
+			switch e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '\\', '\'', '"':
+				b.WriteByte(e)
+			case 'x', 'u', 'U':
+				width := map[byte]int{'x': 2, 'u': 4, 'U': 8}[e]
+				if i+2+width > len(s) {
+					return "", 0, fmt.Errorf("truncated escape in %q", s)
+				}
+				code, err := strconv.ParseUint(s[i+2:i+2+width], 16, 32)
+				if err != nil {
+					return "", 0, err
+				}
+				b.WriteRune(rune(code))
+				i += width
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(e)
+			}
+			i += 2
+		default:
+			_, size := utf8.DecodeRuneInString(s[i:])
+			b.WriteString(s[i : i+size])
+			i += size
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string in %q", s)
+}
@@ -7,4 +7,23 @@ import (
 
 type Repository interface {
 	ReadMovieById(ctx context.Context, movieId int) (movieDomain.Movie, error)
+	ExistMovieById(ctx context.Context, movieId int) (bool, error)
+	FindMoviesByIds(ctx context.Context, movieIds []int) ([]movieDomain.Movie, error)
+
+	AllGenres(ctx context.Context) ([]movieDomain.GenreWithCount, error)
+	FindMoviesByGenreId(ctx context.Context, genreId int, offset int, limit int) ([]movieDomain.Movie, error)
+	AllProductionCompanies(ctx context.Context, offset int, limit int) ([]movieDomain.ProductionCompanyWithCount, error)
+	ReadProductionCompanyById(ctx context.Context, companyId int) (movieDomain.ProductionCompanyWithCount, error)
+	FindMoviesByCompanyId(ctx context.Context, companyId int, offset int, limit int) ([]movieDomain.Movie, error)
+
+	ReadCreditsByMovieId(ctx context.Context, movieId int) (movieDomain.Credits, error)
+	ReadPersonById(ctx context.Context, personId int) (movieDomain.Person, error)
+	FindCastCreditsByPersonId(ctx context.Context, personId int) ([]movieDomain.CastCredit, error)
+	FindCrewCreditsByPersonId(ctx context.Context, personId int) ([]movieDomain.CrewCredit, error)
+	FindPeopleByName(ctx context.Context, name string, limit int) ([]movieDomain.Person, error)
+	FindMoviesByPersonName(ctx context.Context, name string, role string, offset int, limit int) ([]movieDomain.Movie, error)
+
+	UpsertMovies(ctx context.Context, movies []movieDomain.Movie) error
+	UpsertKeywords(ctx context.Context, keywords []movieDomain.MovieKeywords) error
+	UpsertCredits(ctx context.Context, credits []movieDomain.Credits) error
 }
@@ -0,0 +1,514 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	"github.com/null-like/movie-backend/movie"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type castRow struct {
+	movieId   int
+	personId  int
+	character string
+	order     int
+}
+
+type crewRow struct {
+	movieId    int
+	personId   int
+	job        string
+	department string
+}
+
+// memoryMovieRepository keeps the catalog in maps shaped like the MariaDB
+// tables, so joins and orderings can be answered the same way.
+type memoryMovieRepository struct {
+	mu sync.RWMutex
+
+	movies         map[int]movieDomain.Movie // without genres and companies
+	genres         map[int]movieDomain.Genre
+	companies      map[int]movieDomain.ProductionCompany
+	movieGenres    map[int][]int
+	movieCompanies map[int][]int
+	keywords       map[int]movieDomain.Keyword
+	movieKeywords  map[int][]int
+	people         map[int]movieDomain.Person
+	cast           map[string]castRow // by credit id
+	crew           map[string]crewRow
+}
+
+// NewMemoryMovieRepository returns an empty catalog held in memory. It
+// answers like the MariaDB repository, missing rows included, and is safe for
+// concurrent use.
+func NewMemoryMovieRepository() movie.Repository {
+	return &memoryMovieRepository{
+		movies:         make(map[int]movieDomain.Movie),
+		genres:         make(map[int]movieDomain.Genre),
+		companies:      make(map[int]movieDomain.ProductionCompany),
+		movieGenres:    make(map[int][]int),
+		movieCompanies: make(map[int][]int),
+		keywords:       make(map[int]movieDomain.Keyword),
+		movieKeywords:  make(map[int][]int),
+		people:         make(map[int]movieDomain.Person),
+		cast:           make(map[string]castRow),
+		crew:           make(map[string]crewRow),
+	}
+}
+
+func (r *memoryMovieRepository) ReadMovieById(ctx context.Context, movieId int) (movieDomain.Movie, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.movies[movieId]
+	if !ok {
+		return movieDomain.Movie{}, sql.ErrNoRows
+	}
+	m.Genres = r.genresOf(movieId)
+	for _, id := range r.movieCompanies[movieId] {
+		m.ProductionCompanies = append(m.ProductionCompanies, r.companies[id])
+	}
+	sort.SliceStable(m.ProductionCompanies, func(i, j int) bool {
+		return m.ProductionCompanies[i].Name < m.ProductionCompanies[j].Name
+	})
+	return m, nil
+}
+
+// genresOf lists a movie's genres by name. The caller holds the lock.
+func (r *memoryMovieRepository) genresOf(movieId int) []movieDomain.Genre {
+	var genres []movieDomain.Genre
+	for _, id := range r.movieGenres[movieId] {
+		genres = append(genres, r.genres[id])
+	}
+	sort.SliceStable(genres, func(i, j int) bool {
+		return genres[i].Name < genres[j].Name
+	})
+	return genres
+}
+
+func (r *memoryMovieRepository) ExistMovieById(ctx context.Context, movieId int) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.movies[movieId]
+	return ok, nil
+}
+
+func (r *memoryMovieRepository) FindMoviesByIds(ctx context.Context, movieIds []int) ([]movieDomain.Movie, error) {
+	if len(movieIds) == 0 {
+		return nil, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[int]bool, len(movieIds))
+	for _, id := range movieIds {
+		wanted[id] = true
+	}
+	movies := r.moviesWhere(func(m movieDomain.Movie) bool { return wanted[m.Id] })
+	for i := range movies {
+		movies[i].Genres = r.genresOf(movies[i].Id)
+	}
+	return movies, nil
+}
+
+// moviesWhere returns the movies matching keep in id order, without genres or
+// companies. The caller holds the lock.
+func (r *memoryMovieRepository) moviesWhere(keep func(m movieDomain.Movie) bool) []movieDomain.Movie {
+	var movies []movieDomain.Movie
+	for _, m := range r.movies {
+		if keep(m) {
+			movies = append(movies, m)
+		}
+	}
+	sort.Slice(movies, func(i, j int) bool {
+		return movies[i].Id < movies[j].Id
+	})
+	return movies
+}
+
+func (r *memoryMovieRepository) AllGenres(ctx context.Context) ([]movieDomain.GenreWithCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[int]int)
+	for _, ids := range r.movieGenres {
+		for _, id := range ids {
+			counts[id]++
+		}
+	}
+
+	var genres []movieDomain.GenreWithCount
+	for _, g := range r.genres {
+		genres = append(genres, movieDomain.GenreWithCount{Id: g.Id, Name: g.Name, MovieCount: counts[g.Id]})
+	}
+	sort.Slice(genres, func(i, j int) bool {
+		if genres[i].Name != genres[j].Name {
+			return genres[i].Name < genres[j].Name
+		}
+		return genres[i].Id < genres[j].Id
+	})
+	return genres, nil
+}
+
+func (r *memoryMovieRepository) FindMoviesByGenreId(ctx context.Context, genreId int, offset int, limit int) ([]movieDomain.Movie, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	movies := r.moviesWhere(func(m movieDomain.Movie) bool { return containsId(r.movieGenres[m.Id], genreId) })
+	sort.SliceStable(movies, func(i, j int) bool {
+		return movies[i].Votes > movies[j].Votes
+	})
+	return paginate(movies, offset, limit), nil
+}
+
+func (r *memoryMovieRepository) AllProductionCompanies(ctx context.Context, offset int, limit int) ([]movieDomain.ProductionCompanyWithCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[int]int)
+	for _, ids := range r.movieCompanies {
+		for _, id := range ids {
+			counts[id]++
+		}
+	}
+
+	var companies []movieDomain.ProductionCompanyWithCount
+	for _, c := range r.companies {
+		companies = append(companies, movieDomain.ProductionCompanyWithCount{
+			Id: c.Id, Name: c.Name, Country: c.Country, MovieCount: counts[c.Id],
+		})
+	}
+	sort.Slice(companies, func(i, j int) bool {
+		a, b := companies[i], companies[j]
+		if a.MovieCount != b.MovieCount {
+			return a.MovieCount > b.MovieCount
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Id < b.Id
+	})
+	return paginate(companies, offset, limit), nil
+}
+
+func (r *memoryMovieRepository) ReadProductionCompanyById(ctx context.Context, companyId int) (movieDomain.ProductionCompanyWithCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.companies[companyId]
+	if !ok {
+		return movieDomain.ProductionCompanyWithCount{}, sql.ErrNoRows
+	}
+	company := movieDomain.ProductionCompanyWithCount{Id: c.Id, Name: c.Name, Country: c.Country}
+	for _, ids := range r.movieCompanies {
+		if containsId(ids, companyId) {
+			company.MovieCount++
+		}
+	}
+	return company, nil
+}
+
+func (r *memoryMovieRepository) FindMoviesByCompanyId(ctx context.Context, companyId int, offset int, limit int) ([]movieDomain.Movie, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	movies := r.moviesWhere(func(m movieDomain.Movie) bool { return containsId(r.movieCompanies[m.Id], companyId) })
+	sort.SliceStable(movies, func(i, j int) bool {
+		return movies[i].ReleaseDate > movies[j].ReleaseDate
+	})
+	return paginate(movies, offset, limit), nil
+}
+
+func (r *memoryMovieRepository) ReadCreditsByMovieId(ctx context.Context, movieId int) (movieDomain.Credits, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	credits := movieDomain.Credits{MovieId: movieId}
+	for creditId, row := range r.cast {
+		person, ok := r.people[row.personId]
+		if row.movieId != movieId || !ok {
+			continue
+		}
+		credits.Cast = append(credits.Cast, movieDomain.Cast{
+			CreditId:    creditId,
+			PersonId:    person.Id,
+			Name:        person.Name,
+			Gender:      person.Gender,
+			ProfilePath: person.ProfilePath,
+			Character:   row.character,
+			Order:       row.order,
+		})
+	}
+	sort.Slice(credits.Cast, func(i, j int) bool {
+		a, b := credits.Cast[i], credits.Cast[j]
+		if a.Order != b.Order {
+			return a.Order < b.Order
+		}
+		return a.CreditId < b.CreditId
+	})
+
+	for creditId, row := range r.crew {
+		person, ok := r.people[row.personId]
+		if row.movieId != movieId || !ok {
+			continue
+		}
+		credits.Crew = append(credits.Crew, movieDomain.Crew{
+			CreditId:    creditId,
+			PersonId:    person.Id,
+			Name:        person.Name,
+			Gender:      person.Gender,
+			ProfilePath: person.ProfilePath,
+			Job:         row.job,
+			Department:  row.department,
+		})
+	}
+	sort.Slice(credits.Crew, func(i, j int) bool {
+		a, b := credits.Crew[i], credits.Crew[j]
+		if a.Department != b.Department {
+			return a.Department < b.Department
+		}
+		if a.Job != b.Job {
+			return a.Job < b.Job
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.CreditId < b.CreditId
+	})
+
+	return credits, nil
+}
+
+func (r *memoryMovieRepository) ReadPersonById(ctx context.Context, personId int) (movieDomain.Person, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	person, ok := r.people[personId]
+	if !ok {
+		return movieDomain.Person{}, sql.ErrNoRows
+	}
+	return person, nil
+}
+
+func (r *memoryMovieRepository) FindCastCreditsByPersonId(ctx context.Context, personId int) ([]movieDomain.CastCredit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var credits []movieDomain.CastCredit
+	for _, row := range r.cast {
+		m, ok := r.movies[row.movieId]
+		if row.personId != personId || !ok {
+			continue
+		}
+		credits = append(credits, movieDomain.CastCredit{
+			MovieId:     m.Id,
+			Title:       m.Title,
+			Poster:      m.Poster,
+			ReleaseDate: m.ReleaseDate,
+			Character:   row.character,
+			Order:       row.order,
+		})
+	}
+	sort.Slice(credits, func(i, j int) bool {
+		if credits[i].ReleaseDate != credits[j].ReleaseDate {
+			return credits[i].ReleaseDate > credits[j].ReleaseDate
+		}
+		return credits[i].MovieId < credits[j].MovieId
+	})
+	return credits, nil
+}
+
+func (r *memoryMovieRepository) FindCrewCreditsByPersonId(ctx context.Context, personId int) ([]movieDomain.CrewCredit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var credits []movieDomain.CrewCredit
+	for _, row := range r.crew {
+		m, ok := r.movies[row.movieId]
+		if row.personId != personId || !ok {
+			continue
+		}
+		credits = append(credits, movieDomain.CrewCredit{
+			MovieId:     m.Id,
+			Title:       m.Title,
+			Poster:      m.Poster,
+			ReleaseDate: m.ReleaseDate,
+			Job:         row.job,
+			Department:  row.department,
+		})
+	}
+	sort.Slice(credits, func(i, j int) bool {
+		a, b := credits[i], credits[j]
+		if a.ReleaseDate != b.ReleaseDate {
+			return a.ReleaseDate > b.ReleaseDate
+		}
+		if a.MovieId != b.MovieId {
+			return a.MovieId < b.MovieId
+		}
+		return a.Job < b.Job
+	})
+	return credits, nil
+}
+
+// nameMatches is the in-memory LIKE '%name%': a case-insensitive substring
+// match, as under the database's default collation.
+func nameMatches(s string, name string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(name))
+}
+
+func (r *memoryMovieRepository) FindPeopleByName(ctx context.Context, name string, limit int) ([]movieDomain.Person, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var people []movieDomain.Person
+	for _, p := range r.people {
+		if nameMatches(p.Name, name) {
+			people = append(people, p)
+		}
+	}
+	sort.Slice(people, func(i, j int) bool {
+		if people[i].Name != people[j].Name {
+			return people[i].Name < people[j].Name
+		}
+		return people[i].Id < people[j].Id
+	})
+	return paginate(people, 0, limit), nil
+}
+
+func (r *memoryMovieRepository) FindMoviesByPersonName(ctx context.Context, name string, role string, offset int, limit int) ([]movieDomain.Movie, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make(map[int]bool)
+	switch role {
+	case movieDomain.RoleActor:
+		for _, row := range r.cast {
+			if p, ok := r.people[row.personId]; ok && nameMatches(p.Name, name) {
+				matched[row.movieId] = true
+			}
+		}
+	case movieDomain.RoleDirector:
+		for _, row := range r.crew {
+			if p, ok := r.people[row.personId]; ok && row.job == "Director" && nameMatches(p.Name, name) {
+				matched[row.movieId] = true
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown role: %q", role)
+	}
+
+	movies := r.moviesWhere(func(m movieDomain.Movie) bool { return matched[m.Id] })
+	sort.SliceStable(movies, func(i, j int) bool {
+		return movies[i].Votes > movies[j].Votes
+	})
+	return paginate(movies, offset, limit), nil
+}
+
+func (r *memoryMovieRepository) UpsertMovies(ctx context.Context, movies []movieDomain.Movie) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, m := range movies {
+		var genreIds, companyIds []int
+		for _, g := range m.Genres {
+			r.genres[g.Id] = g
+			if !containsId(genreIds, g.Id) {
+				genreIds = append(genreIds, g.Id)
+			}
+		}
+		for _, c := range m.ProductionCompanies {
+			if old, ok := r.companies[c.Id]; ok && c.Country == "" {
+				c.Country = old.Country
+			}
+			r.companies[c.Id] = c
+			if !containsId(companyIds, c.Id) {
+				companyIds = append(companyIds, c.Id)
+			}
+		}
+		r.movieGenres[m.Id] = genreIds
+		r.movieCompanies[m.Id] = companyIds
+
+		m.Genres, m.ProductionCompanies, m.Cast, m.Crew = nil, nil, nil, nil
+		r.movies[m.Id] = m
+	}
+	return nil
+}
+
+func (r *memoryMovieRepository) UpsertKeywords(ctx context.Context, keywords []movieDomain.MovieKeywords) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, mk := range keywords {
+		var keywordIds []int
+		for _, k := range mk.Keywords {
+			r.keywords[k.Id] = k
+			if !containsId(keywordIds, k.Id) {
+				keywordIds = append(keywordIds, k.Id)
+			}
+		}
+		r.movieKeywords[mk.MovieId] = keywordIds
+	}
+	return nil
+}
+
+func (r *memoryMovieRepository) UpsertCredits(ctx context.Context, credits []movieDomain.Credits) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	replaced := make(map[int]bool, len(credits))
+	for _, c := range credits {
+		replaced[c.MovieId] = true
+	}
+	for creditId, row := range r.cast {
+		if replaced[row.movieId] {
+			delete(r.cast, creditId)
+		}
+	}
+	for creditId, row := range r.crew {
+		if replaced[row.movieId] {
+			delete(r.crew, creditId)
+		}
+	}
+
+	for _, c := range credits {
+		for _, cast := range c.Cast {
+			r.people[cast.PersonId] = movieDomain.Person{Id: cast.PersonId, Name: cast.Name, Gender: cast.Gender, ProfilePath: cast.ProfilePath}
+			r.cast[cast.CreditId] = castRow{movieId: c.MovieId, personId: cast.PersonId, character: cast.Character, order: cast.Order}
+		}
+		for _, crew := range c.Crew {
+			r.people[crew.PersonId] = movieDomain.Person{Id: crew.PersonId, Name: crew.Name, Gender: crew.Gender, ProfilePath: crew.ProfilePath}
+			r.crew[crew.CreditId] = crewRow{movieId: c.MovieId, personId: crew.PersonId, job: crew.Job, department: crew.Department}
+		}
+	}
+	return nil
+}
+
+func containsId(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// paginate applies LIMIT and OFFSET to rows already in order.
+func paginate[T any](rows []T, offset int, limit int) []T {
+	if offset >= len(rows) {
+		return nil
+	}
+	rows = rows[offset:]
+	if limit < len(rows) {
+		rows = rows[:limit]
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return rows
+}
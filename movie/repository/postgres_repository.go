@@ -0,0 +1,959 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	"github.com/null-like/movie-backend/movie"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"unicode"
+)
+
+type postgresMovieRepository struct {
+	logger    *logrus.Logger
+	db        *sql.DB
+	schemaMap map[string]string
+}
+
+// NewPostgresMovieRepository reads the catalog from PostgreSQL schemas
+// migrated with the PostgreSQL dialect.
+func NewPostgresMovieRepository(l *logrus.Logger, db *sql.DB, sm map[string]string) movie.Repository {
+	return &postgresMovieRepository{
+		logger:    l,
+		db:        db,
+		schemaMap: sm,
+	}
+}
+
+func (r *postgresMovieRepository) ReadMovieById(ctx context.Context, movieId int) (movieDomain.Movie, error) {
+	query := fmt.Sprintf(`
+			SELECT id, adult, title, language, overview, poster, release_date, revenue,
+				runtime, tagline, rating, votes
+			FROM %s.Movie
+			WHERE id = %d
+		`,
+		r.schemaMap["movie"],
+		movieId,
+	)
+	r.logger.Debug(query)
+	row := r.db.QueryRowContext(ctx, query)
+
+	var movieInfo movieDomain.Movie
+	err := row.Scan(&movieInfo.Id, &movieInfo.Adult, &movieInfo.Title, &movieInfo.Language, &movieInfo.Overview,
+		&movieInfo.Poster, &movieInfo.ReleaseDate, &movieInfo.Revenue, &movieInfo.Runtime, &movieInfo.Tagline,
+		&movieInfo.Rating, &movieInfo.Votes)
+	if err != nil {
+		r.logger.Error(err)
+		return movieInfo, err
+	}
+
+	movieInfo.Genres, err = r.readGenresByMovieId(ctx, movieId)
+	if err != nil {
+		return movieInfo, err
+	}
+
+	movieInfo.ProductionCompanies, err = r.readProductionCompaniesByMovieId(ctx, movieId)
+	if err != nil {
+		return movieInfo, err
+	}
+
+	return movieInfo, nil
+}
+
+func (r *postgresMovieRepository) readGenresByMovieId(ctx context.Context, movieId int) ([]movieDomain.Genre, error) {
+	query := fmt.Sprintf(`
+			SELECT g.id, g.name
+			FROM %s.MovieGenre mg
+			JOIN %s.Genre g ON g.id = mg.genre_id
+			WHERE mg.movie_id = %d
+			ORDER BY g.name
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		movieId,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var genres []movieDomain.Genre
+	for rows.Next() {
+		var genre movieDomain.Genre
+		err = rows.Scan(&genre.Id, &genre.Name)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+
+	r.logger.Debug(query)
+	return genres, nil
+}
+
+func (r *postgresMovieRepository) readProductionCompaniesByMovieId(ctx context.Context, movieId int) ([]movieDomain.ProductionCompany, error) {
+	query := fmt.Sprintf(`
+			SELECT pc.id, pc.name, pc.country
+			FROM %s.MovieProductionCompany mpc
+			JOIN %s.ProductionCompany pc ON pc.id = mpc.company_id
+			WHERE mpc.movie_id = %d
+			ORDER BY pc.name
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		movieId,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var companies []movieDomain.ProductionCompany
+	for rows.Next() {
+		var company movieDomain.ProductionCompany
+		err = rows.Scan(&company.Id, &company.Name, &company.Country)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		companies = append(companies, company)
+	}
+
+	r.logger.Debug(query)
+	return companies, nil
+}
+
+// queryMovies runs a query selecting the Movie columns in ReadMovieById order.
+// Genres and production companies are left empty.
+func (r *postgresMovieRepository) queryMovies(ctx context.Context, query string) ([]movieDomain.Movie, error) {
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var movies []movieDomain.Movie
+	for rows.Next() {
+		var m movieDomain.Movie
+		err = rows.Scan(&m.Id, &m.Adult, &m.Title, &m.Language, &m.Overview, &m.Poster, &m.ReleaseDate, &m.Revenue,
+			&m.Runtime, &m.Tagline, &m.Rating, &m.Votes)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		movies = append(movies, m)
+	}
+
+	r.logger.Debug(query)
+	return movies, nil
+}
+
+// FindMoviesByIds reads the movies with their genres, skipping ids that don't
+// exist. Production companies are left empty.
+func (r *postgresMovieRepository) FindMoviesByIds(ctx context.Context, movieIds []int) ([]movieDomain.Movie, error) {
+	if len(movieIds) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`
+			SELECT id, adult, title, language, overview, poster, release_date, revenue,
+				runtime, tagline, rating, votes
+			FROM %s.Movie
+			WHERE id IN (%s)
+		`,
+		r.schemaMap["movie"],
+		joinIds(movieIds),
+	)
+	movies, err := r.queryMovies(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	genres, err := r.readGenresByMovieIds(ctx, movieIds)
+	if err != nil {
+		return nil, err
+	}
+	for i := range movies {
+		movies[i].Genres = genres[movies[i].Id]
+	}
+
+	return movies, nil
+}
+
+func (r *postgresMovieRepository) readGenresByMovieIds(ctx context.Context, movieIds []int) (map[int][]movieDomain.Genre, error) {
+	query := fmt.Sprintf(`
+			SELECT mg.movie_id, g.id, g.name
+			FROM %s.MovieGenre mg
+			JOIN %s.Genre g ON g.id = mg.genre_id
+			WHERE mg.movie_id IN (%s)
+			ORDER BY g.name
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		joinIds(movieIds),
+	)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	genres := map[int][]movieDomain.Genre{}
+	for rows.Next() {
+		var movieId int
+		var genre movieDomain.Genre
+		err = rows.Scan(&movieId, &genre.Id, &genre.Name)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		genres[movieId] = append(genres[movieId], genre)
+	}
+
+	r.logger.Debug(query)
+	return genres, nil
+}
+
+func (r *postgresMovieRepository) ExistMovieById(ctx context.Context, movieId int) (bool, error) {
+	query := fmt.Sprintf(`
+			SELECT EXISTS(SELECT 1 FROM %s.Movie WHERE id = %d)
+		`,
+		r.schemaMap["movie"],
+		movieId,
+	)
+	r.logger.Debug(query)
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query).Scan(&exists)
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *postgresMovieRepository) AllGenres(ctx context.Context) ([]movieDomain.GenreWithCount, error) {
+	query := fmt.Sprintf(`
+			SELECT g.id, g.name, COUNT(mg.movie_id)
+			FROM %s.Genre g
+			LEFT JOIN %s.MovieGenre mg ON mg.genre_id = g.id
+			GROUP BY g.id, g.name
+			ORDER BY g.name
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+	)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var genres []movieDomain.GenreWithCount
+	for rows.Next() {
+		var genre movieDomain.GenreWithCount
+		err = rows.Scan(&genre.Id, &genre.Name, &genre.MovieCount)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+
+	r.logger.Debug(query)
+	return genres, nil
+}
+
+func (r *postgresMovieRepository) FindMoviesByGenreId(ctx context.Context, genreId int, offset int, limit int) ([]movieDomain.Movie, error) {
+	query := fmt.Sprintf(`
+			SELECT m.id, m.adult, m.title, m.language, m.overview, m.poster, m.release_date, m.revenue,
+				m.runtime, m.tagline, m.rating, m.votes
+			FROM %s.MovieGenre mg
+			JOIN %s.Movie m ON m.id = mg.movie_id
+			WHERE mg.genre_id = %d
+			ORDER BY m.votes DESC, m.id
+			LIMIT %d OFFSET %d
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		genreId,
+		limit,
+		offset,
+	)
+
+	return r.queryMovies(ctx, query)
+}
+
+func (r *postgresMovieRepository) AllProductionCompanies(ctx context.Context, offset int, limit int) ([]movieDomain.ProductionCompanyWithCount, error) {
+	query := fmt.Sprintf(`
+			SELECT pc.id, pc.name, pc.country, COUNT(mpc.movie_id) AS movie_count
+			FROM %s.ProductionCompany pc
+			LEFT JOIN %s.MovieProductionCompany mpc ON mpc.company_id = pc.id
+			GROUP BY pc.id, pc.name, pc.country
+			ORDER BY movie_count DESC, pc.name
+			LIMIT %d OFFSET %d
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		limit,
+		offset,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var companies []movieDomain.ProductionCompanyWithCount
+	for rows.Next() {
+		var company movieDomain.ProductionCompanyWithCount
+		err = rows.Scan(&company.Id, &company.Name, &company.Country, &company.MovieCount)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		companies = append(companies, company)
+	}
+
+	r.logger.Debug(query)
+	return companies, nil
+}
+
+func (r *postgresMovieRepository) ReadProductionCompanyById(ctx context.Context, companyId int) (movieDomain.ProductionCompanyWithCount, error) {
+	query := fmt.Sprintf(`
+			SELECT pc.id, pc.name, pc.country,
+				(SELECT COUNT(*) FROM %s.MovieProductionCompany mpc WHERE mpc.company_id = pc.id)
+			FROM %s.ProductionCompany pc
+			WHERE pc.id = %d
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		companyId,
+	)
+	r.logger.Debug(query)
+
+	var company movieDomain.ProductionCompanyWithCount
+	err := r.db.QueryRowContext(ctx, query).Scan(&company.Id, &company.Name, &company.Country, &company.MovieCount)
+	if err != nil {
+		r.logger.Error(err)
+		return company, err
+	}
+
+	return company, nil
+}
+
+func (r *postgresMovieRepository) FindMoviesByCompanyId(ctx context.Context, companyId int, offset int, limit int) ([]movieDomain.Movie, error) {
+	query := fmt.Sprintf(`
+			SELECT m.id, m.adult, m.title, m.language, m.overview, m.poster, m.release_date, m.revenue,
+				m.runtime, m.tagline, m.rating, m.votes
+			FROM %s.MovieProductionCompany mpc
+			JOIN %s.Movie m ON m.id = mpc.movie_id
+			WHERE mpc.company_id = %d
+			ORDER BY m.release_date DESC, m.id
+			LIMIT %d OFFSET %d
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		companyId,
+		limit,
+		offset,
+	)
+
+	return r.queryMovies(ctx, query)
+}
+
+func (r *postgresMovieRepository) ReadCreditsByMovieId(ctx context.Context, movieId int) (movieDomain.Credits, error) {
+	credits := movieDomain.Credits{MovieId: movieId}
+
+	castQuery := fmt.Sprintf(`
+			SELECT mc.credit_id, p.id, p.name, p.gender, p.profile_path, mc.character_name, mc.cast_order
+			FROM %s.MovieCast mc
+			JOIN %s.Person p ON p.id = mc.person_id
+			WHERE mc.movie_id = %d
+			ORDER BY mc.cast_order
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		movieId,
+	)
+
+	rows, err := r.db.QueryContext(ctx, castQuery)
+	if err != nil {
+		r.logger.Error(err)
+		return credits, err
+	}
+	for rows.Next() {
+		var cast movieDomain.Cast
+		err = rows.Scan(&cast.CreditId, &cast.PersonId, &cast.Name, &cast.Gender, &cast.ProfilePath, &cast.Character, &cast.Order)
+		if err != nil {
+			r.logger.Error(err)
+			rows.Close()
+			return credits, err
+		}
+		credits.Cast = append(credits.Cast, cast)
+	}
+	err = rows.Close()
+	if err != nil {
+		r.logger.Error(err)
+	}
+	r.logger.Debug(castQuery)
+
+	crewQuery := fmt.Sprintf(`
+			SELECT mc.credit_id, p.id, p.name, p.gender, p.profile_path, mc.job, mc.department
+			FROM %s.MovieCrew mc
+			JOIN %s.Person p ON p.id = mc.person_id
+			WHERE mc.movie_id = %d
+			ORDER BY mc.department, mc.job, p.name
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		movieId,
+	)
+
+	rows, err = r.db.QueryContext(ctx, crewQuery)
+	if err != nil {
+		r.logger.Error(err)
+		return credits, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+	for rows.Next() {
+		var crew movieDomain.Crew
+		err = rows.Scan(&crew.CreditId, &crew.PersonId, &crew.Name, &crew.Gender, &crew.ProfilePath, &crew.Job, &crew.Department)
+		if err != nil {
+			r.logger.Error(err)
+			return credits, err
+		}
+		credits.Crew = append(credits.Crew, crew)
+	}
+
+	r.logger.Debug(crewQuery)
+	return credits, nil
+}
+
+func (r *postgresMovieRepository) ReadPersonById(ctx context.Context, personId int) (movieDomain.Person, error) {
+	query := fmt.Sprintf(`
+			SELECT id, name, gender, profile_path
+			FROM %s.Person
+			WHERE id = %d
+		`,
+		r.schemaMap["movie"],
+		personId,
+	)
+	r.logger.Debug(query)
+
+	var person movieDomain.Person
+	err := r.db.QueryRowContext(ctx, query).Scan(&person.Id, &person.Name, &person.Gender, &person.ProfilePath)
+	if err != nil {
+		r.logger.Error(err)
+		return person, err
+	}
+
+	return person, nil
+}
+
+func (r *postgresMovieRepository) FindCastCreditsByPersonId(ctx context.Context, personId int) ([]movieDomain.CastCredit, error) {
+	query := fmt.Sprintf(`
+			SELECT m.id, m.title, m.poster, m.release_date, mc.character_name, mc.cast_order
+			FROM %s.MovieCast mc
+			JOIN %s.Movie m ON m.id = mc.movie_id
+			WHERE mc.person_id = %d
+			ORDER BY m.release_date DESC
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		personId,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var credits []movieDomain.CastCredit
+	for rows.Next() {
+		var credit movieDomain.CastCredit
+		err = rows.Scan(&credit.MovieId, &credit.Title, &credit.Poster, &credit.ReleaseDate, &credit.Character, &credit.Order)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		credits = append(credits, credit)
+	}
+
+	r.logger.Debug(query)
+	return credits, nil
+}
+
+func (r *postgresMovieRepository) FindCrewCreditsByPersonId(ctx context.Context, personId int) ([]movieDomain.CrewCredit, error) {
+	query := fmt.Sprintf(`
+			SELECT m.id, m.title, m.poster, m.release_date, mc.job, mc.department
+			FROM %s.MovieCrew mc
+			JOIN %s.Movie m ON m.id = mc.movie_id
+			WHERE mc.person_id = %d
+			ORDER BY m.release_date DESC
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		personId,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var credits []movieDomain.CrewCredit
+	for rows.Next() {
+		var credit movieDomain.CrewCredit
+		err = rows.Scan(&credit.MovieId, &credit.Title, &credit.Poster, &credit.ReleaseDate, &credit.Job, &credit.Department)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		credits = append(credits, credit)
+	}
+
+	r.logger.Debug(query)
+	return credits, nil
+}
+
+// nameQuery turns user input into a full-text query matching names that have
+// a word starting with each word of the input, in any order. It is empty when
+// the input has no words.
+func nameQuery(s string) string {
+	words := strings.FieldsFunc(s, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+	for i, word := range words {
+		words[i] = strings.ToLower(word) + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// FindPeopleByName matches names through full-text search on their words, so
+// "doe jane" finds Jane Doe, and falls back to a case insensitive substring
+// match like the MariaDB repository for input that isn't on a word boundary.
+func (r *postgresMovieRepository) FindPeopleByName(ctx context.Context, name string, limit int) ([]movieDomain.Person, error) {
+	query := fmt.Sprintf(`
+			SELECT id, name, gender, profile_path
+			FROM %s.Person
+			WHERE to_tsvector('simple', name) @@ to_tsquery('simple', $1) OR name ILIKE $2
+			ORDER BY name, id
+			LIMIT %d
+		`,
+		r.schemaMap["movie"],
+		limit,
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.db.QueryContext(ctx, query, nameQuery(name), likePattern(name))
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var people []movieDomain.Person
+	for rows.Next() {
+		var person movieDomain.Person
+		err = rows.Scan(&person.Id, &person.Name, &person.Gender, &person.ProfilePath)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		people = append(people, person)
+	}
+
+	return people, nil
+}
+
+func (r *postgresMovieRepository) FindMoviesByPersonName(ctx context.Context, name string, role string, offset int, limit int) ([]movieDomain.Movie, error) {
+	var credits string
+	switch role {
+	case movieDomain.RoleActor:
+		credits = fmt.Sprintf(`SELECT movie_id, person_id FROM %s.MovieCast`, r.schemaMap["movie"])
+	case movieDomain.RoleDirector:
+		credits = fmt.Sprintf(`SELECT movie_id, person_id FROM %s.MovieCrew WHERE job = 'Director'`, r.schemaMap["movie"])
+	default:
+		return nil, fmt.Errorf("unknown role: %q", role)
+	}
+
+	query := fmt.Sprintf(`
+			SELECT DISTINCT m.id, m.adult, m.title, m.language, m.overview, m.poster, m.release_date, m.revenue,
+				m.runtime, m.tagline, m.rating, m.votes
+			FROM (%s) c
+			JOIN %s.Person p ON p.id = c.person_id
+			JOIN %s.Movie m ON m.id = c.movie_id
+			WHERE to_tsvector('simple', p.name) @@ to_tsquery('simple', $1) OR p.name ILIKE $2
+			ORDER BY m.votes DESC, m.id
+			LIMIT %d OFFSET %d
+		`,
+		credits,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		limit,
+		offset,
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.db.QueryContext(ctx, query, nameQuery(name), likePattern(name))
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var movies []movieDomain.Movie
+	for rows.Next() {
+		var m movieDomain.Movie
+		err = rows.Scan(&m.Id, &m.Adult, &m.Title, &m.Language, &m.Overview, &m.Poster, &m.ReleaseDate, &m.Revenue,
+			&m.Runtime, &m.Tagline, &m.Rating, &m.Votes)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		movies = append(movies, m)
+	}
+
+	return movies, nil
+}
+
+// bulkInsert runs "prefix VALUES (...), (...) suffix" for rows, splitting them
+// into as many statements as the placeholder limit requires. Bulk imports carry
+// free text (titles, overviews, character names), so values are bound instead
+// of being formatted into the query. PostgreSQL refuses to upsert the same row
+// twice in one statement, so for upserts only the last row of each key, the
+// first column, is kept.
+func (r *postgresMovieRepository) bulkInsert(ctx context.Context, tx *sql.Tx, prefix string, suffix string, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if suffix != "" {
+		rows = lastByKey(rows)
+	}
+
+	columns := len(rows[0])
+	chunk := maxPlaceholders / columns
+
+	for start := 0; start < len(rows); start += chunk {
+		end := start + chunk
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		tuples := make([]string, 0, end-start)
+		args := make([]interface{}, 0, (end-start)*columns)
+		for _, row := range rows[start:end] {
+			placeholders := make([]string, columns)
+			for i := range placeholders {
+				placeholders[i] = fmt.Sprintf("$%d", len(args)+i+1)
+			}
+			tuples = append(tuples, "("+strings.Join(placeholders, ", ")+")")
+			args = append(args, row...)
+		}
+
+		query := fmt.Sprintf("%s VALUES %s %s", prefix, strings.Join(tuples, ", "), suffix)
+		r.logger.Debugf("%s (%d rows)", prefix, end-start)
+		_, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lastByKey drops the rows whose first column repeats in a later row.
+func lastByKey(rows [][]interface{}) [][]interface{} {
+	last := make(map[interface{}]int, len(rows))
+	for i, row := range rows {
+		last[row[0]] = i
+	}
+
+	kept := make([][]interface{}, 0, len(last))
+	for i, row := range rows {
+		if last[row[0]] == i {
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
+
+func (r *postgresMovieRepository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		r.logger.Error(err)
+		if rbErr := tx.Rollback(); rbErr != nil {
+			r.logger.Error(rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *postgresMovieRepository) UpsertMovies(ctx context.Context, movies []movieDomain.Movie) error {
+	if len(movies) == 0 {
+		return nil
+	}
+
+	schema := r.schemaMap["movie"]
+	var movieIds []int
+	var movieRows, genreRows, companyRows, movieGenreRows, movieCompanyRows [][]interface{}
+	seenGenre := make(map[[2]int]bool)
+	seenCompany := make(map[[2]int]bool)
+	companyRow := make(map[int]int)
+	for _, m := range movies {
+		movieIds = append(movieIds, m.Id)
+		movieRows = append(movieRows, []interface{}{
+			m.Id, m.Adult, m.Title, m.Language, m.Overview, m.Poster, m.ReleaseDate, m.Revenue, m.Runtime,
+			m.Tagline, m.Rating, m.Votes,
+		})
+		for _, g := range m.Genres {
+			genreRows = append(genreRows, []interface{}{g.Id, g.Name})
+			if !seenGenre[[2]int{m.Id, g.Id}] {
+				seenGenre[[2]int{m.Id, g.Id}] = true
+				movieGenreRows = append(movieGenreRows, []interface{}{m.Id, g.Id})
+			}
+		}
+		for _, c := range m.ProductionCompanies {
+			// A company repeated without a country keeps the one it was
+			// given earlier in the batch, as the upsert does across batches.
+			if i, ok := companyRow[c.Id]; ok && c.Country == "" {
+				c.Country = companyRows[i][2].(string)
+			}
+			companyRow[c.Id] = len(companyRows)
+			companyRows = append(companyRows, []interface{}{c.Id, c.Name, c.Country})
+			if !seenCompany[[2]int{m.Id, c.Id}] {
+				seenCompany[[2]int{m.Id, c.Id}] = true
+				movieCompanyRows = append(movieCompanyRows, []interface{}{m.Id, c.Id})
+			}
+		}
+	}
+
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		err := r.bulkInsert(ctx, tx,
+			fmt.Sprintf(`INSERT INTO %s.Movie (id, adult, title, language, overview, poster, release_date, revenue,
+				runtime, tagline, rating, votes)`, schema),
+			`ON CONFLICT (id) DO UPDATE SET adult = EXCLUDED.adult, title = EXCLUDED.title, language = EXCLUDED.language,
+				overview = EXCLUDED.overview, poster = EXCLUDED.poster, release_date = EXCLUDED.release_date,
+				revenue = EXCLUDED.revenue, runtime = EXCLUDED.runtime, tagline = EXCLUDED.tagline,
+				rating = EXCLUDED.rating, votes = EXCLUDED.votes`,
+			movieRows,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = r.bulkInsert(ctx, tx,
+			fmt.Sprintf(`INSERT INTO %s.Genre (id, name)`, schema),
+			`ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name`,
+			genreRows,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = r.bulkInsert(ctx, tx,
+			fmt.Sprintf(`INSERT INTO %s.ProductionCompany AS pc (id, name, country)`, schema),
+			`ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name,
+				country = CASE WHEN EXCLUDED.country = '' THEN pc.country ELSE EXCLUDED.country END`,
+			companyRows,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s.MovieGenre WHERE movie_id IN (%s)`, schema, joinIds(movieIds)))
+		if err != nil {
+			return err
+		}
+		err = r.bulkInsert(ctx, tx, fmt.Sprintf(`INSERT INTO %s.MovieGenre (movie_id, genre_id)`, schema), "", movieGenreRows)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s.MovieProductionCompany WHERE movie_id IN (%s)`, schema, joinIds(movieIds)))
+		if err != nil {
+			return err
+		}
+		return r.bulkInsert(ctx, tx, fmt.Sprintf(`INSERT INTO %s.MovieProductionCompany (movie_id, company_id)`, schema), "", movieCompanyRows)
+	})
+}
+
+func (r *postgresMovieRepository) UpsertKeywords(ctx context.Context, keywords []movieDomain.MovieKeywords) error {
+	if len(keywords) == 0 {
+		return nil
+	}
+
+	schema := r.schemaMap["movie"]
+	var movieIds []int
+	var keywordRows, movieKeywordRows [][]interface{}
+	seen := make(map[[2]int]bool)
+	for _, mk := range keywords {
+		movieIds = append(movieIds, mk.MovieId)
+		for _, k := range mk.Keywords {
+			keywordRows = append(keywordRows, []interface{}{k.Id, k.Name})
+			if !seen[[2]int{mk.MovieId, k.Id}] {
+				seen[[2]int{mk.MovieId, k.Id}] = true
+				movieKeywordRows = append(movieKeywordRows, []interface{}{mk.MovieId, k.Id})
+			}
+		}
+	}
+
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		err := r.bulkInsert(ctx, tx,
+			fmt.Sprintf(`INSERT INTO %s.Keyword (id, name)`, schema),
+			`ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name`,
+			keywordRows,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s.MovieKeyword WHERE movie_id IN (%s)`, schema, joinIds(movieIds)))
+		if err != nil {
+			return err
+		}
+		return r.bulkInsert(ctx, tx, fmt.Sprintf(`INSERT INTO %s.MovieKeyword (movie_id, keyword_id)`, schema), "", movieKeywordRows)
+	})
+}
+
+func (r *postgresMovieRepository) UpsertCredits(ctx context.Context, credits []movieDomain.Credits) error {
+	if len(credits) == 0 {
+		return nil
+	}
+
+	schema := r.schemaMap["movie"]
+	var movieIds []int
+	var personRows, castRows, crewRows [][]interface{}
+	for _, c := range credits {
+		movieIds = append(movieIds, c.MovieId)
+		for _, cast := range c.Cast {
+			personRows = append(personRows, []interface{}{cast.PersonId, cast.Name, cast.Gender, cast.ProfilePath})
+			castRows = append(castRows, []interface{}{cast.CreditId, c.MovieId, cast.PersonId, cast.Character, cast.Order})
+		}
+		for _, crew := range c.Crew {
+			personRows = append(personRows, []interface{}{crew.PersonId, crew.Name, crew.Gender, crew.ProfilePath})
+			crewRows = append(crewRows, []interface{}{crew.CreditId, c.MovieId, crew.PersonId, crew.Job, crew.Department})
+		}
+	}
+
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		err := r.bulkInsert(ctx, tx,
+			fmt.Sprintf(`INSERT INTO %s.Person (id, name, gender, profile_path)`, schema),
+			`ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, gender = EXCLUDED.gender, profile_path = EXCLUDED.profile_path`,
+			personRows,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s.MovieCast WHERE movie_id IN (%s)`, schema, joinIds(movieIds)))
+		if err != nil {
+			return err
+		}
+		err = r.bulkInsert(ctx, tx,
+			fmt.Sprintf(`INSERT INTO %s.MovieCast (credit_id, movie_id, person_id, character_name, cast_order)`, schema),
+			`ON CONFLICT (credit_id) DO UPDATE SET movie_id = EXCLUDED.movie_id, person_id = EXCLUDED.person_id,
+				character_name = EXCLUDED.character_name, cast_order = EXCLUDED.cast_order`,
+			castRows,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s.MovieCrew WHERE movie_id IN (%s)`, schema, joinIds(movieIds)))
+		if err != nil {
+			return err
+		}
+		return r.bulkInsert(ctx, tx,
+			fmt.Sprintf(`INSERT INTO %s.MovieCrew (credit_id, movie_id, person_id, job, department)`, schema),
+			`ON CONFLICT (credit_id) DO UPDATE SET movie_id = EXCLUDED.movie_id, person_id = EXCLUDED.person_id,
+				job = EXCLUDED.job, department = EXCLUDED.department`,
+			crewRows,
+		)
+	})
+}
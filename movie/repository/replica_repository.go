@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	"github.com/null-like/movie-backend/movie"
+	"github.com/null-like/movie-backend/replica"
+)
+
+type replicaMovieRepository struct {
+	router   *replica.Router
+	primary  movie.Repository
+	replicas []movie.Repository
+}
+
+// NewReplicaMovieRepository sends the Find, All, Read and Exist methods to
+// the repository of the replica the router picks and everything else to
+// primary. replicas[i] reads from the router's i-th replica.
+func NewReplicaMovieRepository(router *replica.Router, primary movie.Repository, replicas []movie.Repository) movie.Repository {
+	return &replicaMovieRepository{
+		router:   router,
+		primary:  primary,
+		replicas: replicas,
+	}
+}
+
+func (r *replicaMovieRepository) on(i int) movie.Repository {
+	if i == replica.Primary {
+		return r.primary
+	}
+	return r.replicas[i]
+}
+
+func (r *replicaMovieRepository) ReadMovieById(ctx context.Context, movieId int) (movieDomain.Movie, error) {
+	return replica.Read(ctx, r.router, func(i int) (movieDomain.Movie, error) {
+		return r.on(i).ReadMovieById(ctx, movieId)
+	})
+}
+
+func (r *replicaMovieRepository) ExistMovieById(ctx context.Context, movieId int) (bool, error) {
+	return replica.Read(ctx, r.router, func(i int) (bool, error) {
+		return r.on(i).ExistMovieById(ctx, movieId)
+	})
+}
+
+func (r *replicaMovieRepository) FindMoviesByIds(ctx context.Context, movieIds []int) ([]movieDomain.Movie, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]movieDomain.Movie, error) {
+		return r.on(i).FindMoviesByIds(ctx, movieIds)
+	})
+}
+
+func (r *replicaMovieRepository) AllGenres(ctx context.Context) ([]movieDomain.GenreWithCount, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]movieDomain.GenreWithCount, error) {
+		return r.on(i).AllGenres(ctx)
+	})
+}
+
+func (r *replicaMovieRepository) FindMoviesByGenreId(ctx context.Context, genreId int, offset int, limit int) ([]movieDomain.Movie, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]movieDomain.Movie, error) {
+		return r.on(i).FindMoviesByGenreId(ctx, genreId, offset, limit)
+	})
+}
+
+func (r *replicaMovieRepository) AllProductionCompanies(ctx context.Context, offset int, limit int) ([]movieDomain.ProductionCompanyWithCount, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]movieDomain.ProductionCompanyWithCount, error) {
+		return r.on(i).AllProductionCompanies(ctx, offset, limit)
+	})
+}
+
+func (r *replicaMovieRepository) ReadProductionCompanyById(ctx context.Context, companyId int) (movieDomain.ProductionCompanyWithCount, error) {
+	return replica.Read(ctx, r.router, func(i int) (movieDomain.ProductionCompanyWithCount, error) {
+		return r.on(i).ReadProductionCompanyById(ctx, companyId)
+	})
+}
+
+func (r *replicaMovieRepository) FindMoviesByCompanyId(ctx context.Context, companyId int, offset int, limit int) ([]movieDomain.Movie, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]movieDomain.Movie, error) {
+		return r.on(i).FindMoviesByCompanyId(ctx, companyId, offset, limit)
+	})
+}
+
+func (r *replicaMovieRepository) ReadCreditsByMovieId(ctx context.Context, movieId int) (movieDomain.Credits, error) {
+	return replica.Read(ctx, r.router, func(i int) (movieDomain.Credits, error) {
+		return r.on(i).ReadCreditsByMovieId(ctx, movieId)
+	})
+}
+
+func (r *replicaMovieRepository) ReadPersonById(ctx context.Context, personId int) (movieDomain.Person, error) {
+	return replica.Read(ctx, r.router, func(i int) (movieDomain.Person, error) {
+		return r.on(i).ReadPersonById(ctx, personId)
+	})
+}
+
+func (r *replicaMovieRepository) FindCastCreditsByPersonId(ctx context.Context, personId int) ([]movieDomain.CastCredit, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]movieDomain.CastCredit, error) {
+		return r.on(i).FindCastCreditsByPersonId(ctx, personId)
+	})
+}
+
+func (r *replicaMovieRepository) FindCrewCreditsByPersonId(ctx context.Context, personId int) ([]movieDomain.CrewCredit, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]movieDomain.CrewCredit, error) {
+		return r.on(i).FindCrewCreditsByPersonId(ctx, personId)
+	})
+}
+
+func (r *replicaMovieRepository) FindPeopleByName(ctx context.Context, name string, limit int) ([]movieDomain.Person, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]movieDomain.Person, error) {
+		return r.on(i).FindPeopleByName(ctx, name, limit)
+	})
+}
+
+func (r *replicaMovieRepository) FindMoviesByPersonName(ctx context.Context, name string, role string, offset int, limit int) ([]movieDomain.Movie, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]movieDomain.Movie, error) {
+		return r.on(i).FindMoviesByPersonName(ctx, name, role, offset, limit)
+	})
+}
+
+func (r *replicaMovieRepository) UpsertMovies(ctx context.Context, movies []movieDomain.Movie) error {
+	replica.Wrote(ctx)
+	return r.primary.UpsertMovies(ctx, movies)
+}
+
+func (r *replicaMovieRepository) UpsertKeywords(ctx context.Context, keywords []movieDomain.MovieKeywords) error {
+	replica.Wrote(ctx)
+	return r.primary.UpsertKeywords(ctx, keywords)
+}
+
+func (r *replicaMovieRepository) UpsertCredits(ctx context.Context, credits []movieDomain.Credits) error {
+	replica.Wrote(ctx)
+	return r.primary.UpsertCredits(ctx, credits)
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/null-like/movie-backend/movie"
+	"github.com/null-like/movie-backend/replica"
+	"github.com/null-like/movie-backend/repositorytest"
+	"testing"
+)
+
+func TestMemoryMovieRepository(t *testing.T) {
+	repositorytest.MovieRepository(t, func(t *testing.T) movie.Repository {
+		return NewMemoryMovieRepository()
+	})
+}
+
+func TestMariaDBMovieRepository(t *testing.T) {
+	repositorytest.RequireMariaDB(t)
+	repositorytest.MovieRepository(t, func(t *testing.T) movie.Repository {
+		db, schemaMap := repositorytest.MariaDB(t)
+		return NewMariaDBMovieRepository(repositorytest.Logger(), db, schemaMap)
+	})
+}
+
+func TestSQLiteMovieRepository(t *testing.T) {
+	repositorytest.MovieRepository(t, func(t *testing.T) movie.Repository {
+		return NewSQLiteMovieRepository(repositorytest.Logger(), repositorytest.SQLite(t))
+	})
+}
+
+func TestPostgresMovieRepository(t *testing.T) {
+	repositorytest.RequirePostgres(t)
+	repositorytest.MovieRepository(t, func(t *testing.T) movie.Repository {
+		db, schemaMap := repositorytest.Postgres(t)
+		return NewPostgresMovieRepository(repositorytest.Logger(), db, schemaMap)
+	})
+}
+
+func TestReplicaMovieRepository(t *testing.T) {
+	repositorytest.MovieRepository(t, func(t *testing.T) movie.Repository {
+		db := repositorytest.SQLite(t)
+		router := replica.NewRouter(repositorytest.Logger(), []*sql.DB{db})
+		return NewReplicaMovieRepository(router, NewSQLiteMovieRepository(repositorytest.Logger(), db),
+			[]movie.Repository{NewSQLiteMovieRepository(repositorytest.Logger(), db)})
+	})
+}
@@ -0,0 +1,880 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	"github.com/null-like/movie-backend/movie"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+type sqliteMovieRepository struct {
+	logger *logrus.Logger
+	db     *sql.DB
+}
+
+// NewSQLiteMovieRepository reads the catalog from a SQLite database migrated
+// with the SQLite dialect. SQLite has no schemas, so there is no schema map.
+func NewSQLiteMovieRepository(l *logrus.Logger, db *sql.DB) movie.Repository {
+	return &sqliteMovieRepository{
+		logger: l,
+		db:     db,
+	}
+}
+
+func (r *sqliteMovieRepository) ReadMovieById(ctx context.Context, movieId int) (movieDomain.Movie, error) {
+	query := fmt.Sprintf(`
+			SELECT id, adult, title, language, overview, poster, release_date, revenue,
+				runtime, tagline, rating, votes
+			FROM Movie
+			WHERE id = %d
+		`,
+		movieId,
+	)
+	r.logger.Debug(query)
+	row := r.db.QueryRowContext(ctx, query)
+
+	var movieInfo movieDomain.Movie
+	err := row.Scan(&movieInfo.Id, &movieInfo.Adult, &movieInfo.Title, &movieInfo.Language, &movieInfo.Overview,
+		&movieInfo.Poster, &movieInfo.ReleaseDate, &movieInfo.Revenue, &movieInfo.Runtime, &movieInfo.Tagline,
+		&movieInfo.Rating, &movieInfo.Votes)
+	if err != nil {
+		r.logger.Error(err)
+		return movieInfo, err
+	}
+
+	movieInfo.Genres, err = r.readGenresByMovieId(ctx, movieId)
+	if err != nil {
+		return movieInfo, err
+	}
+
+	movieInfo.ProductionCompanies, err = r.readProductionCompaniesByMovieId(ctx, movieId)
+	if err != nil {
+		return movieInfo, err
+	}
+
+	return movieInfo, nil
+}
+
+func (r *sqliteMovieRepository) readGenresByMovieId(ctx context.Context, movieId int) ([]movieDomain.Genre, error) {
+	query := fmt.Sprintf(`
+			SELECT g.id, g.name
+			FROM MovieGenre mg
+			JOIN Genre g ON g.id = mg.genre_id
+			WHERE mg.movie_id = %d
+			ORDER BY g.name
+		`,
+		movieId,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var genres []movieDomain.Genre
+	for rows.Next() {
+		var genre movieDomain.Genre
+		err = rows.Scan(&genre.Id, &genre.Name)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+
+	r.logger.Debug(query)
+	return genres, nil
+}
+
+func (r *sqliteMovieRepository) readProductionCompaniesByMovieId(ctx context.Context, movieId int) ([]movieDomain.ProductionCompany, error) {
+	query := fmt.Sprintf(`
+			SELECT pc.id, pc.name, pc.country
+			FROM MovieProductionCompany mpc
+			JOIN ProductionCompany pc ON pc.id = mpc.company_id
+			WHERE mpc.movie_id = %d
+			ORDER BY pc.name
+		`,
+		movieId,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var companies []movieDomain.ProductionCompany
+	for rows.Next() {
+		var company movieDomain.ProductionCompany
+		err = rows.Scan(&company.Id, &company.Name, &company.Country)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		companies = append(companies, company)
+	}
+
+	r.logger.Debug(query)
+	return companies, nil
+}
+
+// queryMovies runs a query selecting the Movie columns in ReadMovieById order.
+// Genres and production companies are left empty.
+func (r *sqliteMovieRepository) queryMovies(ctx context.Context, query string) ([]movieDomain.Movie, error) {
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var movies []movieDomain.Movie
+	for rows.Next() {
+		var m movieDomain.Movie
+		err = rows.Scan(&m.Id, &m.Adult, &m.Title, &m.Language, &m.Overview, &m.Poster, &m.ReleaseDate, &m.Revenue,
+			&m.Runtime, &m.Tagline, &m.Rating, &m.Votes)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		movies = append(movies, m)
+	}
+
+	r.logger.Debug(query)
+	return movies, nil
+}
+
+// FindMoviesByIds reads the movies with their genres, skipping ids that don't
+// exist. Production companies are left empty.
+func (r *sqliteMovieRepository) FindMoviesByIds(ctx context.Context, movieIds []int) ([]movieDomain.Movie, error) {
+	if len(movieIds) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`
+			SELECT id, adult, title, language, overview, poster, release_date, revenue,
+				runtime, tagline, rating, votes
+			FROM Movie
+			WHERE id IN (%s)
+		`,
+		joinIds(movieIds),
+	)
+	movies, err := r.queryMovies(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	genres, err := r.readGenresByMovieIds(ctx, movieIds)
+	if err != nil {
+		return nil, err
+	}
+	for i := range movies {
+		movies[i].Genres = genres[movies[i].Id]
+	}
+
+	return movies, nil
+}
+
+func (r *sqliteMovieRepository) readGenresByMovieIds(ctx context.Context, movieIds []int) (map[int][]movieDomain.Genre, error) {
+	query := fmt.Sprintf(`
+			SELECT mg.movie_id, g.id, g.name
+			FROM MovieGenre mg
+			JOIN Genre g ON g.id = mg.genre_id
+			WHERE mg.movie_id IN (%s)
+			ORDER BY g.name
+		`,
+		joinIds(movieIds),
+	)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	genres := map[int][]movieDomain.Genre{}
+	for rows.Next() {
+		var movieId int
+		var genre movieDomain.Genre
+		err = rows.Scan(&movieId, &genre.Id, &genre.Name)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		genres[movieId] = append(genres[movieId], genre)
+	}
+
+	r.logger.Debug(query)
+	return genres, nil
+}
+
+func (r *sqliteMovieRepository) ExistMovieById(ctx context.Context, movieId int) (bool, error) {
+	query := fmt.Sprintf(`
+			SELECT EXISTS(SELECT 1 FROM Movie WHERE id = %d)
+		`,
+		movieId,
+	)
+	r.logger.Debug(query)
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query).Scan(&exists)
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *sqliteMovieRepository) AllGenres(ctx context.Context) ([]movieDomain.GenreWithCount, error) {
+	query := `
+			SELECT g.id, g.name, COUNT(mg.movie_id)
+			FROM Genre g
+			LEFT JOIN MovieGenre mg ON mg.genre_id = g.id
+			GROUP BY g.id, g.name
+			ORDER BY g.name
+		`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var genres []movieDomain.GenreWithCount
+	for rows.Next() {
+		var genre movieDomain.GenreWithCount
+		err = rows.Scan(&genre.Id, &genre.Name, &genre.MovieCount)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+
+	r.logger.Debug(query)
+	return genres, nil
+}
+
+func (r *sqliteMovieRepository) FindMoviesByGenreId(ctx context.Context, genreId int, offset int, limit int) ([]movieDomain.Movie, error) {
+	query := fmt.Sprintf(`
+			SELECT m.id, m.adult, m.title, m.language, m.overview, m.poster, m.release_date, m.revenue,
+				m.runtime, m.tagline, m.rating, m.votes
+			FROM MovieGenre mg
+			JOIN Movie m ON m.id = mg.movie_id
+			WHERE mg.genre_id = %d
+			ORDER BY m.votes DESC, m.id
+			LIMIT %d OFFSET %d
+		`,
+		genreId,
+		limit,
+		offset,
+	)
+
+	return r.queryMovies(ctx, query)
+}
+
+func (r *sqliteMovieRepository) AllProductionCompanies(ctx context.Context, offset int, limit int) ([]movieDomain.ProductionCompanyWithCount, error) {
+	query := fmt.Sprintf(`
+			SELECT pc.id, pc.name, pc.country, COUNT(mpc.movie_id) AS movie_count
+			FROM ProductionCompany pc
+			LEFT JOIN MovieProductionCompany mpc ON mpc.company_id = pc.id
+			GROUP BY pc.id, pc.name, pc.country
+			ORDER BY movie_count DESC, pc.name
+			LIMIT %d OFFSET %d
+		`,
+		limit,
+		offset,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var companies []movieDomain.ProductionCompanyWithCount
+	for rows.Next() {
+		var company movieDomain.ProductionCompanyWithCount
+		err = rows.Scan(&company.Id, &company.Name, &company.Country, &company.MovieCount)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		companies = append(companies, company)
+	}
+
+	r.logger.Debug(query)
+	return companies, nil
+}
+
+func (r *sqliteMovieRepository) ReadProductionCompanyById(ctx context.Context, companyId int) (movieDomain.ProductionCompanyWithCount, error) {
+	query := fmt.Sprintf(`
+			SELECT pc.id, pc.name, pc.country,
+				(SELECT COUNT(*) FROM MovieProductionCompany mpc WHERE mpc.company_id = pc.id)
+			FROM ProductionCompany pc
+			WHERE pc.id = %d
+		`,
+		companyId,
+	)
+	r.logger.Debug(query)
+
+	var company movieDomain.ProductionCompanyWithCount
+	err := r.db.QueryRowContext(ctx, query).Scan(&company.Id, &company.Name, &company.Country, &company.MovieCount)
+	if err != nil {
+		r.logger.Error(err)
+		return company, err
+	}
+
+	return company, nil
+}
+
+func (r *sqliteMovieRepository) FindMoviesByCompanyId(ctx context.Context, companyId int, offset int, limit int) ([]movieDomain.Movie, error) {
+	query := fmt.Sprintf(`
+			SELECT m.id, m.adult, m.title, m.language, m.overview, m.poster, m.release_date, m.revenue,
+				m.runtime, m.tagline, m.rating, m.votes
+			FROM MovieProductionCompany mpc
+			JOIN Movie m ON m.id = mpc.movie_id
+			WHERE mpc.company_id = %d
+			ORDER BY m.release_date DESC, m.id
+			LIMIT %d OFFSET %d
+		`,
+		companyId,
+		limit,
+		offset,
+	)
+
+	return r.queryMovies(ctx, query)
+}
+
+func (r *sqliteMovieRepository) ReadCreditsByMovieId(ctx context.Context, movieId int) (movieDomain.Credits, error) {
+	credits := movieDomain.Credits{MovieId: movieId}
+
+	castQuery := fmt.Sprintf(`
+			SELECT mc.credit_id, p.id, p.name, p.gender, p.profile_path, mc.character_name, mc.cast_order
+			FROM MovieCast mc
+			JOIN Person p ON p.id = mc.person_id
+			WHERE mc.movie_id = %d
+			ORDER BY mc.cast_order
+		`,
+		movieId,
+	)
+
+	rows, err := r.db.QueryContext(ctx, castQuery)
+	if err != nil {
+		r.logger.Error(err)
+		return credits, err
+	}
+	for rows.Next() {
+		var cast movieDomain.Cast
+		err = rows.Scan(&cast.CreditId, &cast.PersonId, &cast.Name, &cast.Gender, &cast.ProfilePath, &cast.Character, &cast.Order)
+		if err != nil {
+			r.logger.Error(err)
+			rows.Close()
+			return credits, err
+		}
+		credits.Cast = append(credits.Cast, cast)
+	}
+	err = rows.Close()
+	if err != nil {
+		r.logger.Error(err)
+	}
+	r.logger.Debug(castQuery)
+
+	crewQuery := fmt.Sprintf(`
+			SELECT mc.credit_id, p.id, p.name, p.gender, p.profile_path, mc.job, mc.department
+			FROM MovieCrew mc
+			JOIN Person p ON p.id = mc.person_id
+			WHERE mc.movie_id = %d
+			ORDER BY mc.department, mc.job, p.name
+		`,
+		movieId,
+	)
+
+	rows, err = r.db.QueryContext(ctx, crewQuery)
+	if err != nil {
+		r.logger.Error(err)
+		return credits, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+	for rows.Next() {
+		var crew movieDomain.Crew
+		err = rows.Scan(&crew.CreditId, &crew.PersonId, &crew.Name, &crew.Gender, &crew.ProfilePath, &crew.Job, &crew.Department)
+		if err != nil {
+			r.logger.Error(err)
+			return credits, err
+		}
+		credits.Crew = append(credits.Crew, crew)
+	}
+
+	r.logger.Debug(crewQuery)
+	return credits, nil
+}
+
+func (r *sqliteMovieRepository) ReadPersonById(ctx context.Context, personId int) (movieDomain.Person, error) {
+	query := fmt.Sprintf(`
+			SELECT id, name, gender, profile_path
+			FROM Person
+			WHERE id = %d
+		`,
+		personId,
+	)
+	r.logger.Debug(query)
+
+	var person movieDomain.Person
+	err := r.db.QueryRowContext(ctx, query).Scan(&person.Id, &person.Name, &person.Gender, &person.ProfilePath)
+	if err != nil {
+		r.logger.Error(err)
+		return person, err
+	}
+
+	return person, nil
+}
+
+func (r *sqliteMovieRepository) FindCastCreditsByPersonId(ctx context.Context, personId int) ([]movieDomain.CastCredit, error) {
+	query := fmt.Sprintf(`
+			SELECT m.id, m.title, m.poster, m.release_date, mc.character_name, mc.cast_order
+			FROM MovieCast mc
+			JOIN Movie m ON m.id = mc.movie_id
+			WHERE mc.person_id = %d
+			ORDER BY m.release_date DESC
+		`,
+		personId,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var credits []movieDomain.CastCredit
+	for rows.Next() {
+		var credit movieDomain.CastCredit
+		err = rows.Scan(&credit.MovieId, &credit.Title, &credit.Poster, &credit.ReleaseDate, &credit.Character, &credit.Order)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		credits = append(credits, credit)
+	}
+
+	r.logger.Debug(query)
+	return credits, nil
+}
+
+func (r *sqliteMovieRepository) FindCrewCreditsByPersonId(ctx context.Context, personId int) ([]movieDomain.CrewCredit, error) {
+	query := fmt.Sprintf(`
+			SELECT m.id, m.title, m.poster, m.release_date, mc.job, mc.department
+			FROM MovieCrew mc
+			JOIN Movie m ON m.id = mc.movie_id
+			WHERE mc.person_id = %d
+			ORDER BY m.release_date DESC
+		`,
+		personId,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var credits []movieDomain.CrewCredit
+	for rows.Next() {
+		var credit movieDomain.CrewCredit
+		err = rows.Scan(&credit.MovieId, &credit.Title, &credit.Poster, &credit.ReleaseDate, &credit.Job, &credit.Department)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		credits = append(credits, credit)
+	}
+
+	r.logger.Debug(query)
+	return credits, nil
+}
+
+// FindPeopleByName matches like the MariaDB repository. SQLite's LIKE is case
+// insensitive too but has no default escape character, so it is named.
+func (r *sqliteMovieRepository) FindPeopleByName(ctx context.Context, name string, limit int) ([]movieDomain.Person, error) {
+	query := fmt.Sprintf(`
+			SELECT id, name, gender, profile_path
+			FROM Person
+			WHERE name LIKE ? ESCAPE '\'
+			ORDER BY name, id
+			LIMIT %d
+		`,
+		limit,
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.db.QueryContext(ctx, query, likePattern(name))
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var people []movieDomain.Person
+	for rows.Next() {
+		var person movieDomain.Person
+		err = rows.Scan(&person.Id, &person.Name, &person.Gender, &person.ProfilePath)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		people = append(people, person)
+	}
+
+	return people, nil
+}
+
+func (r *sqliteMovieRepository) FindMoviesByPersonName(ctx context.Context, name string, role string, offset int, limit int) ([]movieDomain.Movie, error) {
+	var credits string
+	switch role {
+	case movieDomain.RoleActor:
+		credits = `SELECT movie_id, person_id FROM MovieCast`
+	case movieDomain.RoleDirector:
+		credits = `SELECT movie_id, person_id FROM MovieCrew WHERE job = 'Director'`
+	default:
+		return nil, fmt.Errorf("unknown role: %q", role)
+	}
+
+	query := fmt.Sprintf(`
+			SELECT DISTINCT m.id, m.adult, m.title, m.language, m.overview, m.poster, m.release_date, m.revenue,
+				m.runtime, m.tagline, m.rating, m.votes
+			FROM (%s) c
+			JOIN Person p ON p.id = c.person_id
+			JOIN Movie m ON m.id = c.movie_id
+			WHERE p.name LIKE ? ESCAPE '\'
+			ORDER BY m.votes DESC, m.id
+			LIMIT %d OFFSET %d
+		`,
+		credits,
+		limit,
+		offset,
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.db.QueryContext(ctx, query, likePattern(name))
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var movies []movieDomain.Movie
+	for rows.Next() {
+		var m movieDomain.Movie
+		err = rows.Scan(&m.Id, &m.Adult, &m.Title, &m.Language, &m.Overview, &m.Poster, &m.ReleaseDate, &m.Revenue,
+			&m.Runtime, &m.Tagline, &m.Rating, &m.Votes)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		movies = append(movies, m)
+	}
+
+	return movies, nil
+}
+
+// sqliteMaxVariables keeps bulk statements below SQLite's default limit of
+// 32766 bound variables.
+const sqliteMaxVariables = 30000
+
+// bulkInsert runs "prefix VALUES (...), (...) suffix" for rows, splitting them
+// into as many statements as the placeholder limit requires. Bulk imports carry
+// free text (titles, overviews, character names), so values are bound instead
+// of being formatted into the query.
+func (r *sqliteMovieRepository) bulkInsert(ctx context.Context, tx *sql.Tx, prefix string, suffix string, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	columns := len(rows[0])
+	tuple := "(" + strings.TrimSuffix(strings.Repeat("?, ", columns), ", ") + ")"
+	chunk := sqliteMaxVariables / columns
+
+	for start := 0; start < len(rows); start += chunk {
+		end := start + chunk
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		tuples := make([]string, 0, end-start)
+		args := make([]interface{}, 0, (end-start)*columns)
+		for _, row := range rows[start:end] {
+			tuples = append(tuples, tuple)
+			args = append(args, row...)
+		}
+
+		query := fmt.Sprintf("%s VALUES %s %s", prefix, strings.Join(tuples, ", "), suffix)
+		r.logger.Debugf("%s (%d rows)", prefix, end-start)
+		_, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *sqliteMovieRepository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		r.logger.Error(err)
+		if rbErr := tx.Rollback(); rbErr != nil {
+			r.logger.Error(rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *sqliteMovieRepository) UpsertMovies(ctx context.Context, movies []movieDomain.Movie) error {
+	if len(movies) == 0 {
+		return nil
+	}
+
+	var movieIds []int
+	var movieRows, genreRows, companyRows, movieGenreRows, movieCompanyRows [][]interface{}
+	seenGenre := make(map[[2]int]bool)
+	seenCompany := make(map[[2]int]bool)
+	for _, m := range movies {
+		movieIds = append(movieIds, m.Id)
+		movieRows = append(movieRows, []interface{}{
+			m.Id, m.Adult, m.Title, m.Language, m.Overview, m.Poster, m.ReleaseDate, m.Revenue, m.Runtime,
+			m.Tagline, m.Rating, m.Votes,
+		})
+		for _, g := range m.Genres {
+			genreRows = append(genreRows, []interface{}{g.Id, g.Name})
+			if !seenGenre[[2]int{m.Id, g.Id}] {
+				seenGenre[[2]int{m.Id, g.Id}] = true
+				movieGenreRows = append(movieGenreRows, []interface{}{m.Id, g.Id})
+			}
+		}
+		for _, c := range m.ProductionCompanies {
+			companyRows = append(companyRows, []interface{}{c.Id, c.Name, c.Country})
+			if !seenCompany[[2]int{m.Id, c.Id}] {
+				seenCompany[[2]int{m.Id, c.Id}] = true
+				movieCompanyRows = append(movieCompanyRows, []interface{}{m.Id, c.Id})
+			}
+		}
+	}
+
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		err := r.bulkInsert(ctx, tx,
+			`INSERT INTO Movie (id, adult, title, language, overview, poster, release_date, revenue,
+				runtime, tagline, rating, votes)`,
+			`ON CONFLICT (id) DO UPDATE SET adult = excluded.adult, title = excluded.title, language = excluded.language,
+				overview = excluded.overview, poster = excluded.poster, release_date = excluded.release_date,
+				revenue = excluded.revenue, runtime = excluded.runtime, tagline = excluded.tagline,
+				rating = excluded.rating, votes = excluded.votes`,
+			movieRows,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = r.bulkInsert(ctx, tx,
+			`INSERT INTO Genre (id, name)`,
+			`ON CONFLICT (id) DO UPDATE SET name = excluded.name`,
+			genreRows,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = r.bulkInsert(ctx, tx,
+			`INSERT INTO ProductionCompany (id, name, country)`,
+			`ON CONFLICT (id) DO UPDATE SET name = excluded.name,
+				country = CASE WHEN excluded.country = '' THEN ProductionCompany.country ELSE excluded.country END`,
+			companyRows,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM MovieGenre WHERE movie_id IN (%s)`, joinIds(movieIds)))
+		if err != nil {
+			return err
+		}
+		err = r.bulkInsert(ctx, tx, `INSERT INTO MovieGenre (movie_id, genre_id)`, "", movieGenreRows)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM MovieProductionCompany WHERE movie_id IN (%s)`, joinIds(movieIds)))
+		if err != nil {
+			return err
+		}
+		return r.bulkInsert(ctx, tx, `INSERT INTO MovieProductionCompany (movie_id, company_id)`, "", movieCompanyRows)
+	})
+}
+
+func (r *sqliteMovieRepository) UpsertKeywords(ctx context.Context, keywords []movieDomain.MovieKeywords) error {
+	if len(keywords) == 0 {
+		return nil
+	}
+
+	var movieIds []int
+	var keywordRows, movieKeywordRows [][]interface{}
+	seen := make(map[[2]int]bool)
+	for _, mk := range keywords {
+		movieIds = append(movieIds, mk.MovieId)
+		for _, k := range mk.Keywords {
+			keywordRows = append(keywordRows, []interface{}{k.Id, k.Name})
+			if !seen[[2]int{mk.MovieId, k.Id}] {
+				seen[[2]int{mk.MovieId, k.Id}] = true
+				movieKeywordRows = append(movieKeywordRows, []interface{}{mk.MovieId, k.Id})
+			}
+		}
+	}
+
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		err := r.bulkInsert(ctx, tx,
+			`INSERT INTO Keyword (id, name)`,
+			`ON CONFLICT (id) DO UPDATE SET name = excluded.name`,
+			keywordRows,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM MovieKeyword WHERE movie_id IN (%s)`, joinIds(movieIds)))
+		if err != nil {
+			return err
+		}
+		return r.bulkInsert(ctx, tx, `INSERT INTO MovieKeyword (movie_id, keyword_id)`, "", movieKeywordRows)
+	})
+}
+
+func (r *sqliteMovieRepository) UpsertCredits(ctx context.Context, credits []movieDomain.Credits) error {
+	if len(credits) == 0 {
+		return nil
+	}
+
+	var movieIds []int
+	var personRows, castRows, crewRows [][]interface{}
+	for _, c := range credits {
+		movieIds = append(movieIds, c.MovieId)
+		for _, cast := range c.Cast {
+			personRows = append(personRows, []interface{}{cast.PersonId, cast.Name, cast.Gender, cast.ProfilePath})
+			castRows = append(castRows, []interface{}{cast.CreditId, c.MovieId, cast.PersonId, cast.Character, cast.Order})
+		}
+		for _, crew := range c.Crew {
+			personRows = append(personRows, []interface{}{crew.PersonId, crew.Name, crew.Gender, crew.ProfilePath})
+			crewRows = append(crewRows, []interface{}{crew.CreditId, c.MovieId, crew.PersonId, crew.Job, crew.Department})
+		}
+	}
+
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		err := r.bulkInsert(ctx, tx,
+			`INSERT INTO Person (id, name, gender, profile_path)`,
+			`ON CONFLICT (id) DO UPDATE SET name = excluded.name, gender = excluded.gender, profile_path = excluded.profile_path`,
+			personRows,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM MovieCast WHERE movie_id IN (%s)`, joinIds(movieIds)))
+		if err != nil {
+			return err
+		}
+		err = r.bulkInsert(ctx, tx,
+			`INSERT INTO MovieCast (credit_id, movie_id, person_id, character_name, cast_order)`,
+			`ON CONFLICT (credit_id) DO UPDATE SET movie_id = excluded.movie_id, person_id = excluded.person_id,
+				character_name = excluded.character_name, cast_order = excluded.cast_order`,
+			castRows,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM MovieCrew WHERE movie_id IN (%s)`, joinIds(movieIds)))
+		if err != nil {
+			return err
+		}
+		return r.bulkInsert(ctx, tx,
+			`INSERT INTO MovieCrew (credit_id, movie_id, person_id, job, department)`,
+			`ON CONFLICT (credit_id) DO UPDATE SET movie_id = excluded.movie_id, person_id = excluded.person_id,
+				job = excluded.job, department = excluded.department`,
+			crewRows,
+		)
+	})
+}
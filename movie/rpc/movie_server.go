@@ -0,0 +1,294 @@
+package rpc
+
+import (
+	"context"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	"github.com/null-like/movie-backend/movie"
+	"github.com/null-like/movie-backend/proto/moviepb"
+	"github.com/null-like/movie-backend/request"
+	"google.golang.org/grpc"
+	"strings"
+)
+
+const defaultPageSize = 20
+
+type movieServer struct {
+	moviepb.UnimplementedMovieServiceServer
+	Usecase movie.Usecase
+}
+
+func NewMovieServer(s *grpc.Server, u movie.Usecase) {
+	moviepb.RegisterMovieServiceServer(s, &movieServer{Usecase: u})
+}
+
+// The requests below mirror the HTTP ones, so a call is checked by the same
+// rules whichever way it comes in.
+
+type idRequest struct {
+	Id int `json:"id" validate:"required,gt=0"`
+}
+
+type idsRequest struct {
+	Ids []int `json:"ids" validate:"max=100,dive,gt=0"`
+}
+
+type pageRequest struct {
+	Offset int `json:"offset" validate:"gte=0"`
+	Limit  int `json:"limit" validate:"gte=0,lte=100"`
+}
+
+// page returns the offset and limit, defaulting the limit when unset.
+func (p pageRequest) page() (int, int) {
+	if p.Limit == 0 {
+		return p.Offset, defaultPageSize
+	}
+	return p.Offset, p.Limit
+}
+
+type pagedIdRequest struct {
+	idRequest
+	pageRequest
+}
+
+type peopleRequest struct {
+	Name  string `json:"name" validate:"notblank,max=255"`
+	Limit int    `json:"limit" validate:"gte=0,lte=100"`
+}
+
+type moviesByPersonRequest struct {
+	pageRequest
+	Name string `json:"name" validate:"notblank,max=255"`
+	Role string `json:"role" validate:"omitempty,oneof=actor director"`
+}
+
+func (s *movieServer) GetMovie(ctx context.Context, in *moviepb.GetMovieRequest) (*moviepb.Movie, error) {
+	req := idRequest{Id: int(in.Id)}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	m, err := s.Usecase.GetMovieInfo(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return toMovie(m), nil
+}
+
+func (s *movieServer) GetMovies(ctx context.Context, in *moviepb.GetMoviesRequest) (*moviepb.MovieList, error) {
+	req := idsRequest{Ids: make([]int, len(in.Ids))}
+	for i, id := range in.Ids {
+		req.Ids[i] = int(id)
+	}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	movies, err := s.Usecase.GetMovies(ctx, req.Ids)
+	if err != nil {
+		return nil, err
+	}
+	return toMovieList(movies), nil
+}
+
+func (s *movieServer) ListGenres(ctx context.Context, in *moviepb.ListGenresRequest) (*moviepb.ListGenresResponse, error) {
+	genres, err := s.Usecase.GetGenres(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &moviepb.ListGenresResponse{Genres: make([]*moviepb.GenreWithCount, len(genres))}
+	for i, g := range genres {
+		res.Genres[i] = &moviepb.GenreWithCount{Id: int64(g.Id), Name: g.Name, MovieCount: int32(g.MovieCount)}
+	}
+	return res, nil
+}
+
+func (s *movieServer) ListMoviesByGenre(ctx context.Context, in *moviepb.ListMoviesByGenreRequest) (*moviepb.MovieList, error) {
+	req := pagedIdRequest{idRequest{Id: int(in.GenreId)}, pageRequest{Offset: int(in.Offset), Limit: int(in.Limit)}}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	offset, limit := req.page()
+	movies, err := s.Usecase.GetMoviesByGenre(ctx, req.Id, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return toMovieList(movies), nil
+}
+
+func (s *movieServer) ListCompanies(ctx context.Context, in *moviepb.ListCompaniesRequest) (*moviepb.ListCompaniesResponse, error) {
+	req := pageRequest{Offset: int(in.Offset), Limit: int(in.Limit)}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	offset, limit := req.page()
+	companies, err := s.Usecase.GetCompanies(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &moviepb.ListCompaniesResponse{Companies: make([]*moviepb.ProductionCompanyWithCount, len(companies))}
+	for i, c := range companies {
+		res.Companies[i] = toCompany(c)
+	}
+	return res, nil
+}
+
+func (s *movieServer) ListMoviesByCompany(ctx context.Context, in *moviepb.ListMoviesByCompanyRequest) (*moviepb.ListMoviesByCompanyResponse, error) {
+	req := pagedIdRequest{idRequest{Id: int(in.CompanyId)}, pageRequest{Offset: int(in.Offset), Limit: int(in.Limit)}}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	offset, limit := req.page()
+	company, movies, err := s.Usecase.GetMoviesByCompany(ctx, req.Id, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &moviepb.ListMoviesByCompanyResponse{Company: toCompany(company), Movies: toMovieList(movies).Movies}, nil
+}
+
+func (s *movieServer) GetPerson(ctx context.Context, in *moviepb.GetPersonRequest) (*moviepb.PersonDetail, error) {
+	req := idRequest{Id: int(in.Id)}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	detail, err := s.Usecase.GetPersonDetail(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &moviepb.PersonDetail{
+		Person: toPerson(detail.Person),
+		Cast:   make([]*moviepb.CastCredit, len(detail.Cast)),
+		Crew:   make([]*moviepb.CrewCredit, len(detail.Crew)),
+	}
+	for i, c := range detail.Cast {
+		res.Cast[i] = &moviepb.CastCredit{
+			MovieId:     int64(c.MovieId),
+			Title:       c.Title,
+			Poster:      c.Poster,
+			ReleaseDate: c.ReleaseDate,
+			Character:   c.Character,
+			Order:       int32(c.Order),
+		}
+	}
+	for i, c := range detail.Crew {
+		res.Crew[i] = &moviepb.CrewCredit{
+			MovieId:     int64(c.MovieId),
+			Title:       c.Title,
+			Poster:      c.Poster,
+			ReleaseDate: c.ReleaseDate,
+			Job:         c.Job,
+			Department:  c.Department,
+		}
+	}
+	return res, nil
+}
+
+func (s *movieServer) SearchPeople(ctx context.Context, in *moviepb.SearchPeopleRequest) (*moviepb.SearchPeopleResponse, error) {
+	req := peopleRequest{Name: in.Name, Limit: int(in.Limit)}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	_, limit := pageRequest{Limit: req.Limit}.page()
+	people, err := s.Usecase.SearchPeople(ctx, strings.TrimSpace(req.Name), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &moviepb.SearchPeopleResponse{People: make([]*moviepb.Person, len(people))}
+	for i, p := range people {
+		res.People[i] = toPerson(p)
+	}
+	return res, nil
+}
+
+func (s *movieServer) SearchMoviesByPerson(ctx context.Context, in *moviepb.SearchMoviesByPersonRequest) (*moviepb.MovieList, error) {
+	req := moviesByPersonRequest{pageRequest{Offset: int(in.Offset), Limit: int(in.Limit)}, in.Name, in.Role}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	role := req.Role
+	if role == "" {
+		role = movieDomain.RoleActor
+	}
+
+	offset, limit := req.page()
+	movies, err := s.Usecase.SearchMoviesByPerson(ctx, strings.TrimSpace(req.Name), role, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return toMovieList(movies), nil
+}
+
+func toMovieList(movies []movieDomain.Movie) *moviepb.MovieList {
+	res := &moviepb.MovieList{Movies: make([]*moviepb.Movie, len(movies))}
+	for i, m := range movies {
+		res.Movies[i] = toMovie(m)
+	}
+	return res
+}
+
+func toMovie(m movieDomain.Movie) *moviepb.Movie {
+	res := &moviepb.Movie{
+		Id:                  int64(m.Id),
+		Adult:               m.Adult,
+		Genres:              make([]*moviepb.Genre, len(m.Genres)),
+		Title:               m.Title,
+		Language:            m.Language,
+		Overview:            m.Overview,
+		Poster:              m.Poster,
+		ProductionCompanies: make([]*moviepb.ProductionCompany, len(m.ProductionCompanies)),
+		ReleaseDate:         m.ReleaseDate,
+		Revenue:             int64(m.Revenue),
+		Runtime:             int32(m.Runtime),
+		Tagline:             m.Tagline,
+		Rating:              m.Rating,
+		Votes:               int32(m.Votes),
+		Cast:                make([]*moviepb.Cast, len(m.Cast)),
+		Crew:                make([]*moviepb.Crew, len(m.Crew)),
+	}
+	for i, g := range m.Genres {
+		res.Genres[i] = &moviepb.Genre{Id: int64(g.Id), Name: g.Name}
+	}
+	for i, c := range m.ProductionCompanies {
+		res.ProductionCompanies[i] = &moviepb.ProductionCompany{Id: int64(c.Id), Name: c.Name, Country: c.Country}
+	}
+	for i, c := range m.Cast {
+		res.Cast[i] = &moviepb.Cast{
+			CreditId:    c.CreditId,
+			PersonId:    int64(c.PersonId),
+			Name:        c.Name,
+			Gender:      int32(c.Gender),
+			ProfilePath: c.ProfilePath,
+			Character:   c.Character,
+			Order:       int32(c.Order),
+		}
+	}
+	for i, c := range m.Crew {
+		res.Crew[i] = &moviepb.Crew{
+			CreditId:    c.CreditId,
+			PersonId:    int64(c.PersonId),
+			Name:        c.Name,
+			Gender:      int32(c.Gender),
+			ProfilePath: c.ProfilePath,
+			Job:         c.Job,
+			Department:  c.Department,
+		}
+	}
+	return res
+}
+
+func toCompany(c movieDomain.ProductionCompanyWithCount) *moviepb.ProductionCompanyWithCount {
+	return &moviepb.ProductionCompanyWithCount{Id: int64(c.Id), Name: c.Name, Country: c.Country, MovieCount: int32(c.MovieCount)}
+}
+
+func toPerson(p movieDomain.Person) *moviepb.Person {
+	return &moviepb.Person{Id: int64(p.Id), Name: p.Name, Gender: int32(p.Gender), ProfilePath: p.ProfilePath}
+}
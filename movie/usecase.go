@@ -7,4 +7,14 @@ import (
 
 type Usecase interface {
 	GetMovieInfo(c context.Context, movieId int) (movieDomain.Movie, error)
+	GetMovies(ctx context.Context, movieIds []int) ([]movieDomain.Movie, error)
+
+	GetGenres(ctx context.Context) ([]movieDomain.GenreWithCount, error)
+	GetMoviesByGenre(ctx context.Context, genreId int, offset int, limit int) ([]movieDomain.Movie, error)
+	GetCompanies(ctx context.Context, offset int, limit int) ([]movieDomain.ProductionCompanyWithCount, error)
+	GetMoviesByCompany(ctx context.Context, companyId int, offset int, limit int) (movieDomain.ProductionCompanyWithCount, []movieDomain.Movie, error)
+
+	GetPersonDetail(ctx context.Context, personId int) (movieDomain.PersonDetail, error)
+	SearchPeople(ctx context.Context, name string, limit int) ([]movieDomain.Person, error)
+	SearchMoviesByPerson(ctx context.Context, name string, role string, offset int, limit int) ([]movieDomain.Movie, error)
 }
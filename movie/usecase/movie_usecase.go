@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	movieDomain "github.com/null-like/movie-backend/domain/movie"
 	"github.com/null-like/movie-backend/movie"
 	"github.com/sirupsen/logrus"
@@ -21,9 +23,123 @@ func NewMovieUsecase(l *logrus.Logger, r movie.Repository) movie.Usecase {
 
 func (u *movieUsecase) GetMovieInfo(ctx context.Context, movieId int) (movieDomain.Movie, error) {
 	movieInfo, err := u.movieRepo.ReadMovieById(ctx, movieId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return movieInfo, movieDomain.ErrMovieNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return movieInfo, err
+	}
+
+	credits, err := u.movieRepo.ReadCreditsByMovieId(ctx, movieId)
 	if err != nil {
 		u.logger.Error(err)
 		return movieInfo, err
 	}
+	movieInfo.Cast = credits.Cast
+	movieInfo.Crew = credits.Crew
+
 	return movieInfo, nil
 }
+
+// GetMovies reads many movies at once, without credits, for list views. Ids
+// that don't exist are left out.
+func (u *movieUsecase) GetMovies(ctx context.Context, movieIds []int) ([]movieDomain.Movie, error) {
+	movies, err := u.movieRepo.FindMoviesByIds(ctx, movieIds)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	return movies, nil
+}
+
+func (u *movieUsecase) GetGenres(ctx context.Context) ([]movieDomain.GenreWithCount, error) {
+	genres, err := u.movieRepo.AllGenres(ctx)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	return genres, nil
+}
+
+func (u *movieUsecase) GetMoviesByGenre(ctx context.Context, genreId int, offset int, limit int) ([]movieDomain.Movie, error) {
+	movies, err := u.movieRepo.FindMoviesByGenreId(ctx, genreId, offset, limit)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	return movies, nil
+}
+
+func (u *movieUsecase) GetCompanies(ctx context.Context, offset int, limit int) ([]movieDomain.ProductionCompanyWithCount, error) {
+	companies, err := u.movieRepo.AllProductionCompanies(ctx, offset, limit)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	return companies, nil
+}
+
+func (u *movieUsecase) GetMoviesByCompany(ctx context.Context, companyId int, offset int, limit int) (movieDomain.ProductionCompanyWithCount, []movieDomain.Movie, error) {
+	company, err := u.movieRepo.ReadProductionCompanyById(ctx, companyId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return company, nil, movieDomain.ErrCompanyNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return company, nil, err
+	}
+
+	movies, err := u.movieRepo.FindMoviesByCompanyId(ctx, companyId, offset, limit)
+	if err != nil {
+		u.logger.Error(err)
+		return company, nil, err
+	}
+	return company, movies, nil
+}
+
+func (u *movieUsecase) GetPersonDetail(ctx context.Context, personId int) (movieDomain.PersonDetail, error) {
+	var detail movieDomain.PersonDetail
+
+	person, err := u.movieRepo.ReadPersonById(ctx, personId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return detail, movieDomain.ErrPersonNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return detail, err
+	}
+	detail.Person = person
+
+	detail.Cast, err = u.movieRepo.FindCastCreditsByPersonId(ctx, personId)
+	if err != nil {
+		u.logger.Error(err)
+		return detail, err
+	}
+
+	detail.Crew, err = u.movieRepo.FindCrewCreditsByPersonId(ctx, personId)
+	if err != nil {
+		u.logger.Error(err)
+		return detail, err
+	}
+
+	return detail, nil
+}
+
+func (u *movieUsecase) SearchPeople(ctx context.Context, name string, limit int) ([]movieDomain.Person, error) {
+	people, err := u.movieRepo.FindPeopleByName(ctx, name, limit)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	return people, nil
+}
+
+func (u *movieUsecase) SearchMoviesByPerson(ctx context.Context, name string, role string, offset int, limit int) ([]movieDomain.Movie, error) {
+	movies, err := u.movieRepo.FindMoviesByPersonName(ctx, name, role, offset, limit)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	return movies, nil
+}
@@ -0,0 +1,35 @@
+package openapi
+
+import (
+	"github.com/labstack/echo/v4"
+	swaggerFiles "github.com/swaggo/files/v2"
+	"net/http"
+)
+
+// initializer points the bundled Swagger UI at the served document instead of
+// its demo one.
+const initializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "/openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// Serve mounts doc at /openapi.json and Swagger UI at /docs/.
+func Serve(e *echo.Echo, doc *Document) {
+	e.GET("/openapi.json", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, doc)
+	})
+	e.GET("/docs", func(c echo.Context) error {
+		return c.Redirect(http.StatusMovedPermanently, "/docs/")
+	})
+	e.GET("/docs/swagger-initializer.js", func(c echo.Context) error {
+		return c.Blob(http.StatusOK, "application/javascript", []byte(initializer))
+	})
+	e.StaticFS("/docs/", swaggerFiles.FS)
+}
@@ -0,0 +1,420 @@
+package openapi
+
+import (
+	"github.com/null-like/movie-backend/problem"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operation documents one route. Request is the struct the handler passes to
+// request.Bind: its param and query fields become parameters, its json fields
+// the request body, and its validate tags the constraints on both. Response
+// is a value of the success body's type, nil for none.
+type Operation struct {
+	Method   string
+	Path     string
+	Summary  string
+	Tag      string
+	Request  interface{}
+	Response interface{}
+	// Status is the success status, 200 when zero.
+	Status int
+	// ContentType is the success body's type when it isn't JSON.
+	ContentType string
+	// Upload names the multipart file field of an upload.
+	Upload     string
+	Deprecated bool
+}
+
+// Deprecated marks every operation in ops as deprecated.
+func Deprecated(ops []Operation) []Operation {
+	marked := make([]Operation, len(ops))
+	for i, op := range ops {
+		op.Deprecated = true
+		marked[i] = op
+	}
+	return marked
+}
+
+// Document is an OpenAPI 3.0 document, holding the subset of the format the
+// service needs.
+type Document struct {
+	OpenAPI    string                        `json:"openapi"`
+	Info       Info                          `json:"info"`
+	Paths      map[string]map[string]*OpSpec `json:"paths"`
+	Components Components                    `json:"components"`
+
+	// names maps each documented struct type to its schema name.
+	names map[reflect.Type]string
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// OpSpec is the OpenAPI operation object.
+type OpSpec struct {
+	Tags        []string            `json:"tags,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	OperationId string              `json:"operationId"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+// New starts a document with no paths. Error responses of every operation
+// point at the problem+json schema.
+func New(title string, version string, description string) *Document {
+	d := &Document{
+		OpenAPI:    "3.0.3",
+		Info:       Info{Title: title, Version: version, Description: description},
+		Paths:      map[string]map[string]*OpSpec{},
+		Components: Components{Schemas: map[string]*Schema{}},
+		names:      map[reflect.Type]string{},
+	}
+	problemType := reflect.TypeOf(problem.Details{})
+	d.Components.Schemas["Problem"] = d.structSchema(problemType)
+	d.names[problemType] = "Problem"
+	return d
+}
+
+// Add documents ops under prefix, e.g. "/v2".
+func (d *Document) Add(prefix string, ops ...Operation) {
+	for _, op := range ops {
+		path := Path(prefix + op.Path)
+		if d.Paths[path] == nil {
+			d.Paths[path] = map[string]*OpSpec{}
+		}
+		d.Paths[path][strings.ToLower(op.Method)] = d.operation(path, op)
+	}
+}
+
+// Has reports whether the document describes the echo route method path.
+func (d *Document) Has(method string, path string) bool {
+	_, ok := d.Paths[Path(path)][strings.ToLower(method)]
+	return ok
+}
+
+// Path turns an echo path into an OpenAPI one: ":id" becomes "{id}" and a
+// trailing "*" becomes "{path}".
+func Path(echoPath string) string {
+	segments := strings.Split(echoPath, "/")
+	for i, s := range segments {
+		switch {
+		case strings.HasPrefix(s, ":"):
+			segments[i] = "{" + s[1:] + "}"
+		case s == "*":
+			segments[i] = "{path}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func (d *Document) operation(path string, op Operation) *OpSpec {
+	spec := &OpSpec{
+		Summary:     op.Summary,
+		OperationId: operationId(op.Method, path),
+		Deprecated:  op.Deprecated,
+		Responses:   map[string]Response{},
+	}
+	if op.Tag != "" {
+		spec.Tags = []string{op.Tag}
+	}
+
+	if op.Request != nil {
+		t := reflect.TypeOf(op.Request)
+		spec.Parameters = d.parameters(t, path)
+		if body := d.body(t); body != nil {
+			spec.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: body}},
+			}
+		}
+	}
+	if op.Upload != "" {
+		spec.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{"multipart/form-data": {Schema: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{op.Upload: {Type: "string", Format: "binary"}},
+				Required:   []string{op.Upload},
+			}}},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	switch {
+	case op.ContentType != "":
+		success.Content = map[string]MediaType{op.ContentType: {Schema: &Schema{Type: "string", Format: "binary"}}}
+	case op.Response != nil:
+		success.Content = map[string]MediaType{"application/json": {Schema: d.schemaOf(reflect.TypeOf(op.Response))}}
+	}
+	spec.Responses[strconv.Itoa(status)] = success
+	spec.Responses["default"] = Response{
+		Description: "Problem",
+		Content:     map[string]MediaType{"application/problem+json": {Schema: d.schemaOf(reflect.TypeOf(problem.Details{}))}},
+	}
+	return spec
+}
+
+// operationId names an operation after its method and path, e.g.
+// get_v2_users_user_id_ratings.
+func operationId(method string, path string) string {
+	id := strings.ToLower(method) + path
+	return strings.NewReplacer("/", "_", "{", "", "}", "", "-", "_").Replace(id)
+}
+
+// parameters lists the param and query fields of a request struct. A field
+// tagged both ways is a path param where the path names it and a query param
+// elsewhere, as the /v1 and /v2 routes share request structs.
+func (d *Document) parameters(t reflect.Type, path string) []Parameter {
+	var params []Parameter
+	eachField(t, func(f reflect.StructField) {
+		rules := parseRules(f.Tag.Get("validate"))
+		if name := tagName(f, "param"); name != "" {
+			in := name
+			if in == "*" {
+				in = "path"
+			}
+			if strings.Contains(path, "{"+in+"}") {
+				params = append(params, Parameter{Name: in, In: "path", Required: true, Schema: d.fieldSchema(f.Type, rules)})
+				return
+			}
+		}
+		if name := tagName(f, "query"); name != "" {
+			params = append(params, Parameter{Name: name, In: "query", Required: rules.required, Schema: d.fieldSchema(f.Type, rules)})
+		}
+	})
+	return params
+}
+
+// body describes the json fields of a request struct as a component schema
+// named after it, or returns nil when it has none.
+func (d *Document) body(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	eachField(t, func(f reflect.StructField) {
+		name := tagName(f, "json")
+		if name == "" {
+			return
+		}
+		rules := parseRules(f.Tag.Get("validate"))
+		schema.Properties[name] = d.fieldSchema(f.Type, rules)
+		if rules.required {
+			schema.Required = append(schema.Required, name)
+		}
+	})
+	if len(schema.Properties) == 0 {
+		return nil
+	}
+	return d.component(t, schema)
+}
+
+// eachField calls fn for the fields of a struct, flattening the anonymous
+// structs echo's binder also flattens.
+func eachField(t reflect.Type, fn func(f reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			eachField(f.Type, fn)
+			continue
+		}
+		if f.IsExported() {
+			fn(f)
+		}
+	}
+}
+
+func tagName(f reflect.StructField, tag string) string {
+	name := strings.Split(f.Tag.Get(tag), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// fieldSchema is the schema of a request field with its validate rules.
+func (d *Document) fieldSchema(t reflect.Type, r rules) *Schema {
+	s := d.schemaOf(t)
+	if s.Ref != "" {
+		return s
+	}
+	// A pointer field is optional rather than nullable.
+	s.Nullable = false
+	s.Enum = r.enum(t)
+	if r.email {
+		s.Format = "email"
+	}
+	if s.Type == "string" {
+		s.MinLength, s.MaxLength = intBound(r.min), intBound(r.max)
+	} else {
+		s.Minimum, s.Maximum = r.min, r.max
+		s.ExclusiveMinimum, s.ExclusiveMaximum = r.exclusiveMin, r.exclusiveMax
+	}
+	return s
+}
+
+func intBound(f *float64) *int {
+	if f == nil {
+		return nil
+	}
+	n := int(*f)
+	return &n
+}
+
+// schemaOf describes a Go type the way encoding/json writes it. Structs
+// become component schemas referenced by name.
+func (d *Document) schemaOf(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		s := d.schemaOf(t.Elem())
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if ref := d.ref(t); ref != nil {
+			return ref
+		}
+		// Claim the name before the fields so recursive types terminate.
+		name := d.name(t)
+		d.Components.Schemas[name] = &Schema{}
+		*d.Components.Schemas[name] = *d.structSchema(t)
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			embedded := d.structSchema(f.Type)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = d.schemaOf(f.Type)
+	}
+	return s
+}
+
+// component registers a request body schema under its type's name.
+func (d *Document) component(t reflect.Type, s *Schema) *Schema {
+	if ref := d.ref(t); ref != nil {
+		return ref
+	}
+	name := d.name(t)
+	d.Components.Schemas[name] = s
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (d *Document) ref(t reflect.Type) *Schema {
+	name, ok := d.names[t]
+	if !ok {
+		return nil
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// name picks a schema name for t: its type name, or the name prefixed with
+// its package when another type already has it, e.g. tv.Genre as TvGenre.
+func (d *Document) name(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := d.Components.Schemas[name]; taken {
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + t.Name()
+	}
+	d.names[t] = name
+	return name
+}
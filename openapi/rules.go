@@ -0,0 +1,65 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// rules are the parts of a validate tag the document can express.
+type rules struct {
+	required     bool
+	email        bool
+	oneof        []string
+	min, max     *float64
+	exclusiveMin bool
+	exclusiveMax bool
+}
+
+func parseRules(tag string) rules {
+	var r rules
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		bound, err := strconv.ParseFloat(param, 64)
+		hasBound := err == nil
+
+		switch {
+		case name == "required" || name == "notblank":
+			r.required = true
+		case name == "email":
+			r.email = true
+		case name == "oneof":
+			r.oneof = strings.Fields(param)
+		case (name == "min" || name == "gte") && hasBound:
+			r.min = &bound
+		case name == "gt" && hasBound:
+			r.min, r.exclusiveMin = &bound, true
+		case (name == "max" || name == "lte") && hasBound:
+			r.max = &bound
+		case name == "lt" && hasBound:
+			r.max, r.exclusiveMax = &bound, true
+		}
+	}
+	return r
+}
+
+// enum returns the oneof values as t's JSON type.
+func (r rules) enum(t reflect.Type) []interface{} {
+	if len(r.oneof) == 0 {
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	values := make([]interface{}, len(r.oneof))
+	for i, v := range r.oneof {
+		values[i] = v
+		if t.Kind() != reflect.String {
+			if n, err := strconv.Atoi(v); err == nil {
+				values[i] = n
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,10 @@
+package poster
+
+import "time"
+
+// Cache keeps generated poster variants. Get reports when the entry was
+// stored so it can be served as Last-Modified.
+type Cache interface {
+	Get(key string) ([]byte, time.Time, bool)
+	Put(key string, data []byte) error
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/null-like/movie-backend/poster"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+type diskCache struct {
+	logger   *logrus.Logger
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	used    int64
+	order   *list.List // front is most recently used
+	entries map[string]*list.Element
+}
+
+// NewDiskCache keeps up to maxBytes of entries as files in dir, evicting the
+// least recently used. Files left by a previous run are picked up, oldest
+// first, so a restart keeps a warm cache.
+func NewDiskCache(l *logrus.Logger, dir string, maxBytes int64) (poster.Cache, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &diskCache{
+		logger:   l,
+		dir:      dir,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var found []entry
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		found = append(found, entry{name: f.Name(), size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].modTime.Before(found[j].modTime) })
+	for _, e := range found {
+		c.add(e)
+	}
+	c.evict()
+
+	return c, nil
+}
+
+// fileName hashes the key so any poster path maps onto a flat, safe name.
+func fileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *diskCache) Get(key string) ([]byte, time.Time, bool) {
+	name := fileName(key)
+
+	c.mu.Lock()
+	el, ok := c.entries[name]
+	if ok {
+		c.order.MoveToFront(el)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, time.Time{}, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.dir, name))
+	if err != nil {
+		// Removed behind our back; forget it so the next Put rewrites it.
+		c.logger.Error(err)
+		c.remove(name)
+		return nil, time.Time{}, false
+	}
+	return data, el.Value.(entry).modTime, true
+}
+
+func (c *diskCache) Put(key string, data []byte) error {
+	name := fileName(key)
+
+	tmp, err := os.CreateTemp(c.dir, ".put-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmp.Name(), filepath.Join(c.dir, name))
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[name]; ok {
+		c.used -= el.Value.(entry).size
+		c.order.Remove(el)
+		delete(c.entries, name)
+	}
+	c.add(entry{name: name, size: int64(len(data)), modTime: time.Now()})
+	c.evict()
+	return nil
+}
+
+// add and evict expect c.mu to be held, or c not yet shared.
+func (c *diskCache) add(e entry) {
+	c.entries[e.name] = c.order.PushFront(e)
+	c.used += e.size
+}
+
+func (c *diskCache) evict() {
+	for c.used > c.maxBytes && c.order.Len() > 0 {
+		el := c.order.Back()
+		e := el.Value.(entry)
+		c.order.Remove(el)
+		delete(c.entries, e.name)
+		c.used -= e.size
+
+		err := os.Remove(filepath.Join(c.dir, e.name))
+		if err != nil && !os.IsNotExist(err) {
+			c.logger.Error(err)
+		}
+	}
+}
+
+func (c *diskCache) remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[name]; ok {
+		c.used -= el.Value.(entry).size
+		c.order.Remove(el)
+		delete(c.entries, name)
+	}
+}
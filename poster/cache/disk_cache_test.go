@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"bytes"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiskCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	dir := t.TempDir()
+	c, err := NewDiskCache(logger, dir, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := func(key string) []byte { return bytes.Repeat([]byte(key), 10) }
+	for _, key := range []string{"a", "b", "c"} {
+		err = c.Put(key, data(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a is now the most recently used, which leaves b to go first.
+	if got, _, ok := c.Get("a"); !ok || !bytes.Equal(got, data("a")) {
+		t.Fatalf("a: got %q, %v", got, ok)
+	}
+	err = c.Put("d", data("d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantCached(t, dir, map[string]bool{"a": true, "b": false, "c": true, "d": true})
+
+	// An entry larger than what's left pushes out as many as it needs to.
+	err = c.Put("e", bytes.Repeat([]byte("e"), 20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCached(t, dir, map[string]bool{"a": false, "c": false, "d": true, "e": true})
+
+	// A restart picks the files up again within the limit.
+	c, err = NewDiskCache(logger, dir, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCached(t, dir, map[string]bool{"d": false, "e": true})
+	if got, _, ok := c.Get("e"); !ok || len(got) != 20 {
+		t.Errorf("e after restart: got %q, %v", got, ok)
+	}
+	if _, _, ok := c.Get("d"); ok {
+		t.Error("d after restart: still cached")
+	}
+}
+
+// wantCached checks which keys have a file in dir, without touching their
+// recency.
+func wantCached(t *testing.T, dir string, want map[string]bool) {
+	t.Helper()
+	for key, cached := range want {
+		_, err := os.Stat(filepath.Join(dir, fileName(key)))
+		if cached != (err == nil) {
+			t.Errorf("%s: file exists %v, want %v", key, err == nil, cached)
+		}
+	}
+}
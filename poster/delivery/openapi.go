@@ -0,0 +1,11 @@
+package delivery
+
+import "github.com/null-like/movie-backend/openapi"
+
+// Operations documents the routes of NewPosterHandler, which are the same in
+// /v1 and /v2.
+func Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: "GET", Path: "/poster/:size/*", Tag: "posters", Summary: "Get a poster in a size variant", Request: PosterRequest{}, ContentType: "image/*"},
+	}
+}
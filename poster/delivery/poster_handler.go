@@ -0,0 +1,69 @@
+package delivery
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/null-like/movie-backend/domain/apperror"
+	"github.com/null-like/movie-backend/poster"
+	"github.com/null-like/movie-backend/request"
+	"net/http"
+	"strings"
+)
+
+type posterHandler struct {
+	Usecase poster.Usecase
+}
+
+func NewPosterHandler(g *echo.Group, u poster.Usecase) {
+	handler := &posterHandler{
+		Usecase: u,
+	}
+	g.GET("/poster/:size/*", handler.GetPoster)
+}
+
+// PosterRequest is a size variant, "original" or one of PosterWidths, and
+// the poster path the movie has.
+type PosterRequest struct {
+	Size string `param:"size" validate:"required"`
+	Path string `param:"*"`
+}
+
+// GetPoster serves /poster/<size>/<Movie.Poster>, e.g.
+// /poster/w342/kqjL17yufvn9OVLyXYpvtyrFfak.jpg.
+func (h *posterHandler) GetPoster(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req PosterRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	img, err := h.Usecase.GetPoster(ctx, req.Size, "/"+req.Path)
+	if err != nil && apperror.KindOf(err) == apperror.KindInternal {
+		// Anything but a bad or missing poster is the origin failing.
+		return echo.NewHTTPError(http.StatusBadGateway, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	res := c.Response()
+	res.Header().Set("ETag", img.ETag)
+	res.Header().Set("Cache-Control", "public, max-age=86400")
+	res.Header().Set("Last-Modified", img.ModTime.UTC().Format(http.TimeFormat))
+	if etagMatches(c.Request().Header.Get("If-None-Match"), img.ETag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.Blob(http.StatusOK, img.ContentType, img.Data)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag.
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
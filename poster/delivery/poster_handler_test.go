@@ -0,0 +1,83 @@
+package delivery
+
+import (
+	"bytes"
+	"github.com/labstack/echo/v4"
+	_posterCache "github.com/null-like/movie-backend/poster/cache"
+	_posterOrigin "github.com/null-like/movie-backend/poster/origin"
+	_posterUsecase "github.com/null-like/movie-backend/poster/usecase"
+	"github.com/null-like/movie-backend/problem"
+	"github.com/sirupsen/logrus"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetPosterNotModified(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 6)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "poster.png"), buf.Bytes(), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := _posterCache.NewDiskCache(logger, filepath.Join(dir, "cache"), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	e.HTTPErrorHandler = problem.HTTPErrorHandler(logger)
+	NewPosterHandler(e.Group(""), _posterUsecase.NewPosterUsecase(logger, _posterOrigin.NewDirOrigin(dir), cache))
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/poster/original/poster.png", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("")
+	if rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), buf.Bytes()) {
+		t.Fatalf("got status %d with %d bytes, want the poster", rec.Code, rec.Body.Len())
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+
+	for _, tc := range []struct {
+		ifNoneMatch string
+		want        int
+	}{
+		{etag, http.StatusNotModified},
+		{"W/" + etag, http.StatusNotModified},
+		{`"other", ` + etag, http.StatusNotModified},
+		{"*", http.StatusNotModified},
+		{`"other"`, http.StatusOK},
+	} {
+		rec = get(tc.ifNoneMatch)
+		if rec.Code != tc.want {
+			t.Errorf("If-None-Match %s: got status %d, want %d", tc.ifNoneMatch, rec.Code, tc.want)
+		}
+		if rec.Code == http.StatusNotModified && rec.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: 304 with a body", tc.ifNoneMatch)
+		}
+		if got := rec.Header().Get("ETag"); got != etag {
+			t.Errorf("If-None-Match %s: got ETag %s, want %s", tc.ifNoneMatch, got, etag)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package poster
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNotFound = errors.New("poster not found at origin")
+
+// Origin is where poster images come from. path is a Movie.Poster value such
+// as "/kqjL17yufvn9OVLyXYpvtyrFfak.jpg".
+type Origin interface {
+	Fetch(ctx context.Context, path string) ([]byte, error)
+}
@@ -0,0 +1,36 @@
+package origin
+
+import (
+	"context"
+	"errors"
+	"github.com/null-like/movie-backend/poster"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type dirOrigin struct {
+	fsys fs.FS
+}
+
+// NewDirOrigin reads posters from files under dir, the poster path being
+// relative to it.
+func NewDirOrigin(dir string) poster.Origin {
+	return &dirOrigin{fsys: os.DirFS(dir)}
+}
+
+func (o *dirOrigin) Fetch(ctx context.Context, path string) ([]byte, error) {
+	name := filepath.ToSlash(path)
+	if len(name) > 0 && name[0] == '/' {
+		name = name[1:]
+	}
+	if !fs.ValidPath(name) {
+		return nil, poster.ErrNotFound
+	}
+
+	data, err := fs.ReadFile(o.fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, poster.ErrNotFound
+	}
+	return data, err
+}
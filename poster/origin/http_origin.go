@@ -0,0 +1,57 @@
+package origin
+
+import (
+	"context"
+	"fmt"
+	"github.com/null-like/movie-backend/poster"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxPosterBytes caps how much of an origin response is read.
+const maxPosterBytes = 20 << 20
+
+type httpOrigin struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPOrigin fetches posters with GET baseURL+path, for example from
+// https://image.tmdb.org/t/p/original.
+func NewHTTPOrigin(baseURL string) poster.Origin {
+	return &httpOrigin{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (o *httpOrigin) Fetch(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return nil, poster.ErrNotFound
+	case res.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("poster origin answered %s for %s", res.Status, path)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxPosterBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxPosterBytes {
+		return nil, fmt.Errorf("poster %s is larger than %d bytes", path, maxPosterBytes)
+	}
+	return data, nil
+}
@@ -0,0 +1,10 @@
+package poster
+
+import (
+	"context"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+)
+
+type Usecase interface {
+	GetPoster(ctx context.Context, size string, path string) (movieDomain.PosterImage, error)
+}
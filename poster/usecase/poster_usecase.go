@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	"github.com/null-like/movie-backend/poster"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/image/draw"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+type posterUsecase struct {
+	logger *logrus.Logger
+	origin poster.Origin
+	cache  poster.Cache
+
+	mu       sync.Mutex
+	inflight map[string]*variantCall
+}
+
+// variantCall lets concurrent requests for the same uncached variant share
+// one fetch and resize.
+type variantCall struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+func NewPosterUsecase(l *logrus.Logger, o poster.Origin, c poster.Cache) poster.Usecase {
+	return &posterUsecase{
+		logger:   l,
+		origin:   o,
+		cache:    c,
+		inflight: make(map[string]*variantCall),
+	}
+}
+
+// posterExtensions are the file types a poster path may name.
+var posterExtensions = map[string]bool{".jpg": true, ".jpeg": true, ".png": true}
+
+func (u *posterUsecase) GetPoster(ctx context.Context, size string, posterPath string) (movieDomain.PosterImage, error) {
+	_, known := movieDomain.PosterWidths[size]
+	if !known && size != movieDomain.OriginalPosterSize {
+		return movieDomain.PosterImage{}, movieDomain.ErrInvalidPosterSize
+	}
+	if !strings.HasPrefix(posterPath, "/") || path.Clean(posterPath) != posterPath ||
+		!posterExtensions[strings.ToLower(path.Ext(posterPath))] {
+		return movieDomain.PosterImage{}, movieDomain.ErrInvalidPosterPath
+	}
+
+	key := size + posterPath
+	data, modTime, ok := u.cache.Get(key)
+	if !ok {
+		var err error
+		data, err = u.generate(ctx, key, size, posterPath)
+		if err != nil {
+			return movieDomain.PosterImage{}, err
+		}
+		modTime = time.Now()
+	}
+
+	sum := sha256.Sum256(data)
+	return movieDomain.PosterImage{
+		Data:        data,
+		ContentType: http.DetectContentType(data),
+		ETag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+		ModTime:     modTime,
+	}, nil
+}
+
+// generate fetches and resizes a variant missing from the cache, joining a
+// call already doing so for the same key.
+func (u *posterUsecase) generate(ctx context.Context, key string, size string, posterPath string) ([]byte, error) {
+	u.mu.Lock()
+	if call, ok := u.inflight[key]; ok {
+		u.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.data, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &variantCall{done: make(chan struct{})}
+	u.inflight[key] = call
+	u.mu.Unlock()
+
+	defer func() {
+		u.mu.Lock()
+		delete(u.inflight, key)
+		u.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.data, call.err = u.variant(ctx, size, posterPath)
+	if call.err != nil {
+		return nil, call.err
+	}
+
+	err := u.cache.Put(key, call.data)
+	if err != nil {
+		// The variant is still good to serve; it'll be made again next time.
+		u.logger.Error(err)
+	}
+	return call.data, nil
+}
+
+func (u *posterUsecase) variant(ctx context.Context, size string, posterPath string) ([]byte, error) {
+	original, err := u.origin.Fetch(ctx, posterPath)
+	if errors.Is(err, poster.ErrNotFound) {
+		return nil, movieDomain.ErrPosterNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	if size == movieDomain.OriginalPosterSize {
+		return original, nil
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(original))
+	if err != nil {
+		u.logger.Error(err)
+		return nil, fmt.Errorf("decoding poster %s: %w", posterPath, err)
+	}
+
+	// Never upscale; a small original is served as is for every larger size.
+	width := movieDomain.PosterWidths[size]
+	b := src.Bounds()
+	if b.Dx() <= width {
+		return original, nil
+	}
+	height := b.Dy() * width / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if format == "png" {
+		err = png.Encode(&buf, dst)
+	} else {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,110 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/null-like/movie-backend/domain/apperror"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+const ContentType = "application/problem+json"
+
+// Details is an RFC 7807 problem document. Type is "about:blank" unless the
+// error has a kind, in which case it names the kind.
+type Details struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+}
+
+// InvalidParam is a request field that failed validation.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Status is the HTTP status answered for an error of the given kind.
+func Status(kind apperror.Kind) int {
+	switch kind {
+	case apperror.KindNotFound:
+		return http.StatusNotFound
+	case apperror.KindConflict:
+		return http.StatusConflict
+	case apperror.KindValidation:
+		return http.StatusBadRequest
+	case apperror.KindUnauthorized:
+		return http.StatusUnauthorized
+	case apperror.KindForbidden:
+		return http.StatusForbidden
+	case apperror.KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case apperror.KindUnsupported:
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// New describes err for the request it failed. Errors echo raises itself,
+// like an unknown route, keep their status. Internal errors don't show
+// their message.
+func New(err error, instance string) Details {
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return Details{
+			Type:     "about:blank",
+			Title:    http.StatusText(he.Code),
+			Status:   he.Code,
+			Detail:   fmt.Sprint(he.Message),
+			Instance: instance,
+		}
+	}
+
+	kind := apperror.KindOf(err)
+	status := Status(kind)
+	details := Details{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Instance: instance,
+	}
+	if kind != apperror.KindInternal {
+		details.Type = "/problems/" + kind.String()
+		details.Detail = err.Error()
+	}
+	for _, f := range apperror.FieldsOf(err) {
+		details.InvalidParams = append(details.InvalidParams, InvalidParam{Name: f.Field, Reason: f.Message})
+	}
+	return details
+}
+
+// HTTPErrorHandler answers every error a handler returns with a problem
+// document. 5xx errors are logged with the request they failed.
+func HTTPErrorHandler(l *logrus.Logger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		details := New(err, c.Request().URL.Path)
+		if details.Status >= http.StatusInternalServerError {
+			l.WithField("path", c.Request().URL.Path).Error(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, ContentType)
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(details.Status)
+		} else {
+			err = c.JSON(details.Status, details)
+		}
+		if err != nil {
+			l.Error(err)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.23.4
+// source: movie.proto
+
+// The catalogue as movie.Usecase serves it. Paged calls take an offset and a
+// limit of at most 100, 20 when unset, as in the HTTP API.
+
+package moviepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	MovieService_GetMovie_FullMethodName             = "/moviebackend.movie.MovieService/GetMovie"
+	MovieService_GetMovies_FullMethodName            = "/moviebackend.movie.MovieService/GetMovies"
+	MovieService_ListGenres_FullMethodName           = "/moviebackend.movie.MovieService/ListGenres"
+	MovieService_ListMoviesByGenre_FullMethodName    = "/moviebackend.movie.MovieService/ListMoviesByGenre"
+	MovieService_ListCompanies_FullMethodName        = "/moviebackend.movie.MovieService/ListCompanies"
+	MovieService_ListMoviesByCompany_FullMethodName  = "/moviebackend.movie.MovieService/ListMoviesByCompany"
+	MovieService_GetPerson_FullMethodName            = "/moviebackend.movie.MovieService/GetPerson"
+	MovieService_SearchPeople_FullMethodName         = "/moviebackend.movie.MovieService/SearchPeople"
+	MovieService_SearchMoviesByPerson_FullMethodName = "/moviebackend.movie.MovieService/SearchMoviesByPerson"
+)
+
+// MovieServiceClient is the client API for MovieService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MovieServiceClient interface {
+	GetMovie(ctx context.Context, in *GetMovieRequest, opts ...grpc.CallOption) (*Movie, error)
+	GetMovies(ctx context.Context, in *GetMoviesRequest, opts ...grpc.CallOption) (*MovieList, error)
+	ListGenres(ctx context.Context, in *ListGenresRequest, opts ...grpc.CallOption) (*ListGenresResponse, error)
+	ListMoviesByGenre(ctx context.Context, in *ListMoviesByGenreRequest, opts ...grpc.CallOption) (*MovieList, error)
+	ListCompanies(ctx context.Context, in *ListCompaniesRequest, opts ...grpc.CallOption) (*ListCompaniesResponse, error)
+	ListMoviesByCompany(ctx context.Context, in *ListMoviesByCompanyRequest, opts ...grpc.CallOption) (*ListMoviesByCompanyResponse, error)
+	GetPerson(ctx context.Context, in *GetPersonRequest, opts ...grpc.CallOption) (*PersonDetail, error)
+	SearchPeople(ctx context.Context, in *SearchPeopleRequest, opts ...grpc.CallOption) (*SearchPeopleResponse, error)
+	SearchMoviesByPerson(ctx context.Context, in *SearchMoviesByPersonRequest, opts ...grpc.CallOption) (*MovieList, error)
+}
+
+type movieServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMovieServiceClient(cc grpc.ClientConnInterface) MovieServiceClient {
+	return &movieServiceClient{cc}
+}
+
+func (c *movieServiceClient) GetMovie(ctx context.Context, in *GetMovieRequest, opts ...grpc.CallOption) (*Movie, error) {
+	out := new(Movie)
+	err := c.cc.Invoke(ctx, MovieService_GetMovie_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movieServiceClient) GetMovies(ctx context.Context, in *GetMoviesRequest, opts ...grpc.CallOption) (*MovieList, error) {
+	out := new(MovieList)
+	err := c.cc.Invoke(ctx, MovieService_GetMovies_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movieServiceClient) ListGenres(ctx context.Context, in *ListGenresRequest, opts ...grpc.CallOption) (*ListGenresResponse, error) {
+	out := new(ListGenresResponse)
+	err := c.cc.Invoke(ctx, MovieService_ListGenres_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movieServiceClient) ListMoviesByGenre(ctx context.Context, in *ListMoviesByGenreRequest, opts ...grpc.CallOption) (*MovieList, error) {
+	out := new(MovieList)
+	err := c.cc.Invoke(ctx, MovieService_ListMoviesByGenre_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movieServiceClient) ListCompanies(ctx context.Context, in *ListCompaniesRequest, opts ...grpc.CallOption) (*ListCompaniesResponse, error) {
+	out := new(ListCompaniesResponse)
+	err := c.cc.Invoke(ctx, MovieService_ListCompanies_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movieServiceClient) ListMoviesByCompany(ctx context.Context, in *ListMoviesByCompanyRequest, opts ...grpc.CallOption) (*ListMoviesByCompanyResponse, error) {
+	out := new(ListMoviesByCompanyResponse)
+	err := c.cc.Invoke(ctx, MovieService_ListMoviesByCompany_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movieServiceClient) GetPerson(ctx context.Context, in *GetPersonRequest, opts ...grpc.CallOption) (*PersonDetail, error) {
+	out := new(PersonDetail)
+	err := c.cc.Invoke(ctx, MovieService_GetPerson_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movieServiceClient) SearchPeople(ctx context.Context, in *SearchPeopleRequest, opts ...grpc.CallOption) (*SearchPeopleResponse, error) {
+	out := new(SearchPeopleResponse)
+	err := c.cc.Invoke(ctx, MovieService_SearchPeople_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movieServiceClient) SearchMoviesByPerson(ctx context.Context, in *SearchMoviesByPersonRequest, opts ...grpc.CallOption) (*MovieList, error) {
+	out := new(MovieList)
+	err := c.cc.Invoke(ctx, MovieService_SearchMoviesByPerson_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MovieServiceServer is the server API for MovieService service.
+// All implementations must embed UnimplementedMovieServiceServer
+// for forward compatibility
+type MovieServiceServer interface {
+	GetMovie(context.Context, *GetMovieRequest) (*Movie, error)
+	GetMovies(context.Context, *GetMoviesRequest) (*MovieList, error)
+	ListGenres(context.Context, *ListGenresRequest) (*ListGenresResponse, error)
+	ListMoviesByGenre(context.Context, *ListMoviesByGenreRequest) (*MovieList, error)
+	ListCompanies(context.Context, *ListCompaniesRequest) (*ListCompaniesResponse, error)
+	ListMoviesByCompany(context.Context, *ListMoviesByCompanyRequest) (*ListMoviesByCompanyResponse, error)
+	GetPerson(context.Context, *GetPersonRequest) (*PersonDetail, error)
+	SearchPeople(context.Context, *SearchPeopleRequest) (*SearchPeopleResponse, error)
+	SearchMoviesByPerson(context.Context, *SearchMoviesByPersonRequest) (*MovieList, error)
+	mustEmbedUnimplementedMovieServiceServer()
+}
+
+// UnimplementedMovieServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedMovieServiceServer struct {
+}
+
+func (UnimplementedMovieServiceServer) GetMovie(context.Context, *GetMovieRequest) (*Movie, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMovie not implemented")
+}
+func (UnimplementedMovieServiceServer) GetMovies(context.Context, *GetMoviesRequest) (*MovieList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMovies not implemented")
+}
+func (UnimplementedMovieServiceServer) ListGenres(context.Context, *ListGenresRequest) (*ListGenresResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGenres not implemented")
+}
+func (UnimplementedMovieServiceServer) ListMoviesByGenre(context.Context, *ListMoviesByGenreRequest) (*MovieList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMoviesByGenre not implemented")
+}
+func (UnimplementedMovieServiceServer) ListCompanies(context.Context, *ListCompaniesRequest) (*ListCompaniesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCompanies not implemented")
+}
+func (UnimplementedMovieServiceServer) ListMoviesByCompany(context.Context, *ListMoviesByCompanyRequest) (*ListMoviesByCompanyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMoviesByCompany not implemented")
+}
+func (UnimplementedMovieServiceServer) GetPerson(context.Context, *GetPersonRequest) (*PersonDetail, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPerson not implemented")
+}
+func (UnimplementedMovieServiceServer) SearchPeople(context.Context, *SearchPeopleRequest) (*SearchPeopleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchPeople not implemented")
+}
+func (UnimplementedMovieServiceServer) SearchMoviesByPerson(context.Context, *SearchMoviesByPersonRequest) (*MovieList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchMoviesByPerson not implemented")
+}
+func (UnimplementedMovieServiceServer) mustEmbedUnimplementedMovieServiceServer() {}
+
+// UnsafeMovieServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MovieServiceServer will
+// result in compilation errors.
+type UnsafeMovieServiceServer interface {
+	mustEmbedUnimplementedMovieServiceServer()
+}
+
+func RegisterMovieServiceServer(s grpc.ServiceRegistrar, srv MovieServiceServer) {
+	s.RegisterService(&MovieService_ServiceDesc, srv)
+}
+
+func _MovieService_GetMovie_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMovieRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).GetMovie(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovieService_GetMovie_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).GetMovie(ctx, req.(*GetMovieRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovieService_GetMovies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMoviesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).GetMovies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovieService_GetMovies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).GetMovies(ctx, req.(*GetMoviesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovieService_ListGenres_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGenresRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).ListGenres(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovieService_ListGenres_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).ListGenres(ctx, req.(*ListGenresRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovieService_ListMoviesByGenre_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMoviesByGenreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).ListMoviesByGenre(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovieService_ListMoviesByGenre_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).ListMoviesByGenre(ctx, req.(*ListMoviesByGenreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovieService_ListCompanies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCompaniesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).ListCompanies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovieService_ListCompanies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).ListCompanies(ctx, req.(*ListCompaniesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovieService_ListMoviesByCompany_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMoviesByCompanyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).ListMoviesByCompany(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovieService_ListMoviesByCompany_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).ListMoviesByCompany(ctx, req.(*ListMoviesByCompanyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovieService_GetPerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).GetPerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovieService_GetPerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).GetPerson(ctx, req.(*GetPersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovieService_SearchPeople_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchPeopleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).SearchPeople(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovieService_SearchPeople_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).SearchPeople(ctx, req.(*SearchPeopleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovieService_SearchMoviesByPerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchMoviesByPersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).SearchMoviesByPerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovieService_SearchMoviesByPerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).SearchMoviesByPerson(ctx, req.(*SearchMoviesByPersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MovieService_ServiceDesc is the grpc.ServiceDesc for MovieService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MovieService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "moviebackend.movie.MovieService",
+	HandlerType: (*MovieServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetMovie",
+			Handler:    _MovieService_GetMovie_Handler,
+		},
+		{
+			MethodName: "GetMovies",
+			Handler:    _MovieService_GetMovies_Handler,
+		},
+		{
+			MethodName: "ListGenres",
+			Handler:    _MovieService_ListGenres_Handler,
+		},
+		{
+			MethodName: "ListMoviesByGenre",
+			Handler:    _MovieService_ListMoviesByGenre_Handler,
+		},
+		{
+			MethodName: "ListCompanies",
+			Handler:    _MovieService_ListCompanies_Handler,
+		},
+		{
+			MethodName: "ListMoviesByCompany",
+			Handler:    _MovieService_ListMoviesByCompany_Handler,
+		},
+		{
+			MethodName: "GetPerson",
+			Handler:    _MovieService_GetPerson_Handler,
+		},
+		{
+			MethodName: "SearchPeople",
+			Handler:    _MovieService_SearchPeople_Handler,
+		},
+		{
+			MethodName: "SearchMoviesByPerson",
+			Handler:    _MovieService_SearchMoviesByPerson_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "movie.proto",
+}
@@ -0,0 +1,106 @@
+// Package replica spreads repository reads over read replicas of the primary
+// database. Repositories are built once per database and wrapped by a
+// decorator that asks a Router where each call should go: reads go to a
+// healthy replica and writes to the primary. Once a request has written, the
+// rest of its reads go to the primary too, so it reads its own writes.
+package replica
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"sync/atomic"
+	"time"
+)
+
+// Primary is the replica index Read passes for the primary database.
+const Primary = -1
+
+// pingTimeout bounds a health check of one replica.
+const pingTimeout = 1 * time.Second
+
+// Router picks the database a read goes to. Replicas start out healthy and are
+// marked down when a health check fails, until one succeeds again.
+type Router struct {
+	logger   *logrus.Logger
+	replicas []*sql.DB
+	down     []atomic.Bool
+	next     atomic.Uint32
+}
+
+// NewRouter routes reads over replicas, which repositories index in the same
+// order.
+func NewRouter(l *logrus.Logger, replicas []*sql.DB) *Router {
+	return &Router{
+		logger:   l,
+		replicas: replicas,
+		down:     make([]atomic.Bool, len(replicas)),
+	}
+}
+
+// Run checks every replica each interval until ctx is done.
+func (r *Router) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for i := range r.replicas {
+			r.check(ctx, i)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check pings replica i, records whether it is up and reports it.
+func (r *Router) check(ctx context.Context, i int) bool {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	err := r.replicas[i].PingContext(ctx)
+	wasDown := r.down[i].Swap(err != nil)
+	if err != nil && !wasDown {
+		r.logger.WithField("replica", i).Warnf("replica is down, reading from the primary instead: %v", err)
+	}
+	if err == nil && wasDown {
+		r.logger.WithField("replica", i).Info("replica is back up")
+	}
+	return err == nil
+}
+
+// pick returns the replica the next read of the request in ctx goes to, in
+// turn among the healthy ones, or Primary when the request has written or no
+// replica is healthy.
+func (r *Router) pick(ctx context.Context) int {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok && s.wrote.Load() {
+		return Primary
+	}
+
+	n := len(r.replicas)
+	start := int(r.next.Add(1))
+	for j := 0; j < n; j++ {
+		i := (start + j) % n
+		if !r.down[i].Load() {
+			return i
+		}
+	}
+	return Primary
+}
+
+// Read runs read on the replica the router picks. A replica that fails the
+// read and then its health check is marked down and the read is retried on
+// the primary; other errors, like missing rows, are the read's own.
+func Read[T any](ctx context.Context, r *Router, read func(replica int) (T, error)) (T, error) {
+	i := r.pick(ctx)
+	v, err := read(i)
+	if err == nil || i == Primary || errors.Is(err, sql.ErrNoRows) || ctx.Err() != nil {
+		return v, err
+	}
+	if r.check(ctx, i) {
+		return v, err
+	}
+	return read(Primary)
+}
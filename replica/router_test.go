@@ -0,0 +1,182 @@
+package replica
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/labstack/echo/v4"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// databases opens a handle per entry of healthy, closing the ones that
+// should fail their health check.
+func databases(t *testing.T, healthy ...bool) []*sql.DB {
+	t.Helper()
+	var dbs []*sql.DB
+	for _, up := range healthy {
+		db, err := sql.Open("sqlite3", ":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if up {
+			t.Cleanup(func() { db.Close() })
+		} else {
+			db.Close()
+		}
+		dbs = append(dbs, db)
+	}
+	return dbs
+}
+
+func newRouter(t *testing.T, healthy ...bool) *Router {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewRouter(logger, databases(t, healthy...))
+}
+
+// reads runs n reads with ctx and returns where each went.
+func reads(ctx context.Context, r *Router, n int) []int {
+	var went []int
+	for j := 0; j < n; j++ {
+		i, _ := Read(ctx, r, func(i int) (int, error) { return i, nil })
+		went = append(went, i)
+	}
+	return went
+}
+
+// checkAll runs the health checks Run makes every interval.
+func checkAll(r *Router) {
+	for i := range r.replicas {
+		r.check(context.Background(), i)
+	}
+}
+
+func count(went []int, i int) int {
+	n := 0
+	for _, w := range went {
+		if w == i {
+			n++
+		}
+	}
+	return n
+}
+
+func TestReadsGoToReplicas(t *testing.T) {
+	r := newRouter(t, true, true)
+	went := reads(WithSession(context.Background()), r, 10)
+	if count(went, 0) != 5 || count(went, 1) != 5 {
+		t.Errorf("got reads on %v, want them shared by both replicas", went)
+	}
+
+	r = newRouter(t)
+	if went := reads(context.Background(), r, 2); count(went, Primary) != 2 {
+		t.Errorf("without replicas: got reads on %v, want the primary", went)
+	}
+}
+
+func TestReadAfterWrite(t *testing.T) {
+	r := newRouter(t, true)
+	ctx := WithSession(context.Background())
+	other := WithSession(context.Background())
+
+	if went := reads(ctx, r, 1); went[0] != 0 {
+		t.Errorf("before writing: got read on %d, want the replica", went[0])
+	}
+	Wrote(ctx)
+	if went := reads(ctx, r, 3); count(went, Primary) != 3 {
+		t.Errorf("after writing: got reads on %v, want the primary", went)
+	}
+	if went := reads(other, r, 1); went[0] != 0 {
+		t.Errorf("another session: got read on %d, want the replica", went[0])
+	}
+
+	// Without a session there is nothing to remember the write by.
+	Wrote(context.Background())
+	if went := reads(context.Background(), r, 1); went[0] != 0 {
+		t.Errorf("without a session: got read on %d, want the replica", went[0])
+	}
+}
+
+func TestReplicaDown(t *testing.T) {
+	r := newRouter(t, false, true)
+	checkAll(r)
+	if went := reads(context.Background(), r, 4); count(went, 1) != 4 {
+		t.Errorf("replica 0 down: got reads on %v, want replica 1", went)
+	}
+
+	r = newRouter(t, false, false)
+	checkAll(r)
+	if went := reads(context.Background(), r, 2); count(went, Primary) != 2 {
+		t.Errorf("every replica down: got reads on %v, want the primary", went)
+	}
+}
+
+func TestReadFallsBackToPrimary(t *testing.T) {
+	failing := errors.New("connection refused")
+	ctx := context.Background()
+
+	// The replica isn't known to be down yet: the read fails there, the
+	// health check fails too, and the read is retried on the primary.
+	r := newRouter(t, false)
+	var tried []int
+	v, err := Read(ctx, r, func(i int) (int, error) {
+		tried = append(tried, i)
+		if i != Primary {
+			return 0, failing
+		}
+		return 42, nil
+	})
+	if err != nil || v != 42 || len(tried) != 2 || tried[0] != 0 || tried[1] != Primary {
+		t.Errorf("got %d, %v after trying %v, want 42 from the primary after replica 0", v, err, tried)
+	}
+	if went := reads(ctx, r, 1); went[0] != Primary {
+		t.Errorf("after the failed check: got read on %d, want the primary", went[0])
+	}
+
+	// A healthy replica's errors, missing rows included, are the read's own.
+	r = newRouter(t, true)
+	for _, want := range []error{failing, sql.ErrNoRows} {
+		tried = nil
+		_, err = Read(ctx, r, func(i int) (int, error) {
+			tried = append(tried, i)
+			return 0, want
+		})
+		if !errors.Is(err, want) || len(tried) != 1 || tried[0] != 0 {
+			t.Errorf("got %v after trying %v, want %v from replica 0 only", err, tried, want)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	r := newRouter(t, true)
+	e := echo.New()
+	var went []int
+	e.Use(Middleware)
+	e.GET("/read", func(c echo.Context) error {
+		went = append(went, reads(c.Request().Context(), r, 1)...)
+		return c.NoContent(http.StatusOK)
+	})
+	e.POST("/write", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		went = append(went, reads(ctx, r, 1)...)
+		Wrote(ctx)
+		went = append(went, reads(ctx, r, 1)...)
+		return c.NoContent(http.StatusOK)
+	})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/write", nil),
+		httptest.NewRequest(http.MethodGet, "/read", nil),
+	} {
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	want := []int{0, Primary, 0}
+	if len(went) != len(want) || went[0] != want[0] || went[1] != want[1] || went[2] != want[2] {
+		t.Errorf("got reads on %v, want %v", went, want)
+	}
+}
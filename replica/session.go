@@ -0,0 +1,36 @@
+package replica
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"sync/atomic"
+)
+
+type sessionKey struct{}
+
+type session struct {
+	wrote atomic.Bool
+}
+
+// WithSession starts the session of one request. Reads made with ctx after a
+// write made with it go to the primary. Without a session every read may go
+// to a replica.
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &session{})
+}
+
+// Wrote records that the request in ctx is writing to the primary. Replica
+// decorators call it before every write.
+func Wrote(ctx context.Context) {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.wrote.Store(true)
+	}
+}
+
+// Middleware starts a session for every HTTP request.
+func Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.SetRequest(c.Request().WithContext(WithSession(c.Request().Context())))
+		return next(c)
+	}
+}
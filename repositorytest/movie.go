@@ -0,0 +1,332 @@
+package repositorytest
+
+import (
+	"context"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	"github.com/null-like/movie-backend/movie"
+	"testing"
+)
+
+var (
+	drama  = movieDomain.Genre{Id: 18, Name: "Drama"}
+	action = movieDomain.Genre{Id: 28, Name: "Action"}
+
+	zeta = movieDomain.ProductionCompany{Id: 1, Name: "Zeta Pictures", Country: "US"}
+	acme = movieDomain.ProductionCompany{Id: 2, Name: "Acme Films", Country: "KR"}
+)
+
+// catalog is the movies the suites start from. Beta names Acme without a
+// country, which must not clear the one Alpha gave it.
+func catalog() []movieDomain.Movie {
+	return []movieDomain.Movie{
+		{
+			Id: 1, Title: "Alpha", Overview: "It's the first one", ReleaseDate: "2001-01-01", Runtime: 100, Votes: 100,
+			Genres:              []movieDomain.Genre{drama, action},
+			ProductionCompanies: []movieDomain.ProductionCompany{zeta, acme},
+		},
+		{
+			Id: 2, Title: "Beta", ReleaseDate: "2003-01-01", Runtime: 90, Votes: 300,
+			Genres:              []movieDomain.Genre{drama},
+			ProductionCompanies: []movieDomain.ProductionCompany{{Id: acme.Id, Name: acme.Name}},
+		},
+		{
+			Id: 3, Title: "Gamma", ReleaseDate: "2002-01-01", Runtime: 80, Votes: 200,
+			Genres: []movieDomain.Genre{action},
+		},
+	}
+}
+
+func credits() []movieDomain.Credits {
+	jane := movieDomain.Cast{PersonId: 10, Name: "Jane Doe", Gender: 1, ProfilePath: "/jane.jpg"}
+	return []movieDomain.Credits{
+		{
+			MovieId: 1,
+			Cast: []movieDomain.Cast{
+				{CreditId: "c1", PersonId: jane.PersonId, Name: jane.Name, Gender: jane.Gender, ProfilePath: jane.ProfilePath, Character: "O'Neil", Order: 1},
+				{CreditId: "c2", PersonId: 11, Name: "John Roe", Gender: 2, Character: "Lead", Order: 0},
+			},
+			Crew: []movieDomain.Crew{
+				{CreditId: "c4", PersonId: jane.PersonId, Name: jane.Name, Gender: jane.Gender, ProfilePath: jane.ProfilePath, Job: "Writer", Department: "Writing"},
+				{CreditId: "c3", PersonId: 12, Name: "Ann Director", Job: "Director", Department: "Directing"},
+			},
+		},
+		{
+			MovieId: 2,
+			Cast: []movieDomain.Cast{
+				{CreditId: "c5", PersonId: jane.PersonId, Name: jane.Name, Gender: jane.Gender, ProfilePath: jane.ProfilePath, Character: "Herself", Order: 0},
+			},
+		},
+	}
+}
+
+func movieIds(movies []movieDomain.Movie) []int {
+	var ids []int
+	for _, m := range movies {
+		ids = append(ids, m.Id)
+	}
+	return ids
+}
+
+func genreIds(genres []movieDomain.Genre) []int {
+	var ids []int
+	for _, g := range genres {
+		ids = append(ids, g.Id)
+	}
+	return ids
+}
+
+// MovieRepository checks a movie.Repository against the behavior of the
+// MariaDB one. newRepo returns an empty repository.
+func MovieRepository(t *testing.T, newRepo func(t *testing.T) movie.Repository) {
+	ctx := context.Background()
+
+	seeded := func(t *testing.T) movie.Repository {
+		r := newRepo(t)
+		must(t, r.UpsertMovies(ctx, catalog()))
+		must(t, r.UpsertCredits(ctx, credits()))
+		return r
+	}
+
+	t.Run("ReadMovieById", func(t *testing.T) {
+		r := seeded(t)
+
+		m, err := r.ReadMovieById(ctx, 1)
+		must(t, err)
+		wantEqual(t, "title", m.Title, "Alpha")
+		wantEqual(t, "overview", m.Overview, "It's the first one")
+		wantEqual(t, "runtime", m.Runtime, 100)
+		wantInts(t, "genres", genreIds(m.Genres), []int{action.Id, drama.Id})
+		if len(m.ProductionCompanies) != 2 {
+			t.Fatalf("got %d production companies, want 2", len(m.ProductionCompanies))
+		}
+		wantEqual(t, "first company", m.ProductionCompanies[0], acme)
+		wantEqual(t, "second company", m.ProductionCompanies[1], zeta)
+
+		_, err = r.ReadMovieById(ctx, 99)
+		wantNoRows(t, "missing movie", err)
+	})
+
+	t.Run("ExistMovieById", func(t *testing.T) {
+		r := seeded(t)
+
+		exists, err := r.ExistMovieById(ctx, 2)
+		must(t, err)
+		wantEqual(t, "movie 2 exists", exists, true)
+		exists, err = r.ExistMovieById(ctx, 99)
+		must(t, err)
+		wantEqual(t, "movie 99 exists", exists, false)
+	})
+
+	t.Run("FindMoviesByIds", func(t *testing.T) {
+		r := seeded(t)
+
+		movies, err := r.FindMoviesByIds(ctx, []int{3, 1, 99})
+		must(t, err)
+		if len(movies) != 2 {
+			t.Fatalf("got movies %v, want 1 and 3", movieIds(movies))
+		}
+		for _, m := range movies {
+			if m.Id == 1 {
+				wantInts(t, "genres", genreIds(m.Genres), []int{action.Id, drama.Id})
+			}
+			if len(m.ProductionCompanies) != 0 {
+				t.Errorf("movie %d has production companies", m.Id)
+			}
+		}
+
+		movies, err = r.FindMoviesByIds(ctx, nil)
+		must(t, err)
+		wantEqual(t, "movies for no ids", len(movies), 0)
+	})
+
+	t.Run("AllGenres", func(t *testing.T) {
+		r := seeded(t)
+
+		genres, err := r.AllGenres(ctx)
+		must(t, err)
+		want := []movieDomain.GenreWithCount{{Id: 28, Name: "Action", MovieCount: 2}, {Id: 18, Name: "Drama", MovieCount: 2}}
+		if len(genres) != len(want) {
+			t.Fatalf("got genres %v, want %v", genres, want)
+		}
+		for i := range want {
+			wantEqual(t, "genre", genres[i], want[i])
+		}
+	})
+
+	t.Run("FindMoviesByGenreId", func(t *testing.T) {
+		r := seeded(t)
+
+		movies, err := r.FindMoviesByGenreId(ctx, drama.Id, 0, 10)
+		must(t, err)
+		wantInts(t, "drama", movieIds(movies), []int{2, 1})
+
+		movies, err = r.FindMoviesByGenreId(ctx, drama.Id, 1, 1)
+		must(t, err)
+		wantInts(t, "second drama", movieIds(movies), []int{1})
+
+		movies, err = r.FindMoviesByGenreId(ctx, 99, 0, 10)
+		must(t, err)
+		wantEqual(t, "movies of a missing genre", len(movies), 0)
+	})
+
+	t.Run("ProductionCompanies", func(t *testing.T) {
+		r := seeded(t)
+
+		companies, err := r.AllProductionCompanies(ctx, 0, 10)
+		must(t, err)
+		want := []movieDomain.ProductionCompanyWithCount{
+			{Id: acme.Id, Name: acme.Name, Country: acme.Country, MovieCount: 2},
+			{Id: zeta.Id, Name: zeta.Name, Country: zeta.Country, MovieCount: 1},
+		}
+		if len(companies) != len(want) {
+			t.Fatalf("got companies %v, want %v", companies, want)
+		}
+		for i := range want {
+			wantEqual(t, "company", companies[i], want[i])
+		}
+
+		companies, err = r.AllProductionCompanies(ctx, 1, 10)
+		must(t, err)
+		wantEqual(t, "companies after the first", len(companies), 1)
+
+		company, err := r.ReadProductionCompanyById(ctx, zeta.Id)
+		must(t, err)
+		wantEqual(t, "zeta", company, want[1])
+		_, err = r.ReadProductionCompanyById(ctx, 99)
+		wantNoRows(t, "missing company", err)
+
+		movies, err := r.FindMoviesByCompanyId(ctx, acme.Id, 0, 10)
+		must(t, err)
+		wantInts(t, "acme movies", movieIds(movies), []int{2, 1})
+	})
+
+	t.Run("UpsertMoviesReplacesLinks", func(t *testing.T) {
+		r := seeded(t)
+
+		alpha := catalog()[0]
+		alpha.Title = "Alpha Redux"
+		alpha.Genres = []movieDomain.Genre{drama, drama}
+		alpha.ProductionCompanies = nil
+		must(t, r.UpsertMovies(ctx, []movieDomain.Movie{alpha}))
+
+		m, err := r.ReadMovieById(ctx, 1)
+		must(t, err)
+		wantEqual(t, "title", m.Title, "Alpha Redux")
+		wantInts(t, "genres", genreIds(m.Genres), []int{drama.Id})
+		wantEqual(t, "production companies", len(m.ProductionCompanies), 0)
+
+		company, err := r.ReadProductionCompanyById(ctx, zeta.Id)
+		must(t, err)
+		wantEqual(t, "zeta movies", company.MovieCount, 0)
+	})
+
+	t.Run("UpsertKeywords", func(t *testing.T) {
+		r := seeded(t)
+
+		keywords := []movieDomain.MovieKeywords{{MovieId: 1, Keywords: []movieDomain.Keyword{{Id: 1, Name: "heist"}, {Id: 1, Name: "heist"}}}}
+		must(t, r.UpsertKeywords(ctx, keywords))
+		must(t, r.UpsertKeywords(ctx, keywords))
+		must(t, r.UpsertKeywords(ctx, nil))
+	})
+
+	t.Run("ReadCreditsByMovieId", func(t *testing.T) {
+		r := seeded(t)
+
+		c, err := r.ReadCreditsByMovieId(ctx, 1)
+		must(t, err)
+		wantEqual(t, "movie id", c.MovieId, 1)
+		if len(c.Cast) != 2 || len(c.Crew) != 2 {
+			t.Fatalf("got %d cast and %d crew, want 2 of each", len(c.Cast), len(c.Crew))
+		}
+		wantEqual(t, "first cast", c.Cast[0].CreditId, "c2")
+		wantEqual(t, "second cast", c.Cast[1], credits()[0].Cast[0])
+		wantEqual(t, "first crew", c.Crew[0].CreditId, "c3")
+		wantEqual(t, "second crew", c.Crew[1], credits()[0].Crew[0])
+	})
+
+	t.Run("UpsertCreditsReplacesCredits", func(t *testing.T) {
+		r := seeded(t)
+
+		replaced := movieDomain.Credits{MovieId: 1, Cast: credits()[0].Cast[:1]}
+		must(t, r.UpsertCredits(ctx, []movieDomain.Credits{replaced}))
+
+		c, err := r.ReadCreditsByMovieId(ctx, 1)
+		must(t, err)
+		wantEqual(t, "cast", len(c.Cast), 1)
+		wantEqual(t, "crew", len(c.Crew), 0)
+
+		c, err = r.ReadCreditsByMovieId(ctx, 2)
+		must(t, err)
+		wantEqual(t, "cast of an untouched movie", len(c.Cast), 1)
+	})
+
+	t.Run("People", func(t *testing.T) {
+		r := seeded(t)
+
+		p, err := r.ReadPersonById(ctx, 10)
+		must(t, err)
+		wantEqual(t, "person", p, movieDomain.Person{Id: 10, Name: "Jane Doe", Gender: 1, ProfilePath: "/jane.jpg"})
+		_, err = r.ReadPersonById(ctx, 99)
+		wantNoRows(t, "missing person", err)
+
+		cast, err := r.FindCastCreditsByPersonId(ctx, 10)
+		must(t, err)
+		if len(cast) != 2 {
+			t.Fatalf("got %d cast credits, want 2", len(cast))
+		}
+		wantEqual(t, "newest cast credit", cast[0], movieDomain.CastCredit{MovieId: 2, Title: "Beta", ReleaseDate: "2003-01-01", Character: "Herself"})
+		wantEqual(t, "older cast credit", cast[1].MovieId, 1)
+
+		crew, err := r.FindCrewCreditsByPersonId(ctx, 10)
+		must(t, err)
+		if len(crew) != 1 {
+			t.Fatalf("got %d crew credits, want 1", len(crew))
+		}
+		wantEqual(t, "crew credit", crew[0], movieDomain.CrewCredit{MovieId: 1, Title: "Alpha", ReleaseDate: "2001-01-01", Job: "Writer", Department: "Writing"})
+	})
+
+	t.Run("FindPeopleByName", func(t *testing.T) {
+		r := seeded(t)
+
+		people, err := r.FindPeopleByName(ctx, "DOE", 10)
+		must(t, err)
+		if len(people) != 1 || people[0].Id != 10 {
+			t.Errorf("got %v, want Jane Doe", people)
+		}
+
+		people, err = r.FindPeopleByName(ctx, "o", 2)
+		must(t, err)
+		if len(people) != 2 || people[0].Name != "Ann Director" || people[1].Name != "Jane Doe" {
+			t.Errorf("got %v, want Ann Director and Jane Doe", people)
+		}
+
+		people, err = r.FindPeopleByName(ctx, "%", 10)
+		must(t, err)
+		wantEqual(t, "people matching a literal %", len(people), 0)
+	})
+
+	t.Run("FindMoviesByPersonName", func(t *testing.T) {
+		r := seeded(t)
+
+		movies, err := r.FindMoviesByPersonName(ctx, "jane", movieDomain.RoleActor, 0, 10)
+		must(t, err)
+		wantInts(t, "jane's movies", movieIds(movies), []int{2, 1})
+
+		movies, err = r.FindMoviesByPersonName(ctx, "jane", movieDomain.RoleActor, 1, 10)
+		must(t, err)
+		wantInts(t, "jane's movies after the first", movieIds(movies), []int{1})
+
+		movies, err = r.FindMoviesByPersonName(ctx, "ann", movieDomain.RoleDirector, 0, 10)
+		must(t, err)
+		wantInts(t, "ann's movies", movieIds(movies), []int{1})
+
+		movies, err = r.FindMoviesByPersonName(ctx, "jane", movieDomain.RoleDirector, 0, 10)
+		must(t, err)
+		wantEqual(t, "movies jane directed", len(movies), 0)
+
+		_, err = r.FindMoviesByPersonName(ctx, "jane", "producer", 0, 10)
+		if err == nil {
+			t.Error("unknown role: got no error")
+		}
+	})
+}
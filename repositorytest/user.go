@@ -0,0 +1,811 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	userDomain "github.com/null-like/movie-backend/domain/user"
+	"github.com/null-like/movie-backend/movie"
+	"github.com/null-like/movie-backend/user"
+	"sort"
+	"sync"
+	"testing"
+)
+
+const tvType = "tv"
+
+func newUser(t *testing.T, r user.Repository, email string, nickname string) int {
+	t.Helper()
+	ctx := context.Background()
+	must(t, r.InsertUser(ctx, userDomain.User{Email: email, Password: "hash-" + nickname, Nickname: nickname}))
+	id, _, _, _, _, err := r.FindIdAndPasswdByEmail(ctx, email)
+	must(t, err)
+	return id
+}
+
+func itemIds(items []userDomain.PlaylistItem) []int {
+	var ids []int
+	for _, item := range items {
+		ids = append(ids, item.Id)
+	}
+	return ids
+}
+
+func itemPositions(items []userDomain.PlaylistItem) []int {
+	var positions []int
+	for _, item := range items {
+		positions = append(positions, item.Position)
+	}
+	return positions
+}
+
+func bannerIds(banners []userDomain.Banner) []int {
+	var ids []int
+	for _, b := range banners {
+		ids = append(ids, b.Id)
+	}
+	return ids
+}
+
+// UserRepository checks a user.Repository against the behavior of the
+// MariaDB one. newRepos returns an empty user repository together with the
+// movie repository whose catalog it reads genres and runtimes from.
+func UserRepository(t *testing.T, newRepos func(t *testing.T) (user.Repository, movie.Repository)) {
+	ctx := context.Background()
+
+	t.Run("Users", func(t *testing.T) {
+		r, _ := newRepos(t)
+		a := newUser(t, r, "a@example.com", "alice")
+		b := newUser(t, r, "b@example.com", "bob")
+
+		if err := r.InsertUser(ctx, userDomain.User{Email: "a@example.com", Password: "x", Nickname: "again"}); err == nil {
+			t.Error("duplicate email: got no error")
+		}
+
+		exists, err := r.FindIdByEmail(ctx, "b@example.com")
+		must(t, err)
+		wantEqual(t, "b@example.com registered", exists, true)
+		exists, err = r.FindIdByEmail(ctx, "c@example.com")
+		must(t, err)
+		wantEqual(t, "c@example.com registered", exists, false)
+
+		id, email, hash, nickname, rank, err := r.FindIdAndPasswdByEmail(ctx, "a@example.com")
+		must(t, err)
+		wantEqual(t, "id", id, a)
+		wantEqual(t, "email", email, "a@example.com")
+		wantEqual(t, "password", hash, "hash-alice")
+		wantEqual(t, "nickname", nickname, "alice")
+		wantEqual(t, "rank", rank, "회원")
+		_, _, _, _, _, err = r.FindIdAndPasswdByEmail(ctx, "c@example.com")
+		if err == nil {
+			t.Error("unknown email: got no error")
+		}
+
+		users, err := r.FindAllUser(ctx)
+		must(t, err)
+		if len(users) != 2 || users[0].Id != a || users[1].Id != b {
+			t.Errorf("got users %v, want alice then bob", users)
+		}
+
+		must(t, r.UpdateUser(ctx, b, "관리자"))
+		info, err := r.FindUserById(ctx, b)
+		must(t, err)
+		wantEqual(t, "rank", info.Rank, "관리자")
+		wantEqual(t, "email", info.Email, "b@example.com")
+		if info.SignUpDate == "" {
+			t.Error("sign up date is empty")
+		}
+
+		nickname, err = r.FindNicknameByUserId(ctx, b)
+		must(t, err)
+		wantEqual(t, "nickname", nickname, "bob")
+
+		must(t, r.DeleteUser(ctx, b))
+		_, err = r.FindUserById(ctx, b)
+		wantNoRows(t, "deleted user", err)
+		_, err = r.FindNicknameByUserId(ctx, b)
+		wantNoRows(t, "deleted user's nickname", err)
+	})
+
+	t.Run("QuotedEmail", func(t *testing.T) {
+		r, _ := newRepos(t)
+		a := newUser(t, r, "o'brien@example.com", `o'brien "the" #1`)
+		newUser(t, r, "b@example.com", "bob")
+
+		exists, err := r.FindIdByEmail(ctx, "x' OR '1' = '1")
+		must(t, err)
+		wantEqual(t, "injected email registered", exists, false)
+		_, _, _, _, _, err = r.FindIdAndPasswdByEmail(ctx, "x' OR '1' = '1")
+		wantNoRows(t, "injected email", err)
+
+		id, _, _, nickname, _, err := r.FindIdAndPasswdByEmail(ctx, "o'brien@example.com")
+		must(t, err)
+		wantEqual(t, "id", id, a)
+		wantEqual(t, "nickname", nickname, `o'brien "the" #1`)
+	})
+
+	t.Run("Profile", func(t *testing.T) {
+		r, _ := newRepos(t)
+		a := newUser(t, r, "a@example.com", "alice")
+
+		must(t, r.UpdateBio(ctx, a, "It's me"))
+		must(t, r.UpdateAvatar(ctx, a, fmt.Sprintf("%d/avatar.png", a)))
+		must(t, r.InsertRating(ctx, a, 1, 8, movieDomain.MediaType))
+		must(t, r.InsertRating(ctx, a, 1, 6, tvType))
+		must(t, r.InsertFavorite(ctx, a, 1, movieDomain.MediaType))
+		_, err := r.InsertPlaylist(ctx, a, "Public", movieDomain.MediaType, userDomain.VisibilityPublic)
+		must(t, err)
+		_, err = r.InsertPlaylist(ctx, a, "Private", movieDomain.MediaType, userDomain.VisibilityPrivate)
+		must(t, err)
+
+		p, err := r.FindProfileById(ctx, a)
+		must(t, err)
+		wantEqual(t, "nickname", p.Nickname, "alice")
+		wantEqual(t, "bio", p.Bio, "It's me")
+		wantEqual(t, "avatar", p.Avatar, fmt.Sprintf("%d/avatar.png", a))
+		wantEqual(t, "join date length", len(p.JoinDate), len("2006-01-02"))
+		wantEqual(t, "ratings", p.RatingCount, 2)
+		wantEqual(t, "favorites", p.FavoriteCount, 1)
+		wantEqual(t, "public playlists", p.PlaylistCount, 1)
+
+		_, err = r.FindProfileById(ctx, a+100)
+		wantNoRows(t, "missing profile", err)
+
+		b := newUser(t, r, "b@example.com", "bob")
+		profiles, err := r.FindProfilesByIds(ctx, []int{b, a, a + 100})
+		must(t, err)
+		sort.Slice(profiles, func(i, j int) bool { return profiles[i].Id < profiles[j].Id })
+		if len(profiles) != 2 {
+			t.Fatalf("got %d profiles, want 2", len(profiles))
+		}
+		wantEqual(t, "batched profile", fmt.Sprint(profiles[0]), fmt.Sprint(p))
+		wantEqual(t, "batched nickname", profiles[1].Nickname, "bob")
+		wantEqual(t, "batched ratings", profiles[1].RatingCount, 0)
+		profiles, err = r.FindProfilesByIds(ctx, nil)
+		must(t, err)
+		wantEqual(t, "profiles of no ids", len(profiles), 0)
+	})
+
+	t.Run("Ratings", func(t *testing.T) {
+		r, _ := newRepos(t)
+		a := newUser(t, r, "a@example.com", "alice")
+
+		must(t, r.InsertRating(ctx, a, 1, 7, movieDomain.MediaType))
+		must(t, r.InsertRating(ctx, a, 1, 9, movieDomain.MediaType))
+		must(t, r.InsertRating(ctx, a, 2, 9, movieDomain.MediaType))
+		must(t, r.InsertRating(ctx, a, 1, 5, tvType))
+
+		rating, err := r.FindRatingByMovieId(ctx, a, 1, movieDomain.MediaType)
+		must(t, err)
+		wantEqual(t, "rating after rerating", rating, 9)
+		_, err = r.FindRatingByMovieId(ctx, a, 3, movieDomain.MediaType)
+		wantNoRows(t, "missing rating", err)
+
+		ratings, err := r.FindRatingsByUserId(ctx, a)
+		must(t, err)
+		if len(ratings) != 3 {
+			t.Fatalf("got %d ratings, want 3", len(ratings))
+		}
+		for _, rate := range ratings {
+			if rate.ApplyDate == "" {
+				t.Errorf("rating of %d %s has no date", rate.Id, rate.Type)
+			}
+		}
+
+		b := newUser(t, r, "b@example.com", "bob")
+		must(t, r.InsertRating(ctx, b, 2, 4, movieDomain.MediaType))
+		byUser, err := r.FindRatingsByUserIds(ctx, []int{a, b, b + 100})
+		must(t, err)
+		wantEqual(t, "users with ratings", len(byUser), 2)
+		wantEqual(t, "batched ratings", len(byUser[a]), 3)
+		if len(byUser[b]) != 1 || byUser[b][0].Rating != 4 || byUser[b][0].ApplyDate == "" {
+			t.Errorf("batched ratings of b = %v", byUser[b])
+		}
+
+		buckets, err := r.FindRatingDistribution(ctx, a)
+		must(t, err)
+		want := []userDomain.RatingBucket{{Rating: 5, Count: 1}, {Rating: 9, Count: 2}}
+		if fmt.Sprint(buckets) != fmt.Sprint(want) {
+			t.Errorf("distribution = %v, want %v", buckets, want)
+		}
+	})
+
+	t.Run("RatedGenresAndRuntime", func(t *testing.T) {
+		r, movies := newRepos(t)
+		must(t, movies.UpsertMovies(ctx, catalog()))
+		a := newUser(t, r, "a@example.com", "alice")
+
+		must(t, r.InsertRating(ctx, a, 1, 8, movieDomain.MediaType))
+		must(t, r.InsertRating(ctx, a, 2, 5, movieDomain.MediaType))
+		must(t, r.InsertRating(ctx, a, 3, 10, movieDomain.MediaType))
+		must(t, r.InsertRating(ctx, a, 2, 1, tvType))
+
+		genres, err := r.FindRatedGenres(ctx, a, 10)
+		must(t, err)
+		want := []userDomain.GenreAffinity{
+			{Id: action.Id, Name: action.Name, RatedCount: 2, AverageRating: 9},
+			{Id: drama.Id, Name: drama.Name, RatedCount: 2, AverageRating: 6.5},
+		}
+		if fmt.Sprint(genres) != fmt.Sprint(want) {
+			t.Errorf("rated genres = %v, want %v", genres, want)
+		}
+		genres, err = r.FindRatedGenres(ctx, a, 1)
+		must(t, err)
+		wantEqual(t, "genres with limit 1", len(genres), 1)
+
+		must(t, r.InsertWatch(ctx, a, 1, movieDomain.MediaType, "2024-01-01 10:00:00"))
+		must(t, r.InsertWatch(ctx, a, 1, movieDomain.MediaType, "2024-01-02 10:00:00"))
+		must(t, r.InsertWatch(ctx, a, 2, movieDomain.MediaType, ""))
+		must(t, r.InsertWatch(ctx, a, 1, tvType, ""))
+		runtime, err := r.FindWatchedRuntime(ctx, a)
+		must(t, err)
+		wantEqual(t, "watched runtime", runtime, 290)
+	})
+
+	t.Run("Favorites", func(t *testing.T) {
+		r, _ := newRepos(t)
+		a := newUser(t, r, "a@example.com", "alice")
+
+		must(t, r.InsertFavorite(ctx, a, 2, movieDomain.MediaType))
+		must(t, r.InsertFavorite(ctx, a, 1, tvType))
+		if err := r.InsertFavorite(ctx, a, 2, movieDomain.MediaType); err == nil {
+			t.Error("duplicate favorite: got no error")
+		}
+
+		favorite, err := r.FindIsFavorite(ctx, a, 2, movieDomain.MediaType)
+		must(t, err)
+		wantEqual(t, "favorite", favorite, true)
+		favorite, err = r.FindIsFavorite(ctx, a, 2, tvType)
+		wantNoRows(t, "missing favorite", err)
+		wantEqual(t, "missing favorite", favorite, false)
+
+		favorites, err := r.FindFavoriteByUserId(ctx, a)
+		must(t, err)
+		want := []userDomain.Favorite{{Id: 1, Type: tvType}, {Id: 2, Type: movieDomain.MediaType}}
+		if fmt.Sprint(favorites) != fmt.Sprint(want) {
+			t.Errorf("favorites = %v, want %v", favorites, want)
+		}
+
+		b := newUser(t, r, "b@example.com", "bob")
+		must(t, r.InsertFavorite(ctx, b, 3, movieDomain.MediaType))
+		byUser, err := r.FindFavoritesByUserIds(ctx, []int{a, b, b + 100})
+		must(t, err)
+		wantEqual(t, "users with favorites", len(byUser), 2)
+		sort.Slice(byUser[a], func(i, j int) bool { return byUser[a][i].Id < byUser[a][j].Id })
+		wantEqual(t, "batched favorites", fmt.Sprint(byUser[a]), fmt.Sprint(want))
+		wantEqual(t, "batched favorites of b", fmt.Sprint(byUser[b]), fmt.Sprint([]userDomain.Favorite{{Id: 3, Type: movieDomain.MediaType}}))
+
+		must(t, r.DeleteFavorite(ctx, a, 2, movieDomain.MediaType))
+		_, err = r.FindIsFavorite(ctx, a, 2, movieDomain.MediaType)
+		wantNoRows(t, "deleted favorite", err)
+	})
+
+	t.Run("Watchlist", func(t *testing.T) {
+		r, _ := newRepos(t)
+		a := newUser(t, r, "a@example.com", "alice")
+
+		must(t, r.UpsertWantToWatch(ctx, a, 1, movieDomain.MediaType))
+		entry, err := r.FindWatchEntry(ctx, a, 1, movieDomain.MediaType)
+		must(t, err)
+		wantEqual(t, "state", entry.State, userDomain.WatchStateWant)
+		wantEqual(t, "last watched", entry.LastWatchedAt, "")
+
+		must(t, r.InsertWatch(ctx, a, 1, movieDomain.MediaType, "2024-01-02 10:00:00"))
+		must(t, r.InsertWatch(ctx, a, 1, movieDomain.MediaType, "2024-01-01 10:00:00"))
+		entry, err = r.FindWatchEntry(ctx, a, 1, movieDomain.MediaType)
+		must(t, err)
+		wantEqual(t, "state", entry.State, userDomain.WatchStateWatched)
+		wantEqual(t, "watch count", entry.WatchCount, 2)
+		wantEqual(t, "rewatch count", entry.RewatchCount, 1)
+		wantEqual(t, "last watched", entry.LastWatchedAt, "2024-01-02 10:00:00")
+
+		must(t, r.UpsertWantToWatch(ctx, a, 1, movieDomain.MediaType))
+		entry, err = r.FindWatchEntry(ctx, a, 1, movieDomain.MediaType)
+		must(t, err)
+		wantEqual(t, "state after wanting again", entry.State, userDomain.WatchStateWant)
+		wantEqual(t, "watch count after wanting again", entry.WatchCount, 2)
+
+		must(t, r.InsertWatch(ctx, a, 2, tvType, "2024-01-03 10:00:00"))
+		entries, err := r.FindWatchlist(ctx, a, "", 0, 10)
+		must(t, err)
+		wantEqual(t, "entries", len(entries), 2)
+		entries, err = r.FindWatchlist(ctx, a, userDomain.WatchStateWatched, 0, 10)
+		must(t, err)
+		if len(entries) != 1 || entries[0].MediaId != 2 || entries[0].RewatchCount != 0 {
+			t.Errorf("watched entries = %v, want tv 2 watched once", entries)
+		}
+		entries, err = r.FindWatchlist(ctx, a, "", 1, 10)
+		must(t, err)
+		wantEqual(t, "entries after the first", len(entries), 1)
+
+		_, err = r.FindWatchEntry(ctx, a, 3, movieDomain.MediaType)
+		wantNoRows(t, "missing entry", err)
+
+		events, err := r.FindWatchHistory(ctx, a, 0, 10)
+		must(t, err)
+		var watched []string
+		for _, e := range events {
+			watched = append(watched, e.WatchedAt)
+		}
+		want := []string{"2024-01-03 10:00:00", "2024-01-02 10:00:00", "2024-01-01 10:00:00"}
+		if fmt.Sprint(watched) != fmt.Sprint(want) {
+			t.Errorf("history = %v, want %v", watched, want)
+		}
+		events, err = r.FindWatchHistory(ctx, a, 1, 1)
+		must(t, err)
+		if len(events) != 1 || events[0].WatchedAt != want[1] {
+			t.Errorf("second page = %v, want the viewing of %s", events, want[1])
+		}
+
+		must(t, r.DeleteWatchEntry(ctx, a, 1, movieDomain.MediaType))
+		_, err = r.FindWatchEntry(ctx, a, 1, movieDomain.MediaType)
+		wantNoRows(t, "deleted entry", err)
+		events, err = r.FindWatchHistory(ctx, a, 0, 10)
+		must(t, err)
+		wantEqual(t, "history kept after removing the entry", len(events), 3)
+	})
+
+	t.Run("Playlists", func(t *testing.T) {
+		r, _ := newRepos(t)
+		a := newUser(t, r, "a@example.com", "alice")
+		b := newUser(t, r, "b@example.com", "bob")
+
+		public, err := r.InsertPlaylist(ctx, a, "Favorites", movieDomain.MediaType, userDomain.VisibilityPublic)
+		must(t, err)
+		private, err := r.InsertPlaylist(ctx, a, "Secret", tvType, userDomain.VisibilityPrivate)
+		must(t, err)
+		other, err := r.InsertPlaylist(ctx, b, "Bob's list", movieDomain.MediaType, userDomain.VisibilityPublic)
+		must(t, err)
+		p, err := r.ReadPlaylistById(ctx, other)
+		must(t, err)
+		wantEqual(t, "name with an apostrophe", p.Name, "Bob's list")
+
+		p, err = r.ReadPlaylistById(ctx, private)
+		must(t, err)
+		wantEqual(t, "playlist", fmt.Sprint(p), fmt.Sprint(userDomain.Playlist{
+			Id: private, UserId: a, Name: "Secret", Type: tvType, Visibility: userDomain.VisibilityPrivate, Version: 1,
+		}))
+		_, err = r.ReadPlaylistById(ctx, other+100)
+		wantNoRows(t, "missing playlist", err)
+
+		playlists, err := r.FindPlaylistsByIds(ctx, []int{other, private, other + 100})
+		must(t, err)
+		sort.Slice(playlists, func(i, j int) bool { return playlists[i].Id < playlists[j].Id })
+		if len(playlists) != 2 {
+			t.Fatalf("got %d playlists by id, want 2", len(playlists))
+		}
+		wantEqual(t, "playlist by id", fmt.Sprint(playlists[0]), fmt.Sprint(p))
+		wantEqual(t, "other playlist by id", playlists[1].Name, "Bob's list")
+
+		playlists, err = r.FindPublicPlaylists(ctx)
+		must(t, err)
+		ids := []int{}
+		for _, p := range playlists {
+			ids = append(ids, p.Id)
+		}
+		sort.Ints(ids)
+		wantInts(t, "public playlists", ids, []int{public, other})
+
+		playlists, err = r.FindPlaylistsByUserId(ctx, a)
+		must(t, err)
+		wantEqual(t, "alice's playlists", len(playlists), 2)
+
+		must(t, r.UpdatePlaylist(ctx, private, "Ann's, not so secret", userDomain.VisibilityUnlisted))
+		must(t, r.IncrementPlaylistViewCount(ctx, private))
+		must(t, r.IncrementPlaylistViewCount(ctx, private))
+		p, err = r.ReadPlaylistById(ctx, private)
+		must(t, err)
+		wantEqual(t, "name", p.Name, "Ann's, not so secret")
+		wantEqual(t, "visibility", p.Visibility, userDomain.VisibilityUnlisted)
+		wantEqual(t, "views", p.ViewCount, 2)
+
+		must(t, r.UpdatePlaylistShareSlug(ctx, private, "abc123"))
+		p, err = r.ReadPlaylistByShareSlug(ctx, "abc123")
+		must(t, err)
+		wantEqual(t, "shared playlist", p.Id, private)
+		if err := r.UpdatePlaylistShareSlug(ctx, public, "abc123"); err == nil {
+			t.Error("slug shared by two playlists: got no error")
+		}
+		must(t, r.UpdatePlaylistShareSlug(ctx, private, ""))
+		_, err = r.ReadPlaylistByShareSlug(ctx, "abc123")
+		wantNoRows(t, "revoked slug", err)
+		p, err = r.ReadPlaylistById(ctx, private)
+		must(t, err)
+		wantEqual(t, "revoked slug", p.ShareSlug, "")
+	})
+
+	t.Run("PlaylistItems", func(t *testing.T) {
+		r, _ := newRepos(t)
+		a := newUser(t, r, "a@example.com", "alice")
+		b := newUser(t, r, "b@example.com", "bob")
+		id, err := r.InsertPlaylist(ctx, a, "List", movieDomain.MediaType, userDomain.VisibilityPublic)
+		must(t, err)
+
+		must(t, r.InsertPlaylistItem(ctx, id, 1, a, 10, movieDomain.MediaType, "Don't miss it"))
+		must(t, r.InsertPlaylistItem(ctx, id, 2, b, 20, movieDomain.MediaType, ""))
+		must(t, r.InsertPlaylistItem(ctx, id, 0, a, 30, movieDomain.MediaType, ""))
+
+		err = r.InsertPlaylistItem(ctx, id, 1, a, 40, movieDomain.MediaType, "")
+		if !errors.Is(err, userDomain.ErrPlaylistVersionConflict) {
+			t.Errorf("stale version: got error %v, want a version conflict", err)
+		}
+		if err := r.InsertPlaylistItem(ctx, id, 0, a, 10, movieDomain.MediaType, ""); err == nil {
+			t.Error("duplicate item: got no error")
+		}
+
+		p, err := r.ReadPlaylistById(ctx, id)
+		must(t, err)
+		wantEqual(t, "version", p.Version, 4)
+
+		items, err := r.FindPlaylistItems(ctx, id)
+		must(t, err)
+		if len(items) != 3 {
+			t.Fatalf("got %d items, want 3", len(items))
+		}
+		wantInts(t, "positions", itemPositions(items), []int{1, 2, 3})
+		wantEqual(t, "note", items[0].Note, "Don't miss it")
+		wantEqual(t, "added by", items[1].AddedBy, b)
+		if items[0].AddedAt == "" {
+			t.Error("item has no added date")
+		}
+		first, second, third := items[0].Id, items[1].Id, items[2].Id
+
+		err = r.DeletePlaylistItem(ctx, id, 0, a, third+100)
+		if !errors.Is(err, userDomain.ErrPlaylistItemNotFound) {
+			t.Errorf("missing item: got error %v, want ErrPlaylistItemNotFound", err)
+		}
+		p, err = r.ReadPlaylistById(ctx, id)
+		must(t, err)
+		wantEqual(t, "version after a failed change", p.Version, 4)
+
+		must(t, r.DeletePlaylistItem(ctx, id, 4, a, second))
+		items, err = r.FindPlaylistItems(ctx, id)
+		must(t, err)
+		wantInts(t, "items after removal", itemIds(items), []int{first, third})
+		wantInts(t, "positions after removal", itemPositions(items), []int{1, 2})
+
+		must(t, r.UpdatePlaylistItemPositions(ctx, id, 5, b, []int{third, first}))
+		items, err = r.FindPlaylistItems(ctx, id)
+		must(t, err)
+		wantInts(t, "items after moving", itemIds(items), []int{third, first})
+
+		err = r.UpdatePlaylistItemPositions(ctx, id, 5, a, []int{first, third})
+		if !errors.Is(err, userDomain.ErrPlaylistVersionConflict) {
+			t.Errorf("stale move: got error %v, want a version conflict", err)
+		}
+		err = r.DeletePlaylistItem(ctx, id, 5, a, first)
+		if !errors.Is(err, userDomain.ErrPlaylistVersionConflict) {
+			t.Errorf("stale removal: got error %v, want a version conflict", err)
+		}
+		items, err = r.FindPlaylistItems(ctx, id)
+		must(t, err)
+		wantInts(t, "items after stale changes", itemIds(items), []int{third, first})
+
+		empty, err := r.InsertPlaylist(ctx, b, "Empty", movieDomain.MediaType, userDomain.VisibilityPublic)
+		must(t, err)
+		byPlaylist, err := r.FindPlaylistItemsByPlaylistIds(ctx, []int{id, empty, empty + 100})
+		must(t, err)
+		wantEqual(t, "playlists with items", len(byPlaylist), 1)
+		wantInts(t, "batched items", itemIds(byPlaylist[id]), []int{third, first})
+		wantEqual(t, "batched note", byPlaylist[id][1].Note, "Don't miss it")
+
+		changes, err := r.FindPlaylistChanges(ctx, id, 10)
+		must(t, err)
+		var actions []string
+		var versions []int
+		for _, c := range changes {
+			actions = append(actions, c.Action)
+			versions = append(versions, c.Version)
+		}
+		wantEqual(t, "actions", fmt.Sprint(actions), fmt.Sprint([]string{
+			userDomain.PlaylistActionMove, userDomain.PlaylistActionRemove,
+			userDomain.PlaylistActionAdd, userDomain.PlaylistActionAdd, userDomain.PlaylistActionAdd,
+		}))
+		wantInts(t, "versions", versions, []int{6, 5, 4, 3, 2})
+		wantEqual(t, "removed item", changes[1].ItemId, second)
+		wantEqual(t, "removed media", changes[1].MediaId, 20)
+		wantEqual(t, "removed by", changes[1].UserId, a)
+		changes, err = r.FindPlaylistChanges(ctx, id, 2)
+		must(t, err)
+		wantEqual(t, "changes with limit 2", len(changes), 2)
+	})
+
+	t.Run("ForkAndDeletePlaylist", func(t *testing.T) {
+		r, _ := newRepos(t)
+		a := newUser(t, r, "a@example.com", "alice")
+		b := newUser(t, r, "b@example.com", "bob")
+		source, err := r.InsertPlaylist(ctx, a, "Source", tvType, userDomain.VisibilityPublic)
+		must(t, err)
+		must(t, r.InsertPlaylistItem(ctx, source, 0, a, 10, tvType, "first"))
+		must(t, r.InsertPlaylistItem(ctx, source, 0, a, 20, tvType, "second"))
+		must(t, r.UpsertPlaylistMember(ctx, source, b, userDomain.PlaylistRoleEditor, a))
+
+		fork, err := r.ForkPlaylist(ctx, source, b, "Bob's fork")
+		must(t, err)
+		p, err := r.ReadPlaylistById(ctx, fork)
+		must(t, err)
+		wantEqual(t, "owner", p.UserId, b)
+		wantEqual(t, "name", p.Name, "Bob's fork")
+		wantEqual(t, "type", p.Type, tvType)
+		wantEqual(t, "visibility", p.Visibility, userDomain.VisibilityPrivate)
+		wantEqual(t, "forked from", p.ForkedFrom, source)
+
+		items, err := r.FindPlaylistItems(ctx, fork)
+		must(t, err)
+		if len(items) != 2 {
+			t.Fatalf("got %d forked items, want 2", len(items))
+		}
+		wantEqual(t, "first note", items[0].Note, "first")
+		wantEqual(t, "second media", items[1].MediaId, 20)
+		wantInts(t, "positions", itemPositions(items), []int{1, 2})
+		wantEqual(t, "added by", items[0].AddedBy, b)
+
+		must(t, r.DeletePlaylist(ctx, source))
+		_, err = r.ReadPlaylistById(ctx, source)
+		wantNoRows(t, "deleted playlist", err)
+		items, err = r.FindPlaylistItems(ctx, source)
+		must(t, err)
+		wantEqual(t, "items of a deleted playlist", len(items), 0)
+		members, err := r.FindPlaylistMembers(ctx, source)
+		must(t, err)
+		wantEqual(t, "members of a deleted playlist", len(members), 0)
+		changes, err := r.FindPlaylistChanges(ctx, source, 10)
+		must(t, err)
+		wantEqual(t, "changes of a deleted playlist", len(changes), 0)
+
+		items, err = r.FindPlaylistItems(ctx, fork)
+		must(t, err)
+		wantEqual(t, "items of the fork", len(items), 2)
+	})
+
+	t.Run("PlaylistMembers", func(t *testing.T) {
+		r, _ := newRepos(t)
+		a := newUser(t, r, "a@example.com", "alice")
+		b := newUser(t, r, "b@example.com", "bob")
+		id, err := r.InsertPlaylist(ctx, a, "Shared", movieDomain.MediaType, userDomain.VisibilityPrivate)
+		must(t, err)
+
+		must(t, r.UpsertPlaylistMember(ctx, id, b, userDomain.PlaylistRoleEditor, a))
+		must(t, r.UpsertPlaylistMember(ctx, id, b, userDomain.PlaylistRoleViewer, a))
+		role, err := r.FindPlaylistRole(ctx, id, b)
+		must(t, err)
+		wantEqual(t, "role", role, userDomain.PlaylistRoleViewer)
+		_, err = r.FindPlaylistRole(ctx, id, a)
+		wantNoRows(t, "owner's role", err)
+
+		members, err := r.FindPlaylistMembers(ctx, id)
+		must(t, err)
+		if len(members) != 1 {
+			t.Fatalf("got %d members, want 1", len(members))
+		}
+		wantEqual(t, "member", members[0].UserId, b)
+		wantEqual(t, "nickname", members[0].Nickname, "bob")
+		wantEqual(t, "invited by", members[0].InvitedBy, a)
+
+		playlists, err := r.FindPlaylistsByMemberId(ctx, b)
+		must(t, err)
+		if len(playlists) != 1 || playlists[0].Id != id {
+			t.Errorf("bob's shared playlists = %v, want %d", playlists, id)
+		}
+
+		must(t, r.DeletePlaylistMember(ctx, id, b))
+		_, err = r.FindPlaylistRole(ctx, id, b)
+		wantNoRows(t, "removed member", err)
+	})
+
+	t.Run("Follows", func(t *testing.T) {
+		r, _ := newRepos(t)
+		a := newUser(t, r, "a@example.com", "alice")
+		b := newUser(t, r, "b@example.com", "bob")
+
+		must(t, r.InsertFollow(ctx, a, b))
+		must(t, r.InsertFollow(ctx, a, b))
+
+		following, err := r.FindIsFollowing(ctx, a, b)
+		must(t, err)
+		wantEqual(t, "alice follows bob", following, true)
+		following, err = r.FindIsFollowing(ctx, b, a)
+		must(t, err)
+		wantEqual(t, "bob follows alice", following, false)
+
+		users, err := r.FindFollowing(ctx, a)
+		must(t, err)
+		if len(users) != 1 || users[0].Id != b || users[0].Nickname != "bob" || users[0].FollowedAt == "" {
+			t.Errorf("alice follows %v, want bob", users)
+		}
+		users, err = r.FindFollowers(ctx, b)
+		must(t, err)
+		if len(users) != 1 || users[0].Id != a || users[0].Nickname != "alice" {
+			t.Errorf("bob's followers = %v, want alice", users)
+		}
+
+		must(t, r.DeleteFollow(ctx, a, b))
+		users, err = r.FindFollowing(ctx, a)
+		must(t, err)
+		wantEqual(t, "follows after unfollowing", len(users), 0)
+	})
+
+	t.Run("Activity", func(t *testing.T) {
+		r, _ := newRepos(t)
+		a := newUser(t, r, "a@example.com", "alice")
+		b := newUser(t, r, "b@example.com", "bob")
+		public, err := r.InsertPlaylist(ctx, b, "Open", movieDomain.MediaType, userDomain.VisibilityPublic)
+		must(t, err)
+		private, err := r.InsertPlaylist(ctx, b, "Closed", movieDomain.MediaType, userDomain.VisibilityPrivate)
+		must(t, err)
+
+		visibility, err := r.FindActivityVisibility(ctx, b)
+		must(t, err)
+		wantEqual(t, "default visibility", visibility, userDomain.ActivityPublic)
+		_, err = r.FindActivityVisibility(ctx, b+100)
+		wantNoRows(t, "missing user's visibility", err)
+
+		must(t, r.InsertFollow(ctx, a, b))
+		must(t, r.InsertActivityEvent(ctx, userDomain.ActivityEvent{UserId: b, Kind: userDomain.ActivityRating, MediaId: 1, Type: movieDomain.MediaType, Rating: 8}))
+		must(t, r.InsertActivityEvent(ctx, userDomain.ActivityEvent{UserId: b, Kind: userDomain.ActivityPlaylistItem, MediaId: 2, Type: movieDomain.MediaType, PlaylistId: private}))
+		must(t, r.InsertActivityEvent(ctx, userDomain.ActivityEvent{UserId: b, Kind: userDomain.ActivityPlaylistPublished, PlaylistId: public}))
+		must(t, r.InsertActivityEvent(ctx, userDomain.ActivityEvent{UserId: a, Kind: userDomain.ActivityFavorite, MediaId: 3, Type: tvType}))
+
+		feed, err := r.FindFeed(ctx, a, 0, 10)
+		must(t, err)
+		if len(feed) != 2 {
+			t.Fatalf("got %d feed events, want 2", len(feed))
+		}
+		wantEqual(t, "newest kind", feed[0].Kind, userDomain.ActivityPlaylistPublished)
+		wantEqual(t, "playlist name", feed[0].PlaylistName, "Open")
+		wantEqual(t, "nickname", feed[0].Nickname, "bob")
+		wantEqual(t, "oldest kind", feed[1].Kind, userDomain.ActivityRating)
+		wantEqual(t, "rating", feed[1].Rating, 8)
+		if feed[1].CreatedAt == "" {
+			t.Error("event has no date")
+		}
+
+		page, err := r.FindFeed(ctx, a, feed[0].Id, 10)
+		must(t, err)
+		if len(page) != 1 || page[0].Id != feed[1].Id {
+			t.Errorf("feed before %d = %v, want the rating", feed[0].Id, page)
+		}
+		page, err = r.FindFeed(ctx, a, 0, 1)
+		must(t, err)
+		wantEqual(t, "feed with limit 1", len(page), 1)
+
+		must(t, r.UpdateActivityVisibility(ctx, b, userDomain.ActivityPrivate))
+		visibility, err = r.FindActivityVisibility(ctx, b)
+		must(t, err)
+		wantEqual(t, "visibility", visibility, userDomain.ActivityPrivate)
+		feed, err = r.FindFeed(ctx, a, 0, 10)
+		must(t, err)
+		wantEqual(t, "feed of a private user", len(feed), 0)
+
+		events, err := r.FindActivityByUserId(ctx, b, 0, 10)
+		must(t, err)
+		wantEqual(t, "bob's own activity", len(events), 2)
+	})
+
+	t.Run("Banners", func(t *testing.T) {
+		r, _ := newRepos(t)
+
+		always, err := r.InsertBanner(ctx, userDomain.Banner{MovieId: 1, Title: "Always", Type: movieDomain.MediaType, Audience: userDomain.AudienceAll, Weight: 1})
+		must(t, err)
+		korean, err := r.InsertBanner(ctx, userDomain.Banner{MovieId: 2, Title: "Korean", Type: movieDomain.MediaType, Priority: 5, Locale: "ko", Audience: userDomain.AudienceAll, Weight: 1})
+		must(t, err)
+		ended, err := r.InsertBanner(ctx, userDomain.Banner{MovieId: 3, Title: "Ended", Type: movieDomain.MediaType, EndAt: "2000-01-01 00:00:00", Audience: userDomain.AudienceAll, Weight: 1})
+		must(t, err)
+		upcoming, err := r.InsertBanner(ctx, userDomain.Banner{MovieId: 4, Title: "Upcoming", Type: movieDomain.MediaType, StartAt: "2999-01-01 00:00:00", Audience: userDomain.AudienceAll, Weight: 1})
+		must(t, err)
+
+		b, err := r.ReadBannerById(ctx, upcoming)
+		must(t, err)
+		wantEqual(t, "start", b.StartAt, "2999-01-01 00:00:00")
+		wantEqual(t, "end", b.EndAt, "")
+
+		quoted := userDomain.Banner{MovieId: 7, Title: "Schindler's List", Type: movieDomain.MediaType, Comment: "It's back'); --",
+			Audience: userDomain.AudienceAll, TargetRank: "o'", Locale: "'", Slot: "it's", Weight: 1}
+		quoted.Id, err = r.InsertBanner(ctx, quoted)
+		must(t, err)
+		b, err = r.ReadBannerById(ctx, quoted.Id)
+		must(t, err)
+		wantEqual(t, "banner with apostrophes", b, quoted)
+		quoted.Title = "Schindler's List, again"
+		must(t, r.UpdateBanner(ctx, quoted))
+		b, err = r.ReadBannerById(ctx, quoted.Id)
+		must(t, err)
+		wantEqual(t, "updated banner with apostrophes", b, quoted)
+		must(t, r.DeleteBanner(ctx, quoted.Id))
+
+		banners, err := r.AllBanner(ctx)
+		must(t, err)
+		wantInts(t, "all banners", bannerIds(banners), []int{korean, always, ended, upcoming})
+
+		banners, err = r.FindActiveBanners(ctx, "ko")
+		must(t, err)
+		wantInts(t, "active in ko", bannerIds(banners), []int{korean, always})
+		banners, err = r.FindActiveBanners(ctx, "en")
+		must(t, err)
+		wantInts(t, "active in en", bannerIds(banners), []int{always})
+
+		updated := userDomain.Banner{Id: always, MovieId: 1, Title: "Still always", Type: movieDomain.MediaType, Comment: "Back again",
+			Priority: 10, Audience: userDomain.AudienceRank, TargetRank: "관리자", Slot: "home", Weight: 3}
+		must(t, r.UpdateBanner(ctx, updated))
+		b, err = r.ReadBannerById(ctx, always)
+		must(t, err)
+		wantEqual(t, "updated banner", b, updated)
+
+		inserted := userDomain.Banner{Id: upcoming + 100, MovieId: 5, Title: "Upserted", Type: tvType, Audience: userDomain.AudienceAll, Weight: 1}
+		must(t, r.UpdateBanner(ctx, inserted))
+		b, err = r.ReadBannerById(ctx, inserted.Id)
+		must(t, err)
+		wantEqual(t, "upserted banner", b, inserted)
+		next, err := r.InsertBanner(ctx, userDomain.Banner{MovieId: 6, Title: "Next", Type: movieDomain.MediaType, Audience: userDomain.AudienceAll, Weight: 1})
+		must(t, err)
+		if next <= inserted.Id {
+			t.Errorf("banner inserted after %d got id %d", inserted.Id, next)
+		}
+
+		must(t, r.DeleteBanner(ctx, ended))
+		_, err = r.ReadBannerById(ctx, ended)
+		wantNoRows(t, "deleted banner", err)
+	})
+
+	t.Run("BannerExperiments", func(t *testing.T) {
+		r, _ := newRepos(t)
+
+		control, err := r.InsertBanner(ctx, userDomain.Banner{MovieId: 1, Title: "Control", Type: movieDomain.MediaType, Audience: userDomain.AudienceAll, Slot: "home", Weight: 1})
+		must(t, err)
+		variant, err := r.InsertBanner(ctx, userDomain.Banner{MovieId: 2, Title: "Variant", Type: movieDomain.MediaType, Audience: userDomain.AudienceAll, Slot: "home", Weight: 2})
+		must(t, err)
+		_, err = r.InsertBanner(ctx, userDomain.Banner{MovieId: 3, Title: "Elsewhere", Type: movieDomain.MediaType, Audience: userDomain.AudienceAll, Slot: "side", Weight: 1})
+		must(t, err)
+
+		_, err = r.FindBannerAssignment(ctx, "home", "user:1")
+		wantNoRows(t, "missing assignment", err)
+		must(t, r.UpsertBannerAssignment(ctx, "home", "user:1", control))
+		must(t, r.UpsertBannerAssignment(ctx, "home", "user:1", variant))
+		assigned, err := r.FindBannerAssignment(ctx, "home", "user:1")
+		must(t, err)
+		wantEqual(t, "assignment", assigned, variant)
+
+		must(t, r.InsertBannerEvent(ctx, control, "user:1", userDomain.BannerEventImpression))
+		must(t, r.InsertBannerEvent(ctx, control, "user:2", userDomain.BannerEventImpression))
+		must(t, r.InsertBannerEvent(ctx, control, "user:2", userDomain.BannerEventClick))
+		must(t, r.InsertBannerEvent(ctx, variant, "user:1", userDomain.BannerEventImpression))
+
+		stats, err := r.FindBannerSlotStats(ctx, "home")
+		must(t, err)
+		want := []userDomain.BannerVariantStats{
+			{BannerId: control, Title: "Control", Weight: 1, Impressions: 2, Clicks: 1},
+			{BannerId: variant, Title: "Variant", Weight: 2, Impressions: 1},
+		}
+		if fmt.Sprint(stats) != fmt.Sprint(want) {
+			t.Errorf("stats = %v, want %v", stats, want)
+		}
+	})
+
+	t.Run("ConcurrentUse", func(t *testing.T) {
+		r, _ := newRepos(t)
+		a := newUser(t, r, "a@example.com", "alice")
+
+		const writers = 20
+		var wg sync.WaitGroup
+		errs := make(chan error, 2*writers)
+		for i := 1; i <= writers; i++ {
+			wg.Add(1)
+			go func(mediaId int) {
+				defer wg.Done()
+				errs <- r.InsertRating(ctx, a, mediaId, mediaId%10+1, movieDomain.MediaType)
+				_, err := r.FindRatingsByUserId(ctx, a)
+				errs <- err
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			must(t, err)
+		}
+
+		ratings, err := r.FindRatingsByUserId(ctx, a)
+		must(t, err)
+		wantEqual(t, "ratings", len(ratings), writers)
+	})
+}
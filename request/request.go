@@ -0,0 +1,198 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/go-playground/validator/v10/non-standard/validators"
+	"github.com/labstack/echo/v4"
+	"github.com/null-like/movie-backend/domain/apperror"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(fieldName)
+	v.RegisterValidation("notblank", validators.NotBlank)
+	return v
+}
+
+// fieldName names a field the way the client sends it, so validation errors
+// point at "movie_id" rather than "MovieId".
+func fieldName(f reflect.StructField) string {
+	for _, tag := range []string{"param", "query", "json", "form"} {
+		name := strings.Split(f.Tag.Get(tag), ",")[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// Validator lets echo's Context.Validate use the same rules as Bind.
+type Validator struct{}
+
+func (Validator) Validate(i interface{}) error {
+	return Validate(i)
+}
+
+// Validate checks the validate tags of a request struct, reporting every
+// field that fails.
+func Validate(req interface{}) error {
+	err := validate.Struct(req)
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+
+	fields := make([]apperror.FieldError, len(errs))
+	for i, fe := range errs {
+		fields[i] = apperror.FieldError{Field: fe.Field(), Message: reason(fe)}
+	}
+	return apperror.Invalid(fields...)
+}
+
+// Bind fills a request struct from the path params, the query and the body,
+// in that order, then validates it. Unlike echo's own Bind the query is read
+// for every method, since the acting user is still a query param.
+func Bind(c echo.Context, req interface{}) error {
+	binder := &echo.DefaultBinder{}
+
+	path := url.Values{}
+	for i, name := range c.ParamNames() {
+		path.Set(name, c.ParamValues()[i])
+	}
+	if err := binder.BindPathParams(c, req); err != nil {
+		return bindError(err, mismatches(req, path, "param"))
+	}
+	if err := binder.BindQueryParams(c, req); err != nil {
+		return bindError(err, mismatches(req, c.QueryParams(), "query"))
+	}
+	if err := binder.BindBody(c, req); err != nil {
+		return bodyError(err)
+	}
+
+	return Validate(req)
+}
+
+func bindError(err error, fields []apperror.FieldError) error {
+	if len(fields) == 0 {
+		return apperror.Validation(err.Error())
+	}
+	return apperror.Invalid(fields...)
+}
+
+func bodyError(err error) error {
+	if errors.Is(err, echo.ErrUnsupportedMediaType) {
+		return apperror.Unsupported("request body must be JSON or a form")
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return apperror.Invalid(apperror.FieldError{Field: typeErr.Field, Message: "must be a " + typeName(typeErr.Type)})
+	}
+
+	var he *echo.HTTPError
+	if errors.As(err, &he) && he.Internal != nil {
+		err = he.Internal
+	}
+	return apperror.Validation("malformed request body: " + err.Error())
+}
+
+// mismatches finds the fields echo's binder couldn't parse, since its own
+// error doesn't name them.
+func mismatches(req interface{}, values url.Values, tag string) []apperror.FieldError {
+	var fields []apperror.FieldError
+
+	t := reflect.TypeOf(req).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Tag.Get(tag)
+		if name == "" && f.Anonymous && f.Type.Kind() == reflect.Struct {
+			fields = append(fields, mismatches(reflect.New(f.Type).Interface(), values, tag)...)
+			continue
+		}
+		if name == "" || values.Get(name) == "" {
+			continue
+		}
+		if !parses(f.Type, values.Get(name)) {
+			fields = append(fields, apperror.FieldError{Field: name, Message: "must be a " + typeName(f.Type)})
+		}
+	}
+	return fields
+}
+
+func parses(t reflect.Type, v string) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var err error
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(v, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(v, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(v, t.Bits())
+	case reflect.Bool:
+		_, err = strconv.ParseBool(v)
+	}
+	return err == nil
+}
+
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "whole number"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	default:
+		return t.Kind().String()
+	}
+}
+
+func reason(fe validator.FieldError) string {
+	unit := ""
+	if fe.Kind() == reflect.String {
+		unit = " characters"
+	}
+
+	switch fe.Tag() {
+	case "required", "notblank":
+		return "is required"
+	case "email":
+		return "must be an email address"
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "min", "gte":
+		return fmt.Sprintf("must be at least %s%s", fe.Param(), unit)
+	case "max", "lte":
+		return fmt.Sprintf("must be at most %s%s", fe.Param(), unit)
+	case "gt":
+		return fmt.Sprintf("must be more than %s", fe.Param())
+	case "lt":
+		return fmt.Sprintf("must be less than %s", fe.Param())
+	case "len":
+		return fmt.Sprintf("must be exactly %s%s", fe.Param(), unit)
+	case "hexadecimal":
+		return "must be hexadecimal"
+	default:
+		return "is invalid"
+	}
+}
@@ -0,0 +1,131 @@
+package rpc
+
+import (
+	"context"
+	"github.com/null-like/movie-backend/auth"
+	"github.com/null-like/movie-backend/domain/apperror"
+	"github.com/null-like/movie-backend/replica"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"strings"
+	"time"
+)
+
+// NewServer returns a gRPC server whose calls need one of tokens, with the
+// health and reflection services registered. Services are registered on it
+// by their packages, as HTTP handlers are on an echo group.
+func NewServer(logger *logrus.Logger, tokens auth.Tokens) *grpc.Server {
+	i := &interceptor{tokens: tokens, logger: logger}
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(i.unary),
+		grpc.ChainStreamInterceptor(i.stream),
+	)
+
+	// The health server answers SERVING from the start, as nothing can call
+	// it before the server is up.
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	reflection.Register(s)
+	return s
+}
+
+type interceptor struct {
+	tokens auth.Tokens
+	logger *logrus.Logger
+}
+
+func (i *interceptor) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	ctx, err := i.authenticate(replica.WithSession(ctx), info.FullMethod)
+	var res interface{}
+	if err == nil {
+		res, err = handler(ctx, req)
+	}
+
+	err = i.status(info.FullMethod, err)
+	i.logger.WithFields(logrus.Fields{
+		"method":   info.FullMethod,
+		"service":  auth.ServiceFrom(ctx),
+		"code":     status.Code(err).String(),
+		"duration": time.Since(start).String(),
+	}).Debug("grpc call")
+	return res, err
+}
+
+func (i *interceptor) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := i.authenticate(replica.WithSession(ss.Context()), info.FullMethod)
+	if err != nil {
+		return i.status(info.FullMethod, err)
+	}
+	return i.status(info.FullMethod, handler(srv, &serverStream{ServerStream: ss, ctx: ctx}))
+}
+
+// authenticate checks the authorization metadata, which carries the same
+// "Bearer <token>" an HTTP Authorization header would, as Tokens.Middleware
+// does. Unlike HTTP requests, calls without a token are rejected. Health
+// checks and reflection are open, for load balancers and tools like grpcurl.
+func (i *interceptor) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if strings.HasPrefix(method, "/grpc.health.") || strings.HasPrefix(method, "/grpc.reflection.") {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	authorization := ""
+	if values := md.Get("authorization"); len(values) > 0 {
+		authorization = values[0]
+	}
+	return i.tokens.Authenticate(ctx, authorization)
+}
+
+// status turns an error into a gRPC status by its kind. Internal errors don't
+// show their message and are logged instead.
+func (i *interceptor) status(method string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	kind := apperror.KindOf(err)
+	if kind == apperror.KindInternal {
+		i.logger.WithField("method", method).Error(err)
+		return status.Error(codes.Internal, "internal error")
+	}
+	return status.Error(Code(kind), err.Error())
+}
+
+// Code is the gRPC code answered for an error of the given kind.
+func Code(kind apperror.Kind) codes.Code {
+	switch kind {
+	case apperror.KindNotFound:
+		return codes.NotFound
+	case apperror.KindConflict:
+		return codes.AlreadyExists
+	case apperror.KindValidation, apperror.KindUnsupported:
+		return codes.InvalidArgument
+	case apperror.KindUnauthorized:
+		return codes.Unauthenticated
+	case apperror.KindForbidden:
+		return codes.PermissionDenied
+	case apperror.KindTooLarge:
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
+
+// serverStream carries the authenticated context into a stream's handler.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
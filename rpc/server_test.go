@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"github.com/null-like/movie-backend/auth"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestAuthenticateMatchesHTTP sends the same Authorization values over both
+// transports. Only a missing token is treated differently: the HTTP API lets
+// it through anonymously.
+func TestAuthenticateMatchesHTTP(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	tokens := auth.Tokens{"billing": "secret"}
+	i := &interceptor{tokens: tokens, logger: logger}
+
+	for _, authorization := range []string{"Bearer secret", "bearer secret", "Bearer guess", "Bearer ", "Basic secret", "secret"} {
+		t.Run(authorization, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", authorization))
+			ctx, grpcErr := i.authenticate(ctx, "/movie.MovieService/GetMovie")
+			grpcService := auth.ServiceFrom(ctx)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(echo.HeaderAuthorization, authorization)
+			httpService := ""
+			httpErr := tokens.Middleware(func(c echo.Context) error {
+				httpService = auth.ServiceFrom(c.Request().Context())
+				return nil
+			})(echo.New().NewContext(req, httptest.NewRecorder()))
+
+			if (grpcErr == nil) != (httpErr == nil) || grpcService != httpService {
+				t.Errorf("gRPC got %q, %v; HTTP got %q, %v", grpcService, grpcErr, httpService, httpErr)
+			}
+		})
+	}
+
+	_, err := i.authenticate(context.Background(), "/movie.MovieService/GetMovie")
+	if got := status.Code(i.status("/movie.MovieService/GetMovie", err)); got != codes.Unauthenticated {
+		t.Errorf("no token: got code %v, want %v", got, codes.Unauthenticated)
+	}
+}
@@ -0,0 +1,24 @@
+package delivery
+
+import (
+	tvDomain "github.com/null-like/movie-backend/domain/tv"
+	"github.com/null-like/movie-backend/openapi"
+)
+
+// Operations documents the routes of NewTvHandler.
+func Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: "GET", Path: "/tv/tv-info", Tag: "tv", Summary: "Get a series with its seasons", Request: SeriesRequest{}, Response: tvDomain.Series{}},
+		{Method: "GET", Path: "/tv/season-info", Tag: "tv", Summary: "Get a season with its episodes", Request: SeasonRequest{}, Response: tvDomain.Season{}},
+		{Method: "GET", Path: "/tv/episode-info", Tag: "tv", Summary: "Get an episode", Request: EpisodeRequest{}, Response: tvDomain.Episode{}},
+	}
+}
+
+// OperationsV2 documents the routes of NewTvHandlerV2.
+func OperationsV2() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: "GET", Path: "/tv/:tv_id", Tag: "tv", Summary: "Get a series with its seasons", Request: SeriesRequest{}, Response: tvDomain.Series{}},
+		{Method: "GET", Path: "/tv/:tv_id/seasons/:season", Tag: "tv", Summary: "Get a season with its episodes", Request: SeasonRequest{}, Response: tvDomain.Season{}},
+		{Method: "GET", Path: "/tv/:tv_id/seasons/:season/episodes/:episode", Tag: "tv", Summary: "Get an episode", Request: EpisodeRequest{}, Response: tvDomain.Episode{}},
+	}
+}
@@ -0,0 +1,20 @@
+package delivery
+
+// Ids are read from the path under /v2 and from the query under /v1, so the
+// fields carry both tags.
+
+type SeriesRequest struct {
+	SeriesId int `param:"tv_id" query:"tv_id" validate:"required,gt=0"`
+}
+
+// SeasonRequest takes a pointer so season 0, the specials, is told apart from
+// a missing season.
+type SeasonRequest struct {
+	SeriesRequest
+	Season *int `param:"season" query:"season" validate:"required,gte=0"`
+}
+
+type EpisodeRequest struct {
+	SeasonRequest
+	Episode int `param:"episode" query:"episode" validate:"required,gt=0"`
+}
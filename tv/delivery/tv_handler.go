@@ -0,0 +1,66 @@
+package delivery
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/null-like/movie-backend/request"
+	"github.com/null-like/movie-backend/tv"
+	"net/http"
+)
+
+type tvHandler struct {
+	Usecase tv.Usecase
+}
+
+func NewTvHandler(g *echo.Group, u tv.Usecase) {
+	handler := &tvHandler{
+		Usecase: u,
+	}
+	g.GET("/tv/tv-info", handler.GetSeriesInfo)
+	g.GET("/tv/season-info", handler.GetSeasonInfo)
+	g.GET("/tv/episode-info", handler.GetEpisodeInfo)
+}
+
+func (h *tvHandler) GetSeriesInfo(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req SeriesRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	series, err := h.Usecase.GetSeriesInfo(ctx, req.SeriesId)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, series)
+}
+
+func (h *tvHandler) GetSeasonInfo(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req SeasonRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	season, err := h.Usecase.GetSeasonInfo(ctx, req.SeriesId, *req.Season)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, season)
+}
+
+func (h *tvHandler) GetEpisodeInfo(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req EpisodeRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	episode, err := h.Usecase.GetEpisodeInfo(ctx, req.SeriesId, *req.Season, req.Episode)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, episode)
+}
@@ -0,0 +1,17 @@
+package delivery
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/null-like/movie-backend/tv"
+)
+
+// NewTvHandlerV2 registers the /v2 TV routes, with the series, season and
+// episode in the path instead of the query.
+func NewTvHandlerV2(g *echo.Group, u tv.Usecase) {
+	handler := &tvHandler{
+		Usecase: u,
+	}
+	g.GET("/tv/:tv_id", handler.GetSeriesInfo)
+	g.GET("/tv/:tv_id/seasons/:season", handler.GetSeasonInfo)
+	g.GET("/tv/:tv_id/seasons/:season/episodes/:episode", handler.GetEpisodeInfo)
+}
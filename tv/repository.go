@@ -0,0 +1,15 @@
+package tv
+
+import (
+	"context"
+	tvDomain "github.com/null-like/movie-backend/domain/tv"
+)
+
+type Repository interface {
+	ReadSeriesById(ctx context.Context, seriesId int) (tvDomain.Series, error)
+	ExistSeriesById(ctx context.Context, seriesId int) (bool, error)
+	ReadSeasonsBySeriesId(ctx context.Context, seriesId int) ([]tvDomain.Season, error)
+	ReadSeason(ctx context.Context, seriesId int, seasonNumber int) (tvDomain.Season, error)
+	ReadEpisodesBySeason(ctx context.Context, seriesId int, seasonNumber int) ([]tvDomain.Episode, error)
+	ReadEpisode(ctx context.Context, seriesId int, seasonNumber int, episodeNumber int) (tvDomain.Episode, error)
+}
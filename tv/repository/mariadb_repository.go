@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	tvDomain "github.com/null-like/movie-backend/domain/tv"
+	"github.com/null-like/movie-backend/tv"
+	"github.com/sirupsen/logrus"
+)
+
+type mariaDBTvRepository struct {
+	logger    *logrus.Logger
+	db        *sql.DB
+	schemaMap map[string]string
+}
+
+func NewMariaDBTvRepository(l *logrus.Logger, db *sql.DB, sm map[string]string) tv.Repository {
+	return &mariaDBTvRepository{
+		logger:    l,
+		db:        db,
+		schemaMap: sm,
+	}
+}
+
+func (r *mariaDBTvRepository) ReadSeriesById(ctx context.Context, seriesId int) (tvDomain.Series, error) {
+	query := fmt.Sprintf(`
+		SELECT id, adult, title, language, overview, poster, first_air_date, last_air_date, status,
+			number_of_seasons, number_of_episodes, rating, votes
+		FROM %s.Series
+		WHERE id = %d;
+		`,
+		r.schemaMap["movie"],
+		seriesId,
+	)
+	r.logger.Debug(query)
+	row := r.db.QueryRowContext(ctx, query)
+
+	var series tvDomain.Series
+	err := row.Scan(&series.Id, &series.Adult, &series.Title, &series.Language, &series.Overview, &series.Poster,
+		&series.FirstAirDate, &series.LastAirDate, &series.Status, &series.NumberOfSeasons, &series.NumberOfEpisodes,
+		&series.Rating, &series.Votes)
+	if err != nil {
+		r.logger.Error(err)
+		return series, err
+	}
+
+	return series, nil
+}
+
+func (r *mariaDBTvRepository) ExistSeriesById(ctx context.Context, seriesId int) (bool, error) {
+	query := fmt.Sprintf(`
+		SELECT EXISTS(SELECT 1 FROM %s.Series WHERE id = %d);
+		`,
+		r.schemaMap["movie"],
+		seriesId,
+	)
+	r.logger.Debug(query)
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query).Scan(&exists)
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *mariaDBTvRepository) ReadSeasonsBySeriesId(ctx context.Context, seriesId int) ([]tvDomain.Season, error) {
+	query := fmt.Sprintf(`
+		SELECT id, series_id, season_number, title, overview, poster, air_date, episode_count
+		FROM %s.Season
+		WHERE series_id = %d
+		ORDER BY season_number;
+		`,
+		r.schemaMap["movie"],
+		seriesId,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var seasons []tvDomain.Season
+	for rows.Next() {
+		var season tvDomain.Season
+		err = rows.Scan(&season.Id, &season.SeriesId, &season.SeasonNumber, &season.Title, &season.Overview,
+			&season.Poster, &season.AirDate, &season.EpisodeCount)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		seasons = append(seasons, season)
+	}
+
+	r.logger.Debug(query)
+	return seasons, nil
+}
+
+func (r *mariaDBTvRepository) ReadSeason(ctx context.Context, seriesId int, seasonNumber int) (tvDomain.Season, error) {
+	query := fmt.Sprintf(`
+		SELECT id, series_id, season_number, title, overview, poster, air_date, episode_count
+		FROM %s.Season
+		WHERE series_id = %d and season_number = %d;
+		`,
+		r.schemaMap["movie"],
+		seriesId,
+		seasonNumber,
+	)
+	r.logger.Debug(query)
+	row := r.db.QueryRowContext(ctx, query)
+
+	var season tvDomain.Season
+	err := row.Scan(&season.Id, &season.SeriesId, &season.SeasonNumber, &season.Title, &season.Overview,
+		&season.Poster, &season.AirDate, &season.EpisodeCount)
+	if err != nil {
+		r.logger.Error(err)
+		return season, err
+	}
+
+	return season, nil
+}
+
+func (r *mariaDBTvRepository) ReadEpisodesBySeason(ctx context.Context, seriesId int, seasonNumber int) ([]tvDomain.Episode, error) {
+	query := fmt.Sprintf(`
+		SELECT id, series_id, season_number, episode_number, title, overview, still, air_date, runtime, rating, votes
+		FROM %s.Episode
+		WHERE series_id = %d and season_number = %d
+		ORDER BY episode_number;
+		`,
+		r.schemaMap["movie"],
+		seriesId,
+		seasonNumber,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var episodes []tvDomain.Episode
+	for rows.Next() {
+		var episode tvDomain.Episode
+		err = rows.Scan(&episode.Id, &episode.SeriesId, &episode.SeasonNumber, &episode.EpisodeNumber, &episode.Title,
+			&episode.Overview, &episode.Still, &episode.AirDate, &episode.Runtime, &episode.Rating, &episode.Votes)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		episodes = append(episodes, episode)
+	}
+
+	r.logger.Debug(query)
+	return episodes, nil
+}
+
+func (r *mariaDBTvRepository) ReadEpisode(ctx context.Context, seriesId int, seasonNumber int, episodeNumber int) (tvDomain.Episode, error) {
+	query := fmt.Sprintf(`
+		SELECT id, series_id, season_number, episode_number, title, overview, still, air_date, runtime, rating, votes
+		FROM %s.Episode
+		WHERE series_id = %d and season_number = %d and episode_number = %d;
+		`,
+		r.schemaMap["movie"],
+		seriesId,
+		seasonNumber,
+		episodeNumber,
+	)
+	r.logger.Debug(query)
+	row := r.db.QueryRowContext(ctx, query)
+
+	var episode tvDomain.Episode
+	err := row.Scan(&episode.Id, &episode.SeriesId, &episode.SeasonNumber, &episode.EpisodeNumber, &episode.Title,
+		&episode.Overview, &episode.Still, &episode.AirDate, &episode.Runtime, &episode.Rating, &episode.Votes)
+	if err != nil {
+		r.logger.Error(err)
+		return episode, err
+	}
+
+	return episode, nil
+}
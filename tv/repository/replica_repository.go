@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	tvDomain "github.com/null-like/movie-backend/domain/tv"
+	"github.com/null-like/movie-backend/replica"
+	"github.com/null-like/movie-backend/tv"
+)
+
+type replicaTvRepository struct {
+	router   *replica.Router
+	primary  tv.Repository
+	replicas []tv.Repository
+}
+
+// NewReplicaTvRepository reads from the repository of the replica the router
+// picks. The tv repository only reads, so primary is where reads fall back
+// to. replicas[i] reads from the router's i-th replica.
+func NewReplicaTvRepository(router *replica.Router, primary tv.Repository, replicas []tv.Repository) tv.Repository {
+	return &replicaTvRepository{
+		router:   router,
+		primary:  primary,
+		replicas: replicas,
+	}
+}
+
+func (r *replicaTvRepository) on(i int) tv.Repository {
+	if i == replica.Primary {
+		return r.primary
+	}
+	return r.replicas[i]
+}
+
+func (r *replicaTvRepository) ReadSeriesById(ctx context.Context, seriesId int) (tvDomain.Series, error) {
+	return replica.Read(ctx, r.router, func(i int) (tvDomain.Series, error) {
+		return r.on(i).ReadSeriesById(ctx, seriesId)
+	})
+}
+
+func (r *replicaTvRepository) ExistSeriesById(ctx context.Context, seriesId int) (bool, error) {
+	return replica.Read(ctx, r.router, func(i int) (bool, error) {
+		return r.on(i).ExistSeriesById(ctx, seriesId)
+	})
+}
+
+func (r *replicaTvRepository) ReadSeasonsBySeriesId(ctx context.Context, seriesId int) ([]tvDomain.Season, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]tvDomain.Season, error) {
+		return r.on(i).ReadSeasonsBySeriesId(ctx, seriesId)
+	})
+}
+
+func (r *replicaTvRepository) ReadSeason(ctx context.Context, seriesId int, seasonNumber int) (tvDomain.Season, error) {
+	return replica.Read(ctx, r.router, func(i int) (tvDomain.Season, error) {
+		return r.on(i).ReadSeason(ctx, seriesId, seasonNumber)
+	})
+}
+
+func (r *replicaTvRepository) ReadEpisodesBySeason(ctx context.Context, seriesId int, seasonNumber int) ([]tvDomain.Episode, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]tvDomain.Episode, error) {
+		return r.on(i).ReadEpisodesBySeason(ctx, seriesId, seasonNumber)
+	})
+}
+
+func (r *replicaTvRepository) ReadEpisode(ctx context.Context, seriesId int, seasonNumber int, episodeNumber int) (tvDomain.Episode, error) {
+	return replica.Read(ctx, r.router, func(i int) (tvDomain.Episode, error) {
+		return r.on(i).ReadEpisode(ctx, seriesId, seasonNumber, episodeNumber)
+	})
+}
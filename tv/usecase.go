@@ -0,0 +1,12 @@
+package tv
+
+import (
+	"context"
+	tvDomain "github.com/null-like/movie-backend/domain/tv"
+)
+
+type Usecase interface {
+	GetSeriesInfo(ctx context.Context, seriesId int) (tvDomain.Series, error)
+	GetSeasonInfo(ctx context.Context, seriesId int, seasonNumber int) (tvDomain.Season, error)
+	GetEpisodeInfo(ctx context.Context, seriesId int, seasonNumber int, episodeNumber int) (tvDomain.Episode, error)
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	tvDomain "github.com/null-like/movie-backend/domain/tv"
+	"github.com/null-like/movie-backend/tv"
+	"github.com/sirupsen/logrus"
+)
+
+type tvUsecase struct {
+	logger *logrus.Logger
+	tvRepo tv.Repository
+}
+
+func NewTvUsecase(l *logrus.Logger, r tv.Repository) tv.Usecase {
+	return &tvUsecase{
+		logger: l,
+		tvRepo: r,
+	}
+}
+
+func (u *tvUsecase) GetSeriesInfo(ctx context.Context, seriesId int) (tvDomain.Series, error) {
+	series, err := u.tvRepo.ReadSeriesById(ctx, seriesId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return series, tvDomain.ErrSeriesNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return series, err
+	}
+
+	seasons, err := u.tvRepo.ReadSeasonsBySeriesId(ctx, seriesId)
+	if err != nil {
+		u.logger.Error(err)
+		return series, err
+	}
+	series.Seasons = seasons
+
+	return series, nil
+}
+
+func (u *tvUsecase) GetSeasonInfo(ctx context.Context, seriesId int, seasonNumber int) (tvDomain.Season, error) {
+	season, err := u.tvRepo.ReadSeason(ctx, seriesId, seasonNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return season, tvDomain.ErrSeasonNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return season, err
+	}
+
+	episodes, err := u.tvRepo.ReadEpisodesBySeason(ctx, seriesId, seasonNumber)
+	if err != nil {
+		u.logger.Error(err)
+		return season, err
+	}
+	season.Episodes = episodes
+
+	return season, nil
+}
+
+func (u *tvUsecase) GetEpisodeInfo(ctx context.Context, seriesId int, seasonNumber int, episodeNumber int) (tvDomain.Episode, error) {
+	episode, err := u.tvRepo.ReadEpisode(ctx, seriesId, seasonNumber, episodeNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return episode, tvDomain.ErrEpisodeNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return episode, err
+	}
+	return episode, nil
+}
@@ -0,0 +1,144 @@
+package delivery
+
+import (
+	UserDomain "github.com/null-like/movie-backend/domain/user"
+	"github.com/null-like/movie-backend/openapi"
+	"net/http"
+)
+
+// Operations documents the routes of NewUserHandler.
+func Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: "POST", Path: "/sign-up", Tag: "users", Summary: "Register a user; answers 1", Request: SignUpUser{}, Response: 0},
+		{Method: "POST", Path: "/sign-in", Tag: "users", Summary: "Sign in with email and password", Request: SignInUser{}, Response: UserDomain.UserInfo{}},
+		{Method: "GET", Path: "/check", Tag: "users", Summary: "Check an email; -1 when taken, 1 when free", Request: EmailQuery{}, Response: 0},
+		{Method: "GET", Path: "/all-user", Tag: "users", Summary: "List all users", Response: []UserDomain.AllUserInfo{}},
+		{Method: "GET", Path: "/delete-user", Tag: "users", Summary: "Delete a user and list the rest", Request: IdQuery{}, Response: []UserDomain.AllUserInfo{}},
+		{Method: "GET", Path: "/update-user", Tag: "users", Summary: "Change a user's rank and list all users", Request: RankQuery{}, Response: []UserDomain.AllUserInfo{}},
+		{Method: "GET", Path: "/nickname", Tag: "users", Summary: "Get a user's nickname", Request: IdQuery{}, Response: ""},
+		{Method: "GET", Path: "/profile", Tag: "users", Summary: "Get a user's public profile", Request: UserQuery{}, Response: UserDomain.Profile{}},
+		{Method: "GET", Path: "/change-bio", Tag: "users", Summary: "Change a user's bio", Request: BioQuery{}, Response: UserDomain.Profile{}},
+		{Method: "POST", Path: "/avatar", Tag: "users", Summary: "Upload a user's avatar", Request: UserQuery{}, Upload: "avatar", Response: UserDomain.Profile{}},
+		{Method: "GET", Path: "/avatar/:size/:user_id/:name", Tag: "users", Summary: "Get an avatar thumbnail", Request: AvatarPath{}, ContentType: "image/*"},
+
+		{Method: "GET", Path: "/favorite", Tag: "favorites", Summary: "List a user's favorites", Request: IdQuery{}, Response: []UserDomain.Favorite{}},
+		{Method: "GET", Path: "/is-favorite", Tag: "favorites", Summary: "Check whether a title is a favorite", Request: MediaQuery{}, Response: false},
+		{Method: "GET", Path: "/toggle-fav", Tag: "favorites", Summary: "Like or unlike a title and list the favorites", Request: LikeQuery{}, Response: []UserDomain.Favorite{}},
+
+		{Method: "GET", Path: "/rating", Tag: "ratings", Summary: "Get a user's rating of a title; 0 when unrated", Request: MediaQuery{}, Response: 0},
+		{Method: "GET", Path: "/rating-list", Tag: "ratings", Summary: "List a user's ratings", Request: UserQuery{}, Response: []UserDomain.Rate{}},
+		{Method: "GET", Path: "/rating-list-changed", Tag: "ratings", Summary: "Rate a title and list the ratings", Request: RatingQuery{}, Response: []UserDomain.Rate{}},
+
+		{Method: "GET", Path: "/watchlist", Tag: "watchlist", Summary: "List a user's watchlist", Request: WatchlistQuery{}, Response: []UserDomain.WatchEntry{}},
+		{Method: "GET", Path: "/want-to-watch", Tag: "watchlist", Summary: "Put a title on the watchlist", Request: MediaQuery{}, Response: UserDomain.WatchEntry{}},
+		{Method: "GET", Path: "/mark-watched", Tag: "watchlist", Summary: "Record a viewing", Request: MarkWatchedQuery{}, Response: UserDomain.WatchEntry{}},
+		{Method: "GET", Path: "/delete-watch", Tag: "watchlist", Summary: "Take a title off the watchlist and list the rest", Request: DeleteWatchQuery{}, Response: []UserDomain.WatchEntry{}},
+		{Method: "GET", Path: "/watch-history", Tag: "watchlist", Summary: "List a user's viewings", Request: HistoryQuery{}, Response: []UserDomain.WatchEvent{}},
+
+		{Method: "GET", Path: "/playlist", Tag: "playlists", Summary: "List a user's playlists, or the public ones without user_id", Request: ActorQuery{}, Response: []UserDomain.Playlist{}},
+		{Method: "GET", Path: "/public-playlist", Tag: "playlists", Summary: "List public playlists", Response: []UserDomain.Playlist{}},
+		{Method: "GET", Path: "/user-playlist", Tag: "playlists", Summary: "List another user's playlists", Request: UserPlaylistsQuery{}, Response: []UserDomain.Playlist{}},
+		{Method: "GET", Path: "/playlist-detail", Tag: "playlists", Summary: "Get a playlist", Request: PlaylistQuery{}, Response: UserDomain.Playlist{}},
+		{Method: "GET", Path: "/add-playlist", Tag: "playlists", Summary: "Create a playlist and list the user's playlists", Request: AddPlaylistQuery{}, Response: []UserDomain.Playlist{}},
+		{Method: "GET", Path: "/change-playlist", Tag: "playlists", Summary: "Rename a playlist or change its visibility", Request: ChangePlaylistQuery{}, Response: []UserDomain.Playlist{}},
+		{Method: "GET", Path: "/delete-playlist", Tag: "playlists", Summary: "Delete a playlist and list the rest", Request: PlaylistQuery{}, Response: []UserDomain.Playlist{}},
+		{Method: "GET", Path: "/playlist-item", Tag: "playlists", Summary: "List a playlist's items", Request: PlaylistItemsQuery{}, Response: []UserDomain.PlaylistItem{}},
+		{Method: "GET", Path: "/add-playlist-item", Tag: "playlists", Summary: "Add a title to a playlist", Request: AddPlaylistItemQuery{}, Response: UserDomain.Playlist{}},
+		{Method: "GET", Path: "/delete-playlist-item", Tag: "playlists", Summary: "Remove an item from a playlist", Request: DeletePlaylistItemQuery{}, Response: UserDomain.Playlist{}},
+		{Method: "GET", Path: "/move-playlist-item", Tag: "playlists", Summary: "Move an item within a playlist", Request: MovePlaylistItemQuery{}, Response: UserDomain.Playlist{}},
+		{Method: "GET", Path: "/playlist-change", Tag: "playlists", Summary: "List a playlist's change log", Request: PlaylistItemsQuery{}, Response: []UserDomain.PlaylistChange{}},
+		{Method: "GET", Path: "/share-playlist", Tag: "playlists", Summary: "Create a share link for a playlist", Request: PlaylistQuery{}, Response: UserDomain.Playlist{}},
+		{Method: "GET", Path: "/unshare-playlist", Tag: "playlists", Summary: "Revoke a playlist's share link", Request: PlaylistQuery{}, Response: UserDomain.Playlist{}},
+		{Method: "GET", Path: "/shared/:slug", Tag: "playlists", Summary: "Get a shared playlist", Request: SlugPath{}, Response: UserDomain.SharedPlaylist{}},
+		{Method: "GET", Path: "/fork-playlist", Tag: "playlists", Summary: "Copy a shared playlist", Request: ForkQuery{}, Response: UserDomain.Playlist{}},
+		{Method: "GET", Path: "/collab-playlist", Tag: "playlists", Summary: "List the playlists a user collaborates on", Request: UserQuery{}, Response: []UserDomain.Playlist{}},
+		{Method: "GET", Path: "/playlist-member", Tag: "playlists", Summary: "List a playlist's members", Request: PlaylistItemsQuery{}, Response: []UserDomain.PlaylistMember{}},
+		{Method: "GET", Path: "/add-playlist-member", Tag: "playlists", Summary: "Add a member to a playlist", Request: AddPlaylistMemberQuery{}, Response: []UserDomain.PlaylistMember{}},
+		{Method: "GET", Path: "/delete-playlist-member", Tag: "playlists", Summary: "Remove a member from a playlist", Request: PlaylistMemberQuery{}, Response: []UserDomain.PlaylistMember{}},
+
+		{Method: "GET", Path: "/follow", Tag: "social", Summary: "Follow a user and list the followed users", Request: FollowQuery{}, Response: []UserDomain.FollowUser{}},
+		{Method: "GET", Path: "/unfollow", Tag: "social", Summary: "Unfollow a user and list the followed users", Request: FollowQuery{}, Response: []UserDomain.FollowUser{}},
+		{Method: "GET", Path: "/following", Tag: "social", Summary: "List the users a user follows", Request: UserQuery{}, Response: []UserDomain.FollowUser{}},
+		{Method: "GET", Path: "/followers", Tag: "social", Summary: "List a user's followers", Request: UserQuery{}, Response: []UserDomain.FollowUser{}},
+		{Method: "GET", Path: "/feed", Tag: "social", Summary: "Get the activity of followed users", Request: FeedQuery{}, Response: UserDomain.Feed{}},
+		{Method: "GET", Path: "/user-activity", Tag: "social", Summary: "Get a user's activity", Request: ActivityQuery{}, Response: UserDomain.Feed{}},
+		{Method: "GET", Path: "/activity-privacy", Tag: "social", Summary: "Change who sees a user's activity", Request: ActivityPrivacyQuery{}, Response: map[string]string{}},
+
+		{Method: "GET", Path: "/banner", Tag: "banners", Summary: "List all banners", Response: []UserDomain.Banner{}},
+		{Method: "GET", Path: "/active-banner", Tag: "banners", Summary: "List the banners shown to a viewer", Request: ActiveBannersQuery{}, Response: []UserDomain.Banner{}},
+		{Method: "GET", Path: "/banner-impression", Tag: "banners", Summary: "Record a banner impression", Request: BannerEventQuery{}, Status: http.StatusNoContent},
+		{Method: "GET", Path: "/banner-click", Tag: "banners", Summary: "Record a banner click", Request: BannerEventQuery{}, Status: http.StatusNoContent},
+		{Method: "GET", Path: "/banner-report", Tag: "banners", Summary: "Compare the banner variants of a slot", Request: SlotQuery{}, Response: UserDomain.BannerReport{}},
+		{Method: "GET", Path: "/change-banner", Tag: "banners", Summary: "Change a banner and list all banners", Request: BannerQuery{}, Response: []UserDomain.Banner{}},
+		{Method: "GET", Path: "/add-banner", Tag: "banners", Summary: "Add a banner and list all banners", Request: BannerQuery{}, Response: []UserDomain.Banner{}},
+		{Method: "GET", Path: "/delete-banner", Tag: "banners", Summary: "Delete a banner and list the rest", Request: IdQuery{}, Response: []UserDomain.Banner{}},
+	}
+}
+
+// OperationsV2 documents the routes of NewUserHandlerV2.
+func OperationsV2() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: "POST", Path: "/users", Tag: "users", Summary: "Register a user", Request: SignUpUser{}, Status: http.StatusCreated},
+		{Method: "GET", Path: "/users", Tag: "users", Summary: "List all users", Response: []UserDomain.AllUserInfo{}},
+		{Method: "GET", Path: "/users/check", Tag: "users", Summary: "Check whether an email is available", Request: EmailQuery{}, Response: map[string]bool{}},
+		{Method: "POST", Path: "/sessions", Tag: "users", Summary: "Sign in with email and password", Request: SignInUser{}, Response: UserDomain.UserInfo{}, Status: http.StatusCreated},
+		{Method: "GET", Path: "/users/:user_id", Tag: "users", Summary: "Get a user's public profile", Request: UserPath{}, Response: UserDomain.Profile{}},
+		{Method: "DELETE", Path: "/users/:user_id", Tag: "users", Summary: "Delete a user", Request: UserPath{}, Status: http.StatusNoContent},
+		{Method: "PUT", Path: "/users/:user_id/rank", Tag: "users", Summary: "Change a user's rank", Request: RankRequest{}, Response: UserDomain.AllUserInfo{}},
+		{Method: "PUT", Path: "/users/:user_id/bio", Tag: "users", Summary: "Change a user's bio", Request: BioRequest{}, Response: UserDomain.Profile{}},
+		{Method: "PUT", Path: "/users/:user_id/avatar", Tag: "users", Summary: "Upload a user's avatar", Request: UserPath{}, Upload: "avatar", Response: UserDomain.Profile{}},
+		{Method: "GET", Path: "/avatars/:size/:user_id/:name", Tag: "users", Summary: "Get an avatar thumbnail", Request: AvatarPath{}, ContentType: "image/*"},
+
+		{Method: "GET", Path: "/users/:user_id/favorites", Tag: "favorites", Summary: "List a user's favorites", Request: UserPath{}, Response: []UserDomain.Favorite{}},
+		{Method: "GET", Path: "/users/:user_id/favorites/:media_type/:media_id", Tag: "favorites", Summary: "Check whether a title is a favorite; 404 when not", Request: MediaPath{}, Status: http.StatusNoContent},
+		{Method: "POST", Path: "/users/:user_id/favorites/:media_type/:media_id", Tag: "favorites", Summary: "Add a favorite", Request: MediaPath{}, Status: http.StatusCreated},
+		{Method: "DELETE", Path: "/users/:user_id/favorites/:media_type/:media_id", Tag: "favorites", Summary: "Remove a favorite", Request: MediaPath{}, Status: http.StatusNoContent},
+
+		{Method: "GET", Path: "/users/:user_id/ratings", Tag: "ratings", Summary: "List a user's ratings", Request: UserPath{}, Response: []UserDomain.Rate{}},
+		{Method: "GET", Path: "/users/:user_id/ratings/:media_type/:media_id", Tag: "ratings", Summary: "Get a user's rating of a title", Request: MediaPath{}, Response: UserDomain.Rate{}},
+		{Method: "PUT", Path: "/users/:user_id/ratings/:media_type/:media_id", Tag: "ratings", Summary: "Rate a title", Request: RatingRequest{}, Response: UserDomain.Rate{}},
+
+		{Method: "GET", Path: "/users/:user_id/watchlist", Tag: "watchlist", Summary: "List a user's watchlist", Request: WatchlistRequest{}, Response: []UserDomain.WatchEntry{}},
+		{Method: "PUT", Path: "/users/:user_id/watchlist/:media_type/:media_id", Tag: "watchlist", Summary: "Put a title on the watchlist", Request: MediaPath{}, Response: UserDomain.WatchEntry{}},
+		{Method: "DELETE", Path: "/users/:user_id/watchlist/:media_type/:media_id", Tag: "watchlist", Summary: "Take a title off the watchlist", Request: MediaPath{}, Status: http.StatusNoContent},
+		{Method: "GET", Path: "/users/:user_id/history", Tag: "watchlist", Summary: "List a user's viewings", Request: HistoryRequest{}, Response: []UserDomain.WatchEvent{}},
+		{Method: "POST", Path: "/users/:user_id/history", Tag: "watchlist", Summary: "Record a viewing", Request: WatchRequest{}, Response: UserDomain.WatchEntry{}, Status: http.StatusCreated},
+
+		{Method: "GET", Path: "/users/:user_id/following", Tag: "social", Summary: "List the users a user follows", Request: UserPath{}, Response: []UserDomain.FollowUser{}},
+		{Method: "GET", Path: "/users/:user_id/followers", Tag: "social", Summary: "List a user's followers", Request: UserPath{}, Response: []UserDomain.FollowUser{}},
+		{Method: "PUT", Path: "/users/:user_id/following/:followee_id", Tag: "social", Summary: "Follow a user", Request: FollowRequest{}, Status: http.StatusNoContent},
+		{Method: "DELETE", Path: "/users/:user_id/following/:followee_id", Tag: "social", Summary: "Unfollow a user", Request: FollowRequest{}, Status: http.StatusNoContent},
+		{Method: "GET", Path: "/users/:user_id/feed", Tag: "social", Summary: "Get the activity of followed users", Request: FeedRequest{}, Response: UserDomain.Feed{}},
+		{Method: "GET", Path: "/users/:user_id/activity", Tag: "social", Summary: "Get a user's activity", Request: ActivityRequest{}, Response: UserDomain.Feed{}},
+		{Method: "PUT", Path: "/users/:user_id/activity-visibility", Tag: "social", Summary: "Change who sees a user's activity", Request: VisibilityRequest{}, Response: map[string]string{}},
+
+		{Method: "GET", Path: "/playlists", Tag: "playlists", Summary: "List public playlists", Response: []UserDomain.Playlist{}},
+		{Method: "POST", Path: "/playlists", Tag: "playlists", Summary: "Create a playlist", Request: PlaylistRequest{}, Response: UserDomain.Playlist{}, Status: http.StatusCreated},
+		{Method: "GET", Path: "/users/:user_id/playlists", Tag: "playlists", Summary: "List a user's playlists", Request: UserPlaylistsRequest{}, Response: []UserDomain.Playlist{}},
+		{Method: "GET", Path: "/users/:user_id/collaborating-playlists", Tag: "playlists", Summary: "List the playlists a user collaborates on", Request: UserPath{}, Response: []UserDomain.Playlist{}},
+		{Method: "GET", Path: "/playlists/:playlist_id", Tag: "playlists", Summary: "Get a playlist", Request: PlaylistPath{}, Response: UserDomain.Playlist{}},
+		{Method: "PATCH", Path: "/playlists/:playlist_id", Tag: "playlists", Summary: "Rename a playlist or change its visibility", Request: PatchPlaylistRequest{}, Response: UserDomain.Playlist{}},
+		{Method: "DELETE", Path: "/playlists/:playlist_id", Tag: "playlists", Summary: "Delete a playlist", Request: PlaylistPath{}, Status: http.StatusNoContent},
+		{Method: "GET", Path: "/playlists/:playlist_id/items", Tag: "playlists", Summary: "List a playlist's items", Request: PlaylistPath{}, Response: []UserDomain.PlaylistItem{}},
+		{Method: "POST", Path: "/playlists/:playlist_id/items", Tag: "playlists", Summary: "Add a title to a playlist", Request: PlaylistItemRequest{}, Response: UserDomain.Playlist{}, Status: http.StatusCreated},
+		{Method: "DELETE", Path: "/playlists/:playlist_id/items/:item_id", Tag: "playlists", Summary: "Remove an item from a playlist", Request: PlaylistItemPath{}, Response: UserDomain.Playlist{}},
+		{Method: "PUT", Path: "/playlists/:playlist_id/items/:item_id/position", Tag: "playlists", Summary: "Move an item within a playlist", Request: PositionRequest{}, Response: UserDomain.Playlist{}},
+		{Method: "GET", Path: "/playlists/:playlist_id/changes", Tag: "playlists", Summary: "List a playlist's change log", Request: PlaylistPath{}, Response: []UserDomain.PlaylistChange{}},
+		{Method: "GET", Path: "/playlists/:playlist_id/members", Tag: "playlists", Summary: "List a playlist's members", Request: PlaylistPath{}, Response: []UserDomain.PlaylistMember{}},
+		{Method: "PUT", Path: "/playlists/:playlist_id/members/:member_id", Tag: "playlists", Summary: "Add a member to a playlist or change their role", Request: MemberRequest{}, Response: []UserDomain.PlaylistMember{}},
+		{Method: "DELETE", Path: "/playlists/:playlist_id/members/:member_id", Tag: "playlists", Summary: "Remove a member from a playlist", Request: MemberPath{}, Status: http.StatusNoContent},
+		{Method: "PUT", Path: "/playlists/:playlist_id/share", Tag: "playlists", Summary: "Create a share link for a playlist", Request: PlaylistPath{}, Response: UserDomain.Playlist{}},
+		{Method: "DELETE", Path: "/playlists/:playlist_id/share", Tag: "playlists", Summary: "Revoke a playlist's share link", Request: PlaylistPath{}, Response: UserDomain.Playlist{}},
+		{Method: "GET", Path: "/shared/:slug", Tag: "playlists", Summary: "Get a shared playlist", Request: SlugPath{}, Response: UserDomain.SharedPlaylist{}},
+		{Method: "POST", Path: "/shared/:slug/forks", Tag: "playlists", Summary: "Copy a shared playlist", Request: ForkRequest{}, Response: UserDomain.Playlist{}, Status: http.StatusCreated},
+
+		{Method: "GET", Path: "/banners", Tag: "banners", Summary: "List all banners", Response: []UserDomain.Banner{}},
+		{Method: "POST", Path: "/banners", Tag: "banners", Summary: "Add a banner", Request: BannerRequest{}, Response: UserDomain.Banner{}, Status: http.StatusCreated},
+		{Method: "GET", Path: "/banners/active", Tag: "banners", Summary: "List the banners shown to a viewer", Request: ActiveBannersQuery{}, Response: []UserDomain.Banner{}},
+		{Method: "PUT", Path: "/banners/:banner_id", Tag: "banners", Summary: "Change a banner", Request: BannerRequest{}, Response: UserDomain.Banner{}},
+		{Method: "DELETE", Path: "/banners/:banner_id", Tag: "banners", Summary: "Delete a banner", Request: BannerPath{}, Status: http.StatusNoContent},
+		{Method: "POST", Path: "/banners/:banner_id/impressions", Tag: "banners", Summary: "Record a banner impression", Request: BannerEventRequest{}, Status: http.StatusNoContent},
+		{Method: "POST", Path: "/banners/:banner_id/clicks", Tag: "banners", Summary: "Record a banner click", Request: BannerEventRequest{}, Status: http.StatusNoContent},
+		{Method: "GET", Path: "/banner-reports/:slot", Tag: "banners", Summary: "Compare the banner variants of a slot", Request: SlotPath{}, Response: UserDomain.BannerReport{}},
+	}
+}
@@ -0,0 +1,449 @@
+package delivery
+
+import UserDomain "github.com/null-like/movie-backend/domain/user"
+
+// Request structs are filled by request.Bind and checked against their
+// validate tags. A user_id that may be 0 is the optional acting user; 0 means
+// an anonymous viewer.
+
+const defaultPageSize = 20
+
+// Page is the offset and limit of a list request.
+type Page struct {
+	Offset int `query:"offset" validate:"gte=0"`
+	Limit  int `query:"limit" validate:"gte=0,lte=100"`
+}
+
+// page returns the offset and limit, defaulting the limit when unset.
+func (p Page) page() (int, int) {
+	if p.Limit == 0 {
+		return p.Offset, defaultPageSize
+	}
+	return p.Offset, p.Limit
+}
+
+// FeedPage pages a feed by event id: Before is the NextBefore of the previous
+// page, 0 for the newest events.
+type FeedPage struct {
+	Before int `query:"before" validate:"gte=0"`
+	Limit  int `query:"limit" validate:"gte=0,lte=100"`
+}
+
+func (p FeedPage) page() (int, int) {
+	if p.Limit == 0 {
+		return p.Before, defaultPageSize
+	}
+	return p.Before, p.Limit
+}
+
+type SignUpUser struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+	Nickname string `json:"nickname" validate:"required,min=2,max=20"`
+}
+
+type SignInUser struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type EmailQuery struct {
+	Email string `query:"email" validate:"required,email"`
+}
+
+type IdQuery struct {
+	Id int `query:"id" validate:"required,gt=0"`
+}
+
+type RankQuery struct {
+	Id   int    `query:"id" validate:"required,gt=0"`
+	Rank string `query:"rank" validate:"required,max=20"`
+}
+
+type UserQuery struct {
+	UserId int `query:"user_id" validate:"required,gt=0"`
+}
+
+type BioQuery struct {
+	UserId int    `query:"user_id" validate:"required,gt=0"`
+	Bio    string `query:"bio" validate:"max=500"`
+}
+
+type AvatarPath struct {
+	Size   int    `param:"size" validate:"required,gt=0"`
+	UserId int    `param:"user_id" validate:"required,gt=0"`
+	Name   string `param:"name" validate:"required"`
+}
+
+// MediaQuery names a title a user acts on. /v1 calls every media id movie_id,
+// TV series included.
+type MediaQuery struct {
+	UserId  int    `query:"user_id" validate:"required,gt=0"`
+	MediaId int    `query:"movie_id" validate:"required,gt=0"`
+	Type    string `query:"type" validate:"required,oneof=movie tv"`
+}
+
+type LikeQuery struct {
+	MediaQuery
+	IsLiked int `query:"is_liked" validate:"oneof=0 1"`
+}
+
+type RatingQuery struct {
+	MediaQuery
+	Rating      int  `query:"rating" validate:"required,min=1,max=10"`
+	MarkWatched bool `query:"mark_watched"`
+}
+
+type WatchlistQuery struct {
+	UserQuery
+	Page
+	State string `query:"state" validate:"omitempty,oneof=want watched"`
+}
+
+type MarkWatchedQuery struct {
+	MediaQuery
+	WatchedAt string `query:"watched_at"`
+}
+
+type DeleteWatchQuery struct {
+	MediaQuery
+	Page
+}
+
+type HistoryQuery struct {
+	UserQuery
+	Page
+}
+
+type ActorQuery struct {
+	UserId int `query:"user_id" validate:"gte=0"`
+}
+
+type UserPlaylistsQuery struct {
+	UserId  int `query:"user_id" validate:"gte=0"`
+	OwnerId int `query:"owner_id" validate:"required,gt=0"`
+}
+
+type PlaylistQuery struct {
+	UserId int `query:"user_id" validate:"gte=0"`
+	Id     int `query:"id" validate:"required,gt=0"`
+}
+
+type AddPlaylistQuery struct {
+	UserQuery
+	Name       string `query:"name" validate:"required,max=255"`
+	Type       string `query:"type" validate:"omitempty,oneof=movie tv"`
+	Visibility string `query:"visibility" validate:"omitempty,oneof=private unlisted public"`
+}
+
+type ChangePlaylistQuery struct {
+	UserQuery
+	Id         int    `query:"id" validate:"required,gt=0"`
+	Name       string `query:"name" validate:"max=255"`
+	Visibility string `query:"visibility" validate:"omitempty,oneof=private unlisted public"`
+}
+
+type PlaylistItemsQuery struct {
+	UserId     int `query:"user_id" validate:"gte=0"`
+	PlaylistId int `query:"playlist_id" validate:"required,gt=0"`
+}
+
+// Version is the playlist version the edit was made against; 0 skips the
+// check where it's optional.
+type AddPlaylistItemQuery struct {
+	PlaylistItemsQuery
+	MediaId int    `query:"media_id" validate:"required,gt=0"`
+	Type    string `query:"type" validate:"required,oneof=movie tv"`
+	Note    string `query:"note" validate:"max=512"`
+	Version int    `query:"version" validate:"gte=0"`
+}
+
+type DeletePlaylistItemQuery struct {
+	PlaylistItemsQuery
+	ItemId  int `query:"item_id" validate:"required,gt=0"`
+	Version int `query:"version" validate:"gte=0"`
+}
+
+type MovePlaylistItemQuery struct {
+	PlaylistItemsQuery
+	ItemId   int `query:"item_id" validate:"required,gt=0"`
+	Position int `query:"position" validate:"required,gt=0"`
+	Version  int `query:"version" validate:"required,gt=0"`
+}
+
+type PlaylistMemberQuery struct {
+	PlaylistItemsQuery
+	MemberId int `query:"member_id" validate:"required,gt=0"`
+}
+
+type AddPlaylistMemberQuery struct {
+	PlaylistMemberQuery
+	Role string `query:"role" validate:"required,oneof=editor viewer"`
+}
+
+type ForkQuery struct {
+	UserQuery
+	Slug string `query:"slug" validate:"required"`
+}
+
+type SlugPath struct {
+	Slug string `param:"slug" validate:"required"`
+}
+
+type FollowQuery struct {
+	UserQuery
+	FolloweeId int `query:"followee_id" validate:"required,gt=0"`
+}
+
+type FeedQuery struct {
+	UserQuery
+	FeedPage
+}
+
+type ActivityQuery struct {
+	UserQuery
+	FeedPage
+	ViewerId int `query:"viewer_id" validate:"gte=0"`
+}
+
+type ActivityPrivacyQuery struct {
+	UserQuery
+	Visibility string `query:"visibility" validate:"required,oneof=public followers private"`
+}
+
+// BannerQuery carries the banner fields of the add and change endpoints.
+// A nil Weight means the default weight of 1.
+type BannerQuery struct {
+	Id       int    `query:"id" validate:"gte=0"`
+	MovieId  int    `query:"movie_id" validate:"required,gt=0"`
+	Title    string `query:"title" validate:"required,max=255"`
+	Type     string `query:"type" validate:"required,oneof=movie tv"`
+	Comment  string `query:"comment" validate:"max=255"`
+	StartAt  string `query:"start_at"`
+	EndAt    string `query:"end_at"`
+	Priority int    `query:"priority"`
+	Audience string `query:"audience" validate:"omitempty,oneof=all logged-in rank"`
+	Rank     string `query:"rank" validate:"max=20"`
+	Locale   string `query:"locale" validate:"max=16"`
+	Slot     string `query:"slot" validate:"max=32"`
+	Weight   *int   `query:"weight" validate:"omitempty,gte=0"`
+}
+
+func (q BannerQuery) banner() UserDomain.Banner {
+	weight := 1
+	if q.Weight != nil {
+		weight = *q.Weight
+	}
+	return UserDomain.Banner{
+		Id:         q.Id,
+		MovieId:    q.MovieId,
+		Title:      q.Title,
+		Type:       q.Type,
+		Comment:    q.Comment,
+		StartAt:    q.StartAt,
+		EndAt:      q.EndAt,
+		Priority:   q.Priority,
+		Audience:   q.Audience,
+		TargetRank: q.Rank,
+		Locale:     q.Locale,
+		Slot:       q.Slot,
+		Weight:     weight,
+	}
+}
+
+type ActiveBannersQuery struct {
+	UserId int    `query:"user_id" validate:"gte=0"`
+	Locale string `query:"locale" validate:"max=16"`
+}
+
+type BannerEventQuery struct {
+	BannerId int `query:"banner_id" validate:"required,gt=0"`
+	UserId   int `query:"user_id" validate:"gte=0"`
+}
+
+type SlotQuery struct {
+	Slot string `query:"slot" validate:"required,max=32"`
+}
+
+// The /v2 requests read resource ids from the path and changes from a JSON
+// body. Path and query fields are kept out of the body with json:"-".
+
+type UserPath struct {
+	UserId int `param:"user_id" json:"-" validate:"required,gt=0"`
+}
+
+type MediaPath struct {
+	UserId  int    `param:"user_id" json:"-" validate:"required,gt=0"`
+	Type    string `param:"media_type" json:"-" validate:"required,oneof=movie tv"`
+	MediaId int    `param:"media_id" json:"-" validate:"required,gt=0"`
+}
+
+type RankRequest struct {
+	UserPath
+	Rank string `json:"rank" validate:"required,max=20"`
+}
+
+type BioRequest struct {
+	UserPath
+	Bio string `json:"bio" validate:"max=500"`
+}
+
+type RatingRequest struct {
+	MediaPath
+	Rating      int  `json:"rating" validate:"required,min=1,max=10"`
+	MarkWatched bool `json:"mark_watched"`
+}
+
+type WatchlistRequest struct {
+	UserPath
+	Page
+	State string `query:"state" json:"-" validate:"omitempty,oneof=want watched"`
+}
+
+type HistoryRequest struct {
+	UserPath
+	Page
+}
+
+type WatchRequest struct {
+	UserPath
+	MediaId   int    `json:"media_id" validate:"required,gt=0"`
+	Type      string `json:"type" validate:"required,oneof=movie tv"`
+	WatchedAt string `json:"watched_at"`
+}
+
+type FollowRequest struct {
+	UserPath
+	FolloweeId int `param:"followee_id" json:"-" validate:"required,gt=0"`
+}
+
+type FeedRequest struct {
+	UserPath
+	FeedPage
+}
+
+type ActivityRequest struct {
+	UserPath
+	FeedPage
+	ViewerId int `query:"viewer_id" json:"-" validate:"gte=0"`
+}
+
+type VisibilityRequest struct {
+	UserPath
+	Visibility string `json:"visibility" validate:"required,oneof=public followers private"`
+}
+
+// UserPlaylistsRequest lists a user's playlists as ViewerId sees them, or as
+// the owner when ViewerId is unset.
+type UserPlaylistsRequest struct {
+	UserPath
+	ViewerId *int `query:"viewer_id" json:"-" validate:"omitempty,gte=0"`
+}
+
+type PlaylistRequest struct {
+	UserId     int    `query:"user_id" json:"-" validate:"required,gt=0"`
+	Name       string `json:"name" validate:"required,max=255"`
+	Type       string `json:"type" validate:"omitempty,oneof=movie tv"`
+	Visibility string `json:"visibility" validate:"omitempty,oneof=private unlisted public"`
+}
+
+type PlaylistPath struct {
+	UserId     int `query:"user_id" json:"-" validate:"gte=0"`
+	PlaylistId int `param:"playlist_id" json:"-" validate:"required,gt=0"`
+}
+
+// PatchPlaylistRequest renames a playlist or changes its visibility; an empty
+// name or visibility keeps the current one.
+type PatchPlaylistRequest struct {
+	PlaylistPath
+	Name       string `json:"name" validate:"max=255"`
+	Visibility string `json:"visibility" validate:"omitempty,oneof=private unlisted public"`
+}
+
+type PlaylistItemRequest struct {
+	PlaylistPath
+	MediaId int    `json:"media_id" validate:"required,gt=0"`
+	Type    string `json:"type" validate:"required,oneof=movie tv"`
+	Note    string `json:"note" validate:"max=512"`
+	Version int    `json:"version" validate:"gte=0"`
+}
+
+type PlaylistItemPath struct {
+	PlaylistPath
+	ItemId  int `param:"item_id" json:"-" validate:"required,gt=0"`
+	Version int `query:"version" json:"-" validate:"gte=0"`
+}
+
+type PositionRequest struct {
+	PlaylistPath
+	ItemId   int `param:"item_id" json:"-" validate:"required,gt=0"`
+	Position int `json:"position" validate:"required,gt=0"`
+	Version  int `json:"version" validate:"required,gt=0"`
+}
+
+type MemberPath struct {
+	PlaylistPath
+	MemberId int `param:"member_id" json:"-" validate:"required,gt=0"`
+}
+
+type MemberRequest struct {
+	MemberPath
+	Role string `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+type ForkRequest struct {
+	UserId int    `query:"user_id" json:"-" validate:"required,gt=0"`
+	Slug   string `param:"slug" json:"-" validate:"required"`
+}
+
+type BannerPath struct {
+	BannerId int `param:"banner_id" json:"-" validate:"required,gt=0"`
+}
+
+// BannerRequest leaves Weight nil for the default weight of 1.
+type BannerRequest struct {
+	BannerId int    `param:"banner_id" json:"-" validate:"gte=0"`
+	MovieId  int    `json:"movie_id" validate:"required,gt=0"`
+	Title    string `json:"title" validate:"required,max=255"`
+	Type     string `json:"type" validate:"required,oneof=movie tv"`
+	Comment  string `json:"comment" validate:"max=255"`
+	StartAt  string `json:"start_at"`
+	EndAt    string `json:"end_at"`
+	Priority int    `json:"priority"`
+	Audience string `json:"audience" validate:"omitempty,oneof=all logged-in rank"`
+	Rank     string `json:"rank" validate:"max=20"`
+	Locale   string `json:"locale" validate:"max=16"`
+	Slot     string `json:"slot" validate:"max=32"`
+	Weight   *int   `json:"weight" validate:"omitempty,gte=0"`
+}
+
+func (r BannerRequest) banner() UserDomain.Banner {
+	weight := 1
+	if r.Weight != nil {
+		weight = *r.Weight
+	}
+	return UserDomain.Banner{
+		Id:         r.BannerId,
+		MovieId:    r.MovieId,
+		Title:      r.Title,
+		Type:       r.Type,
+		Comment:    r.Comment,
+		StartAt:    r.StartAt,
+		EndAt:      r.EndAt,
+		Priority:   r.Priority,
+		Audience:   r.Audience,
+		TargetRank: r.Rank,
+		Locale:     r.Locale,
+		Slot:       r.Slot,
+		Weight:     weight,
+	}
+}
+
+type BannerEventRequest struct {
+	BannerPath
+	UserId int `query:"user_id" json:"-" validate:"gte=0"`
+}
+
+type SlotPath struct {
+	Slot string `param:"slot" json:"-" validate:"required,max=32"`
+}
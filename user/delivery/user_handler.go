@@ -1,9 +1,14 @@
 package delivery
 
 import (
-	"encoding/json"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"github.com/labstack/echo/v4"
+	"github.com/null-like/movie-backend/domain/apperror"
 	UserDomain "github.com/null-like/movie-backend/domain/user"
+	"github.com/null-like/movie-backend/request"
 	"github.com/null-like/movie-backend/user"
 	"github.com/sirupsen/logrus"
 	"net/http"
@@ -15,10 +20,6 @@ type userHandler struct {
 	logger  *logrus.Logger
 }
 
-type ResponseError struct {
-	Message string `json:"message"`
-}
-
 func NewUserHandler(g *echo.Group, u user.Usecase, logger *logrus.Logger) {
 	handler := &userHandler{
 		Usecase: u,
@@ -32,49 +33,73 @@ func NewUserHandler(g *echo.Group, u user.Usecase, logger *logrus.Logger) {
 	g.GET("/delete-user", handler.SendDeletedAllUser)
 	g.GET("/update-user", handler.SendUpdatedAllUser)
 	g.GET("/nickname", handler.SendNickname)
+	g.GET("/profile", handler.SendProfile)
+	g.GET("/change-bio", handler.SendChangedProfile)
+	g.POST("/avatar", handler.UploadAvatar)
+	g.GET("/avatar/:size/:user_id/:name", handler.SendAvatar)
 	g.GET("/favorite", handler.SendFavorite)
 	g.GET("/is-favorite", handler.SendIsFavorite)
 	g.GET("/toggle-fav", handler.ToggleIsLiked)
 	g.GET("/rating", handler.SendRating)
 	g.GET("/rating-list", handler.SendRatings)
 	g.GET("/rating-list-changed", handler.SendChangedRatings)
+	g.GET("/watchlist", handler.SendWatchlist)
+	g.GET("/want-to-watch", handler.SendWantToWatch)
+	g.GET("/mark-watched", handler.SendMarkedWatched)
+	g.GET("/delete-watch", handler.SendDeletedWatch)
+	g.GET("/watch-history", handler.SendWatchHistory)
 	g.GET("/playlist", handler.SendPlaylists)
+	g.GET("/public-playlist", handler.SendPublicPlaylists)
+	g.GET("/user-playlist", handler.SendUserPlaylists)
+	g.GET("/playlist-detail", handler.SendPlaylist)
 	g.GET("/add-playlist", handler.SendAddedPlaylists)
 	g.GET("/change-playlist", handler.SendChangedPlaylists)
 	g.GET("/delete-playlist", handler.SendDeletedPlaylists)
+	g.GET("/playlist-item", handler.SendPlaylistItems)
+	g.GET("/add-playlist-item", handler.SendAddedPlaylistItems)
+	g.GET("/delete-playlist-item", handler.SendDeletedPlaylistItems)
+	g.GET("/move-playlist-item", handler.SendMovedPlaylistItems)
+	g.GET("/playlist-change", handler.SendPlaylistChanges)
+	g.GET("/share-playlist", handler.SendSharedPlaylistLink)
+	g.GET("/unshare-playlist", handler.SendUnsharedPlaylist)
+	g.GET("/shared/:slug", handler.SendSharedPlaylist)
+	g.GET("/fork-playlist", handler.SendForkedPlaylist)
+	g.GET("/collab-playlist", handler.SendCollaboratingPlaylists)
+	g.GET("/playlist-member", handler.SendPlaylistMembers)
+	g.GET("/add-playlist-member", handler.SendAddedPlaylistMembers)
+	g.GET("/delete-playlist-member", handler.SendDeletedPlaylistMembers)
+	g.GET("/follow", handler.SendFollowedUsers)
+	g.GET("/unfollow", handler.SendUnfollowedUsers)
+	g.GET("/following", handler.SendFollowing)
+	g.GET("/followers", handler.SendFollowers)
+	g.GET("/feed", handler.SendFeed)
+	g.GET("/user-activity", handler.SendUserActivity)
+	g.GET("/activity-privacy", handler.SendChangedActivityPrivacy)
 	g.GET("/banner", handler.SendAllBanners)
+	g.GET("/active-banner", handler.SendActiveBanners)
+	g.GET("/banner-impression", handler.RecordBannerImpression)
+	g.GET("/banner-click", handler.RecordBannerClick)
+	g.GET("/banner-report", handler.SendBannerReport)
 	g.GET("/change-banner", handler.SendUpdatedBanners)
 	g.GET("/add-banner", handler.SendAddedBanners)
 	g.GET("/delete-banner", handler.SendDeletedBanners)
 }
 
-type SignUpUser struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Nickname string `json:"nickname"`
-}
-
-type SignInUser struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-}
-
 func (h *userHandler) SignUp(c echo.Context) error {
 	ctx := c.Request().Context()
-	body := c.Request().Body
-	dec := json.NewDecoder(body)
-	u := SignUpUser{}
-	dec.Decode(&u)
+	var req SignUpUser
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
 	user := UserDomain.User{
-		Email:    u.Email,
-		Password: u.Password,
-		Nickname: u.Nickname,
+		Email:    req.Email,
+		Password: req.Password,
+		Nickname: req.Nickname,
 	}
 
 	err := h.Usecase.RegisterUser(ctx, user)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusBadRequest, -1)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, 1)
@@ -82,8 +107,15 @@ func (h *userHandler) SignUp(c echo.Context) error {
 
 func (h *userHandler) CheckDuplicates(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	isExist, _ := h.Usecase.CheckUser(ctx, params.Get("email"))
+	var req EmailQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	isExist, err := h.Usecase.CheckUser(ctx, req.Email)
+	if err != nil {
+		return err
+	}
 
 	if isExist {
 		return c.JSON(http.StatusOK, -1)
@@ -94,16 +126,14 @@ func (h *userHandler) CheckDuplicates(c echo.Context) error {
 
 func (h *userHandler) SignIn(c echo.Context) error {
 	ctx := c.Request().Context()
-	body := c.Request().Body
-	dec := json.NewDecoder(body)
-	u := SignInUser{}
-	dec.Decode(&u)
-
-	userInfo, err := h.Usecase.AuthUser(ctx, u.Email, u.Password)
+	var req SignInUser
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
 
+	userInfo, err := h.Usecase.AuthUser(ctx, req.Email, req.Password)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusBadRequest, userInfo)
+		return err
 	}
 	return c.JSON(http.StatusOK, userInfo)
 }
@@ -112,8 +142,7 @@ func (h *userHandler) SendAllUser(c echo.Context) error {
 	ctx := c.Request().Context()
 	users, err := h.Usecase.GetAllUsers(ctx)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusOK, nil)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, users)
@@ -121,12 +150,14 @@ func (h *userHandler) SendAllUser(c echo.Context) error {
 
 func (h *userHandler) SendDeletedAllUser(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	id, err := strconv.Atoi(params.Get("id"))
-	users, err := h.Usecase.DeleteAndGetAllUsers(ctx, id)
+	var req IdQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	users, err := h.Usecase.DeleteAndGetAllUsers(ctx, req.Id)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusOK, nil)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, users)
@@ -134,12 +165,14 @@ func (h *userHandler) SendDeletedAllUser(c echo.Context) error {
 
 func (h *userHandler) SendUpdatedAllUser(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	id, err := strconv.Atoi(params.Get("id"))
-	users, err := h.Usecase.UpdateAndGetAllUsers(ctx, id, params.Get("rank"))
+	var req RankQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	users, err := h.Usecase.UpdateAndGetAllUsers(ctx, req.Id, req.Rank)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusOK, nil)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, users)
@@ -147,43 +180,133 @@ func (h *userHandler) SendUpdatedAllUser(c echo.Context) error {
 
 func (h *userHandler) SendNickname(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	id, err := strconv.Atoi(params.Get("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, ResponseError{Message: err.Error()})
+	var req IdQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
 	}
-	nickname, err := h.Usecase.GetNickName(ctx, id)
 
+	nickname, err := h.Usecase.GetNickName(ctx, req.Id)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, "")
-	} else {
-		return c.JSON(http.StatusOK, nickname)
+		return err
 	}
+
+	return c.JSON(http.StatusOK, nickname)
 }
 
 func (h *userHandler) SendIsFavorite(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	userId, _ := strconv.Atoi(params.Get("user_id"))
-	movieId, _ := strconv.Atoi(params.Get("movie_id"))
-	mediaType := params.Get("type")
+	var req MediaQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	isFavorite, err := h.Usecase.GetIsFavorite(ctx, req.UserId, req.MediaId, req.Type)
+	if err != nil {
+		return err
+	}
 
-	isFavorite, _ := h.Usecase.GetIsFavorite(ctx, userId, movieId, mediaType)
 	return c.JSON(http.StatusOK, isFavorite)
 }
 
-func (h *userHandler) SendFavorite(c echo.Context) error {
+func (h *userHandler) SendProfile(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	id, err := strconv.Atoi(params.Get("id"))
+	var req UserQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
 
+	profile, err := h.Usecase.GetProfile(ctx, req.UserId)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ResponseError{Message: err.Error()})
+		return err
 	}
 
-	movies, err := h.Usecase.GetFavorites(ctx, id)
+	return c.JSON(http.StatusOK, profile)
+}
+
+func (h *userHandler) SendChangedProfile(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req BioQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	profile, err := h.Usecase.ChangeBioAndGetProfile(ctx, req.UserId, req.Bio)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ResponseError{Message: err.Error()})
+		return err
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// UploadAvatar takes the image in the "avatar" field of a multipart form.
+func (h *userHandler) UploadAvatar(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req UserQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	header, err := c.FormFile("avatar")
+	if err != nil {
+		return apperror.Validation(err.Error())
+	}
+	if header.Size > UserDomain.MaxAvatarBytes {
+		return UserDomain.ErrAvatarTooLarge
+	}
+	file, err := header.Open()
+	if err != nil {
+		return apperror.Validation(err.Error())
+	}
+	defer file.Close()
+
+	profile, err := h.Usecase.ChangeAvatarAndGetProfile(ctx, req.UserId, file)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// SendAvatar serves an avatar thumbnail. Avatar file names change with their
+// content, so responses can be cached indefinitely.
+func (h *userHandler) SendAvatar(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req AvatarPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	etag := fmt.Sprintf(`"%d-%d-%s"`, req.UserId, req.Size, req.Name)
+	res := c.Response()
+	res.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	res.Header().Set("ETag", etag)
+	if c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	rc, info, err := h.Usecase.GetAvatar(ctx, req.UserId, req.Size, req.Name)
+	if err != nil {
+		res.Header().Del("Cache-Control")
+		res.Header().Del("ETag")
+		return err
+	}
+	defer rc.Close()
+
+	res.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	res.Header().Set("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+	return c.Stream(http.StatusOK, info.ContentType, rc)
+}
+
+func (h *userHandler) SendFavorite(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req IdQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	movies, err := h.Usecase.GetFavorites(ctx, req.Id)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, movies)
@@ -191,21 +314,18 @@ func (h *userHandler) SendFavorite(c echo.Context) error {
 
 func (h *userHandler) ToggleIsLiked(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	userId, _ := strconv.Atoi(params.Get("user_id"))
-	movieId, _ := strconv.Atoi(params.Get("movie_id"))
-	isLiked, _ := strconv.Atoi(params.Get("is_liked"))
-	mediaType := params.Get("type")
+	var req LikeQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
 
-	err := h.Usecase.ChangeIsLiked(ctx, userId, movieId, isLiked, mediaType)
+	err := h.Usecase.ChangeIsLiked(ctx, req.UserId, req.MediaId, req.IsLiked, req.Type)
 	if err != nil {
-		h.logger.Error(err)
 		return err
 	}
 
-	movies, err := h.Usecase.GetFavorites(ctx, userId)
+	movies, err := h.Usecase.GetFavorites(ctx, req.UserId)
 	if err != nil {
-		h.logger.Error(err)
 		return err
 	}
 
@@ -214,29 +334,33 @@ func (h *userHandler) ToggleIsLiked(c echo.Context) error {
 
 func (h *userHandler) SendRating(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	userId, _ := strconv.Atoi(params.Get("user_id"))
-	movieId, _ := strconv.Atoi(params.Get("movie_id"))
-	mediaType := params.Get("type")
+	var req MediaQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
 
-	rating, err := h.Usecase.GetRating(ctx, userId, movieId, mediaType)
-	if err != nil {
-		h.logger.Error(err)
+	rating, err := h.Usecase.GetRating(ctx, req.UserId, req.MediaId, req.Type)
+	if errors.Is(err, UserDomain.ErrRatingNotFound) {
+		// v1 answers 0 for a title the user hasn't rated.
 		return c.JSON(http.StatusOK, 0)
 	}
+	if err != nil {
+		return err
+	}
 
 	return c.JSON(http.StatusOK, rating)
 }
 
 func (h *userHandler) SendRatings(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	userId, _ := strconv.Atoi(params.Get("user_id"))
+	var req UserQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
 
-	ratings, err := h.Usecase.GetRatingList(ctx, userId)
+	ratings, err := h.Usecase.GetRatingList(ctx, req.UserId)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusOK, nil)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, ratings)
@@ -244,44 +368,169 @@ func (h *userHandler) SendRatings(c echo.Context) error {
 
 func (h *userHandler) SendChangedRatings(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	userId, _ := strconv.Atoi(params.Get("user_id"))
-	movieId, _ := strconv.Atoi(params.Get("movie_id"))
-	rating, _ := strconv.Atoi(params.Get("rating"))
-	mediaType := params.Get("type")
+	var req RatingQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
 
-	ratings, err := h.Usecase.GetChangedRatingList(ctx, userId, movieId, rating, mediaType)
+	ratings, err := h.Usecase.GetChangedRatingList(ctx, req.UserId, req.MediaId, req.Rating, req.Type, req.MarkWatched)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusOK, nil)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, ratings)
 }
 
+func (h *userHandler) SendWatchlist(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req WatchlistQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+	offset, limit := req.page()
+
+	entries, err := h.Usecase.GetWatchlist(ctx, req.UserId, req.State, offset, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
+
+func (h *userHandler) SendWantToWatch(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req MediaQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	entry, err := h.Usecase.WantToWatch(ctx, req.UserId, req.MediaId, req.Type)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, entry)
+}
+
+func (h *userHandler) SendMarkedWatched(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req MarkWatchedQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	entry, err := h.Usecase.MarkWatched(ctx, req.UserId, req.MediaId, req.Type, req.WatchedAt)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, entry)
+}
+
+func (h *userHandler) SendDeletedWatch(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req DeleteWatchQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+	offset, limit := req.page()
+
+	entries, err := h.Usecase.DeleteAndGetWatchlist(ctx, req.UserId, req.MediaId, req.Type, offset, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
+
+func (h *userHandler) SendWatchHistory(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req HistoryQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+	offset, limit := req.page()
+
+	events, err := h.Usecase.GetWatchHistory(ctx, req.UserId, offset, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, events)
+}
+
 func (h *userHandler) SendPlaylists(c echo.Context) error {
 	ctx := c.Request().Context()
+	var req ActorQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	var playlists []UserDomain.Playlist
+	var err error
+	if req.UserId != 0 {
+		playlists, err = h.Usecase.GetUserPlaylists(ctx, req.UserId, req.UserId)
+	} else {
+		playlists, err = h.Usecase.GetPublicPlaylists(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, playlists)
+}
+
+func (h *userHandler) SendPublicPlaylists(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	playlists, err := h.Usecase.GetPublicPlaylists(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, playlists)
+}
+
+func (h *userHandler) SendUserPlaylists(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req UserPlaylistsQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
 
-	playlists, err := h.Usecase.GetAllPlaylists(ctx)
+	playlists, err := h.Usecase.GetUserPlaylists(ctx, req.UserId, req.OwnerId)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusOK, nil)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, playlists)
 }
 
+func (h *userHandler) SendPlaylist(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req PlaylistQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.GetPlaylist(ctx, req.UserId, req.Id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, playlist)
+}
+
 func (h *userHandler) SendChangedPlaylists(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	id, _ := strconv.Atoi(params.Get("id"))
-	name := params.Get("name")
-	playlist := params.Get("playlist")
+	var req ChangePlaylistQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
 
-	playlists, err := h.Usecase.ChangePlaylistAndGetAllPlaylists(ctx, id, name, playlist)
+	playlists, err := h.Usecase.ChangePlaylistAndGetUserPlaylists(ctx, req.UserId, req.Id, req.Name, req.Visibility)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusOK, nil)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, playlists)
@@ -289,15 +538,14 @@ func (h *userHandler) SendChangedPlaylists(c echo.Context) error {
 
 func (h *userHandler) SendAddedPlaylists(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	name := params.Get("name")
-	playlist := params.Get("playlist")
-	mediaType := params.Get("type")
+	var req AddPlaylistQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
 
-	playlists, err := h.Usecase.AddPlaylistAndGetAllPlaylists(ctx, name, playlist, mediaType)
+	playlists, err := h.Usecase.AddPlaylistAndGetUserPlaylists(ctx, req.UserId, req.Name, req.Type, req.Visibility)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusOK, nil)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, playlists)
@@ -305,43 +553,432 @@ func (h *userHandler) SendAddedPlaylists(c echo.Context) error {
 
 func (h *userHandler) SendDeletedPlaylists(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	id, _ := strconv.Atoi(params.Get("id"))
+	var req PlaylistQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
 
-	playlists, err := h.Usecase.DeletePlaylistAndGetAllPlaylists(ctx, id)
+	playlists, err := h.Usecase.DeletePlaylistAndGetUserPlaylists(ctx, req.UserId, req.Id)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusOK, nil)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, playlists)
 }
 
+func (h *userHandler) SendPlaylistItems(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req PlaylistItemsQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	items, err := h.Usecase.GetPlaylistItems(ctx, req.UserId, req.PlaylistId)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, items)
+}
+
+func (h *userHandler) SendAddedPlaylistItems(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req AddPlaylistItemQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.AddPlaylistItemAndGetPlaylist(ctx, req.UserId, req.PlaylistId, req.Version, req.MediaId, req.Type, req.Note)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, playlist)
+}
+
+func (h *userHandler) SendDeletedPlaylistItems(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req DeletePlaylistItemQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.DeletePlaylistItemAndGetPlaylist(ctx, req.UserId, req.PlaylistId, req.Version, req.ItemId)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, playlist)
+}
+
+func (h *userHandler) SendMovedPlaylistItems(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req MovePlaylistItemQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.MovePlaylistItemAndGetPlaylist(ctx, req.UserId, req.PlaylistId, req.Version, req.ItemId, req.Position)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, playlist)
+}
+
+func (h *userHandler) SendPlaylistChanges(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req PlaylistItemsQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	changes, err := h.Usecase.GetPlaylistChanges(ctx, req.UserId, req.PlaylistId)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, changes)
+}
+
+func (h *userHandler) SendCollaboratingPlaylists(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req UserQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlists, err := h.Usecase.GetCollaboratingPlaylists(ctx, req.UserId)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, playlists)
+}
+
+func (h *userHandler) SendPlaylistMembers(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req PlaylistItemsQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	members, err := h.Usecase.GetPlaylistMembers(ctx, req.UserId, req.PlaylistId)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, members)
+}
+
+func (h *userHandler) SendAddedPlaylistMembers(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req AddPlaylistMemberQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	members, err := h.Usecase.AddPlaylistMemberAndGetMembers(ctx, req.UserId, req.PlaylistId, req.MemberId, req.Role)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, members)
+}
+
+func (h *userHandler) SendDeletedPlaylistMembers(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req PlaylistMemberQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	members, err := h.Usecase.DeletePlaylistMemberAndGetMembers(ctx, req.UserId, req.PlaylistId, req.MemberId)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, members)
+}
+
+func (h *userHandler) SendSharedPlaylistLink(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req PlaylistQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.SharePlaylist(ctx, req.UserId, req.Id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, playlist)
+}
+
+func (h *userHandler) SendUnsharedPlaylist(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req PlaylistQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.UnsharePlaylist(ctx, req.UserId, req.Id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, playlist)
+}
+
+func (h *userHandler) SendSharedPlaylist(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req SlugPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	shared, err := h.Usecase.GetSharedPlaylist(ctx, req.Slug)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, shared)
+}
+
+func (h *userHandler) SendForkedPlaylist(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req ForkQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.ForkSharedPlaylist(ctx, req.UserId, req.Slug)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, playlist)
+}
+
 func (h *userHandler) SendAllBanners(c echo.Context) error {
 	ctx := c.Request().Context()
 
 	banners, err := h.Usecase.GetAllBanners(ctx)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusOK, nil)
+		return err
+	}
+
+	return c.JSON(http.StatusOK, banners)
+}
+
+func (h *userHandler) SendFollowedUsers(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req FollowQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	users, err := h.Usecase.FollowAndGetFollowing(ctx, req.UserId, req.FolloweeId)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, users)
+}
+
+func (h *userHandler) SendUnfollowedUsers(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req FollowQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	users, err := h.Usecase.UnfollowAndGetFollowing(ctx, req.UserId, req.FolloweeId)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, users)
+}
+
+func (h *userHandler) SendFollowing(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req UserQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	users, err := h.Usecase.GetFollowing(ctx, req.UserId)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, users)
+}
+
+func (h *userHandler) SendFollowers(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req UserQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	users, err := h.Usecase.GetFollowers(ctx, req.UserId)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, users)
+}
+
+func (h *userHandler) SendFeed(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req FeedQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+	before, limit := req.page()
+
+	feed, err := h.Usecase.GetFeed(ctx, req.UserId, before, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, feed)
+}
+
+func (h *userHandler) SendUserActivity(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req ActivityQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+	before, limit := req.page()
+
+	feed, err := h.Usecase.GetUserActivity(ctx, req.ViewerId, req.UserId, before, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, feed)
+}
+
+func (h *userHandler) SendChangedActivityPrivacy(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req ActivityPrivacyQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	visibility, err := h.Usecase.ChangeActivityVisibility(ctx, req.UserId, req.Visibility)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"visibility": visibility})
+}
+
+// visitorCookie identifies anonymous viewers so banner experiments can keep
+// showing them the same variant.
+const visitorCookie = "visitor_id"
+
+// visitorId returns the viewer's visitor cookie, setting a fresh one when the
+// request has none.
+func visitorId(c echo.Context) string {
+	if cookie, err := c.Cookie(visitorCookie); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	id := hex.EncodeToString(b)
+	c.SetCookie(&http.Cookie{
+		Name:     visitorCookie,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   365 * 24 * 60 * 60,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return id
+}
+
+func (h *userHandler) SendActiveBanners(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req ActiveBannersQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	visitor := ""
+	if req.UserId == 0 {
+		visitor = visitorId(c)
+	}
+
+	banners, err := h.Usecase.GetActiveBanners(ctx, req.UserId, visitor, req.Locale)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, banners)
 }
 
+func (h *userHandler) recordBannerEvent(c echo.Context, kind string) error {
+	ctx := c.Request().Context()
+	var req BannerEventQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	visitor := ""
+	if req.UserId == 0 {
+		visitor = visitorId(c)
+	}
+
+	err := h.Usecase.RecordBannerEvent(ctx, req.BannerId, req.UserId, visitor, kind)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *userHandler) RecordBannerImpression(c echo.Context) error {
+	return h.recordBannerEvent(c, UserDomain.BannerEventImpression)
+}
+
+func (h *userHandler) RecordBannerClick(c echo.Context) error {
+	return h.recordBannerEvent(c, UserDomain.BannerEventClick)
+}
+
+func (h *userHandler) SendBannerReport(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req SlotQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	report, err := h.Usecase.GetBannerReport(ctx, req.Slot)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
 func (h *userHandler) SendUpdatedBanners(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	id, _ := strconv.Atoi(params.Get("id"))
-	movieId, _ := strconv.Atoi(params.Get("movie_id"))
-	title := params.Get("title")
-	mediaType := params.Get("type")
-	comment := params.Get("comment")
+	var req BannerQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
 
-	banners, err := h.Usecase.UpdateAndGetAllBanners(ctx, id, movieId, title, mediaType, comment)
+	banners, err := h.Usecase.UpdateAndGetAllBanners(ctx, req.banner())
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusOK, nil)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, banners)
@@ -349,16 +986,16 @@ func (h *userHandler) SendUpdatedBanners(c echo.Context) error {
 
 func (h *userHandler) SendAddedBanners(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	movieId, _ := strconv.Atoi(params.Get("movie_id"))
-	title := params.Get("title")
-	mediaType := params.Get("type")
-	comment := params.Get("comment")
+	var req BannerQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+	banner := req.banner()
+	banner.Id = 0
 
-	banners, err := h.Usecase.AddAndGetAllBanners(ctx, movieId, title, mediaType, comment)
+	banners, err := h.Usecase.AddAndGetAllBanners(ctx, banner)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusOK, nil)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, banners)
@@ -366,13 +1003,14 @@ func (h *userHandler) SendAddedBanners(c echo.Context) error {
 
 func (h *userHandler) SendDeletedBanners(c echo.Context) error {
 	ctx := c.Request().Context()
-	params := c.QueryParams()
-	id, _ := strconv.Atoi(params.Get("id"))
+	var req IdQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
 
-	banners, err := h.Usecase.DeleteAndGetAllBanners(ctx, id)
+	banners, err := h.Usecase.DeleteAndGetAllBanners(ctx, req.Id)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusOK, nil)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, banners)
@@ -0,0 +1,819 @@
+package delivery
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/null-like/movie-backend/domain/apperror"
+	UserDomain "github.com/null-like/movie-backend/domain/user"
+	"github.com/null-like/movie-backend/request"
+	"github.com/null-like/movie-backend/user"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// NewUserHandlerV2 registers the /v2 user routes. Resources live at their own
+// URLs and changes use POST, PUT, PATCH and DELETE. Until requests carry a
+// token, the acting user of a playlist or banner call is the user_id query
+// param, the same as in /v1.
+func NewUserHandlerV2(g *echo.Group, u user.Usecase, logger *logrus.Logger) {
+	handler := &userHandler{
+		Usecase: u,
+		logger:  logger,
+	}
+
+	g.POST("/users", handler.CreateUser)
+	g.GET("/users", handler.ListUsers)
+	g.GET("/users/check", handler.CheckEmail)
+	g.POST("/sessions", handler.CreateSession)
+	g.GET("/users/:user_id", handler.GetProfileV2)
+	g.DELETE("/users/:user_id", handler.DeleteUser)
+	g.PUT("/users/:user_id/rank", handler.PutRank)
+	g.PUT("/users/:user_id/bio", handler.PutBio)
+	g.PUT("/users/:user_id/avatar", handler.PutAvatar)
+	g.GET("/avatars/:size/:user_id/:name", handler.SendAvatar)
+
+	g.GET("/users/:user_id/favorites", handler.ListFavorites)
+	g.GET("/users/:user_id/favorites/:media_type/:media_id", handler.GetFavorite)
+	g.POST("/users/:user_id/favorites/:media_type/:media_id", handler.CreateFavorite)
+	g.DELETE("/users/:user_id/favorites/:media_type/:media_id", handler.DeleteFavorite)
+
+	g.GET("/users/:user_id/ratings", handler.ListRatings)
+	g.GET("/users/:user_id/ratings/:media_type/:media_id", handler.GetRatingV2)
+	g.PUT("/users/:user_id/ratings/:media_type/:media_id", handler.PutRating)
+
+	g.GET("/users/:user_id/watchlist", handler.ListWatchlist)
+	g.PUT("/users/:user_id/watchlist/:media_type/:media_id", handler.PutWatchlistEntry)
+	g.DELETE("/users/:user_id/watchlist/:media_type/:media_id", handler.DeleteWatchlistEntry)
+	g.GET("/users/:user_id/history", handler.ListWatchHistory)
+	g.POST("/users/:user_id/history", handler.CreateWatch)
+
+	g.GET("/users/:user_id/following", handler.ListFollowing)
+	g.GET("/users/:user_id/followers", handler.ListFollowers)
+	g.PUT("/users/:user_id/following/:followee_id", handler.PutFollow)
+	g.DELETE("/users/:user_id/following/:followee_id", handler.DeleteFollow)
+	g.GET("/users/:user_id/feed", handler.GetFeedV2)
+	g.GET("/users/:user_id/activity", handler.GetActivity)
+	g.PUT("/users/:user_id/activity-visibility", handler.PutActivityVisibility)
+
+	g.GET("/playlists", handler.ListPublicPlaylists)
+	g.POST("/playlists", handler.CreatePlaylist)
+	g.GET("/users/:user_id/playlists", handler.ListUserPlaylists)
+	g.GET("/users/:user_id/collaborating-playlists", handler.ListCollaboratingPlaylists)
+	g.GET("/playlists/:playlist_id", handler.GetPlaylistV2)
+	g.PATCH("/playlists/:playlist_id", handler.PatchPlaylist)
+	g.DELETE("/playlists/:playlist_id", handler.DeletePlaylist)
+	g.GET("/playlists/:playlist_id/items", handler.ListPlaylistItems)
+	g.POST("/playlists/:playlist_id/items", handler.CreatePlaylistItem)
+	g.DELETE("/playlists/:playlist_id/items/:item_id", handler.DeletePlaylistItem)
+	g.PUT("/playlists/:playlist_id/items/:item_id/position", handler.PutPlaylistItemPosition)
+	g.GET("/playlists/:playlist_id/changes", handler.ListPlaylistChanges)
+	g.GET("/playlists/:playlist_id/members", handler.ListPlaylistMembers)
+	g.PUT("/playlists/:playlist_id/members/:member_id", handler.PutPlaylistMember)
+	g.DELETE("/playlists/:playlist_id/members/:member_id", handler.DeletePlaylistMember)
+	g.PUT("/playlists/:playlist_id/share", handler.PutPlaylistShare)
+	g.DELETE("/playlists/:playlist_id/share", handler.DeletePlaylistShare)
+	g.GET("/shared/:slug", handler.SendSharedPlaylist)
+	g.POST("/shared/:slug/forks", handler.CreateFork)
+
+	g.GET("/banners", handler.ListBanners)
+	g.POST("/banners", handler.CreateBanner)
+	g.GET("/banners/active", handler.SendActiveBanners)
+	g.PUT("/banners/:banner_id", handler.PutBanner)
+	g.DELETE("/banners/:banner_id", handler.DeleteBanner)
+	g.POST("/banners/:banner_id/impressions", handler.CreateBannerImpression)
+	g.POST("/banners/:banner_id/clicks", handler.CreateBannerClick)
+	g.GET("/banner-reports/:slot", handler.GetBannerReport)
+}
+
+func (h *userHandler) CreateUser(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req SignUpUser
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	err := h.Usecase.RegisterUser(ctx, UserDomain.User{Email: req.Email, Password: req.Password, Nickname: req.Nickname})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusCreated)
+}
+
+func (h *userHandler) ListUsers(c echo.Context) error {
+	users, err := h.Usecase.GetAllUsers(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, users)
+}
+
+func (h *userHandler) CheckEmail(c echo.Context) error {
+	var req EmailQuery
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	exists, err := h.Usecase.CheckUser(c.Request().Context(), req.Email)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]bool{"available": !exists})
+}
+
+func (h *userHandler) CreateSession(c echo.Context) error {
+	var req SignInUser
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	userInfo, err := h.Usecase.AuthUser(c.Request().Context(), req.Email, req.Password)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, userInfo)
+}
+
+func (h *userHandler) GetProfileV2(c echo.Context) error {
+	var req UserPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	profile, err := h.Usecase.GetProfile(c.Request().Context(), req.UserId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, profile)
+}
+
+func (h *userHandler) DeleteUser(c echo.Context) error {
+	var req UserPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	_, err := h.Usecase.DeleteAndGetAllUsers(c.Request().Context(), req.UserId)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *userHandler) PutRank(c echo.Context) error {
+	var req RankRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	users, err := h.Usecase.UpdateAndGetAllUsers(c.Request().Context(), req.UserId, req.Rank)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		if u.Id == req.UserId {
+			return c.JSON(http.StatusOK, u)
+		}
+	}
+	return UserDomain.ErrUserNotFound
+}
+
+func (h *userHandler) PutBio(c echo.Context) error {
+	var req BioRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	profile, err := h.Usecase.ChangeBioAndGetProfile(c.Request().Context(), req.UserId, req.Bio)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, profile)
+}
+
+// PutAvatar takes the image in the "avatar" field of a multipart form.
+func (h *userHandler) PutAvatar(c echo.Context) error {
+	var req UserPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	header, err := c.FormFile("avatar")
+	if err != nil {
+		return apperror.Invalid(apperror.FieldError{Field: "avatar", Message: "is required"})
+	}
+	if header.Size > UserDomain.MaxAvatarBytes {
+		return UserDomain.ErrAvatarTooLarge
+	}
+	file, err := header.Open()
+	if err != nil {
+		return apperror.Validation(err.Error())
+	}
+	defer file.Close()
+
+	profile, err := h.Usecase.ChangeAvatarAndGetProfile(c.Request().Context(), req.UserId, file)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, profile)
+}
+
+func (h *userHandler) ListFavorites(c echo.Context) error {
+	var req UserPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	favorites, err := h.Usecase.GetFavorites(c.Request().Context(), req.UserId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, favorites)
+}
+
+// GetFavorite answers 204 when the title is a favorite and 404 when not.
+func (h *userHandler) GetFavorite(c echo.Context) error {
+	var req MediaPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	isFavorite, err := h.Usecase.GetIsFavorite(c.Request().Context(), req.UserId, req.MediaId, req.Type)
+	if err != nil {
+		return err
+	}
+	if !isFavorite {
+		return apperror.NotFound("not a favorite")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *userHandler) CreateFavorite(c echo.Context) error {
+	var req MediaPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	err := h.Usecase.ChangeIsLiked(c.Request().Context(), req.UserId, req.MediaId, 1, req.Type)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusCreated)
+}
+
+func (h *userHandler) DeleteFavorite(c echo.Context) error {
+	var req MediaPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	err := h.Usecase.ChangeIsLiked(c.Request().Context(), req.UserId, req.MediaId, 0, req.Type)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *userHandler) ListRatings(c echo.Context) error {
+	var req UserPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	ratings, err := h.Usecase.GetRatingList(c.Request().Context(), req.UserId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, ratings)
+}
+
+func (h *userHandler) GetRatingV2(c echo.Context) error {
+	var req MediaPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	rating, err := h.Usecase.GetRating(c.Request().Context(), req.UserId, req.MediaId, req.Type)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, UserDomain.Rate{Id: req.MediaId, Rating: rating, Type: req.Type})
+}
+
+func (h *userHandler) PutRating(c echo.Context) error {
+	var req RatingRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	ratings, err := h.Usecase.GetChangedRatingList(c.Request().Context(), req.UserId, req.MediaId, req.Rating, req.Type, req.MarkWatched)
+	if err != nil {
+		return err
+	}
+	for _, rating := range ratings {
+		if rating.Id == req.MediaId && rating.Type == req.Type {
+			return c.JSON(http.StatusOK, rating)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *userHandler) ListWatchlist(c echo.Context) error {
+	var req WatchlistRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	offset, limit := req.page()
+	entries, err := h.Usecase.GetWatchlist(c.Request().Context(), req.UserId, req.State, offset, limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, entries)
+}
+
+// PutWatchlistEntry puts a title on the watchlist as want to watch.
+func (h *userHandler) PutWatchlistEntry(c echo.Context) error {
+	var req MediaPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	entry, err := h.Usecase.WantToWatch(c.Request().Context(), req.UserId, req.MediaId, req.Type)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, entry)
+}
+
+func (h *userHandler) DeleteWatchlistEntry(c echo.Context) error {
+	var req MediaPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	_, err := h.Usecase.DeleteAndGetWatchlist(c.Request().Context(), req.UserId, req.MediaId, req.Type, 0, 1)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *userHandler) ListWatchHistory(c echo.Context) error {
+	var req HistoryRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	offset, limit := req.page()
+	events, err := h.Usecase.GetWatchHistory(c.Request().Context(), req.UserId, offset, limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, events)
+}
+
+// CreateWatch records a viewing and answers with the updated watchlist entry.
+func (h *userHandler) CreateWatch(c echo.Context) error {
+	var req WatchRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	entry, err := h.Usecase.MarkWatched(c.Request().Context(), req.UserId, req.MediaId, req.Type, req.WatchedAt)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, entry)
+}
+
+func (h *userHandler) ListFollowing(c echo.Context) error {
+	var req UserPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	users, err := h.Usecase.GetFollowing(c.Request().Context(), req.UserId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, users)
+}
+
+func (h *userHandler) ListFollowers(c echo.Context) error {
+	var req UserPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	users, err := h.Usecase.GetFollowers(c.Request().Context(), req.UserId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, users)
+}
+
+func (h *userHandler) PutFollow(c echo.Context) error {
+	var req FollowRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	_, err := h.Usecase.FollowAndGetFollowing(c.Request().Context(), req.UserId, req.FolloweeId)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *userHandler) DeleteFollow(c echo.Context) error {
+	var req FollowRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	_, err := h.Usecase.UnfollowAndGetFollowing(c.Request().Context(), req.UserId, req.FolloweeId)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *userHandler) GetFeedV2(c echo.Context) error {
+	var req FeedRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	before, limit := req.page()
+	feed, err := h.Usecase.GetFeed(c.Request().Context(), req.UserId, before, limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, feed)
+}
+
+// GetActivity lists a user's activity as seen by the viewer_id query param.
+func (h *userHandler) GetActivity(c echo.Context) error {
+	var req ActivityRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	before, limit := req.page()
+	feed, err := h.Usecase.GetUserActivity(c.Request().Context(), req.ViewerId, req.UserId, before, limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, feed)
+}
+
+func (h *userHandler) PutActivityVisibility(c echo.Context) error {
+	var req VisibilityRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	visibility, err := h.Usecase.ChangeActivityVisibility(c.Request().Context(), req.UserId, req.Visibility)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]string{"visibility": visibility})
+}
+
+func (h *userHandler) ListPublicPlaylists(c echo.Context) error {
+	playlists, err := h.Usecase.GetPublicPlaylists(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, playlists)
+}
+
+func (h *userHandler) CreatePlaylist(c echo.Context) error {
+	var req PlaylistRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.AddPlaylist(c.Request().Context(), req.UserId, req.Name, req.Type, req.Visibility)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, playlist)
+}
+
+func (h *userHandler) ListUserPlaylists(c echo.Context) error {
+	var req UserPlaylistsRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	requesterId := req.UserId
+	if req.ViewerId != nil {
+		requesterId = *req.ViewerId
+	}
+
+	playlists, err := h.Usecase.GetUserPlaylists(c.Request().Context(), requesterId, req.UserId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, playlists)
+}
+
+func (h *userHandler) ListCollaboratingPlaylists(c echo.Context) error {
+	var req UserPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlists, err := h.Usecase.GetCollaboratingPlaylists(c.Request().Context(), req.UserId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, playlists)
+}
+
+func (h *userHandler) GetPlaylistV2(c echo.Context) error {
+	var req PlaylistPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.GetPlaylist(c.Request().Context(), req.UserId, req.PlaylistId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, playlist)
+}
+
+func (h *userHandler) PatchPlaylist(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req PatchPlaylistRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	_, err := h.Usecase.ChangePlaylistAndGetUserPlaylists(ctx, req.UserId, req.PlaylistId, req.Name, req.Visibility)
+	if err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.GetPlaylist(ctx, req.UserId, req.PlaylistId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, playlist)
+}
+
+func (h *userHandler) DeletePlaylist(c echo.Context) error {
+	var req PlaylistPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	_, err := h.Usecase.DeletePlaylistAndGetUserPlaylists(c.Request().Context(), req.UserId, req.PlaylistId)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *userHandler) ListPlaylistItems(c echo.Context) error {
+	var req PlaylistPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	items, err := h.Usecase.GetPlaylistItems(c.Request().Context(), req.UserId, req.PlaylistId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+func (h *userHandler) CreatePlaylistItem(c echo.Context) error {
+	var req PlaylistItemRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.AddPlaylistItemAndGetPlaylist(c.Request().Context(), req.UserId, req.PlaylistId, req.Version, req.MediaId, req.Type, req.Note)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, playlist)
+}
+
+// DeletePlaylistItem takes the expected playlist version from the version
+// query param; 0 or none skips the check.
+func (h *userHandler) DeletePlaylistItem(c echo.Context) error {
+	var req PlaylistItemPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.DeletePlaylistItemAndGetPlaylist(c.Request().Context(), req.UserId, req.PlaylistId, req.Version, req.ItemId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, playlist)
+}
+
+func (h *userHandler) PutPlaylistItemPosition(c echo.Context) error {
+	var req PositionRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.MovePlaylistItemAndGetPlaylist(c.Request().Context(), req.UserId, req.PlaylistId, req.Version, req.ItemId, req.Position)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, playlist)
+}
+
+func (h *userHandler) ListPlaylistChanges(c echo.Context) error {
+	var req PlaylistPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	changes, err := h.Usecase.GetPlaylistChanges(c.Request().Context(), req.UserId, req.PlaylistId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, changes)
+}
+
+func (h *userHandler) ListPlaylistMembers(c echo.Context) error {
+	var req PlaylistPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	members, err := h.Usecase.GetPlaylistMembers(c.Request().Context(), req.UserId, req.PlaylistId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, members)
+}
+
+func (h *userHandler) PutPlaylistMember(c echo.Context) error {
+	var req MemberRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	members, err := h.Usecase.AddPlaylistMemberAndGetMembers(c.Request().Context(), req.UserId, req.PlaylistId, req.MemberId, req.Role)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, members)
+}
+
+func (h *userHandler) DeletePlaylistMember(c echo.Context) error {
+	var req MemberPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	_, err := h.Usecase.DeletePlaylistMemberAndGetMembers(c.Request().Context(), req.UserId, req.PlaylistId, req.MemberId)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *userHandler) PutPlaylistShare(c echo.Context) error {
+	var req PlaylistPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.SharePlaylist(c.Request().Context(), req.UserId, req.PlaylistId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, playlist)
+}
+
+func (h *userHandler) DeletePlaylistShare(c echo.Context) error {
+	var req PlaylistPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.UnsharePlaylist(c.Request().Context(), req.UserId, req.PlaylistId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, playlist)
+}
+
+func (h *userHandler) CreateFork(c echo.Context) error {
+	var req ForkRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	playlist, err := h.Usecase.ForkSharedPlaylist(c.Request().Context(), req.UserId, req.Slug)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, playlist)
+}
+
+func (h *userHandler) ListBanners(c echo.Context) error {
+	banners, err := h.Usecase.GetAllBanners(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, banners)
+}
+
+func (h *userHandler) CreateBanner(c echo.Context) error {
+	var req BannerRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	banner, err := h.Usecase.AddBanner(c.Request().Context(), req.banner())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, banner)
+}
+
+func (h *userHandler) PutBanner(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req BannerRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	_, err := h.Usecase.UpdateAndGetAllBanners(ctx, req.banner())
+	if err != nil {
+		return err
+	}
+
+	banner, err := h.Usecase.GetBanner(ctx, req.BannerId)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, banner)
+}
+
+func (h *userHandler) DeleteBanner(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req BannerPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	_, err := h.Usecase.GetBanner(ctx, req.BannerId)
+	if err != nil {
+		return err
+	}
+	_, err = h.Usecase.DeleteAndGetAllBanners(ctx, req.BannerId)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *userHandler) createBannerEvent(c echo.Context, kind string) error {
+	var req BannerEventRequest
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+	visitor := ""
+	if req.UserId == 0 {
+		visitor = visitorId(c)
+	}
+
+	err := h.Usecase.RecordBannerEvent(c.Request().Context(), req.BannerId, req.UserId, visitor, kind)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *userHandler) CreateBannerImpression(c echo.Context) error {
+	return h.createBannerEvent(c, UserDomain.BannerEventImpression)
+}
+
+func (h *userHandler) CreateBannerClick(c echo.Context) error {
+	return h.createBannerEvent(c, UserDomain.BannerEventClick)
+}
+
+func (h *userHandler) GetBannerReport(c echo.Context) error {
+	var req SlotPath
+	if err := request.Bind(c, &req); err != nil {
+		return err
+	}
+
+	report, err := h.Usecase.GetBannerReport(c.Request().Context(), req.Slot)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, report)
+}
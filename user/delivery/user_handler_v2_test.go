@@ -0,0 +1,61 @@
+package delivery
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	userDomain "github.com/null-like/movie-backend/domain/user"
+	_movieRepo "github.com/null-like/movie-backend/movie/repository"
+	"github.com/null-like/movie-backend/problem"
+	_userRepo "github.com/null-like/movie-backend/user/repository"
+	_userUsecase "github.com/null-like/movie-backend/user/usecase"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPatchPlaylistKeepsOmittedFields(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	mr := _movieRepo.NewMemoryMovieRepository()
+	ur := _userRepo.NewMemoryUserRepository(mr)
+	e := echo.New()
+	e.HTTPErrorHandler = problem.HTTPErrorHandler(logger)
+	NewUserHandlerV2(e.Group("/v2"), _userUsecase.NewUserUsecase(logger, ur, mr, nil, nil), logger)
+
+	ctx := context.Background()
+	id, err := ur.InsertPlaylist(ctx, 1, "Weekend", movieDomain.MediaType, userDomain.VisibilityPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		body       string
+		name       string
+		visibility string
+	}{
+		{`{"visibility":"public"}`, "Weekend", userDomain.VisibilityPublic},
+		{`{"name":"Sunday"}`, "Sunday", userDomain.VisibilityPublic},
+		{`{}`, "Sunday", userDomain.VisibilityPublic},
+	} {
+		req := httptest.NewRequest(http.MethodPatch, "/v2/playlists/"+strconv.Itoa(id)+"?user_id=1", strings.NewReader(tc.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("PATCH %s: status %d: %s", tc.body, rec.Code, rec.Body)
+		}
+
+		p, err := ur.ReadPlaylistById(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Name != tc.name || p.Visibility != tc.visibility {
+			t.Errorf("PATCH %s: got name %q, visibility %q, want %q, %q", tc.body, p.Name, p.Visibility, tc.name, tc.visibility)
+		}
+	}
+}
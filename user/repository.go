@@ -13,23 +13,77 @@ type Repository interface {
 	DeleteUser(ctx context.Context, id int) error
 	UpdateUser(ctx context.Context, id int, rank string) error
 	FindNicknameByUserId(ctx context.Context, userId int) (string, error)
+	FindUserById(ctx context.Context, userId int) (userDomain.AllUserInfo, error)
+	FindProfileById(ctx context.Context, userId int) (userDomain.Profile, error)
+	FindProfilesByIds(ctx context.Context, userIds []int) ([]userDomain.Profile, error)
+	UpdateBio(ctx context.Context, userId int, bio string) error
+	UpdateAvatar(ctx context.Context, userId int, avatar string) error
+	FindRatingDistribution(ctx context.Context, userId int) ([]userDomain.RatingBucket, error)
+	FindRatedGenres(ctx context.Context, userId int, limit int) ([]userDomain.GenreAffinity, error)
+	FindWatchedRuntime(ctx context.Context, userId int) (int, error)
 
 	FindIsFavorite(ctx context.Context, userId int, movieId int, mediaType string) (bool, error)
 	FindFavoriteByUserId(ctx context.Context, userId int) ([]userDomain.Favorite, error)
+	FindFavoritesByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Favorite, error)
 	InsertFavorite(ctx context.Context, userId int, movieId int, mediaType string) error
 	DeleteFavorite(ctx context.Context, userId int, movieId int, mediaType string) error
 
 	InsertRating(ctx context.Context, userId int, movieId int, rating int, mediaType string) error
 	FindRatingByMovieId(ctx context.Context, userId int, movieId int, mediaType string) (int, error)
 	FindRatingsByUserId(ctx context.Context, userId int) ([]userDomain.Rate, error)
+	FindRatingsByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Rate, error)
 
-	AllPlaylist(ctx context.Context) ([]userDomain.Playlist, error)
-	InsertPlaylist(ctx context.Context, id int, title string, playlist string) error
-	InsertPlaylist2(ctx context.Context, name string, playlist string, mediaType string) error
+	FindWatchlist(ctx context.Context, userId int, state string, offset int, limit int) ([]userDomain.WatchEntry, error)
+	FindWatchEntry(ctx context.Context, userId int, mediaId int, mediaType string) (userDomain.WatchEntry, error)
+	UpsertWantToWatch(ctx context.Context, userId int, mediaId int, mediaType string) error
+	InsertWatch(ctx context.Context, userId int, mediaId int, mediaType string, watchedAt string) error
+	DeleteWatchEntry(ctx context.Context, userId int, mediaId int, mediaType string) error
+	FindWatchHistory(ctx context.Context, userId int, offset int, limit int) ([]userDomain.WatchEvent, error)
+
+	FindPublicPlaylists(ctx context.Context) ([]userDomain.Playlist, error)
+	FindPlaylistsByUserId(ctx context.Context, userId int) ([]userDomain.Playlist, error)
+	FindPlaylistsByMemberId(ctx context.Context, userId int) ([]userDomain.Playlist, error)
+	ReadPlaylistById(ctx context.Context, id int) (userDomain.Playlist, error)
+	FindPlaylistsByIds(ctx context.Context, ids []int) ([]userDomain.Playlist, error)
+	InsertPlaylist(ctx context.Context, userId int, name string, mediaType string, visibility string) (int, error)
+	UpdatePlaylist(ctx context.Context, id int, name string, visibility string) error
 	DeletePlaylist(ctx context.Context, id int) error
+	ReadPlaylistByShareSlug(ctx context.Context, slug string) (userDomain.Playlist, error)
+	UpdatePlaylistShareSlug(ctx context.Context, id int, slug string) error
+	IncrementPlaylistViewCount(ctx context.Context, id int) error
+	ForkPlaylist(ctx context.Context, sourceId int, userId int, name string) (int, error)
+
+	FindPlaylistItems(ctx context.Context, playlistId int) ([]userDomain.PlaylistItem, error)
+	FindPlaylistItemsByPlaylistIds(ctx context.Context, playlistIds []int) (map[int][]userDomain.PlaylistItem, error)
+	InsertPlaylistItem(ctx context.Context, playlistId int, version int, userId int, mediaId int, mediaType string, note string) error
+	DeletePlaylistItem(ctx context.Context, playlistId int, version int, userId int, itemId int) error
+	UpdatePlaylistItemPositions(ctx context.Context, playlistId int, version int, userId int, itemIds []int) error
+	FindPlaylistChanges(ctx context.Context, playlistId int, limit int) ([]userDomain.PlaylistChange, error)
+
+	FindPlaylistMembers(ctx context.Context, playlistId int) ([]userDomain.PlaylistMember, error)
+	FindPlaylistRole(ctx context.Context, playlistId int, userId int) (string, error)
+	UpsertPlaylistMember(ctx context.Context, playlistId int, userId int, role string, invitedBy int) error
+	DeletePlaylistMember(ctx context.Context, playlistId int, userId int) error
+
+	InsertFollow(ctx context.Context, followerId int, followeeId int) error
+	DeleteFollow(ctx context.Context, followerId int, followeeId int) error
+	FindIsFollowing(ctx context.Context, followerId int, followeeId int) (bool, error)
+	FindFollowing(ctx context.Context, userId int) ([]userDomain.FollowUser, error)
+	FindFollowers(ctx context.Context, userId int) ([]userDomain.FollowUser, error)
+	FindActivityVisibility(ctx context.Context, userId int) (string, error)
+	UpdateActivityVisibility(ctx context.Context, userId int, visibility string) error
+	InsertActivityEvent(ctx context.Context, event userDomain.ActivityEvent) error
+	FindFeed(ctx context.Context, userId int, before int, limit int) ([]userDomain.ActivityEvent, error)
+	FindActivityByUserId(ctx context.Context, userId int, before int, limit int) ([]userDomain.ActivityEvent, error)
 
 	AllBanner(ctx context.Context) ([]userDomain.Banner, error)
-	UpdateBanner(ctx context.Context, id int, movieId int, title string, mediaType string, comment string) error
-	InsertBanner(ctx context.Context, movieId int, title string, mediaType string, comment string) error
+	FindActiveBanners(ctx context.Context, locale string) ([]userDomain.Banner, error)
+	UpdateBanner(ctx context.Context, banner userDomain.Banner) error
+	InsertBanner(ctx context.Context, banner userDomain.Banner) (int, error)
 	DeleteBanner(ctx context.Context, id int) error
+	ReadBannerById(ctx context.Context, id int) (userDomain.Banner, error)
+	FindBannerAssignment(ctx context.Context, slot string, subject string) (int, error)
+	UpsertBannerAssignment(ctx context.Context, slot string, subject string, bannerId int) error
+	InsertBannerEvent(ctx context.Context, bannerId int, subject string, kind string) error
+	FindBannerSlotStats(ctx context.Context, slot string) ([]userDomain.BannerVariantStats, error)
 }
@@ -4,9 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
 	userDomain "github.com/null-like/movie-backend/domain/user"
 	"github.com/null-like/movie-backend/user"
 	"github.com/sirupsen/logrus"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type mariaDBUserRepository struct {
@@ -26,16 +30,13 @@ func NewMariaDBUserRepository(l *logrus.Logger, Conn *sql.DB, sm map[string]stri
 func (r *mariaDBUserRepository) InsertUser(ctx context.Context, user userDomain.User) error {
 	query := fmt.Sprintf(`
 		INSERT INTO %s.User (email, password, nickname, rank, signup_date)
-		VALUES ('%s', '%s', '%s', '회원', now());
+		VALUES (?, ?, ?, '회원', now());
 		`,
 		r.schemaMap["movie"],
-		user.Email,
-		user.Password,
-		user.Nickname,
 	)
 	r.logger.Debug(query)
 
-	_, err := r.Conn.ExecContext(ctx, query)
+	_, err := r.Conn.ExecContext(ctx, query, user.Email, user.Password, user.Nickname)
 	if err != nil {
 		r.logger.Error(err)
 		return err
@@ -113,20 +114,21 @@ func (r *mariaDBUserRepository) FindIdByEmail(ctx context.Context, email string)
 	query := fmt.Sprintf(`
 		SELECT id
 		FROM %s.User
-		WHERE email = '%s';
+		WHERE email = ?;
 		`,
 		r.schemaMap["movie"],
-		email,
 	)
 
 	var id int
 	r.logger.Debug(query)
-	row := r.Conn.QueryRowContext(ctx, query)
+	row := r.Conn.QueryRowContext(ctx, query, email)
 	err := row.Scan(&id)
-
-	if err != nil {
+	if err == sql.ErrNoRows {
 		return false, nil
 	}
+	if err != nil {
+		return false, err
+	}
 
 	return true, nil
 }
@@ -135,10 +137,9 @@ func (r *mariaDBUserRepository) FindIdAndPasswdByEmail(ctx context.Context, emai
 	query := fmt.Sprintf(`
 		SELECT id, password, nickname, rank
 		FROM %s.User
-		WHERE email = '%s';
+		WHERE email = ?;
 		`,
 		r.schemaMap["movie"],
-		email,
 	)
 
 	var id int
@@ -146,7 +147,7 @@ func (r *mariaDBUserRepository) FindIdAndPasswdByEmail(ctx context.Context, emai
 	var rank string
 	var hashPassword string
 	r.logger.Debug(query)
-	row := r.Conn.QueryRowContext(ctx, query)
+	row := r.Conn.QueryRowContext(ctx, query, email)
 	err := row.Scan(&id, &hashPassword, &nickname, &rank)
 
 	if err != nil {
@@ -178,6 +179,254 @@ func (r *mariaDBUserRepository) FindNicknameByUserId(ctx context.Context, userId
 	return nickname, nil
 }
 
+func (r *mariaDBUserRepository) FindUserById(ctx context.Context, userId int) (userDomain.AllUserInfo, error) {
+	query := fmt.Sprintf(`
+		SELECT id, email, nickname, rank, signup_date
+		FROM %s.User
+		WHERE id = %d;
+		`,
+		r.schemaMap["movie"],
+		userId,
+	)
+
+	var user userDomain.AllUserInfo
+	r.logger.Debug(query)
+	row := r.Conn.QueryRowContext(ctx, query)
+	err := row.Scan(&user.Id, &user.Email, &user.Nickname, &user.Rank, &user.SignUpDate)
+
+	if err != nil {
+		return userDomain.AllUserInfo{}, err
+	}
+	return user, nil
+}
+
+// FindProfileById reads the profile fields kept on the user along with its
+// rating, favorite and public playlist counts.
+func (r *mariaDBUserRepository) FindProfileById(ctx context.Context, userId int) (userDomain.Profile, error) {
+	query := fmt.Sprintf(`
+		SELECT u.id, u.nickname, u.avatar, u.bio, u.signup_date,
+			(SELECT COUNT(*) FROM %[1]s.Rate WHERE user_id = u.id),
+			(SELECT COUNT(*) FROM %[1]s.Favorite WHERE user_id = u.id),
+			(SELECT COUNT(*) FROM %[1]s.Playlist WHERE user_id = u.id and visibility = '%[2]s')
+		FROM %[1]s.User u
+		WHERE u.id = %[3]d;
+		`,
+		r.schemaMap["movie"],
+		userDomain.VisibilityPublic,
+		userId,
+	)
+	r.logger.Debug(query)
+
+	var profile userDomain.Profile
+	var joinDate time.Time
+	err := r.Conn.QueryRowContext(ctx, query).Scan(&profile.Id, &profile.Nickname, &profile.Avatar, &profile.Bio, &joinDate,
+		&profile.RatingCount, &profile.FavoriteCount, &profile.PlaylistCount)
+	if err != nil {
+		return userDomain.Profile{}, err
+	}
+	profile.JoinDate = joinDate.Format("2006-01-02")
+	return profile, nil
+}
+
+// FindProfilesByIds reads the profiles of the users among userIds like
+// FindProfileById does, skipping ids that don't exist.
+func (r *mariaDBUserRepository) FindProfilesByIds(ctx context.Context, userIds []int) ([]userDomain.Profile, error) {
+	if len(userIds) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT u.id, u.nickname, u.avatar, u.bio, u.signup_date,
+			(SELECT COUNT(*) FROM %[1]s.Rate WHERE user_id = u.id),
+			(SELECT COUNT(*) FROM %[1]s.Favorite WHERE user_id = u.id),
+			(SELECT COUNT(*) FROM %[1]s.Playlist WHERE user_id = u.id and visibility = '%[2]s')
+		FROM %[1]s.User u
+		WHERE u.id IN (%[3]s);
+		`,
+		r.schemaMap["movie"],
+		userDomain.VisibilityPublic,
+		joinIds(userIds),
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var profiles []userDomain.Profile
+	for rows.Next() {
+		var profile userDomain.Profile
+		var joinDate time.Time
+		err = rows.Scan(&profile.Id, &profile.Nickname, &profile.Avatar, &profile.Bio, &joinDate,
+			&profile.RatingCount, &profile.FavoriteCount, &profile.PlaylistCount)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		profile.JoinDate = joinDate.Format("2006-01-02")
+		profiles = append(profiles, profile)
+	}
+	return profiles, rows.Err()
+}
+
+func (r *mariaDBUserRepository) UpdateBio(ctx context.Context, userId int, bio string) error {
+	query := fmt.Sprintf(`
+		UPDATE %s.User
+		SET bio = ?
+		WHERE id = %d;
+		`,
+		r.schemaMap["movie"],
+		userId,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query, bio)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *mariaDBUserRepository) UpdateAvatar(ctx context.Context, userId int, avatar string) error {
+	query := fmt.Sprintf(`
+		UPDATE %s.User
+		SET avatar = '%s'
+		WHERE id = %d;
+		`,
+		r.schemaMap["movie"],
+		avatar,
+		userId,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *mariaDBUserRepository) FindRatingDistribution(ctx context.Context, userId int) ([]userDomain.RatingBucket, error) {
+	query := fmt.Sprintf(`
+		SELECT rating, COUNT(*)
+		FROM %s.Rate
+		WHERE user_id = %d
+		GROUP BY rating
+		ORDER BY rating;
+		`,
+		r.schemaMap["movie"],
+		userId,
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var buckets []userDomain.RatingBucket
+	for rows.Next() {
+		var bucket userDomain.RatingBucket
+		err = rows.Scan(&bucket.Rating, &bucket.Count)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+// FindRatedGenres ranks the genres of the movies a user rated by how many of
+// them they rated, then by their average rating.
+func (r *mariaDBUserRepository) FindRatedGenres(ctx context.Context, userId int, limit int) ([]userDomain.GenreAffinity, error) {
+	query := fmt.Sprintf(`
+		SELECT g.id, g.name, COUNT(*), AVG(r.rating)
+		FROM %[1]s.Rate r
+		JOIN %[1]s.MovieGenre mg ON mg.movie_id = r.movie_id
+		JOIN %[1]s.Genre g ON g.id = mg.genre_id
+		WHERE r.user_id = %[2]d and r.type = '%[3]s'
+		GROUP BY g.id, g.name
+		ORDER BY COUNT(*) DESC, AVG(r.rating) DESC, g.id
+		LIMIT %[4]d;
+		`,
+		r.schemaMap["movie"],
+		userId,
+		movieDomain.MediaType,
+		limit,
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var genres []userDomain.GenreAffinity
+	for rows.Next() {
+		var genre userDomain.GenreAffinity
+		err = rows.Scan(&genre.Id, &genre.Name, &genre.RatedCount, &genre.AverageRating)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+
+	return genres, rows.Err()
+}
+
+// FindWatchedRuntime sums the runtime of every movie viewing in the user's
+// watch history. Series have no single runtime and aren't counted.
+func (r *mariaDBUserRepository) FindWatchedRuntime(ctx context.Context, userId int) (int, error) {
+	query := fmt.Sprintf(`
+		SELECT COALESCE(SUM(m.runtime), 0)
+		FROM %[1]s.WatchHistory h
+		JOIN %[1]s.Movie m ON m.id = h.media_id
+		WHERE h.user_id = %[2]d and h.type = '%[3]s';
+		`,
+		r.schemaMap["movie"],
+		userId,
+		movieDomain.MediaType,
+	)
+	r.logger.Debug(query)
+
+	var runtime int
+	err := r.Conn.QueryRowContext(ctx, query).Scan(&runtime)
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+	return runtime, nil
+}
+
 func (r *mariaDBUserRepository) FindIsFavorite(ctx context.Context, userId int, movieId int, mediaType string) (bool, error) {
 	query := fmt.Sprintf(`
 		SELECT user_id
@@ -238,6 +487,49 @@ func (r *mariaDBUserRepository) FindFavoriteByUserId(ctx context.Context, userId
 	return movies, nil
 }
 
+// FindFavoritesByUserIds reads the favorites of every user among userIds,
+// keyed by user id.
+func (r *mariaDBUserRepository) FindFavoritesByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Favorite, error) {
+	favorites := map[int][]userDomain.Favorite{}
+	if len(userIds) == 0 {
+		return favorites, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT user_id, movie_id, type
+		FROM %s.Favorite
+		WHERE user_id IN (%s);
+		`,
+		r.schemaMap["movie"],
+		joinIds(userIds),
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	for rows.Next() {
+		var userId int
+		var favorite userDomain.Favorite
+		err = rows.Scan(&userId, &favorite.Id, &favorite.Type)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		favorites[userId] = append(favorites[userId], favorite)
+	}
+	return favorites, rows.Err()
+}
+
 func (r *mariaDBUserRepository) InsertFavorite(ctx context.Context, userId int, movieId int, mediaType string) error {
 	query := fmt.Sprintf(`
 		INSERT INTO %s.Favorite (user_id, movie_id, type)
@@ -363,16 +655,25 @@ func (r *mariaDBUserRepository) FindRatingsByUserId(ctx context.Context, userId
 	return movieRatings, nil
 }
 
-func (r *mariaDBUserRepository) AllPlaylist(ctx context.Context) ([]userDomain.Playlist, error) {
+// FindRatingsByUserIds reads the ratings of every user among userIds, keyed
+// by user id.
+func (r *mariaDBUserRepository) FindRatingsByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Rate, error) {
+	ratings := map[int][]userDomain.Rate{}
+	if len(userIds) == 0 {
+		return ratings, nil
+	}
+
 	query := fmt.Sprintf(`
-		SELECT *
-		FROM %s.Playlist
+		SELECT user_id, movie_id, rating, type, apply_date
+		FROM %s.Rate
+		WHERE user_id IN (%s);
 		`,
 		r.schemaMap["movie"],
+		joinIds(userIds),
 	)
+	r.logger.Debug(query)
 
 	rows, err := r.Conn.QueryContext(ctx, query)
-
 	if err != nil {
 		r.logger.Error(err)
 		return nil, err
@@ -384,69 +685,113 @@ func (r *mariaDBUserRepository) AllPlaylist(ctx context.Context) ([]userDomain.P
 		}
 	}()
 
-	var playlists []userDomain.Playlist
 	for rows.Next() {
-		var playlist userDomain.Playlist
-		err = rows.Scan(&playlist.Id, &playlist.Name, &playlist.Playlist, &playlist.Type)
+		var userId int
+		var rating userDomain.Rate
+		err = rows.Scan(&userId, &rating.Id, &rating.Rating, &rating.Type, &rating.ApplyDate)
 		if err != nil {
 			r.logger.Error(err)
 			return nil, err
 		}
-		playlists = append(playlists, playlist)
+		ratings[userId] = append(ratings[userId], rating)
 	}
-
-	r.logger.Debug(query)
-	return playlists, nil
+	return ratings, rows.Err()
 }
 
-func (r *mariaDBUserRepository) InsertPlaylist(ctx context.Context, id int, name string, playlist string) error {
+const watchlistColumns = "media_id, type, state, watch_count, last_watched_at, added_at"
+
+func (r *mariaDBUserRepository) FindWatchlist(ctx context.Context, userId int, state string, offset int, limit int) ([]userDomain.WatchEntry, error) {
 	query := fmt.Sprintf(`
-		INSERT INTO %s.Playlist (id, name, list) VALUES (%d, '%s', '%s')
-		ON DUPLICATE KEY UPDATE name = VALUES(name), list = VALUES(list);
+		SELECT %s
+		FROM %s.Watchlist
+		WHERE user_id = %d and ('%s' = '' or state = '%s')
+		ORDER BY added_at DESC, media_id
+		LIMIT %d OFFSET %d;
 		`,
+		watchlistColumns,
 		r.schemaMap["movie"],
-		id,
-		name,
-		playlist,
+		userId,
+		state,
+		state,
+		limit,
+		offset,
 	)
 	r.logger.Debug(query)
 
-	_, err := r.Conn.ExecContext(ctx, query)
+	rows, err := r.Conn.QueryContext(ctx, query)
 	if err != nil {
 		r.logger.Error(err)
-		return err
+		return nil, err
 	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
 
-	return nil
+	var entries []userDomain.WatchEntry
+	for rows.Next() {
+		entry, err := scanWatchEntry(rows)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
 }
 
-func (r *mariaDBUserRepository) InsertPlaylist2(ctx context.Context, name string, playlist string, mediaType string) error {
+func (r *mariaDBUserRepository) FindWatchEntry(ctx context.Context, userId int, mediaId int, mediaType string) (userDomain.WatchEntry, error) {
 	query := fmt.Sprintf(`
-		INSERT INTO %s.Playlist (name, list, type) VALUES ('%s', '%s', '%s');
+		SELECT %s
+		FROM %s.Watchlist
+		WHERE user_id = %d and media_id = %d and type = '%s';
 		`,
+		watchlistColumns,
 		r.schemaMap["movie"],
-		name,
-		playlist,
+		userId,
+		mediaId,
 		mediaType,
 	)
 	r.logger.Debug(query)
 
-	_, err := r.Conn.ExecContext(ctx, query)
+	return scanWatchEntry(r.Conn.QueryRowContext(ctx, query))
+}
+
+func scanWatchEntry(row interface{ Scan(...interface{}) error }) (userDomain.WatchEntry, error) {
+	var entry userDomain.WatchEntry
+	var lastWatchedAt sql.NullTime
+	var addedAt time.Time
+	err := row.Scan(&entry.MediaId, &entry.Type, &entry.State, &entry.WatchCount, &lastWatchedAt, &addedAt)
 	if err != nil {
-		r.logger.Error(err)
-		return err
+		return userDomain.WatchEntry{}, err
 	}
 
-	return nil
+	if entry.WatchCount > 1 {
+		entry.RewatchCount = entry.WatchCount - 1
+	}
+	if lastWatchedAt.Valid {
+		entry.LastWatchedAt = lastWatchedAt.Time.Format(userDomain.WatchTimeLayout)
+	}
+	entry.AddedAt = addedAt.Format(userDomain.WatchTimeLayout)
+	return entry, nil
 }
 
-func (r *mariaDBUserRepository) DeletePlaylist(ctx context.Context, id int) error {
+// UpsertWantToWatch puts a title on the watchlist as want to watch. A title
+// that was already watched keeps its watch count, so wanting to see it again
+// doesn't lose its history.
+func (r *mariaDBUserRepository) UpsertWantToWatch(ctx context.Context, userId int, mediaId int, mediaType string) error {
 	query := fmt.Sprintf(`
-		DELETE FROM %s.Playlist
-		WHERE id = %d;
+		INSERT INTO %s.Watchlist (user_id, media_id, type, state, added_at) VALUES (%d, %d, '%s', '%s', now())
+		ON DUPLICATE KEY UPDATE state = VALUES(state);
 		`,
 		r.schemaMap["movie"],
-		id,
+		userId,
+		mediaId,
+		mediaType,
+		userDomain.WatchStateWant,
 	)
 	r.logger.Debug(query)
 
@@ -459,19 +804,964 @@ func (r *mariaDBUserRepository) DeletePlaylist(ctx context.Context, id int) erro
 	return nil
 }
 
-func (r *mariaDBUserRepository) AllBanner(ctx context.Context) ([]userDomain.Banner, error) {
-	query := fmt.Sprintf(`
-		SELECT *
-		FROM %s.Banner
-		`,
-		r.schemaMap["movie"],
-	)
-
-	rows, err := r.Conn.QueryContext(ctx, query)
+// InsertWatch records a viewing in the history and marks the title watched,
+// bumping its watch count. An empty watchedAt means now.
+func (r *mariaDBUserRepository) InsertWatch(ctx context.Context, userId int, mediaId int, mediaType string, watchedAt string) error {
+	when := "now()"
+	if watchedAt != "" {
+		when = "'" + watchedAt + "'"
+	}
 
+	tx, err := r.Conn.BeginTx(ctx, nil)
 	if err != nil {
 		r.logger.Error(err)
-		return nil, err
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s.WatchHistory (user_id, media_id, type, watched_at) VALUES (%d, %d, '%s', %s);
+		`,
+		r.schemaMap["movie"],
+		userId,
+		mediaId,
+		mediaType,
+		when,
+	)
+	r.logger.Debug(query)
+
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	query = fmt.Sprintf(`
+		INSERT INTO %s.Watchlist (user_id, media_id, type, state, watch_count, last_watched_at, added_at)
+		VALUES (%d, %d, '%s', '%s', 1, %s, now())
+		ON DUPLICATE KEY UPDATE state = VALUES(state), watch_count = watch_count + 1,
+			last_watched_at = GREATEST(COALESCE(last_watched_at, VALUES(last_watched_at)), VALUES(last_watched_at));
+		`,
+		r.schemaMap["movie"],
+		userId,
+		mediaId,
+		mediaType,
+		userDomain.WatchStateWatched,
+		when,
+	)
+	r.logger.Debug(query)
+
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteWatchEntry takes a title off the watchlist. Its watch history stays.
+func (r *mariaDBUserRepository) DeleteWatchEntry(ctx context.Context, userId int, mediaId int, mediaType string) error {
+	query := fmt.Sprintf(`
+		DELETE FROM %s.Watchlist
+		WHERE user_id = %d and media_id = %d and type = '%s';
+		`,
+		r.schemaMap["movie"],
+		userId,
+		mediaId,
+		mediaType,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *mariaDBUserRepository) FindWatchHistory(ctx context.Context, userId int, offset int, limit int) ([]userDomain.WatchEvent, error) {
+	query := fmt.Sprintf(`
+		SELECT id, media_id, type, watched_at
+		FROM %s.WatchHistory
+		WHERE user_id = %d
+		ORDER BY watched_at DESC, id DESC
+		LIMIT %d OFFSET %d;
+		`,
+		r.schemaMap["movie"],
+		userId,
+		limit,
+		offset,
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var events []userDomain.WatchEvent
+	for rows.Next() {
+		var event userDomain.WatchEvent
+		var watchedAt time.Time
+		err = rows.Scan(&event.Id, &event.MediaId, &event.Type, &watchedAt)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		event.WatchedAt = watchedAt.Format(userDomain.WatchTimeLayout)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *mariaDBUserRepository) queryPlaylists(ctx context.Context, query string) ([]userDomain.Playlist, error) {
+	rows, err := r.Conn.QueryContext(ctx, query)
+
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var playlists []userDomain.Playlist
+	for rows.Next() {
+		var playlist userDomain.Playlist
+		err = rows.Scan(&playlist.Id, &playlist.UserId, &playlist.Name, &playlist.Type, &playlist.Visibility, &playlist.Version,
+			&playlist.ShareSlug, &playlist.ViewCount, &playlist.ForkedFrom)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		playlists = append(playlists, playlist)
+	}
+
+	r.logger.Debug(query)
+	return playlists, nil
+}
+
+func (r *mariaDBUserRepository) FindPublicPlaylists(ctx context.Context) ([]userDomain.Playlist, error) {
+	query := fmt.Sprintf(`
+		SELECT id, user_id, name, type, visibility, version, COALESCE(share_slug, ''), view_count, forked_from
+		FROM %s.Playlist
+		WHERE visibility = '%s';
+		`,
+		r.schemaMap["movie"],
+		userDomain.VisibilityPublic,
+	)
+
+	return r.queryPlaylists(ctx, query)
+}
+
+func (r *mariaDBUserRepository) FindPlaylistsByUserId(ctx context.Context, userId int) ([]userDomain.Playlist, error) {
+	query := fmt.Sprintf(`
+		SELECT id, user_id, name, type, visibility, version, COALESCE(share_slug, ''), view_count, forked_from
+		FROM %s.Playlist
+		WHERE user_id = %d;
+		`,
+		r.schemaMap["movie"],
+		userId,
+	)
+
+	return r.queryPlaylists(ctx, query)
+}
+
+func (r *mariaDBUserRepository) ReadPlaylistById(ctx context.Context, id int) (userDomain.Playlist, error) {
+	query := fmt.Sprintf(`
+		SELECT id, user_id, name, type, visibility, version, COALESCE(share_slug, ''), view_count, forked_from
+		FROM %s.Playlist
+		WHERE id = %d;
+		`,
+		r.schemaMap["movie"],
+		id,
+	)
+
+	var playlist userDomain.Playlist
+	r.logger.Debug(query)
+	row := r.Conn.QueryRowContext(ctx, query)
+	err := row.Scan(&playlist.Id, &playlist.UserId, &playlist.Name, &playlist.Type, &playlist.Visibility, &playlist.Version,
+		&playlist.ShareSlug, &playlist.ViewCount, &playlist.ForkedFrom)
+
+	if err != nil {
+		return playlist, err
+	}
+	return playlist, nil
+}
+
+// FindPlaylistsByIds reads the playlists among ids, skipping ids that don't
+// exist.
+func (r *mariaDBUserRepository) FindPlaylistsByIds(ctx context.Context, ids []int) ([]userDomain.Playlist, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, user_id, name, type, visibility, version, COALESCE(share_slug, ''), view_count, forked_from
+		FROM %s.Playlist
+		WHERE id IN (%s);
+		`,
+		r.schemaMap["movie"],
+		joinIds(ids),
+	)
+
+	return r.queryPlaylists(ctx, query)
+}
+
+func (r *mariaDBUserRepository) InsertPlaylist(ctx context.Context, userId int, name string, mediaType string, visibility string) (int, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.Playlist (user_id, name, type, visibility) VALUES (%d, ?, '%s', '%s');
+		`,
+		r.schemaMap["movie"],
+		userId,
+		mediaType,
+		visibility,
+	)
+	r.logger.Debug(query)
+
+	result, err := r.Conn.ExecContext(ctx, query, name)
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+	return int(id), nil
+}
+
+func (r *mariaDBUserRepository) UpdatePlaylist(ctx context.Context, id int, name string, visibility string) error {
+	query := fmt.Sprintf(`
+		UPDATE %s.Playlist
+		SET name = ?, visibility = '%s'
+		WHERE id = %d;
+		`,
+		r.schemaMap["movie"],
+		visibility,
+		id,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query, name)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// DeletePlaylist removes the playlist together with its items, members and
+// change history.
+func (r *mariaDBUserRepository) DeletePlaylist(ctx context.Context, id int) error {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"PlaylistItem", "PlaylistMember", "PlaylistChange"} {
+		query := fmt.Sprintf(`
+			DELETE FROM %s.%s
+			WHERE playlist_id = %d;
+			`,
+			r.schemaMap["movie"],
+			table,
+			id,
+		)
+		r.logger.Debug(query)
+
+		_, err = tx.ExecContext(ctx, query)
+		if err != nil {
+			r.logger.Error(err)
+			return err
+		}
+	}
+
+	query := fmt.Sprintf(`
+		DELETE FROM %s.Playlist
+		WHERE id = %d;
+		`,
+		r.schemaMap["movie"],
+		id,
+	)
+	r.logger.Debug(query)
+
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *mariaDBUserRepository) ReadPlaylistByShareSlug(ctx context.Context, slug string) (userDomain.Playlist, error) {
+	query := fmt.Sprintf(`
+		SELECT id, user_id, name, type, visibility, version, COALESCE(share_slug, ''), view_count, forked_from
+		FROM %s.Playlist
+		WHERE share_slug = '%s';
+		`,
+		r.schemaMap["movie"],
+		slug,
+	)
+
+	var playlist userDomain.Playlist
+	r.logger.Debug(query)
+	row := r.Conn.QueryRowContext(ctx, query)
+	err := row.Scan(&playlist.Id, &playlist.UserId, &playlist.Name, &playlist.Type, &playlist.Visibility, &playlist.Version,
+		&playlist.ShareSlug, &playlist.ViewCount, &playlist.ForkedFrom)
+
+	if err != nil {
+		return playlist, err
+	}
+	return playlist, nil
+}
+
+// UpdatePlaylistShareSlug sets the share slug, or revokes it when slug is empty.
+func (r *mariaDBUserRepository) UpdatePlaylistShareSlug(ctx context.Context, id int, slug string) error {
+	value := "NULL"
+	if slug != "" {
+		value = fmt.Sprintf("'%s'", slug)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE %s.Playlist
+		SET share_slug = %s
+		WHERE id = %d;
+		`,
+		r.schemaMap["movie"],
+		value,
+		id,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *mariaDBUserRepository) IncrementPlaylistViewCount(ctx context.Context, id int) error {
+	query := fmt.Sprintf(`
+		UPDATE %s.Playlist
+		SET view_count = view_count + 1
+		WHERE id = %d;
+		`,
+		r.schemaMap["movie"],
+		id,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// ForkPlaylist copies the playlist and its items into a new private playlist
+// owned by userId and returns the new id.
+func (r *mariaDBUserRepository) ForkPlaylist(ctx context.Context, sourceId int, userId int, name string) (int, error) {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s.Playlist (user_id, name, type, visibility, forked_from)
+		SELECT %d, ?, type, '%s', id
+		FROM %s.Playlist
+		WHERE id = %d;
+		`,
+		r.schemaMap["movie"],
+		userId,
+		userDomain.VisibilityPrivate,
+		r.schemaMap["movie"],
+		sourceId,
+	)
+	r.logger.Debug(query)
+
+	result, err := tx.ExecContext(ctx, query, name)
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+
+	query = fmt.Sprintf(`
+		INSERT INTO %s.PlaylistItem (playlist_id, media_id, type, position, note, added_by, added_at)
+		SELECT %d, media_id, type, position, note, %d, now()
+		FROM %s.PlaylistItem
+		WHERE playlist_id = %d;
+		`,
+		r.schemaMap["movie"],
+		id,
+		userId,
+		r.schemaMap["movie"],
+		sourceId,
+	)
+	r.logger.Debug(query)
+
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+
+	return int(id), tx.Commit()
+}
+
+func (r *mariaDBUserRepository) FindPlaylistItems(ctx context.Context, playlistId int) ([]userDomain.PlaylistItem, error) {
+	query := fmt.Sprintf(`
+		SELECT id, playlist_id, media_id, type, position, note, added_by, added_at
+		FROM %s.PlaylistItem
+		WHERE playlist_id = %d
+		ORDER BY position, id;
+		`,
+		r.schemaMap["movie"],
+		playlistId,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var items []userDomain.PlaylistItem
+	for rows.Next() {
+		var item userDomain.PlaylistItem
+		err = rows.Scan(&item.Id, &item.PlaylistId, &item.MediaId, &item.Type, &item.Position, &item.Note, &item.AddedBy, &item.AddedAt)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	r.logger.Debug(query)
+	return items, nil
+}
+
+// FindPlaylistItemsByPlaylistIds reads the items of every playlist among
+// playlistIds in their order, keyed by playlist id.
+func (r *mariaDBUserRepository) FindPlaylistItemsByPlaylistIds(ctx context.Context, playlistIds []int) (map[int][]userDomain.PlaylistItem, error) {
+	items := map[int][]userDomain.PlaylistItem{}
+	if len(playlistIds) == 0 {
+		return items, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, playlist_id, media_id, type, position, note, added_by, added_at
+		FROM %s.PlaylistItem
+		WHERE playlist_id IN (%s)
+		ORDER BY playlist_id, position, id;
+		`,
+		r.schemaMap["movie"],
+		joinIds(playlistIds),
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	for rows.Next() {
+		var item userDomain.PlaylistItem
+		err = rows.Scan(&item.Id, &item.PlaylistId, &item.MediaId, &item.Type, &item.Position, &item.Note, &item.AddedBy, &item.AddedAt)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		items[item.PlaylistId] = append(items[item.PlaylistId], item)
+	}
+	return items, rows.Err()
+}
+
+func (r *mariaDBUserRepository) FindPlaylistsByMemberId(ctx context.Context, userId int) ([]userDomain.Playlist, error) {
+	query := fmt.Sprintf(`
+		SELECT p.id, p.user_id, p.name, p.type, p.visibility, p.version, COALESCE(p.share_slug, ''), p.view_count, p.forked_from
+		FROM %s.PlaylistMember pm
+		JOIN %s.Playlist p ON p.id = pm.playlist_id
+		WHERE pm.user_id = %d;
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		userId,
+	)
+
+	return r.queryPlaylists(ctx, query)
+}
+
+// withPlaylistChange runs fn in a transaction that also bumps the playlist
+// version and records the change. A non-zero version must match the current
+// one, otherwise ErrPlaylistVersionConflict is returned and nothing changes.
+// fn returns the item, media id and type the change applies to.
+func (r *mariaDBUserRepository) withPlaylistChange(ctx context.Context, playlistId int, version int, userId int, action string, fn func(tx *sql.Tx) (int, int, string, error)) error {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		UPDATE %s.Playlist
+		SET version = version + 1
+		WHERE id = %d and (%d = 0 or version = %d);
+		`,
+		r.schemaMap["movie"],
+		playlistId,
+		version,
+		version,
+	)
+	r.logger.Debug(query)
+
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+	if affected == 0 {
+		return userDomain.ErrPlaylistVersionConflict
+	}
+
+	itemId, mediaId, mediaType, err := fn(tx)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	query = fmt.Sprintf(`
+		INSERT INTO %s.PlaylistChange (playlist_id, user_id, action, item_id, media_id, type, version, created_at)
+		SELECT id, %d, '%s', %d, %d, '%s', version, now()
+		FROM %s.Playlist
+		WHERE id = %d;
+		`,
+		r.schemaMap["movie"],
+		userId,
+		action,
+		itemId,
+		mediaId,
+		mediaType,
+		r.schemaMap["movie"],
+		playlistId,
+	)
+	r.logger.Debug(query)
+
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// InsertPlaylistItem appends the item at the end of the playlist. The note is
+// free text, so it is bound rather than formatted into the query.
+func (r *mariaDBUserRepository) InsertPlaylistItem(ctx context.Context, playlistId int, version int, userId int, mediaId int, mediaType string, note string) error {
+	return r.withPlaylistChange(ctx, playlistId, version, userId, userDomain.PlaylistActionAdd, func(tx *sql.Tx) (int, int, string, error) {
+		query := fmt.Sprintf(`
+			INSERT INTO %s.PlaylistItem (playlist_id, media_id, type, position, note, added_by, added_at)
+			SELECT %d, %d, '%s', COALESCE(MAX(position), 0) + 1, ?, %d, now()
+			FROM %s.PlaylistItem
+			WHERE playlist_id = %d;
+			`,
+			r.schemaMap["movie"],
+			playlistId,
+			mediaId,
+			mediaType,
+			userId,
+			r.schemaMap["movie"],
+			playlistId,
+		)
+		r.logger.Debug(query)
+
+		result, err := tx.ExecContext(ctx, query, note)
+		if err != nil {
+			return 0, 0, "", err
+		}
+		itemId, err := result.LastInsertId()
+		return int(itemId), mediaId, mediaType, err
+	})
+}
+
+// DeletePlaylistItem removes the item and closes the gap it leaves in the
+// positions.
+func (r *mariaDBUserRepository) DeletePlaylistItem(ctx context.Context, playlistId int, version int, userId int, itemId int) error {
+	return r.withPlaylistChange(ctx, playlistId, version, userId, userDomain.PlaylistActionRemove, func(tx *sql.Tx) (int, int, string, error) {
+		query := fmt.Sprintf(`
+			SELECT media_id, type, position
+			FROM %s.PlaylistItem
+			WHERE playlist_id = %d and id = %d;
+			`,
+			r.schemaMap["movie"],
+			playlistId,
+			itemId,
+		)
+		r.logger.Debug(query)
+
+		var mediaId, position int
+		var mediaType string
+		err := tx.QueryRowContext(ctx, query).Scan(&mediaId, &mediaType, &position)
+		if err == sql.ErrNoRows {
+			return 0, 0, "", userDomain.ErrPlaylistItemNotFound
+		}
+		if err != nil {
+			return 0, 0, "", err
+		}
+
+		query = fmt.Sprintf(`
+			DELETE FROM %s.PlaylistItem
+			WHERE playlist_id = %d and id = %d;
+			`,
+			r.schemaMap["movie"],
+			playlistId,
+			itemId,
+		)
+		r.logger.Debug(query)
+
+		_, err = tx.ExecContext(ctx, query)
+		if err != nil {
+			return 0, 0, "", err
+		}
+
+		query = fmt.Sprintf(`
+			UPDATE %s.PlaylistItem
+			SET position = position - 1
+			WHERE playlist_id = %d and position > %d;
+			`,
+			r.schemaMap["movie"],
+			playlistId,
+			position,
+		)
+		r.logger.Debug(query)
+
+		_, err = tx.ExecContext(ctx, query)
+		return itemId, mediaId, mediaType, err
+	})
+}
+
+// UpdatePlaylistItemPositions numbers the given items 1..n in slice order.
+func (r *mariaDBUserRepository) UpdatePlaylistItemPositions(ctx context.Context, playlistId int, version int, userId int, itemIds []int) error {
+	return r.withPlaylistChange(ctx, playlistId, version, userId, userDomain.PlaylistActionMove, func(tx *sql.Tx) (int, int, string, error) {
+		for i, itemId := range itemIds {
+			query := fmt.Sprintf(`
+				UPDATE %s.PlaylistItem
+				SET position = %d
+				WHERE playlist_id = %d and id = %d;
+				`,
+				r.schemaMap["movie"],
+				i+1,
+				playlistId,
+				itemId,
+			)
+			r.logger.Debug(query)
+
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return 0, 0, "", err
+			}
+		}
+		return 0, 0, "", nil
+	})
+}
+
+func (r *mariaDBUserRepository) FindPlaylistChanges(ctx context.Context, playlistId int, limit int) ([]userDomain.PlaylistChange, error) {
+	query := fmt.Sprintf(`
+		SELECT id, playlist_id, user_id, action, item_id, media_id, type, version, created_at
+		FROM %s.PlaylistChange
+		WHERE playlist_id = %d
+		ORDER BY id DESC
+		LIMIT %d;
+		`,
+		r.schemaMap["movie"],
+		playlistId,
+		limit,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var changes []userDomain.PlaylistChange
+	for rows.Next() {
+		var change userDomain.PlaylistChange
+		err = rows.Scan(&change.Id, &change.PlaylistId, &change.UserId, &change.Action, &change.ItemId, &change.MediaId,
+			&change.Type, &change.Version, &change.CreatedAt)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	r.logger.Debug(query)
+	return changes, nil
+}
+
+func (r *mariaDBUserRepository) FindPlaylistMembers(ctx context.Context, playlistId int) ([]userDomain.PlaylistMember, error) {
+	query := fmt.Sprintf(`
+		SELECT pm.playlist_id, pm.user_id, u.nickname, pm.role, pm.invited_by, pm.added_at
+		FROM %s.PlaylistMember pm
+		JOIN %s.User u ON u.id = pm.user_id
+		WHERE pm.playlist_id = %d
+		ORDER BY pm.added_at;
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		playlistId,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var members []userDomain.PlaylistMember
+	for rows.Next() {
+		var member userDomain.PlaylistMember
+		err = rows.Scan(&member.PlaylistId, &member.UserId, &member.Nickname, &member.Role, &member.InvitedBy, &member.AddedAt)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	r.logger.Debug(query)
+	return members, nil
+}
+
+func (r *mariaDBUserRepository) FindPlaylistRole(ctx context.Context, playlistId int, userId int) (string, error) {
+	query := fmt.Sprintf(`
+		SELECT role
+		FROM %s.PlaylistMember
+		WHERE playlist_id = %d and user_id = %d;
+		`,
+		r.schemaMap["movie"],
+		playlistId,
+		userId,
+	)
+
+	var role string
+	r.logger.Debug(query)
+	row := r.Conn.QueryRowContext(ctx, query)
+	err := row.Scan(&role)
+
+	if err != nil {
+		return "", err
+	}
+	return role, nil
+}
+
+func (r *mariaDBUserRepository) UpsertPlaylistMember(ctx context.Context, playlistId int, userId int, role string, invitedBy int) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.PlaylistMember (playlist_id, user_id, role, invited_by, added_at)
+		VALUES (%d, %d, '%s', %d, now())
+		ON DUPLICATE KEY UPDATE role = VALUES(role);
+		`,
+		r.schemaMap["movie"],
+		playlistId,
+		userId,
+		role,
+		invitedBy,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *mariaDBUserRepository) DeletePlaylistMember(ctx context.Context, playlistId int, userId int) error {
+	query := fmt.Sprintf(`
+		DELETE FROM %s.PlaylistMember
+		WHERE playlist_id = %d and user_id = %d;
+		`,
+		r.schemaMap["movie"],
+		playlistId,
+		userId,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *mariaDBUserRepository) InsertFollow(ctx context.Context, followerId int, followeeId int) error {
+	query := fmt.Sprintf(`
+		INSERT IGNORE INTO %s.Follow (follower_id, followee_id, created_at) VALUES (%d, %d, now());
+		`,
+		r.schemaMap["movie"],
+		followerId,
+		followeeId,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *mariaDBUserRepository) DeleteFollow(ctx context.Context, followerId int, followeeId int) error {
+	query := fmt.Sprintf(`
+		DELETE FROM %s.Follow
+		WHERE follower_id = %d and followee_id = %d;
+		`,
+		r.schemaMap["movie"],
+		followerId,
+		followeeId,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *mariaDBUserRepository) FindIsFollowing(ctx context.Context, followerId int, followeeId int) (bool, error) {
+	query := fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1 FROM %s.Follow WHERE follower_id = %d and followee_id = %d
+		);
+		`,
+		r.schemaMap["movie"],
+		followerId,
+		followeeId,
+	)
+	r.logger.Debug(query)
+
+	var following bool
+	err := r.Conn.QueryRowContext(ctx, query).Scan(&following)
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+	return following, nil
+}
+
+func (r *mariaDBUserRepository) FindFollowing(ctx context.Context, userId int) ([]userDomain.FollowUser, error) {
+	query := fmt.Sprintf(`
+		SELECT u.id, u.nickname, f.created_at
+		FROM %s.Follow f
+		JOIN %s.User u ON u.id = f.followee_id
+		WHERE f.follower_id = %d
+		ORDER BY f.created_at DESC;
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		userId,
+	)
+
+	return r.queryFollowUsers(ctx, query)
+}
+
+func (r *mariaDBUserRepository) FindFollowers(ctx context.Context, userId int) ([]userDomain.FollowUser, error) {
+	query := fmt.Sprintf(`
+		SELECT u.id, u.nickname, f.created_at
+		FROM %s.Follow f
+		JOIN %s.User u ON u.id = f.follower_id
+		WHERE f.followee_id = %d
+		ORDER BY f.created_at DESC;
+		`,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		userId,
+	)
+
+	return r.queryFollowUsers(ctx, query)
+}
+
+func (r *mariaDBUserRepository) queryFollowUsers(ctx context.Context, query string) ([]userDomain.FollowUser, error) {
+	r.logger.Debug(query)
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
 	}
 	defer func() {
 		err := rows.Close()
@@ -480,32 +1770,50 @@ func (r *mariaDBUserRepository) AllBanner(ctx context.Context) ([]userDomain.Ban
 		}
 	}()
 
-	var banners []userDomain.Banner
+	var users []userDomain.FollowUser
 	for rows.Next() {
-		var banner userDomain.Banner
-		err = rows.Scan(&banner.Id, &banner.MovieId, &banner.Title, &banner.Type, &banner.Comment)
+		var user userDomain.FollowUser
+		var followedAt time.Time
+		err = rows.Scan(&user.Id, &user.Nickname, &followedAt)
 		if err != nil {
 			r.logger.Error(err)
 			return nil, err
 		}
-		banners = append(banners, banner)
+		user.FollowedAt = followedAt.Format(userDomain.WatchTimeLayout)
+		users = append(users, user)
 	}
 
+	return users, rows.Err()
+}
+
+func (r *mariaDBUserRepository) FindActivityVisibility(ctx context.Context, userId int) (string, error) {
+	query := fmt.Sprintf(`
+		SELECT activity_visibility
+		FROM %s.User
+		WHERE id = %d;
+		`,
+		r.schemaMap["movie"],
+		userId,
+	)
 	r.logger.Debug(query)
-	return banners, nil
+
+	var visibility string
+	err := r.Conn.QueryRowContext(ctx, query).Scan(&visibility)
+	if err != nil {
+		return "", err
+	}
+	return visibility, nil
 }
 
-func (r *mariaDBUserRepository) UpdateBanner(ctx context.Context, id int, movieId int, title string, mediaType string, comment string) error {
+func (r *mariaDBUserRepository) UpdateActivityVisibility(ctx context.Context, userId int, visibility string) error {
 	query := fmt.Sprintf(`
-		INSERT INTO %s.Banner (id, movie_id, title, type, comment) VALUES (%d, %d, '%s', '%s', '%s')
-		ON DUPLICATE KEY UPDATE movie_id = VALUES(movie_id), title = VALUES(title), type = VALUES(type), comment = VALUES(comment);
+		UPDATE %s.User
+		SET activity_visibility = '%s'
+		WHERE id = %d;
 		`,
 		r.schemaMap["movie"],
-		id,
-		movieId,
-		title,
-		mediaType,
-		comment,
+		visibility,
+		userId,
 	)
 	r.logger.Debug(query)
 
@@ -518,15 +1826,18 @@ func (r *mariaDBUserRepository) UpdateBanner(ctx context.Context, id int, movieI
 	return nil
 }
 
-func (r *mariaDBUserRepository) InsertBanner(ctx context.Context, movieId int, title string, mediaType string, comment string) error {
+func (r *mariaDBUserRepository) InsertActivityEvent(ctx context.Context, event userDomain.ActivityEvent) error {
 	query := fmt.Sprintf(`
-		INSERT INTO %s.Banner (movie_id, title, type, comment) VALUES (%d, '%s', '%s', '%s');
+		INSERT INTO %s.ActivityEvent (user_id, kind, media_id, type, rating, playlist_id, created_at)
+		VALUES (%d, '%s', %d, '%s', %d, %d, now());
 		`,
 		r.schemaMap["movie"],
-		movieId,
-		title,
-		mediaType,
-		comment,
+		event.UserId,
+		event.Kind,
+		event.MediaId,
+		event.Type,
+		event.Rating,
+		event.PlaylistId,
 	)
 	r.logger.Debug(query)
 
@@ -539,6 +1850,231 @@ func (r *mariaDBUserRepository) InsertBanner(ctx context.Context, movieId int, t
 	return nil
 }
 
+// activitySelect reads events with their author's nickname and playlist
+// name. Playlist events only show while the playlist is public.
+const activitySelect = `
+		SELECT e.id, e.user_id, u.nickname, e.kind, e.media_id, e.type, e.rating, e.playlist_id, COALESCE(p.name, ''), e.created_at
+		FROM %[1]s.ActivityEvent e
+		JOIN %[1]s.User u ON u.id = e.user_id
+		LEFT JOIN %[1]s.Playlist p ON p.id = e.playlist_id
+		`
+
+// FindFeed builds the feed on read from the activity of everyone userId
+// follows, skipping users who keep their activity private. before is the id
+// of the oldest event already seen, 0 for the first page.
+func (r *mariaDBUserRepository) FindFeed(ctx context.Context, userId int, before int, limit int) ([]userDomain.ActivityEvent, error) {
+	query := fmt.Sprintf(activitySelect, r.schemaMap["movie"]) + fmt.Sprintf(`
+		JOIN %s.Follow f ON f.followee_id = e.user_id
+		WHERE f.follower_id = %d and u.activity_visibility <> '%s'
+			and (e.playlist_id = 0 or p.visibility = '%s')
+			and (%d = 0 or e.id < %d)
+		ORDER BY e.id DESC
+		LIMIT %d;
+		`,
+		r.schemaMap["movie"],
+		userId,
+		userDomain.ActivityPrivate,
+		userDomain.VisibilityPublic,
+		before,
+		before,
+		limit,
+	)
+
+	return r.queryActivity(ctx, query)
+}
+
+// FindActivityByUserId lists one user's activity regardless of their
+// visibility setting; the caller decides who may see it.
+func (r *mariaDBUserRepository) FindActivityByUserId(ctx context.Context, userId int, before int, limit int) ([]userDomain.ActivityEvent, error) {
+	query := fmt.Sprintf(activitySelect, r.schemaMap["movie"]) + fmt.Sprintf(`
+		WHERE e.user_id = %d
+			and (e.playlist_id = 0 or p.visibility = '%s')
+			and (%d = 0 or e.id < %d)
+		ORDER BY e.id DESC
+		LIMIT %d;
+		`,
+		userId,
+		userDomain.VisibilityPublic,
+		before,
+		before,
+		limit,
+	)
+
+	return r.queryActivity(ctx, query)
+}
+
+func (r *mariaDBUserRepository) queryActivity(ctx context.Context, query string) ([]userDomain.ActivityEvent, error) {
+	r.logger.Debug(query)
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var events []userDomain.ActivityEvent
+	for rows.Next() {
+		var event userDomain.ActivityEvent
+		var createdAt time.Time
+		err = rows.Scan(&event.Id, &event.UserId, &event.Nickname, &event.Kind, &event.MediaId, &event.Type,
+			&event.Rating, &event.PlaylistId, &event.PlaylistName, &createdAt)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		event.CreatedAt = createdAt.Format(userDomain.WatchTimeLayout)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+const bannerColumns = "id, movie_id, title, type, comment, start_at, end_at, priority, audience, target_rank, locale, slot, weight"
+
+func (r *mariaDBUserRepository) AllBanner(ctx context.Context) ([]userDomain.Banner, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.Banner
+		ORDER BY priority DESC, id;
+		`,
+		bannerColumns,
+		r.schemaMap["movie"],
+	)
+
+	return r.queryBanners(ctx, query)
+}
+
+// FindActiveBanners returns the banners scheduled to show right now in the
+// given locale, highest priority first. Audience is left to the caller.
+func (r *mariaDBUserRepository) FindActiveBanners(ctx context.Context, locale string) ([]userDomain.Banner, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.Banner
+		WHERE (start_at IS NULL OR start_at <= NOW())
+			AND (end_at IS NULL OR end_at > NOW())
+			AND (locale = '' OR locale = '%s')
+		ORDER BY priority DESC, id;
+		`,
+		bannerColumns,
+		r.schemaMap["movie"],
+		locale,
+	)
+
+	return r.queryBanners(ctx, query)
+}
+
+func (r *mariaDBUserRepository) queryBanners(ctx context.Context, query string) ([]userDomain.Banner, error) {
+	r.logger.Debug(query)
+	rows, err := r.Conn.QueryContext(ctx, query)
+
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var banners []userDomain.Banner
+	for rows.Next() {
+		var banner userDomain.Banner
+		var startAt, endAt sql.NullTime
+		err = rows.Scan(&banner.Id, &banner.MovieId, &banner.Title, &banner.Type, &banner.Comment,
+			&startAt, &endAt, &banner.Priority, &banner.Audience, &banner.TargetRank, &banner.Locale,
+			&banner.Slot, &banner.Weight)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		if startAt.Valid {
+			banner.StartAt = startAt.Time.Format(userDomain.BannerTimeLayout)
+		}
+		if endAt.Valid {
+			banner.EndAt = endAt.Time.Format(userDomain.BannerTimeLayout)
+		}
+		banners = append(banners, banner)
+	}
+
+	return banners, rows.Err()
+}
+
+// bannerTime renders an optional schedule bound as a SQL literal.
+func bannerTime(t string) string {
+	if t == "" {
+		return "NULL"
+	}
+	return "'" + t + "'"
+}
+
+func (r *mariaDBUserRepository) UpdateBanner(ctx context.Context, banner userDomain.Banner) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.Banner (%s)
+		VALUES (%d, %d, ?, '%s', ?, %s, %s, %d, '%s', ?, ?, ?, %d)
+		ON DUPLICATE KEY UPDATE movie_id = VALUES(movie_id), title = VALUES(title), type = VALUES(type), comment = VALUES(comment),
+			start_at = VALUES(start_at), end_at = VALUES(end_at), priority = VALUES(priority),
+			audience = VALUES(audience), target_rank = VALUES(target_rank), locale = VALUES(locale),
+			slot = VALUES(slot), weight = VALUES(weight);
+		`,
+		r.schemaMap["movie"],
+		bannerColumns,
+		banner.Id,
+		banner.MovieId,
+		banner.Type,
+		bannerTime(banner.StartAt),
+		bannerTime(banner.EndAt),
+		banner.Priority,
+		banner.Audience,
+		banner.Weight,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query, banner.Title, banner.Comment, banner.TargetRank, banner.Locale, banner.Slot)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *mariaDBUserRepository) InsertBanner(ctx context.Context, banner userDomain.Banner) (int, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.Banner (movie_id, title, type, comment, start_at, end_at, priority, audience, target_rank, locale, slot, weight)
+		VALUES (%d, ?, '%s', ?, %s, %s, %d, '%s', ?, ?, ?, %d);
+		`,
+		r.schemaMap["movie"],
+		banner.MovieId,
+		banner.Type,
+		bannerTime(banner.StartAt),
+		bannerTime(banner.EndAt),
+		banner.Priority,
+		banner.Audience,
+		banner.Weight,
+	)
+	r.logger.Debug(query)
+
+	result, err := r.Conn.ExecContext(ctx, query, banner.Title, banner.Comment, banner.TargetRank, banner.Locale, banner.Slot)
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+	return int(id), nil
+}
+
 func (r *mariaDBUserRepository) DeleteBanner(ctx context.Context, id int) error {
 	query := fmt.Sprintf(`
 		DELETE FROM %s.Banner
@@ -557,3 +2093,142 @@ func (r *mariaDBUserRepository) DeleteBanner(ctx context.Context, id int) error
 
 	return nil
 }
+
+func (r *mariaDBUserRepository) ReadBannerById(ctx context.Context, id int) (userDomain.Banner, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.Banner
+		WHERE id = %d;
+		`,
+		bannerColumns,
+		r.schemaMap["movie"],
+		id,
+	)
+
+	banners, err := r.queryBanners(ctx, query)
+	if err != nil {
+		return userDomain.Banner{}, err
+	}
+	if len(banners) == 0 {
+		return userDomain.Banner{}, sql.ErrNoRows
+	}
+	return banners[0], nil
+}
+
+func (r *mariaDBUserRepository) FindBannerAssignment(ctx context.Context, slot string, subject string) (int, error) {
+	query := fmt.Sprintf(`
+		SELECT banner_id
+		FROM %s.BannerAssignment
+		WHERE slot = '%s' and subject = '%s';
+		`,
+		r.schemaMap["movie"],
+		slot,
+		subject,
+	)
+
+	var bannerId int
+	r.logger.Debug(query)
+	err := r.Conn.QueryRowContext(ctx, query).Scan(&bannerId)
+	if err != nil {
+		return 0, err
+	}
+	return bannerId, nil
+}
+
+func (r *mariaDBUserRepository) UpsertBannerAssignment(ctx context.Context, slot string, subject string, bannerId int) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.BannerAssignment (slot, subject, banner_id, assigned_at) VALUES ('%s', '%s', %d, now())
+		ON DUPLICATE KEY UPDATE banner_id = VALUES(banner_id), assigned_at = VALUES(assigned_at);
+		`,
+		r.schemaMap["movie"],
+		slot,
+		subject,
+		bannerId,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *mariaDBUserRepository) InsertBannerEvent(ctx context.Context, bannerId int, subject string, kind string) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.BannerEvent (banner_id, subject, kind, created_at) VALUES (%d, '%s', '%s', now());
+		`,
+		r.schemaMap["movie"],
+		bannerId,
+		subject,
+		kind,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// FindBannerSlotStats counts impressions and clicks for every variant in a
+// slot, oldest variant first. Rates and significance are left to the caller.
+func (r *mariaDBUserRepository) FindBannerSlotStats(ctx context.Context, slot string) ([]userDomain.BannerVariantStats, error) {
+	query := fmt.Sprintf(`
+		SELECT b.id, b.title, b.weight,
+			COALESCE(SUM(e.kind = '%s'), 0),
+			COALESCE(SUM(e.kind = '%s'), 0)
+		FROM %s.Banner b
+		LEFT JOIN %s.BannerEvent e ON e.banner_id = b.id
+		WHERE b.slot = '%s'
+		GROUP BY b.id, b.title, b.weight
+		ORDER BY b.id;
+		`,
+		userDomain.BannerEventImpression,
+		userDomain.BannerEventClick,
+		r.schemaMap["movie"],
+		r.schemaMap["movie"],
+		slot,
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var stats []userDomain.BannerVariantStats
+	for rows.Next() {
+		var variant userDomain.BannerVariantStats
+		err = rows.Scan(&variant.BannerId, &variant.Title, &variant.Weight, &variant.Impressions, &variant.Clicks)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		stats = append(stats, variant)
+	}
+
+	return stats, rows.Err()
+}
+
+// joinIds renders ids for an IN list. They are integers, so they can be
+// formatted into the query.
+func joinIds(ids []int) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.Itoa(id))
+	}
+	return strings.Join(parts, ", ")
+}
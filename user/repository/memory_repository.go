@@ -0,0 +1,1417 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	userDomain "github.com/null-like/movie-backend/domain/user"
+	"github.com/null-like/movie-backend/movie"
+	"github.com/null-like/movie-backend/user"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errDuplicateEntry is returned where the database would reject a row for
+// breaking a unique key.
+var errDuplicateEntry = errors.New("duplicate entry")
+
+type memoryUser struct {
+	userDomain.AllUserInfo
+	password           string
+	avatar             string
+	bio                string
+	activityVisibility string
+	signupDate         time.Time
+}
+
+// mediaKey is the (user, media, type) key shared by favorites, ratings and
+// the watchlist.
+type mediaKey struct {
+	userId    int
+	mediaId   int
+	mediaType string
+}
+
+type memoryRating struct {
+	rating    int
+	applyDate time.Time
+}
+
+type memoryWatchEntry struct {
+	state         string
+	watchCount    int
+	lastWatchedAt time.Time // zero while never watched
+	addedAt       time.Time
+}
+
+type memoryWatchEvent struct {
+	id        int
+	key       mediaKey
+	watchedAt time.Time
+}
+
+type memoryPlaylistItem struct {
+	userDomain.PlaylistItem
+	addedAt time.Time
+}
+
+type memoryPlaylistMember struct {
+	role      string
+	invitedBy int
+	addedAt   time.Time
+}
+
+type memoryActivityEvent struct {
+	userDomain.ActivityEvent
+	createdAt time.Time
+}
+
+type memoryBannerEvent struct {
+	bannerId int
+	subject  string
+	kind     string
+}
+
+// memoryUserRepository keeps user data in maps shaped like the MariaDB
+// tables. Movie genres and runtimes are read from movies, which stands in
+// for the catalog tables the database would join.
+type memoryUserRepository struct {
+	movies movie.Repository
+
+	mu sync.RWMutex
+
+	users        map[int]*memoryUser
+	favorites    map[mediaKey]bool
+	ratings      map[mediaKey]memoryRating
+	watchlist    map[mediaKey]*memoryWatchEntry
+	history      []memoryWatchEvent
+	playlists    map[int]*userDomain.Playlist
+	items        map[int]*memoryPlaylistItem
+	members      map[[2]int]*memoryPlaylistMember // by playlist and user id
+	changes      []userDomain.PlaylistChange
+	follows      map[[2]int]time.Time // by follower and followee id
+	events       []memoryActivityEvent
+	banners      map[int]userDomain.Banner
+	assignments  map[[2]string]int // by slot and subject
+	bannerEvents []memoryBannerEvent
+	lastId       map[string]int // AUTO_INCREMENT counters by table
+}
+
+// NewMemoryUserRepository returns an empty user store held in memory. It
+// answers like the MariaDB repository, missing rows and duplicate keys
+// included, and is safe for concurrent use.
+func NewMemoryUserRepository(movies movie.Repository) user.Repository {
+	return &memoryUserRepository{
+		movies:      movies,
+		users:       make(map[int]*memoryUser),
+		favorites:   make(map[mediaKey]bool),
+		ratings:     make(map[mediaKey]memoryRating),
+		watchlist:   make(map[mediaKey]*memoryWatchEntry),
+		playlists:   make(map[int]*userDomain.Playlist),
+		items:       make(map[int]*memoryPlaylistItem),
+		members:     make(map[[2]int]*memoryPlaylistMember),
+		follows:     make(map[[2]int]time.Time),
+		banners:     make(map[int]userDomain.Banner),
+		assignments: make(map[[2]string]int),
+		lastId:      make(map[string]int),
+	}
+}
+
+// now is the current time as a DATETIME column keeps it.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// datetimeString renders a DATETIME scanned into a string, as database/sql
+// does for the columns the MariaDB repository reads that way.
+func datetimeString(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// nextId hands out the next AUTO_INCREMENT id of table. The caller holds
+// the write lock.
+func (r *memoryUserRepository) nextId(table string) int {
+	r.lastId[table]++
+	return r.lastId[table]
+}
+
+func (r *memoryUserRepository) InsertUser(ctx context.Context, u userDomain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.userByEmail(u.Email) != nil {
+		return fmt.Errorf("%w: %s", userDomain.ErrEmailTaken, u.Email)
+	}
+
+	id := r.nextId("User")
+	r.users[id] = &memoryUser{
+		AllUserInfo:        userDomain.AllUserInfo{Id: id, Email: u.Email, Nickname: u.Nickname, Rank: "회원"},
+		password:           u.Password,
+		activityVisibility: userDomain.ActivityPublic,
+		signupDate:         now(),
+	}
+	return nil
+}
+
+// userByEmail finds a user the way the case-insensitive email column
+// compares. The caller holds the lock.
+func (r *memoryUserRepository) userByEmail(email string) *memoryUser {
+	for _, u := range r.users {
+		if strings.EqualFold(u.Email, email) {
+			return u
+		}
+	}
+	return nil
+}
+
+// userInfo is the row FindAllUser and FindUserById read.
+func (u *memoryUser) userInfo() userDomain.AllUserInfo {
+	info := u.AllUserInfo
+	info.SignUpDate = datetimeString(u.signupDate)
+	return info
+}
+
+func (r *memoryUserRepository) FindAllUser(ctx context.Context) ([]userDomain.AllUserInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users []userDomain.AllUserInfo
+	for _, u := range r.users {
+		users = append(users, u.userInfo())
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Id < users[j].Id
+	})
+	return users, nil
+}
+
+func (r *memoryUserRepository) DeleteUser(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.users, id)
+	return nil
+}
+
+func (r *memoryUserRepository) UpdateUser(ctx context.Context, id int, rank string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if u, ok := r.users[id]; ok {
+		u.Rank = rank
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) FindIdByEmail(ctx context.Context, email string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.userByEmail(email) != nil, nil
+}
+
+func (r *memoryUserRepository) FindIdAndPasswdByEmail(ctx context.Context, email string) (int, string, string, string, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u := r.userByEmail(email)
+	if u == nil {
+		return -1, email, "", "", "", sql.ErrNoRows
+	}
+	return u.Id, email, u.password, u.Nickname, u.Rank, nil
+}
+
+func (r *memoryUserRepository) FindNicknameByUserId(ctx context.Context, userId int) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[userId]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return u.Nickname, nil
+}
+
+func (r *memoryUserRepository) FindUserById(ctx context.Context, userId int) (userDomain.AllUserInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[userId]
+	if !ok {
+		return userDomain.AllUserInfo{}, sql.ErrNoRows
+	}
+	return u.userInfo(), nil
+}
+
+func (r *memoryUserRepository) FindProfileById(ctx context.Context, userId int) (userDomain.Profile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	profile, ok := r.profileOf(userId)
+	if !ok {
+		return userDomain.Profile{}, sql.ErrNoRows
+	}
+	return profile, nil
+}
+
+func (r *memoryUserRepository) FindProfilesByIds(ctx context.Context, userIds []int) ([]userDomain.Profile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var profiles []userDomain.Profile
+	for _, userId := range userIds {
+		if profile, ok := r.profileOf(userId); ok {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles, nil
+}
+
+// profileOf builds the profile FindProfileById reads. The caller holds the
+// read lock.
+func (r *memoryUserRepository) profileOf(userId int) (userDomain.Profile, bool) {
+	u, ok := r.users[userId]
+	if !ok {
+		return userDomain.Profile{}, false
+	}
+
+	profile := userDomain.Profile{
+		Id:       u.Id,
+		Nickname: u.Nickname,
+		Avatar:   u.avatar,
+		Bio:      u.bio,
+		JoinDate: u.signupDate.Format("2006-01-02"),
+	}
+	for key := range r.ratings {
+		if key.userId == userId {
+			profile.RatingCount++
+		}
+	}
+	for key := range r.favorites {
+		if key.userId == userId {
+			profile.FavoriteCount++
+		}
+	}
+	for _, p := range r.playlists {
+		if p.UserId == userId && p.Visibility == userDomain.VisibilityPublic {
+			profile.PlaylistCount++
+		}
+	}
+	return profile, true
+}
+
+func (r *memoryUserRepository) UpdateBio(ctx context.Context, userId int, bio string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if u, ok := r.users[userId]; ok {
+		u.bio = bio
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) UpdateAvatar(ctx context.Context, userId int, avatar string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if u, ok := r.users[userId]; ok {
+		u.avatar = avatar
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) FindRatingDistribution(ctx context.Context, userId int) ([]userDomain.RatingBucket, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[int]int)
+	for key, rating := range r.ratings {
+		if key.userId == userId {
+			counts[rating.rating]++
+		}
+	}
+
+	var buckets []userDomain.RatingBucket
+	for rating, count := range counts {
+		buckets = append(buckets, userDomain.RatingBucket{Rating: rating, Count: count})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Rating < buckets[j].Rating
+	})
+	return buckets, nil
+}
+
+func (r *memoryUserRepository) FindRatedGenres(ctx context.Context, userId int, limit int) ([]userDomain.GenreAffinity, error) {
+	r.mu.RLock()
+	rated := make(map[int]int)
+	var movieIds []int
+	for key, rating := range r.ratings {
+		if key.userId == userId && key.mediaType == movieDomain.MediaType {
+			rated[key.mediaId] = rating.rating
+			movieIds = append(movieIds, key.mediaId)
+		}
+	}
+	r.mu.RUnlock()
+
+	movies, err := r.movies.FindMoviesByIds(ctx, movieIds)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[int]*userDomain.GenreAffinity)
+	sums := make(map[int]int)
+	for _, m := range movies {
+		for _, g := range m.Genres {
+			genre, ok := byId[g.Id]
+			if !ok {
+				genre = &userDomain.GenreAffinity{Id: g.Id, Name: g.Name}
+				byId[g.Id] = genre
+			}
+			genre.RatedCount++
+			sums[g.Id] += rated[m.Id]
+		}
+	}
+
+	var genres []userDomain.GenreAffinity
+	for id, genre := range byId {
+		// AVG over an integer column keeps four decimals.
+		genre.AverageRating = math.Round(float64(sums[id])/float64(genre.RatedCount)*1e4) / 1e4
+		genres = append(genres, *genre)
+	}
+	sort.Slice(genres, func(i, j int) bool {
+		a, b := genres[i], genres[j]
+		if a.RatedCount != b.RatedCount {
+			return a.RatedCount > b.RatedCount
+		}
+		if a.AverageRating != b.AverageRating {
+			return a.AverageRating > b.AverageRating
+		}
+		return a.Id < b.Id
+	})
+	return paginate(genres, 0, limit), nil
+}
+
+func (r *memoryUserRepository) FindWatchedRuntime(ctx context.Context, userId int) (int, error) {
+	r.mu.RLock()
+	viewings := make(map[int]int)
+	var movieIds []int
+	for _, event := range r.history {
+		if event.key.userId == userId && event.key.mediaType == movieDomain.MediaType {
+			if viewings[event.key.mediaId] == 0 {
+				movieIds = append(movieIds, event.key.mediaId)
+			}
+			viewings[event.key.mediaId]++
+		}
+	}
+	r.mu.RUnlock()
+
+	movies, err := r.movies.FindMoviesByIds(ctx, movieIds)
+	if err != nil {
+		return 0, err
+	}
+
+	runtime := 0
+	for _, m := range movies {
+		runtime += m.Runtime * viewings[m.Id]
+	}
+	return runtime, nil
+}
+
+func (r *memoryUserRepository) FindIsFavorite(ctx context.Context, userId int, movieId int, mediaType string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.favorites[mediaKey{userId, movieId, mediaType}] {
+		return false, sql.ErrNoRows
+	}
+	return true, nil
+}
+
+// keysOf lists a user's keys in primary key order.
+func keysOf[V any](rows map[mediaKey]V, userId int) []mediaKey {
+	var keys []mediaKey
+	for key := range rows {
+		if key.userId == userId {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].mediaId != keys[j].mediaId {
+			return keys[i].mediaId < keys[j].mediaId
+		}
+		return keys[i].mediaType < keys[j].mediaType
+	})
+	return keys
+}
+
+func (r *memoryUserRepository) FindFavoriteByUserId(ctx context.Context, userId int) ([]userDomain.Favorite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.favoritesOf(userId), nil
+}
+
+func (r *memoryUserRepository) FindFavoritesByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Favorite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	favorites := map[int][]userDomain.Favorite{}
+	for _, userId := range userIds {
+		if f := r.favoritesOf(userId); f != nil {
+			favorites[userId] = f
+		}
+	}
+	return favorites, nil
+}
+
+// favoritesOf reads one user's favorites. The caller holds the read lock.
+func (r *memoryUserRepository) favoritesOf(userId int) []userDomain.Favorite {
+	var favorites []userDomain.Favorite
+	for _, key := range keysOf(r.favorites, userId) {
+		favorites = append(favorites, userDomain.Favorite{Id: key.mediaId, Type: key.mediaType})
+	}
+	return favorites
+}
+
+func (r *memoryUserRepository) InsertFavorite(ctx context.Context, userId int, movieId int, mediaType string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := mediaKey{userId, movieId, mediaType}
+	if r.favorites[key] {
+		return fmt.Errorf("%w: favorite %d %s", errDuplicateEntry, movieId, mediaType)
+	}
+	r.favorites[key] = true
+	return nil
+}
+
+func (r *memoryUserRepository) DeleteFavorite(ctx context.Context, userId int, movieId int, mediaType string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.favorites, mediaKey{userId, movieId, mediaType})
+	return nil
+}
+
+// InsertRating rates a title, replacing an earlier rating of it.
+func (r *memoryUserRepository) InsertRating(ctx context.Context, userId int, movieId int, rating int, mediaType string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ratings[mediaKey{userId, movieId, mediaType}] = memoryRating{rating: rating, applyDate: now()}
+	return nil
+}
+
+func (r *memoryUserRepository) FindRatingByMovieId(ctx context.Context, userId int, movieId int, mediaType string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rating, ok := r.ratings[mediaKey{userId, movieId, mediaType}]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	return rating.rating, nil
+}
+
+func (r *memoryUserRepository) FindRatingsByUserId(ctx context.Context, userId int) ([]userDomain.Rate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.ratingsOf(userId), nil
+}
+
+func (r *memoryUserRepository) FindRatingsByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Rate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ratings := map[int][]userDomain.Rate{}
+	for _, userId := range userIds {
+		if rates := r.ratingsOf(userId); rates != nil {
+			ratings[userId] = rates
+		}
+	}
+	return ratings, nil
+}
+
+// ratingsOf reads one user's ratings. The caller holds the read lock.
+func (r *memoryUserRepository) ratingsOf(userId int) []userDomain.Rate {
+	var ratings []userDomain.Rate
+	for _, key := range keysOf(r.ratings, userId) {
+		rating := r.ratings[key]
+		ratings = append(ratings, userDomain.Rate{
+			Id:        key.mediaId,
+			Rating:    rating.rating,
+			Type:      key.mediaType,
+			ApplyDate: datetimeString(rating.applyDate),
+		})
+	}
+	return ratings
+}
+
+func watchEntry(key mediaKey, e *memoryWatchEntry) userDomain.WatchEntry {
+	entry := userDomain.WatchEntry{
+		MediaId:    key.mediaId,
+		Type:       key.mediaType,
+		State:      e.state,
+		WatchCount: e.watchCount,
+		AddedAt:    e.addedAt.Format(userDomain.WatchTimeLayout),
+	}
+	if entry.WatchCount > 1 {
+		entry.RewatchCount = entry.WatchCount - 1
+	}
+	if !e.lastWatchedAt.IsZero() {
+		entry.LastWatchedAt = e.lastWatchedAt.Format(userDomain.WatchTimeLayout)
+	}
+	return entry
+}
+
+func (r *memoryUserRepository) FindWatchlist(ctx context.Context, userId int, state string, offset int, limit int) ([]userDomain.WatchEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var keys []mediaKey
+	for key, e := range r.watchlist {
+		if key.userId == userId && (state == "" || e.state == state) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := r.watchlist[keys[i]], r.watchlist[keys[j]]
+		if !a.addedAt.Equal(b.addedAt) {
+			return a.addedAt.After(b.addedAt)
+		}
+		if keys[i].mediaId != keys[j].mediaId {
+			return keys[i].mediaId < keys[j].mediaId
+		}
+		return keys[i].mediaType < keys[j].mediaType
+	})
+
+	var entries []userDomain.WatchEntry
+	for _, key := range paginate(keys, offset, limit) {
+		entries = append(entries, watchEntry(key, r.watchlist[key]))
+	}
+	return entries, nil
+}
+
+func (r *memoryUserRepository) FindWatchEntry(ctx context.Context, userId int, mediaId int, mediaType string) (userDomain.WatchEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key := mediaKey{userId, mediaId, mediaType}
+	e, ok := r.watchlist[key]
+	if !ok {
+		return userDomain.WatchEntry{}, sql.ErrNoRows
+	}
+	return watchEntry(key, e), nil
+}
+
+func (r *memoryUserRepository) UpsertWantToWatch(ctx context.Context, userId int, mediaId int, mediaType string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := mediaKey{userId, mediaId, mediaType}
+	if e, ok := r.watchlist[key]; ok {
+		e.state = userDomain.WatchStateWant
+		return nil
+	}
+	r.watchlist[key] = &memoryWatchEntry{state: userDomain.WatchStateWant, addedAt: now()}
+	return nil
+}
+
+func (r *memoryUserRepository) InsertWatch(ctx context.Context, userId int, mediaId int, mediaType string, watchedAt string) error {
+	when := now()
+	if watchedAt != "" {
+		t, err := time.ParseInLocation(userDomain.WatchTimeLayout, watchedAt, time.UTC)
+		if err != nil {
+			return err
+		}
+		when = t
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := mediaKey{userId, mediaId, mediaType}
+	r.history = append(r.history, memoryWatchEvent{id: r.nextId("WatchHistory"), key: key, watchedAt: when})
+
+	e, ok := r.watchlist[key]
+	if !ok {
+		e = &memoryWatchEntry{addedAt: now()}
+		r.watchlist[key] = e
+	}
+	e.state = userDomain.WatchStateWatched
+	e.watchCount++
+	if when.After(e.lastWatchedAt) {
+		e.lastWatchedAt = when
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) DeleteWatchEntry(ctx context.Context, userId int, mediaId int, mediaType string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.watchlist, mediaKey{userId, mediaId, mediaType})
+	return nil
+}
+
+func (r *memoryUserRepository) FindWatchHistory(ctx context.Context, userId int, offset int, limit int) ([]userDomain.WatchEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var rows []memoryWatchEvent
+	for _, event := range r.history {
+		if event.key.userId == userId {
+			rows = append(rows, event)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].watchedAt.Equal(rows[j].watchedAt) {
+			return rows[i].watchedAt.After(rows[j].watchedAt)
+		}
+		return rows[i].id > rows[j].id
+	})
+
+	var events []userDomain.WatchEvent
+	for _, row := range paginate(rows, offset, limit) {
+		events = append(events, userDomain.WatchEvent{
+			Id:        row.id,
+			MediaId:   row.key.mediaId,
+			Type:      row.key.mediaType,
+			WatchedAt: row.watchedAt.Format(userDomain.WatchTimeLayout),
+		})
+	}
+	return events, nil
+}
+
+// playlistsWhere lists the playlists matching keep in id order, without
+// their items. The caller holds the lock.
+func (r *memoryUserRepository) playlistsWhere(keep func(p *userDomain.Playlist) bool) []userDomain.Playlist {
+	var playlists []userDomain.Playlist
+	for _, p := range r.playlists {
+		if keep(p) {
+			playlists = append(playlists, *p)
+		}
+	}
+	sort.Slice(playlists, func(i, j int) bool {
+		return playlists[i].Id < playlists[j].Id
+	})
+	return playlists
+}
+
+func (r *memoryUserRepository) FindPublicPlaylists(ctx context.Context) ([]userDomain.Playlist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.playlistsWhere(func(p *userDomain.Playlist) bool { return p.Visibility == userDomain.VisibilityPublic }), nil
+}
+
+func (r *memoryUserRepository) FindPlaylistsByUserId(ctx context.Context, userId int) ([]userDomain.Playlist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.playlistsWhere(func(p *userDomain.Playlist) bool { return p.UserId == userId }), nil
+}
+
+func (r *memoryUserRepository) FindPlaylistsByMemberId(ctx context.Context, userId int) ([]userDomain.Playlist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.playlistsWhere(func(p *userDomain.Playlist) bool { return r.members[[2]int{p.Id, userId}] != nil }), nil
+}
+
+func (r *memoryUserRepository) ReadPlaylistById(ctx context.Context, id int) (userDomain.Playlist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.playlists[id]
+	if !ok {
+		return userDomain.Playlist{}, sql.ErrNoRows
+	}
+	return *p, nil
+}
+
+func (r *memoryUserRepository) FindPlaylistsByIds(ctx context.Context, ids []int) ([]userDomain.Playlist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return r.playlistsWhere(func(p *userDomain.Playlist) bool { return wanted[p.Id] }), nil
+}
+
+func (r *memoryUserRepository) InsertPlaylist(ctx context.Context, userId int, name string, mediaType string, visibility string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextId("Playlist")
+	r.playlists[id] = &userDomain.Playlist{Id: id, UserId: userId, Name: name, Type: mediaType, Visibility: visibility, Version: 1}
+	return id, nil
+}
+
+func (r *memoryUserRepository) UpdatePlaylist(ctx context.Context, id int, name string, visibility string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p, ok := r.playlists[id]; ok {
+		p.Name = name
+		p.Visibility = visibility
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) DeletePlaylist(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for itemId, item := range r.items {
+		if item.PlaylistId == id {
+			delete(r.items, itemId)
+		}
+	}
+	for key := range r.members {
+		if key[0] == id {
+			delete(r.members, key)
+		}
+	}
+	changes := r.changes[:0]
+	for _, change := range r.changes {
+		if change.PlaylistId != id {
+			changes = append(changes, change)
+		}
+	}
+	r.changes = changes
+	delete(r.playlists, id)
+	return nil
+}
+
+func (r *memoryUserRepository) ReadPlaylistByShareSlug(ctx context.Context, slug string) (userDomain.Playlist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, p := range r.playlists {
+		if slug != "" && p.ShareSlug == slug {
+			return *p, nil
+		}
+	}
+	return userDomain.Playlist{}, sql.ErrNoRows
+}
+
+func (r *memoryUserRepository) UpdatePlaylistShareSlug(ctx context.Context, id int, slug string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range r.playlists {
+		if slug != "" && p.ShareSlug == slug && p.Id != id {
+			return fmt.Errorf("%w: share slug %s", errDuplicateEntry, slug)
+		}
+	}
+	if p, ok := r.playlists[id]; ok {
+		p.ShareSlug = slug
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) IncrementPlaylistViewCount(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p, ok := r.playlists[id]; ok {
+		p.ViewCount++
+	}
+	return nil
+}
+
+// ForkPlaylist returns 0 when the source doesn't exist, as nothing is
+// inserted.
+func (r *memoryUserRepository) ForkPlaylist(ctx context.Context, sourceId int, userId int, name string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	source, ok := r.playlists[sourceId]
+	if !ok {
+		return 0, nil
+	}
+
+	id := r.nextId("Playlist")
+	r.playlists[id] = &userDomain.Playlist{
+		Id:         id,
+		UserId:     userId,
+		Name:       name,
+		Type:       source.Type,
+		Visibility: userDomain.VisibilityPrivate,
+		Version:    1,
+		ForkedFrom: source.Id,
+	}
+
+	added := now()
+	for _, item := range r.itemsOf(sourceId, func(a, b *memoryPlaylistItem) bool { return a.Id < b.Id }) {
+		copied := *item
+		copied.Id = r.nextId("PlaylistItem")
+		copied.PlaylistId = id
+		copied.AddedBy = userId
+		copied.addedAt = added
+		r.items[copied.Id] = &copied
+	}
+	return id, nil
+}
+
+// itemsOf lists a playlist's items sorted by less. The caller holds the lock.
+func (r *memoryUserRepository) itemsOf(playlistId int, less func(a, b *memoryPlaylistItem) bool) []*memoryPlaylistItem {
+	var items []*memoryPlaylistItem
+	for _, item := range r.items {
+		if item.PlaylistId == playlistId {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return less(items[i], items[j])
+	})
+	return items
+}
+
+func byPosition(a, b *memoryPlaylistItem) bool {
+	if a.Position != b.Position {
+		return a.Position < b.Position
+	}
+	return a.Id < b.Id
+}
+
+func (r *memoryUserRepository) FindPlaylistItems(ctx context.Context, playlistId int) ([]userDomain.PlaylistItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.playlistItemsOf(playlistId), nil
+}
+
+func (r *memoryUserRepository) FindPlaylistItemsByPlaylistIds(ctx context.Context, playlistIds []int) (map[int][]userDomain.PlaylistItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := map[int][]userDomain.PlaylistItem{}
+	for _, playlistId := range playlistIds {
+		if rows := r.playlistItemsOf(playlistId); rows != nil {
+			items[playlistId] = rows
+		}
+	}
+	return items, nil
+}
+
+// playlistItemsOf reads one playlist's items. The caller holds the read lock.
+func (r *memoryUserRepository) playlistItemsOf(playlistId int) []userDomain.PlaylistItem {
+	var items []userDomain.PlaylistItem
+	for _, item := range r.itemsOf(playlistId, byPosition) {
+		row := item.PlaylistItem
+		row.AddedAt = datetimeString(item.addedAt)
+		items = append(items, row)
+	}
+	return items
+}
+
+// withPlaylistChange is the in-memory counterpart of the MariaDB one: fn
+// runs under the write lock and changes nothing when it fails, so the
+// version is only bumped and the change only recorded once it succeeded.
+func (r *memoryUserRepository) withPlaylistChange(playlistId int, version int, userId int, action string, fn func() (int, int, string, error)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.playlists[playlistId]
+	if !ok || (version != 0 && p.Version != version) {
+		return userDomain.ErrPlaylistVersionConflict
+	}
+
+	itemId, mediaId, mediaType, err := fn()
+	if err != nil {
+		return err
+	}
+
+	p.Version++
+	r.changes = append(r.changes, userDomain.PlaylistChange{
+		Id:         r.nextId("PlaylistChange"),
+		PlaylistId: playlistId,
+		UserId:     userId,
+		Action:     action,
+		ItemId:     itemId,
+		MediaId:    mediaId,
+		Type:       mediaType,
+		Version:    p.Version,
+		CreatedAt:  datetimeString(now()),
+	})
+	return nil
+}
+
+func (r *memoryUserRepository) InsertPlaylistItem(ctx context.Context, playlistId int, version int, userId int, mediaId int, mediaType string, note string) error {
+	return r.withPlaylistChange(playlistId, version, userId, userDomain.PlaylistActionAdd, func() (int, int, string, error) {
+		position := 0
+		for _, item := range r.itemsOf(playlistId, byPosition) {
+			if item.MediaId == mediaId && item.Type == mediaType {
+				return 0, 0, "", fmt.Errorf("%w: playlist item %d %s", errDuplicateEntry, mediaId, mediaType)
+			}
+			position = item.Position
+		}
+
+		id := r.nextId("PlaylistItem")
+		r.items[id] = &memoryPlaylistItem{
+			PlaylistItem: userDomain.PlaylistItem{
+				Id:         id,
+				PlaylistId: playlistId,
+				MediaId:    mediaId,
+				Type:       mediaType,
+				Position:   position + 1,
+				Note:       note,
+				AddedBy:    userId,
+			},
+			addedAt: now(),
+		}
+		return id, mediaId, mediaType, nil
+	})
+}
+
+func (r *memoryUserRepository) DeletePlaylistItem(ctx context.Context, playlistId int, version int, userId int, itemId int) error {
+	return r.withPlaylistChange(playlistId, version, userId, userDomain.PlaylistActionRemove, func() (int, int, string, error) {
+		removed, ok := r.items[itemId]
+		if !ok || removed.PlaylistId != playlistId {
+			return 0, 0, "", userDomain.ErrPlaylistItemNotFound
+		}
+
+		delete(r.items, itemId)
+		for _, item := range r.itemsOf(playlistId, byPosition) {
+			if item.Position > removed.Position {
+				item.Position--
+			}
+		}
+		return itemId, removed.MediaId, removed.Type, nil
+	})
+}
+
+func (r *memoryUserRepository) UpdatePlaylistItemPositions(ctx context.Context, playlistId int, version int, userId int, itemIds []int) error {
+	return r.withPlaylistChange(playlistId, version, userId, userDomain.PlaylistActionMove, func() (int, int, string, error) {
+		for i, itemId := range itemIds {
+			if item, ok := r.items[itemId]; ok && item.PlaylistId == playlistId {
+				item.Position = i + 1
+			}
+		}
+		return 0, 0, "", nil
+	})
+}
+
+func (r *memoryUserRepository) FindPlaylistChanges(ctx context.Context, playlistId int, limit int) ([]userDomain.PlaylistChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var changes []userDomain.PlaylistChange
+	for i := len(r.changes) - 1; i >= 0; i-- {
+		if r.changes[i].PlaylistId == playlistId {
+			changes = append(changes, r.changes[i])
+		}
+	}
+	return paginate(changes, 0, limit), nil
+}
+
+func (r *memoryUserRepository) FindPlaylistMembers(ctx context.Context, playlistId int) ([]userDomain.PlaylistMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var members []userDomain.PlaylistMember
+	var added []time.Time
+	for key, m := range r.members {
+		u, ok := r.users[key[1]]
+		if key[0] != playlistId || !ok {
+			continue
+		}
+		members = append(members, userDomain.PlaylistMember{
+			PlaylistId: playlistId,
+			UserId:     u.Id,
+			Nickname:   u.Nickname,
+			Role:       m.role,
+			InvitedBy:  m.invitedBy,
+			AddedAt:    datetimeString(m.addedAt),
+		})
+		added = append(added, m.addedAt)
+	}
+	sort.Sort(byAddedAt{members, added})
+	return members, nil
+}
+
+// byAddedAt orders members by when they were added, then by user id.
+type byAddedAt struct {
+	members []userDomain.PlaylistMember
+	added   []time.Time
+}
+
+func (s byAddedAt) Len() int { return len(s.members) }
+
+func (s byAddedAt) Less(i, j int) bool {
+	if !s.added[i].Equal(s.added[j]) {
+		return s.added[i].Before(s.added[j])
+	}
+	return s.members[i].UserId < s.members[j].UserId
+}
+
+func (s byAddedAt) Swap(i, j int) {
+	s.members[i], s.members[j] = s.members[j], s.members[i]
+	s.added[i], s.added[j] = s.added[j], s.added[i]
+}
+
+func (r *memoryUserRepository) FindPlaylistRole(ctx context.Context, playlistId int, userId int) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.members[[2]int{playlistId, userId}]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return m.role, nil
+}
+
+func (r *memoryUserRepository) UpsertPlaylistMember(ctx context.Context, playlistId int, userId int, role string, invitedBy int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := [2]int{playlistId, userId}
+	if m, ok := r.members[key]; ok {
+		m.role = role
+		return nil
+	}
+	r.members[key] = &memoryPlaylistMember{role: role, invitedBy: invitedBy, addedAt: now()}
+	return nil
+}
+
+func (r *memoryUserRepository) DeletePlaylistMember(ctx context.Context, playlistId int, userId int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.members, [2]int{playlistId, userId})
+	return nil
+}
+
+func (r *memoryUserRepository) InsertFollow(ctx context.Context, followerId int, followeeId int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := [2]int{followerId, followeeId}
+	if _, ok := r.follows[key]; !ok {
+		r.follows[key] = now()
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) DeleteFollow(ctx context.Context, followerId int, followeeId int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.follows, [2]int{followerId, followeeId})
+	return nil
+}
+
+func (r *memoryUserRepository) FindIsFollowing(ctx context.Context, followerId int, followeeId int) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.follows[[2]int{followerId, followeeId}]
+	return ok, nil
+}
+
+func (r *memoryUserRepository) FindFollowing(ctx context.Context, userId int) ([]userDomain.FollowUser, error) {
+	return r.followUsers(func(key [2]int) (int, bool) { return key[1], key[0] == userId })
+}
+
+func (r *memoryUserRepository) FindFollowers(ctx context.Context, userId int) ([]userDomain.FollowUser, error) {
+	return r.followUsers(func(key [2]int) (int, bool) { return key[0], key[1] == userId })
+}
+
+// followUsers lists the other side of the follows match picks, most recent
+// first.
+func (r *memoryUserRepository) followUsers(match func(key [2]int) (int, bool)) ([]userDomain.FollowUser, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type follow struct {
+		user userDomain.FollowUser
+		at   time.Time
+	}
+	var rows []follow
+	for key, at := range r.follows {
+		id, ok := match(key)
+		if !ok {
+			continue
+		}
+		if u, ok := r.users[id]; ok {
+			rows = append(rows, follow{userDomain.FollowUser{Id: u.Id, Nickname: u.Nickname, FollowedAt: at.Format(userDomain.WatchTimeLayout)}, at})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].at.Equal(rows[j].at) {
+			return rows[i].at.After(rows[j].at)
+		}
+		return rows[i].user.Id < rows[j].user.Id
+	})
+
+	var users []userDomain.FollowUser
+	for _, row := range rows {
+		users = append(users, row.user)
+	}
+	return users, nil
+}
+
+func (r *memoryUserRepository) FindActivityVisibility(ctx context.Context, userId int) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[userId]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return u.activityVisibility, nil
+}
+
+func (r *memoryUserRepository) UpdateActivityVisibility(ctx context.Context, userId int, visibility string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if u, ok := r.users[userId]; ok {
+		u.activityVisibility = visibility
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) InsertActivityEvent(ctx context.Context, event userDomain.ActivityEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event.Id = r.nextId("ActivityEvent")
+	event.Nickname, event.PlaylistName, event.CreatedAt = "", "", ""
+	r.events = append(r.events, memoryActivityEvent{ActivityEvent: event, createdAt: now()})
+	return nil
+}
+
+func (r *memoryUserRepository) FindFeed(ctx context.Context, userId int, before int, limit int) ([]userDomain.ActivityEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.activity(before, limit, func(author *memoryUser) bool {
+		_, following := r.follows[[2]int{userId, author.Id}]
+		return following && author.activityVisibility != userDomain.ActivityPrivate
+	}), nil
+}
+
+func (r *memoryUserRepository) FindActivityByUserId(ctx context.Context, userId int, before int, limit int) ([]userDomain.ActivityEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.activity(before, limit, func(author *memoryUser) bool {
+		return author.Id == userId
+	}), nil
+}
+
+// activity pages through the events of the authors keep picks, newest first,
+// with their nickname and playlist name filled in. Playlist events only show
+// while the playlist is public. The caller holds the lock.
+func (r *memoryUserRepository) activity(before int, limit int, keep func(author *memoryUser) bool) []userDomain.ActivityEvent {
+	var events []userDomain.ActivityEvent
+	for i := len(r.events) - 1; i >= 0 && len(events) < limit; i-- {
+		e := r.events[i]
+		author, ok := r.users[e.UserId]
+		if !ok || !keep(author) || (before != 0 && e.Id >= before) {
+			continue
+		}
+
+		event := e.ActivityEvent
+		event.Nickname = author.Nickname
+		event.CreatedAt = e.createdAt.Format(userDomain.WatchTimeLayout)
+		if event.PlaylistId != 0 {
+			p, ok := r.playlists[event.PlaylistId]
+			if !ok || p.Visibility != userDomain.VisibilityPublic {
+				continue
+			}
+			event.PlaylistName = p.Name
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func (r *memoryUserRepository) bannersWhere(keep func(b userDomain.Banner) bool) []userDomain.Banner {
+	var banners []userDomain.Banner
+	for _, b := range r.banners {
+		if keep(b) {
+			banners = append(banners, b)
+		}
+	}
+	sort.Slice(banners, func(i, j int) bool {
+		if banners[i].Priority != banners[j].Priority {
+			return banners[i].Priority > banners[j].Priority
+		}
+		return banners[i].Id < banners[j].Id
+	})
+	return banners
+}
+
+func (r *memoryUserRepository) AllBanner(ctx context.Context) ([]userDomain.Banner, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.bannersWhere(func(b userDomain.Banner) bool { return true }), nil
+}
+
+func (r *memoryUserRepository) FindActiveBanners(ctx context.Context, locale string) ([]userDomain.Banner, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	current := now().Format(userDomain.BannerTimeLayout)
+	return r.bannersWhere(func(b userDomain.Banner) bool {
+		return (b.StartAt == "" || b.StartAt <= current) &&
+			(b.EndAt == "" || b.EndAt > current) &&
+			(b.Locale == "" || b.Locale == locale)
+	}), nil
+}
+
+// normalizeBanner checks the schedule the way a DATETIME column would and
+// keeps it in BannerTimeLayout, which sorts like the times it holds.
+func normalizeBanner(banner userDomain.Banner) (userDomain.Banner, error) {
+	for _, at := range []*string{&banner.StartAt, &banner.EndAt} {
+		if *at == "" {
+			continue
+		}
+		t, err := time.Parse(userDomain.BannerTimeLayout, *at)
+		if err != nil {
+			return banner, err
+		}
+		*at = t.Format(userDomain.BannerTimeLayout)
+	}
+	return banner, nil
+}
+
+// UpdateBanner writes the banner under its id, inserting it when there is
+// none yet. An id of 0 is assigned the next one, as AUTO_INCREMENT does.
+func (r *memoryUserRepository) UpdateBanner(ctx context.Context, banner userDomain.Banner) error {
+	banner, err := normalizeBanner(banner)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if banner.Id == 0 {
+		banner.Id = r.nextId("Banner")
+	} else if banner.Id > r.lastId["Banner"] {
+		r.lastId["Banner"] = banner.Id
+	}
+	r.banners[banner.Id] = banner
+	return nil
+}
+
+func (r *memoryUserRepository) InsertBanner(ctx context.Context, banner userDomain.Banner) (int, error) {
+	banner, err := normalizeBanner(banner)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	banner.Id = r.nextId("Banner")
+	r.banners[banner.Id] = banner
+	return banner.Id, nil
+}
+
+func (r *memoryUserRepository) DeleteBanner(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.banners, id)
+	return nil
+}
+
+func (r *memoryUserRepository) ReadBannerById(ctx context.Context, id int) (userDomain.Banner, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	banner, ok := r.banners[id]
+	if !ok {
+		return userDomain.Banner{}, sql.ErrNoRows
+	}
+	return banner, nil
+}
+
+func (r *memoryUserRepository) FindBannerAssignment(ctx context.Context, slot string, subject string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bannerId, ok := r.assignments[[2]string{slot, subject}]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	return bannerId, nil
+}
+
+func (r *memoryUserRepository) UpsertBannerAssignment(ctx context.Context, slot string, subject string, bannerId int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.assignments[[2]string{slot, subject}] = bannerId
+	return nil
+}
+
+func (r *memoryUserRepository) InsertBannerEvent(ctx context.Context, bannerId int, subject string, kind string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.bannerEvents = append(r.bannerEvents, memoryBannerEvent{bannerId: bannerId, subject: subject, kind: kind})
+	return nil
+}
+
+func (r *memoryUserRepository) FindBannerSlotStats(ctx context.Context, slot string) ([]userDomain.BannerVariantStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var ids []int
+	for id, b := range r.banners {
+		if b.Slot == slot {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	var stats []userDomain.BannerVariantStats
+	for _, id := range ids {
+		b := r.banners[id]
+		variant := userDomain.BannerVariantStats{BannerId: b.Id, Title: b.Title, Weight: b.Weight}
+		for _, e := range r.bannerEvents {
+			if e.bannerId != id {
+				continue
+			}
+			switch e.kind {
+			case userDomain.BannerEventImpression:
+				variant.Impressions++
+			case userDomain.BannerEventClick:
+				variant.Clicks++
+			}
+		}
+		stats = append(stats, variant)
+	}
+	return stats, nil
+}
+
+// paginate applies LIMIT and OFFSET to rows already in order.
+func paginate[T any](rows []T, offset int, limit int) []T {
+	if offset >= len(rows) {
+		return nil
+	}
+	rows = rows[offset:]
+	if limit < len(rows) {
+		rows = rows[:limit]
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return rows
+}
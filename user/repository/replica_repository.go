@@ -0,0 +1,447 @@
+package repository
+
+import (
+	"context"
+	userDomain "github.com/null-like/movie-backend/domain/user"
+	"github.com/null-like/movie-backend/replica"
+	"github.com/null-like/movie-backend/user"
+)
+
+type replicaUserRepository struct {
+	router   *replica.Router
+	primary  user.Repository
+	replicas []user.Repository
+}
+
+// NewReplicaUserRepository sends the Find, All and Read methods to the
+// repository of the replica the router picks and every write to primary,
+// marking the request as having written. replicas[i] reads from the router's
+// i-th replica.
+func NewReplicaUserRepository(router *replica.Router, primary user.Repository, replicas []user.Repository) user.Repository {
+	return &replicaUserRepository{
+		router:   router,
+		primary:  primary,
+		replicas: replicas,
+	}
+}
+
+func (r *replicaUserRepository) on(i int) user.Repository {
+	if i == replica.Primary {
+		return r.primary
+	}
+	return r.replicas[i]
+}
+
+func (r *replicaUserRepository) InsertUser(ctx context.Context, user userDomain.User) error {
+	replica.Wrote(ctx)
+	return r.primary.InsertUser(ctx, user)
+}
+
+func (r *replicaUserRepository) FindIdByEmail(ctx context.Context, email string) (bool, error) {
+	return replica.Read(ctx, r.router, func(i int) (bool, error) {
+		return r.on(i).FindIdByEmail(ctx, email)
+	})
+}
+
+// account is what FindIdAndPasswdByEmail returns, carried through replica.Read.
+type account struct {
+	id           int
+	email        string
+	hashPassword string
+	nickname     string
+	rank         string
+}
+
+func (r *replicaUserRepository) FindIdAndPasswdByEmail(ctx context.Context, email string) (int, string, string, string, string, error) {
+	a, err := replica.Read(ctx, r.router, func(i int) (account, error) {
+		var a account
+		var err error
+		a.id, a.email, a.hashPassword, a.nickname, a.rank, err = r.on(i).FindIdAndPasswdByEmail(ctx, email)
+		return a, err
+	})
+	return a.id, a.email, a.hashPassword, a.nickname, a.rank, err
+}
+
+func (r *replicaUserRepository) FindAllUser(ctx context.Context) ([]userDomain.AllUserInfo, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.AllUserInfo, error) {
+		return r.on(i).FindAllUser(ctx)
+	})
+}
+
+func (r *replicaUserRepository) DeleteUser(ctx context.Context, id int) error {
+	replica.Wrote(ctx)
+	return r.primary.DeleteUser(ctx, id)
+}
+
+func (r *replicaUserRepository) UpdateUser(ctx context.Context, id int, rank string) error {
+	replica.Wrote(ctx)
+	return r.primary.UpdateUser(ctx, id, rank)
+}
+
+func (r *replicaUserRepository) FindNicknameByUserId(ctx context.Context, userId int) (string, error) {
+	return replica.Read(ctx, r.router, func(i int) (string, error) {
+		return r.on(i).FindNicknameByUserId(ctx, userId)
+	})
+}
+
+func (r *replicaUserRepository) FindUserById(ctx context.Context, userId int) (userDomain.AllUserInfo, error) {
+	return replica.Read(ctx, r.router, func(i int) (userDomain.AllUserInfo, error) {
+		return r.on(i).FindUserById(ctx, userId)
+	})
+}
+
+func (r *replicaUserRepository) FindProfileById(ctx context.Context, userId int) (userDomain.Profile, error) {
+	return replica.Read(ctx, r.router, func(i int) (userDomain.Profile, error) {
+		return r.on(i).FindProfileById(ctx, userId)
+	})
+}
+
+func (r *replicaUserRepository) FindProfilesByIds(ctx context.Context, userIds []int) ([]userDomain.Profile, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.Profile, error) {
+		return r.on(i).FindProfilesByIds(ctx, userIds)
+	})
+}
+
+func (r *replicaUserRepository) UpdateBio(ctx context.Context, userId int, bio string) error {
+	replica.Wrote(ctx)
+	return r.primary.UpdateBio(ctx, userId, bio)
+}
+
+func (r *replicaUserRepository) UpdateAvatar(ctx context.Context, userId int, avatar string) error {
+	replica.Wrote(ctx)
+	return r.primary.UpdateAvatar(ctx, userId, avatar)
+}
+
+func (r *replicaUserRepository) FindRatingDistribution(ctx context.Context, userId int) ([]userDomain.RatingBucket, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.RatingBucket, error) {
+		return r.on(i).FindRatingDistribution(ctx, userId)
+	})
+}
+
+func (r *replicaUserRepository) FindRatedGenres(ctx context.Context, userId int, limit int) ([]userDomain.GenreAffinity, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.GenreAffinity, error) {
+		return r.on(i).FindRatedGenres(ctx, userId, limit)
+	})
+}
+
+func (r *replicaUserRepository) FindWatchedRuntime(ctx context.Context, userId int) (int, error) {
+	return replica.Read(ctx, r.router, func(i int) (int, error) {
+		return r.on(i).FindWatchedRuntime(ctx, userId)
+	})
+}
+
+func (r *replicaUserRepository) FindIsFavorite(ctx context.Context, userId int, movieId int, mediaType string) (bool, error) {
+	return replica.Read(ctx, r.router, func(i int) (bool, error) {
+		return r.on(i).FindIsFavorite(ctx, userId, movieId, mediaType)
+	})
+}
+
+func (r *replicaUserRepository) FindFavoriteByUserId(ctx context.Context, userId int) ([]userDomain.Favorite, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.Favorite, error) {
+		return r.on(i).FindFavoriteByUserId(ctx, userId)
+	})
+}
+
+func (r *replicaUserRepository) FindFavoritesByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Favorite, error) {
+	return replica.Read(ctx, r.router, func(i int) (map[int][]userDomain.Favorite, error) {
+		return r.on(i).FindFavoritesByUserIds(ctx, userIds)
+	})
+}
+
+func (r *replicaUserRepository) InsertFavorite(ctx context.Context, userId int, movieId int, mediaType string) error {
+	replica.Wrote(ctx)
+	return r.primary.InsertFavorite(ctx, userId, movieId, mediaType)
+}
+
+func (r *replicaUserRepository) DeleteFavorite(ctx context.Context, userId int, movieId int, mediaType string) error {
+	replica.Wrote(ctx)
+	return r.primary.DeleteFavorite(ctx, userId, movieId, mediaType)
+}
+
+func (r *replicaUserRepository) InsertRating(ctx context.Context, userId int, movieId int, rating int, mediaType string) error {
+	replica.Wrote(ctx)
+	return r.primary.InsertRating(ctx, userId, movieId, rating, mediaType)
+}
+
+func (r *replicaUserRepository) FindRatingByMovieId(ctx context.Context, userId int, movieId int, mediaType string) (int, error) {
+	return replica.Read(ctx, r.router, func(i int) (int, error) {
+		return r.on(i).FindRatingByMovieId(ctx, userId, movieId, mediaType)
+	})
+}
+
+func (r *replicaUserRepository) FindRatingsByUserId(ctx context.Context, userId int) ([]userDomain.Rate, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.Rate, error) {
+		return r.on(i).FindRatingsByUserId(ctx, userId)
+	})
+}
+
+func (r *replicaUserRepository) FindRatingsByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Rate, error) {
+	return replica.Read(ctx, r.router, func(i int) (map[int][]userDomain.Rate, error) {
+		return r.on(i).FindRatingsByUserIds(ctx, userIds)
+	})
+}
+
+func (r *replicaUserRepository) FindWatchlist(ctx context.Context, userId int, state string, offset int, limit int) ([]userDomain.WatchEntry, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.WatchEntry, error) {
+		return r.on(i).FindWatchlist(ctx, userId, state, offset, limit)
+	})
+}
+
+func (r *replicaUserRepository) FindWatchEntry(ctx context.Context, userId int, mediaId int, mediaType string) (userDomain.WatchEntry, error) {
+	return replica.Read(ctx, r.router, func(i int) (userDomain.WatchEntry, error) {
+		return r.on(i).FindWatchEntry(ctx, userId, mediaId, mediaType)
+	})
+}
+
+func (r *replicaUserRepository) UpsertWantToWatch(ctx context.Context, userId int, mediaId int, mediaType string) error {
+	replica.Wrote(ctx)
+	return r.primary.UpsertWantToWatch(ctx, userId, mediaId, mediaType)
+}
+
+func (r *replicaUserRepository) InsertWatch(ctx context.Context, userId int, mediaId int, mediaType string, watchedAt string) error {
+	replica.Wrote(ctx)
+	return r.primary.InsertWatch(ctx, userId, mediaId, mediaType, watchedAt)
+}
+
+func (r *replicaUserRepository) DeleteWatchEntry(ctx context.Context, userId int, mediaId int, mediaType string) error {
+	replica.Wrote(ctx)
+	return r.primary.DeleteWatchEntry(ctx, userId, mediaId, mediaType)
+}
+
+func (r *replicaUserRepository) FindWatchHistory(ctx context.Context, userId int, offset int, limit int) ([]userDomain.WatchEvent, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.WatchEvent, error) {
+		return r.on(i).FindWatchHistory(ctx, userId, offset, limit)
+	})
+}
+
+func (r *replicaUserRepository) FindPublicPlaylists(ctx context.Context) ([]userDomain.Playlist, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.Playlist, error) {
+		return r.on(i).FindPublicPlaylists(ctx)
+	})
+}
+
+func (r *replicaUserRepository) FindPlaylistsByUserId(ctx context.Context, userId int) ([]userDomain.Playlist, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.Playlist, error) {
+		return r.on(i).FindPlaylistsByUserId(ctx, userId)
+	})
+}
+
+func (r *replicaUserRepository) FindPlaylistsByMemberId(ctx context.Context, userId int) ([]userDomain.Playlist, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.Playlist, error) {
+		return r.on(i).FindPlaylistsByMemberId(ctx, userId)
+	})
+}
+
+func (r *replicaUserRepository) ReadPlaylistById(ctx context.Context, id int) (userDomain.Playlist, error) {
+	return replica.Read(ctx, r.router, func(i int) (userDomain.Playlist, error) {
+		return r.on(i).ReadPlaylistById(ctx, id)
+	})
+}
+
+func (r *replicaUserRepository) FindPlaylistsByIds(ctx context.Context, ids []int) ([]userDomain.Playlist, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.Playlist, error) {
+		return r.on(i).FindPlaylistsByIds(ctx, ids)
+	})
+}
+
+func (r *replicaUserRepository) InsertPlaylist(ctx context.Context, userId int, name string, mediaType string, visibility string) (int, error) {
+	replica.Wrote(ctx)
+	return r.primary.InsertPlaylist(ctx, userId, name, mediaType, visibility)
+}
+
+func (r *replicaUserRepository) UpdatePlaylist(ctx context.Context, id int, name string, visibility string) error {
+	replica.Wrote(ctx)
+	return r.primary.UpdatePlaylist(ctx, id, name, visibility)
+}
+
+func (r *replicaUserRepository) DeletePlaylist(ctx context.Context, id int) error {
+	replica.Wrote(ctx)
+	return r.primary.DeletePlaylist(ctx, id)
+}
+
+func (r *replicaUserRepository) ReadPlaylistByShareSlug(ctx context.Context, slug string) (userDomain.Playlist, error) {
+	return replica.Read(ctx, r.router, func(i int) (userDomain.Playlist, error) {
+		return r.on(i).ReadPlaylistByShareSlug(ctx, slug)
+	})
+}
+
+func (r *replicaUserRepository) UpdatePlaylistShareSlug(ctx context.Context, id int, slug string) error {
+	replica.Wrote(ctx)
+	return r.primary.UpdatePlaylistShareSlug(ctx, id, slug)
+}
+
+func (r *replicaUserRepository) IncrementPlaylistViewCount(ctx context.Context, id int) error {
+	replica.Wrote(ctx)
+	return r.primary.IncrementPlaylistViewCount(ctx, id)
+}
+
+func (r *replicaUserRepository) ForkPlaylist(ctx context.Context, sourceId int, userId int, name string) (int, error) {
+	replica.Wrote(ctx)
+	return r.primary.ForkPlaylist(ctx, sourceId, userId, name)
+}
+
+func (r *replicaUserRepository) FindPlaylistItems(ctx context.Context, playlistId int) ([]userDomain.PlaylistItem, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.PlaylistItem, error) {
+		return r.on(i).FindPlaylistItems(ctx, playlistId)
+	})
+}
+
+func (r *replicaUserRepository) FindPlaylistItemsByPlaylistIds(ctx context.Context, playlistIds []int) (map[int][]userDomain.PlaylistItem, error) {
+	return replica.Read(ctx, r.router, func(i int) (map[int][]userDomain.PlaylistItem, error) {
+		return r.on(i).FindPlaylistItemsByPlaylistIds(ctx, playlistIds)
+	})
+}
+
+func (r *replicaUserRepository) InsertPlaylistItem(ctx context.Context, playlistId int, version int, userId int, mediaId int, mediaType string, note string) error {
+	replica.Wrote(ctx)
+	return r.primary.InsertPlaylistItem(ctx, playlistId, version, userId, mediaId, mediaType, note)
+}
+
+func (r *replicaUserRepository) DeletePlaylistItem(ctx context.Context, playlistId int, version int, userId int, itemId int) error {
+	replica.Wrote(ctx)
+	return r.primary.DeletePlaylistItem(ctx, playlistId, version, userId, itemId)
+}
+
+func (r *replicaUserRepository) UpdatePlaylistItemPositions(ctx context.Context, playlistId int, version int, userId int, itemIds []int) error {
+	replica.Wrote(ctx)
+	return r.primary.UpdatePlaylistItemPositions(ctx, playlistId, version, userId, itemIds)
+}
+
+func (r *replicaUserRepository) FindPlaylistChanges(ctx context.Context, playlistId int, limit int) ([]userDomain.PlaylistChange, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.PlaylistChange, error) {
+		return r.on(i).FindPlaylistChanges(ctx, playlistId, limit)
+	})
+}
+
+func (r *replicaUserRepository) FindPlaylistMembers(ctx context.Context, playlistId int) ([]userDomain.PlaylistMember, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.PlaylistMember, error) {
+		return r.on(i).FindPlaylistMembers(ctx, playlistId)
+	})
+}
+
+func (r *replicaUserRepository) FindPlaylistRole(ctx context.Context, playlistId int, userId int) (string, error) {
+	return replica.Read(ctx, r.router, func(i int) (string, error) {
+		return r.on(i).FindPlaylistRole(ctx, playlistId, userId)
+	})
+}
+
+func (r *replicaUserRepository) UpsertPlaylistMember(ctx context.Context, playlistId int, userId int, role string, invitedBy int) error {
+	replica.Wrote(ctx)
+	return r.primary.UpsertPlaylistMember(ctx, playlistId, userId, role, invitedBy)
+}
+
+func (r *replicaUserRepository) DeletePlaylistMember(ctx context.Context, playlistId int, userId int) error {
+	replica.Wrote(ctx)
+	return r.primary.DeletePlaylistMember(ctx, playlistId, userId)
+}
+
+func (r *replicaUserRepository) InsertFollow(ctx context.Context, followerId int, followeeId int) error {
+	replica.Wrote(ctx)
+	return r.primary.InsertFollow(ctx, followerId, followeeId)
+}
+
+func (r *replicaUserRepository) DeleteFollow(ctx context.Context, followerId int, followeeId int) error {
+	replica.Wrote(ctx)
+	return r.primary.DeleteFollow(ctx, followerId, followeeId)
+}
+
+func (r *replicaUserRepository) FindIsFollowing(ctx context.Context, followerId int, followeeId int) (bool, error) {
+	return replica.Read(ctx, r.router, func(i int) (bool, error) {
+		return r.on(i).FindIsFollowing(ctx, followerId, followeeId)
+	})
+}
+
+func (r *replicaUserRepository) FindFollowing(ctx context.Context, userId int) ([]userDomain.FollowUser, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.FollowUser, error) {
+		return r.on(i).FindFollowing(ctx, userId)
+	})
+}
+
+func (r *replicaUserRepository) FindFollowers(ctx context.Context, userId int) ([]userDomain.FollowUser, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.FollowUser, error) {
+		return r.on(i).FindFollowers(ctx, userId)
+	})
+}
+
+func (r *replicaUserRepository) FindActivityVisibility(ctx context.Context, userId int) (string, error) {
+	return replica.Read(ctx, r.router, func(i int) (string, error) {
+		return r.on(i).FindActivityVisibility(ctx, userId)
+	})
+}
+
+func (r *replicaUserRepository) UpdateActivityVisibility(ctx context.Context, userId int, visibility string) error {
+	replica.Wrote(ctx)
+	return r.primary.UpdateActivityVisibility(ctx, userId, visibility)
+}
+
+func (r *replicaUserRepository) InsertActivityEvent(ctx context.Context, event userDomain.ActivityEvent) error {
+	replica.Wrote(ctx)
+	return r.primary.InsertActivityEvent(ctx, event)
+}
+
+func (r *replicaUserRepository) FindFeed(ctx context.Context, userId int, before int, limit int) ([]userDomain.ActivityEvent, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.ActivityEvent, error) {
+		return r.on(i).FindFeed(ctx, userId, before, limit)
+	})
+}
+
+func (r *replicaUserRepository) FindActivityByUserId(ctx context.Context, userId int, before int, limit int) ([]userDomain.ActivityEvent, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.ActivityEvent, error) {
+		return r.on(i).FindActivityByUserId(ctx, userId, before, limit)
+	})
+}
+
+func (r *replicaUserRepository) AllBanner(ctx context.Context) ([]userDomain.Banner, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.Banner, error) {
+		return r.on(i).AllBanner(ctx)
+	})
+}
+
+func (r *replicaUserRepository) FindActiveBanners(ctx context.Context, locale string) ([]userDomain.Banner, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.Banner, error) {
+		return r.on(i).FindActiveBanners(ctx, locale)
+	})
+}
+
+func (r *replicaUserRepository) UpdateBanner(ctx context.Context, banner userDomain.Banner) error {
+	replica.Wrote(ctx)
+	return r.primary.UpdateBanner(ctx, banner)
+}
+
+func (r *replicaUserRepository) InsertBanner(ctx context.Context, banner userDomain.Banner) (int, error) {
+	replica.Wrote(ctx)
+	return r.primary.InsertBanner(ctx, banner)
+}
+
+func (r *replicaUserRepository) DeleteBanner(ctx context.Context, id int) error {
+	replica.Wrote(ctx)
+	return r.primary.DeleteBanner(ctx, id)
+}
+
+func (r *replicaUserRepository) ReadBannerById(ctx context.Context, id int) (userDomain.Banner, error) {
+	return replica.Read(ctx, r.router, func(i int) (userDomain.Banner, error) {
+		return r.on(i).ReadBannerById(ctx, id)
+	})
+}
+
+func (r *replicaUserRepository) FindBannerAssignment(ctx context.Context, slot string, subject string) (int, error) {
+	return replica.Read(ctx, r.router, func(i int) (int, error) {
+		return r.on(i).FindBannerAssignment(ctx, slot, subject)
+	})
+}
+
+func (r *replicaUserRepository) UpsertBannerAssignment(ctx context.Context, slot string, subject string, bannerId int) error {
+	replica.Wrote(ctx)
+	return r.primary.UpsertBannerAssignment(ctx, slot, subject, bannerId)
+}
+
+func (r *replicaUserRepository) InsertBannerEvent(ctx context.Context, bannerId int, subject string, kind string) error {
+	replica.Wrote(ctx)
+	return r.primary.InsertBannerEvent(ctx, bannerId, subject, kind)
+}
+
+func (r *replicaUserRepository) FindBannerSlotStats(ctx context.Context, slot string) ([]userDomain.BannerVariantStats, error) {
+	return replica.Read(ctx, r.router, func(i int) ([]userDomain.BannerVariantStats, error) {
+		return r.on(i).FindBannerSlotStats(ctx, slot)
+	})
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	userDomain "github.com/null-like/movie-backend/domain/user"
+	"github.com/null-like/movie-backend/movie"
+	_movieRepo "github.com/null-like/movie-backend/movie/repository"
+	"github.com/null-like/movie-backend/replica"
+	"github.com/null-like/movie-backend/repositorytest"
+	"github.com/null-like/movie-backend/user"
+	"testing"
+)
+
+func TestMemoryUserRepository(t *testing.T) {
+	repositorytest.UserRepository(t, func(t *testing.T) (user.Repository, movie.Repository) {
+		mr := _movieRepo.NewMemoryMovieRepository()
+		return NewMemoryUserRepository(mr), mr
+	})
+}
+
+func TestMariaDBUserRepository(t *testing.T) {
+	repositorytest.RequireMariaDB(t)
+	repositorytest.UserRepository(t, func(t *testing.T) (user.Repository, movie.Repository) {
+		db, schemaMap := repositorytest.MariaDB(t)
+		return NewMariaDBUserRepository(repositorytest.Logger(), db, schemaMap),
+			_movieRepo.NewMariaDBMovieRepository(repositorytest.Logger(), db, schemaMap)
+	})
+}
+
+func TestSQLiteUserRepository(t *testing.T) {
+	repositorytest.UserRepository(t, func(t *testing.T) (user.Repository, movie.Repository) {
+		db := repositorytest.SQLite(t)
+		return NewSQLiteUserRepository(repositorytest.Logger(), db),
+			_movieRepo.NewSQLiteMovieRepository(repositorytest.Logger(), db)
+	})
+}
+
+func TestPostgresUserRepository(t *testing.T) {
+	repositorytest.RequirePostgres(t)
+	repositorytest.UserRepository(t, func(t *testing.T) (user.Repository, movie.Repository) {
+		db, schemaMap := repositorytest.Postgres(t)
+		return NewPostgresUserRepository(repositorytest.Logger(), db, schemaMap),
+			_movieRepo.NewPostgresMovieRepository(repositorytest.Logger(), db, schemaMap)
+	})
+}
+
+func TestReplicaUserRepository(t *testing.T) {
+	repositorytest.UserRepository(t, func(t *testing.T) (user.Repository, movie.Repository) {
+		db := repositorytest.SQLite(t)
+		router := replica.NewRouter(repositorytest.Logger(), []*sql.DB{db})
+		return NewReplicaUserRepository(router, NewSQLiteUserRepository(repositorytest.Logger(), db),
+				[]user.Repository{NewSQLiteUserRepository(repositorytest.Logger(), db)}),
+			_movieRepo.NewSQLiteMovieRepository(repositorytest.Logger(), db)
+	})
+}
+
+// TestReplicaUserRepositoryRouting keeps the primary and the replica apart
+// to see where each call goes. The replica never receives the writes, as if
+// it lagged behind.
+func TestReplicaUserRepositoryRouting(t *testing.T) {
+	primaryDB, replicaDB := repositorytest.SQLite(t), repositorytest.SQLite(t)
+	router := replica.NewRouter(repositorytest.Logger(), []*sql.DB{replicaDB})
+	r := NewReplicaUserRepository(router, NewSQLiteUserRepository(repositorytest.Logger(), primaryDB),
+		[]user.Repository{NewSQLiteUserRepository(repositorytest.Logger(), replicaDB)})
+
+	ctx := replica.WithSession(context.Background())
+	err := r.InsertUser(ctx, userDomain.User{Email: "a@example.com", Password: "hash", Nickname: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _, _, _, err = r.FindIdAndPasswdByEmail(ctx, "a@example.com")
+	if err != nil {
+		t.Errorf("read after writing: got %v, want the user from the primary", err)
+	}
+	_, _, _, _, _, err = r.FindIdAndPasswdByEmail(replica.WithSession(context.Background()), "a@example.com")
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("read in another session: got %v, want sql.ErrNoRows from the replica", err)
+	}
+
+	replicaDB.Close()
+	_, _, _, _, _, err = r.FindIdAndPasswdByEmail(replica.WithSession(context.Background()), "a@example.com")
+	if err != nil {
+		t.Errorf("read with the replica down: got %v, want the user from the primary", err)
+	}
+}
@@ -0,0 +1,2112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	userDomain "github.com/null-like/movie-backend/domain/user"
+	"github.com/null-like/movie-backend/user"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+type sqliteUserRepository struct {
+	logger *logrus.Logger
+	Conn   *sql.DB
+}
+
+// NewSQLiteUserRepository keeps users in a SQLite database migrated with the
+// SQLite dialect, next to the catalog the movie repository reads.
+func NewSQLiteUserRepository(l *logrus.Logger, Conn *sql.DB) user.Repository {
+	return &sqliteUserRepository{
+		logger: l,
+		Conn:   Conn,
+	}
+}
+
+func (r *sqliteUserRepository) InsertUser(ctx context.Context, user userDomain.User) error {
+	query := `
+		INSERT INTO User (email, password, nickname, rank, signup_date)
+		VALUES (?, ?, ?, '회원', CURRENT_TIMESTAMP);
+		`
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query, user.Email, user.Password, user.Nickname)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) FindAllUser(ctx context.Context) ([]userDomain.AllUserInfo, error) {
+	query := `
+		SELECT id, email, nickname, rank, signup_date
+		FROM User
+		`
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var users []userDomain.AllUserInfo
+	for rows.Next() {
+		var user userDomain.AllUserInfo
+		err = rows.Scan(&user.Id, &user.Email, &user.Nickname, &user.Rank, &user.SignUpDate)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	r.logger.Debug(query)
+	return users, nil
+}
+
+func (r *sqliteUserRepository) DeleteUser(ctx context.Context, id int) error {
+	query := fmt.Sprintf(`
+		DELETE FROM User
+		WHERE id = %d;
+		`,
+		id,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	return err
+}
+
+func (r *sqliteUserRepository) UpdateUser(ctx context.Context, id int, rank string) error {
+	query := fmt.Sprintf(`
+		UPDATE User
+		SET rank = '%s'
+		WHERE id = %d;
+		`,
+		rank,
+		id,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	return err
+}
+
+func (r *sqliteUserRepository) FindIdByEmail(ctx context.Context, email string) (bool, error) {
+	query := `
+		SELECT id
+		FROM User
+		WHERE email = ?;
+		`
+
+	var id int
+	r.logger.Debug(query)
+	row := r.Conn.QueryRowContext(ctx, query, email)
+	err := row.Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *sqliteUserRepository) FindIdAndPasswdByEmail(ctx context.Context, email string) (int, string, string, string, string, error) {
+	query := `
+		SELECT id, password, nickname, rank
+		FROM User
+		WHERE email = ?;
+		`
+
+	var id int
+	var nickname string
+	var rank string
+	var hashPassword string
+	r.logger.Debug(query)
+	row := r.Conn.QueryRowContext(ctx, query, email)
+	err := row.Scan(&id, &hashPassword, &nickname, &rank)
+
+	if err != nil {
+		r.logger.Error(err)
+		return -1, email, "", "", "", err
+	}
+
+	return id, email, hashPassword, nickname, rank, nil
+}
+
+func (r *sqliteUserRepository) FindNicknameByUserId(ctx context.Context, userId int) (string, error) {
+	query := fmt.Sprintf(`
+		SELECT nickname
+		FROM User
+		WHERE id = %d;
+		`,
+		userId,
+	)
+
+	var nickname string
+	r.logger.Debug(query)
+	row := r.Conn.QueryRowContext(ctx, query)
+	err := row.Scan(&nickname)
+
+	if err != nil {
+		return "", err
+	}
+	return nickname, nil
+}
+
+func (r *sqliteUserRepository) FindUserById(ctx context.Context, userId int) (userDomain.AllUserInfo, error) {
+	query := fmt.Sprintf(`
+		SELECT id, email, nickname, rank, signup_date
+		FROM User
+		WHERE id = %d;
+		`,
+		userId,
+	)
+
+	var user userDomain.AllUserInfo
+	r.logger.Debug(query)
+	row := r.Conn.QueryRowContext(ctx, query)
+	err := row.Scan(&user.Id, &user.Email, &user.Nickname, &user.Rank, &user.SignUpDate)
+
+	if err != nil {
+		return userDomain.AllUserInfo{}, err
+	}
+	return user, nil
+}
+
+// FindProfileById reads the profile fields kept on the user along with its
+// rating, favorite and public playlist counts.
+func (r *sqliteUserRepository) FindProfileById(ctx context.Context, userId int) (userDomain.Profile, error) {
+	query := fmt.Sprintf(`
+		SELECT u.id, u.nickname, u.avatar, u.bio, u.signup_date,
+			(SELECT COUNT(*) FROM Rate WHERE user_id = u.id),
+			(SELECT COUNT(*) FROM Favorite WHERE user_id = u.id),
+			(SELECT COUNT(*) FROM Playlist WHERE user_id = u.id and visibility = '%[1]s')
+		FROM User u
+		WHERE u.id = %[2]d;
+		`,
+		userDomain.VisibilityPublic,
+		userId,
+	)
+	r.logger.Debug(query)
+
+	var profile userDomain.Profile
+	var joinDate time.Time
+	err := r.Conn.QueryRowContext(ctx, query).Scan(&profile.Id, &profile.Nickname, &profile.Avatar, &profile.Bio, &joinDate,
+		&profile.RatingCount, &profile.FavoriteCount, &profile.PlaylistCount)
+	if err != nil {
+		return userDomain.Profile{}, err
+	}
+	profile.JoinDate = joinDate.Format("2006-01-02")
+	return profile, nil
+}
+
+// FindProfilesByIds reads the profiles of the users among userIds like
+// FindProfileById does, skipping ids that don't exist.
+func (r *sqliteUserRepository) FindProfilesByIds(ctx context.Context, userIds []int) ([]userDomain.Profile, error) {
+	if len(userIds) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT u.id, u.nickname, u.avatar, u.bio, u.signup_date,
+			(SELECT COUNT(*) FROM Rate WHERE user_id = u.id),
+			(SELECT COUNT(*) FROM Favorite WHERE user_id = u.id),
+			(SELECT COUNT(*) FROM Playlist WHERE user_id = u.id and visibility = '%[1]s')
+		FROM User u
+		WHERE u.id IN (%[2]s);
+		`,
+		userDomain.VisibilityPublic,
+		joinIds(userIds),
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var profiles []userDomain.Profile
+	for rows.Next() {
+		var profile userDomain.Profile
+		var joinDate time.Time
+		err = rows.Scan(&profile.Id, &profile.Nickname, &profile.Avatar, &profile.Bio, &joinDate,
+			&profile.RatingCount, &profile.FavoriteCount, &profile.PlaylistCount)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		profile.JoinDate = joinDate.Format("2006-01-02")
+		profiles = append(profiles, profile)
+	}
+	return profiles, rows.Err()
+}
+
+func (r *sqliteUserRepository) UpdateBio(ctx context.Context, userId int, bio string) error {
+	query := fmt.Sprintf(`
+		UPDATE User
+		SET bio = ?
+		WHERE id = %d;
+		`,
+		userId,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query, bio)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) UpdateAvatar(ctx context.Context, userId int, avatar string) error {
+	query := fmt.Sprintf(`
+		UPDATE User
+		SET avatar = '%s'
+		WHERE id = %d;
+		`,
+		avatar,
+		userId,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) FindRatingDistribution(ctx context.Context, userId int) ([]userDomain.RatingBucket, error) {
+	query := fmt.Sprintf(`
+		SELECT rating, COUNT(*)
+		FROM Rate
+		WHERE user_id = %d
+		GROUP BY rating
+		ORDER BY rating;
+		`,
+		userId,
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var buckets []userDomain.RatingBucket
+	for rows.Next() {
+		var bucket userDomain.RatingBucket
+		err = rows.Scan(&bucket.Rating, &bucket.Count)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+// FindRatedGenres ranks the genres of the movies a user rated by how many of
+// them they rated, then by their average rating. The average is rounded to
+// the four decimals MariaDB's AVG of integers keeps.
+func (r *sqliteUserRepository) FindRatedGenres(ctx context.Context, userId int, limit int) ([]userDomain.GenreAffinity, error) {
+	query := fmt.Sprintf(`
+		SELECT g.id, g.name, COUNT(*), ROUND(AVG(r.rating), 4)
+		FROM Rate r
+		JOIN MovieGenre mg ON mg.movie_id = r.movie_id
+		JOIN Genre g ON g.id = mg.genre_id
+		WHERE r.user_id = %[1]d and r.type = '%[2]s'
+		GROUP BY g.id, g.name
+		ORDER BY COUNT(*) DESC, AVG(r.rating) DESC, g.id
+		LIMIT %[3]d;
+		`,
+		userId,
+		movieDomain.MediaType,
+		limit,
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var genres []userDomain.GenreAffinity
+	for rows.Next() {
+		var genre userDomain.GenreAffinity
+		err = rows.Scan(&genre.Id, &genre.Name, &genre.RatedCount, &genre.AverageRating)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+
+	return genres, rows.Err()
+}
+
+// FindWatchedRuntime sums the runtime of every movie viewing in the user's
+// watch history. Series have no single runtime and aren't counted.
+func (r *sqliteUserRepository) FindWatchedRuntime(ctx context.Context, userId int) (int, error) {
+	query := fmt.Sprintf(`
+		SELECT COALESCE(SUM(m.runtime), 0)
+		FROM WatchHistory h
+		JOIN Movie m ON m.id = h.media_id
+		WHERE h.user_id = %[1]d and h.type = '%[2]s';
+		`,
+		userId,
+		movieDomain.MediaType,
+	)
+	r.logger.Debug(query)
+
+	var runtime int
+	err := r.Conn.QueryRowContext(ctx, query).Scan(&runtime)
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+	return runtime, nil
+}
+
+func (r *sqliteUserRepository) FindIsFavorite(ctx context.Context, userId int, movieId int, mediaType string) (bool, error) {
+	query := fmt.Sprintf(`
+		SELECT user_id
+		FROM Favorite
+		WHERE user_id = %d and movie_id = %d and type = '%s';
+		`,
+		userId,
+		movieId,
+		mediaType,
+	)
+
+	var id int
+	r.logger.Debug(query)
+	row := r.Conn.QueryRowContext(ctx, query)
+	err := row.Scan(&id)
+
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *sqliteUserRepository) FindFavoriteByUserId(ctx context.Context, userId int) ([]userDomain.Favorite, error) {
+	query := fmt.Sprintf(`
+		SELECT movie_id, type
+		FROM Favorite
+		WHERE user_id = %d;
+		`,
+		userId,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var movies []userDomain.Favorite
+	for rows.Next() {
+		var movie userDomain.Favorite
+		err = rows.Scan(&movie.Id, &movie.Type)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		movies = append(movies, movie)
+	}
+
+	r.logger.Debug(query)
+	return movies, nil
+}
+
+// FindFavoritesByUserIds reads the favorites of every user among userIds,
+// keyed by user id.
+func (r *sqliteUserRepository) FindFavoritesByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Favorite, error) {
+	favorites := map[int][]userDomain.Favorite{}
+	if len(userIds) == 0 {
+		return favorites, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT user_id, movie_id, type
+		FROM Favorite
+		WHERE user_id IN (%s);
+		`,
+		joinIds(userIds),
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	for rows.Next() {
+		var userId int
+		var favorite userDomain.Favorite
+		err = rows.Scan(&userId, &favorite.Id, &favorite.Type)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		favorites[userId] = append(favorites[userId], favorite)
+	}
+	return favorites, rows.Err()
+}
+
+func (r *sqliteUserRepository) InsertFavorite(ctx context.Context, userId int, movieId int, mediaType string) error {
+	query := fmt.Sprintf(`
+		INSERT INTO Favorite (user_id, movie_id, type)
+		VALUES (%d, %d, '%s');
+		`,
+		userId,
+		movieId,
+		mediaType,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) DeleteFavorite(ctx context.Context, userId int, movieId int, mediaType string) error {
+	query := fmt.Sprintf(`
+		DELETE FROM Favorite
+		WHERE user_id = %d and movie_id = %d and type = '%s';
+		`,
+		userId,
+		movieId,
+		mediaType,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) InsertRating(ctx context.Context, userId int, movieId int, rating int, mediaType string) error {
+	query := fmt.Sprintf(`
+		INSERT INTO Rate VALUES (%d, %d, %d, '%s', CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id, movie_id, type) DO UPDATE SET rating = excluded.rating, apply_date = excluded.apply_date;
+		`,
+		userId,
+		movieId,
+		rating,
+		mediaType,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) FindRatingByMovieId(ctx context.Context, userId int, movieId int, mediaType string) (int, error) {
+	query := fmt.Sprintf(`
+		SELECT rating
+		FROM Rate
+		WHERE user_id = %d and movie_id = %d and type = '%s';
+		`,
+		userId,
+		movieId,
+		mediaType,
+	)
+
+	var rating int
+	r.logger.Debug(query)
+	row := r.Conn.QueryRowContext(ctx, query)
+	err := row.Scan(&rating)
+
+	if err != nil {
+		return 0, err
+	}
+	return rating, nil
+}
+
+func (r *sqliteUserRepository) FindRatingsByUserId(ctx context.Context, userId int) ([]userDomain.Rate, error) {
+	query := fmt.Sprintf(`
+		SELECT movie_id, rating, type, apply_date
+		FROM Rate
+		WHERE user_id = %d;
+		`,
+		userId,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var movieRatings []userDomain.Rate
+	for rows.Next() {
+		var movieRating userDomain.Rate
+		err = rows.Scan(&movieRating.Id, &movieRating.Rating, &movieRating.Type, &movieRating.ApplyDate)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		movieRatings = append(movieRatings, movieRating)
+	}
+
+	r.logger.Debug(query)
+	return movieRatings, nil
+}
+
+// FindRatingsByUserIds reads the ratings of every user among userIds, keyed
+// by user id.
+func (r *sqliteUserRepository) FindRatingsByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Rate, error) {
+	ratings := map[int][]userDomain.Rate{}
+	if len(userIds) == 0 {
+		return ratings, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT user_id, movie_id, rating, type, apply_date
+		FROM Rate
+		WHERE user_id IN (%s);
+		`,
+		joinIds(userIds),
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	for rows.Next() {
+		var userId int
+		var rating userDomain.Rate
+		err = rows.Scan(&userId, &rating.Id, &rating.Rating, &rating.Type, &rating.ApplyDate)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		ratings[userId] = append(ratings[userId], rating)
+	}
+	return ratings, rows.Err()
+}
+
+func (r *sqliteUserRepository) FindWatchlist(ctx context.Context, userId int, state string, offset int, limit int) ([]userDomain.WatchEntry, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM Watchlist
+		WHERE user_id = %d and ('%s' = '' or state = '%s')
+		ORDER BY added_at DESC, media_id
+		LIMIT %d OFFSET %d;
+		`,
+		watchlistColumns,
+		userId,
+		state,
+		state,
+		limit,
+		offset,
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var entries []userDomain.WatchEntry
+	for rows.Next() {
+		entry, err := scanWatchEntry(rows)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *sqliteUserRepository) FindWatchEntry(ctx context.Context, userId int, mediaId int, mediaType string) (userDomain.WatchEntry, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM Watchlist
+		WHERE user_id = %d and media_id = %d and type = '%s';
+		`,
+		watchlistColumns,
+		userId,
+		mediaId,
+		mediaType,
+	)
+	r.logger.Debug(query)
+
+	return scanWatchEntry(r.Conn.QueryRowContext(ctx, query))
+}
+
+// UpsertWantToWatch puts a title on the watchlist as want to watch. A title
+// that was already watched keeps its watch count, so wanting to see it again
+// doesn't lose its history.
+func (r *sqliteUserRepository) UpsertWantToWatch(ctx context.Context, userId int, mediaId int, mediaType string) error {
+	query := fmt.Sprintf(`
+		INSERT INTO Watchlist (user_id, media_id, type, state, added_at) VALUES (%d, %d, '%s', '%s', CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id, media_id, type) DO UPDATE SET state = excluded.state;
+		`,
+		userId,
+		mediaId,
+		mediaType,
+		userDomain.WatchStateWant,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// InsertWatch records a viewing in the history and marks the title watched,
+// bumping its watch count. An empty watchedAt means now.
+func (r *sqliteUserRepository) InsertWatch(ctx context.Context, userId int, mediaId int, mediaType string, watchedAt string) error {
+	when := "CURRENT_TIMESTAMP"
+	if watchedAt != "" {
+		when = "'" + watchedAt + "'"
+	}
+
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		INSERT INTO WatchHistory (user_id, media_id, type, watched_at) VALUES (%d, %d, '%s', %s);
+		`,
+		userId,
+		mediaId,
+		mediaType,
+		when,
+	)
+	r.logger.Debug(query)
+
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	query = fmt.Sprintf(`
+		INSERT INTO Watchlist (user_id, media_id, type, state, watch_count, last_watched_at, added_at)
+		VALUES (%d, %d, '%s', '%s', 1, %s, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id, media_id, type) DO UPDATE SET state = excluded.state, watch_count = watch_count + 1,
+			last_watched_at = MAX(COALESCE(last_watched_at, excluded.last_watched_at), excluded.last_watched_at);
+		`,
+		userId,
+		mediaId,
+		mediaType,
+		userDomain.WatchStateWatched,
+		when,
+	)
+	r.logger.Debug(query)
+
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteWatchEntry takes a title off the watchlist. Its watch history stays.
+func (r *sqliteUserRepository) DeleteWatchEntry(ctx context.Context, userId int, mediaId int, mediaType string) error {
+	query := fmt.Sprintf(`
+		DELETE FROM Watchlist
+		WHERE user_id = %d and media_id = %d and type = '%s';
+		`,
+		userId,
+		mediaId,
+		mediaType,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) FindWatchHistory(ctx context.Context, userId int, offset int, limit int) ([]userDomain.WatchEvent, error) {
+	query := fmt.Sprintf(`
+		SELECT id, media_id, type, watched_at
+		FROM WatchHistory
+		WHERE user_id = %d
+		ORDER BY watched_at DESC, id DESC
+		LIMIT %d OFFSET %d;
+		`,
+		userId,
+		limit,
+		offset,
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var events []userDomain.WatchEvent
+	for rows.Next() {
+		var event userDomain.WatchEvent
+		var watchedAt time.Time
+		err = rows.Scan(&event.Id, &event.MediaId, &event.Type, &watchedAt)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		event.WatchedAt = watchedAt.Format(userDomain.WatchTimeLayout)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *sqliteUserRepository) queryPlaylists(ctx context.Context, query string) ([]userDomain.Playlist, error) {
+	rows, err := r.Conn.QueryContext(ctx, query)
+
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var playlists []userDomain.Playlist
+	for rows.Next() {
+		var playlist userDomain.Playlist
+		err = rows.Scan(&playlist.Id, &playlist.UserId, &playlist.Name, &playlist.Type, &playlist.Visibility, &playlist.Version,
+			&playlist.ShareSlug, &playlist.ViewCount, &playlist.ForkedFrom)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		playlists = append(playlists, playlist)
+	}
+
+	r.logger.Debug(query)
+	return playlists, nil
+}
+
+func (r *sqliteUserRepository) FindPublicPlaylists(ctx context.Context) ([]userDomain.Playlist, error) {
+	query := fmt.Sprintf(`
+		SELECT id, user_id, name, type, visibility, version, COALESCE(share_slug, ''), view_count, forked_from
+		FROM Playlist
+		WHERE visibility = '%s';
+		`,
+		userDomain.VisibilityPublic,
+	)
+
+	return r.queryPlaylists(ctx, query)
+}
+
+func (r *sqliteUserRepository) FindPlaylistsByUserId(ctx context.Context, userId int) ([]userDomain.Playlist, error) {
+	query := fmt.Sprintf(`
+		SELECT id, user_id, name, type, visibility, version, COALESCE(share_slug, ''), view_count, forked_from
+		FROM Playlist
+		WHERE user_id = %d;
+		`,
+		userId,
+	)
+
+	return r.queryPlaylists(ctx, query)
+}
+
+func (r *sqliteUserRepository) ReadPlaylistById(ctx context.Context, id int) (userDomain.Playlist, error) {
+	query := fmt.Sprintf(`
+		SELECT id, user_id, name, type, visibility, version, COALESCE(share_slug, ''), view_count, forked_from
+		FROM Playlist
+		WHERE id = %d;
+		`,
+		id,
+	)
+
+	var playlist userDomain.Playlist
+	r.logger.Debug(query)
+	row := r.Conn.QueryRowContext(ctx, query)
+	err := row.Scan(&playlist.Id, &playlist.UserId, &playlist.Name, &playlist.Type, &playlist.Visibility, &playlist.Version,
+		&playlist.ShareSlug, &playlist.ViewCount, &playlist.ForkedFrom)
+
+	if err != nil {
+		return playlist, err
+	}
+	return playlist, nil
+}
+
+// FindPlaylistsByIds reads the playlists among ids, skipping ids that don't
+// exist.
+func (r *sqliteUserRepository) FindPlaylistsByIds(ctx context.Context, ids []int) ([]userDomain.Playlist, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, user_id, name, type, visibility, version, COALESCE(share_slug, ''), view_count, forked_from
+		FROM Playlist
+		WHERE id IN (%s);
+		`,
+		joinIds(ids),
+	)
+
+	return r.queryPlaylists(ctx, query)
+}
+
+func (r *sqliteUserRepository) InsertPlaylist(ctx context.Context, userId int, name string, mediaType string, visibility string) (int, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO Playlist (user_id, name, type, visibility) VALUES (%d, ?, '%s', '%s');
+		`,
+		userId,
+		mediaType,
+		visibility,
+	)
+	r.logger.Debug(query)
+
+	result, err := r.Conn.ExecContext(ctx, query, name)
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+	return int(id), nil
+}
+
+func (r *sqliteUserRepository) UpdatePlaylist(ctx context.Context, id int, name string, visibility string) error {
+	query := fmt.Sprintf(`
+		UPDATE Playlist
+		SET name = ?, visibility = '%s'
+		WHERE id = %d;
+		`,
+		visibility,
+		id,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query, name)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// DeletePlaylist removes the playlist together with its items, members and
+// change history.
+func (r *sqliteUserRepository) DeletePlaylist(ctx context.Context, id int) error {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"PlaylistItem", "PlaylistMember", "PlaylistChange"} {
+		query := fmt.Sprintf(`
+			DELETE FROM %s
+			WHERE playlist_id = %d;
+			`,
+			table,
+			id,
+		)
+		r.logger.Debug(query)
+
+		_, err = tx.ExecContext(ctx, query)
+		if err != nil {
+			r.logger.Error(err)
+			return err
+		}
+	}
+
+	query := fmt.Sprintf(`
+		DELETE FROM Playlist
+		WHERE id = %d;
+		`,
+		id,
+	)
+	r.logger.Debug(query)
+
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *sqliteUserRepository) ReadPlaylistByShareSlug(ctx context.Context, slug string) (userDomain.Playlist, error) {
+	query := fmt.Sprintf(`
+		SELECT id, user_id, name, type, visibility, version, COALESCE(share_slug, ''), view_count, forked_from
+		FROM Playlist
+		WHERE share_slug = '%s';
+		`,
+		slug,
+	)
+
+	var playlist userDomain.Playlist
+	r.logger.Debug(query)
+	row := r.Conn.QueryRowContext(ctx, query)
+	err := row.Scan(&playlist.Id, &playlist.UserId, &playlist.Name, &playlist.Type, &playlist.Visibility, &playlist.Version,
+		&playlist.ShareSlug, &playlist.ViewCount, &playlist.ForkedFrom)
+
+	if err != nil {
+		return playlist, err
+	}
+	return playlist, nil
+}
+
+// UpdatePlaylistShareSlug sets the share slug, or revokes it when slug is empty.
+func (r *sqliteUserRepository) UpdatePlaylistShareSlug(ctx context.Context, id int, slug string) error {
+	value := "NULL"
+	if slug != "" {
+		value = fmt.Sprintf("'%s'", slug)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE Playlist
+		SET share_slug = %s
+		WHERE id = %d;
+		`,
+		value,
+		id,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) IncrementPlaylistViewCount(ctx context.Context, id int) error {
+	query := fmt.Sprintf(`
+		UPDATE Playlist
+		SET view_count = view_count + 1
+		WHERE id = %d;
+		`,
+		id,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// ForkPlaylist copies the playlist and its items into a new private playlist
+// owned by userId and returns the new id.
+func (r *sqliteUserRepository) ForkPlaylist(ctx context.Context, sourceId int, userId int, name string) (int, error) {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		INSERT INTO Playlist (user_id, name, type, visibility, forked_from)
+		SELECT %d, ?, type, '%s', id
+		FROM Playlist
+		WHERE id = %d;
+		`,
+		userId,
+		userDomain.VisibilityPrivate,
+		sourceId,
+	)
+	r.logger.Debug(query)
+
+	result, err := tx.ExecContext(ctx, query, name)
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+	// SQLite's last insert id is left over from an earlier insert when the
+	// source playlist doesn't exist.
+	forked, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+	if forked == 0 {
+		return 0, nil
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+
+	query = fmt.Sprintf(`
+		INSERT INTO PlaylistItem (playlist_id, media_id, type, position, note, added_by, added_at)
+		SELECT %d, media_id, type, position, note, %d, CURRENT_TIMESTAMP
+		FROM PlaylistItem
+		WHERE playlist_id = %d;
+		`,
+		id,
+		userId,
+		sourceId,
+	)
+	r.logger.Debug(query)
+
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+
+	return int(id), tx.Commit()
+}
+
+func (r *sqliteUserRepository) FindPlaylistItems(ctx context.Context, playlistId int) ([]userDomain.PlaylistItem, error) {
+	query := fmt.Sprintf(`
+		SELECT id, playlist_id, media_id, type, position, note, added_by, added_at
+		FROM PlaylistItem
+		WHERE playlist_id = %d
+		ORDER BY position, id;
+		`,
+		playlistId,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var items []userDomain.PlaylistItem
+	for rows.Next() {
+		var item userDomain.PlaylistItem
+		err = rows.Scan(&item.Id, &item.PlaylistId, &item.MediaId, &item.Type, &item.Position, &item.Note, &item.AddedBy, &item.AddedAt)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	r.logger.Debug(query)
+	return items, nil
+}
+
+// FindPlaylistItemsByPlaylistIds reads the items of every playlist among
+// playlistIds in their order, keyed by playlist id.
+func (r *sqliteUserRepository) FindPlaylistItemsByPlaylistIds(ctx context.Context, playlistIds []int) (map[int][]userDomain.PlaylistItem, error) {
+	items := map[int][]userDomain.PlaylistItem{}
+	if len(playlistIds) == 0 {
+		return items, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, playlist_id, media_id, type, position, note, added_by, added_at
+		FROM PlaylistItem
+		WHERE playlist_id IN (%s)
+		ORDER BY playlist_id, position, id;
+		`,
+		joinIds(playlistIds),
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	for rows.Next() {
+		var item userDomain.PlaylistItem
+		err = rows.Scan(&item.Id, &item.PlaylistId, &item.MediaId, &item.Type, &item.Position, &item.Note, &item.AddedBy, &item.AddedAt)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		items[item.PlaylistId] = append(items[item.PlaylistId], item)
+	}
+	return items, rows.Err()
+}
+
+func (r *sqliteUserRepository) FindPlaylistsByMemberId(ctx context.Context, userId int) ([]userDomain.Playlist, error) {
+	query := fmt.Sprintf(`
+		SELECT p.id, p.user_id, p.name, p.type, p.visibility, p.version, COALESCE(p.share_slug, ''), p.view_count, p.forked_from
+		FROM PlaylistMember pm
+		JOIN Playlist p ON p.id = pm.playlist_id
+		WHERE pm.user_id = %d;
+		`,
+		userId,
+	)
+
+	return r.queryPlaylists(ctx, query)
+}
+
+// withPlaylistChange runs fn in a transaction that also bumps the playlist
+// version and records the change. A non-zero version must match the current
+// one, otherwise ErrPlaylistVersionConflict is returned and nothing changes.
+// fn returns the item, media id and type the change applies to.
+func (r *sqliteUserRepository) withPlaylistChange(ctx context.Context, playlistId int, version int, userId int, action string, fn func(tx *sql.Tx) (int, int, string, error)) error {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		UPDATE Playlist
+		SET version = version + 1
+		WHERE id = %d and (%d = 0 or version = %d);
+		`,
+		playlistId,
+		version,
+		version,
+	)
+	r.logger.Debug(query)
+
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+	if affected == 0 {
+		return userDomain.ErrPlaylistVersionConflict
+	}
+
+	itemId, mediaId, mediaType, err := fn(tx)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	query = fmt.Sprintf(`
+		INSERT INTO PlaylistChange (playlist_id, user_id, action, item_id, media_id, type, version, created_at)
+		SELECT id, %d, '%s', %d, %d, '%s', version, CURRENT_TIMESTAMP
+		FROM Playlist
+		WHERE id = %d;
+		`,
+		userId,
+		action,
+		itemId,
+		mediaId,
+		mediaType,
+		playlistId,
+	)
+	r.logger.Debug(query)
+
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// InsertPlaylistItem appends the item at the end of the playlist. The note is
+// free text, so it is bound rather than formatted into the query.
+func (r *sqliteUserRepository) InsertPlaylistItem(ctx context.Context, playlistId int, version int, userId int, mediaId int, mediaType string, note string) error {
+	return r.withPlaylistChange(ctx, playlistId, version, userId, userDomain.PlaylistActionAdd, func(tx *sql.Tx) (int, int, string, error) {
+		query := fmt.Sprintf(`
+			INSERT INTO PlaylistItem (playlist_id, media_id, type, position, note, added_by, added_at)
+			SELECT %d, %d, '%s', COALESCE(MAX(position), 0) + 1, ?, %d, CURRENT_TIMESTAMP
+			FROM PlaylistItem
+			WHERE playlist_id = %d;
+			`,
+			playlistId,
+			mediaId,
+			mediaType,
+			userId,
+			playlistId,
+		)
+		r.logger.Debug(query)
+
+		result, err := tx.ExecContext(ctx, query, note)
+		if err != nil {
+			return 0, 0, "", err
+		}
+		itemId, err := result.LastInsertId()
+		return int(itemId), mediaId, mediaType, err
+	})
+}
+
+// DeletePlaylistItem removes the item and closes the gap it leaves in the
+// positions.
+func (r *sqliteUserRepository) DeletePlaylistItem(ctx context.Context, playlistId int, version int, userId int, itemId int) error {
+	return r.withPlaylistChange(ctx, playlistId, version, userId, userDomain.PlaylistActionRemove, func(tx *sql.Tx) (int, int, string, error) {
+		query := fmt.Sprintf(`
+			SELECT media_id, type, position
+			FROM PlaylistItem
+			WHERE playlist_id = %d and id = %d;
+			`,
+			playlistId,
+			itemId,
+		)
+		r.logger.Debug(query)
+
+		var mediaId, position int
+		var mediaType string
+		err := tx.QueryRowContext(ctx, query).Scan(&mediaId, &mediaType, &position)
+		if err == sql.ErrNoRows {
+			return 0, 0, "", userDomain.ErrPlaylistItemNotFound
+		}
+		if err != nil {
+			return 0, 0, "", err
+		}
+
+		query = fmt.Sprintf(`
+			DELETE FROM PlaylistItem
+			WHERE playlist_id = %d and id = %d;
+			`,
+			playlistId,
+			itemId,
+		)
+		r.logger.Debug(query)
+
+		_, err = tx.ExecContext(ctx, query)
+		if err != nil {
+			return 0, 0, "", err
+		}
+
+		query = fmt.Sprintf(`
+			UPDATE PlaylistItem
+			SET position = position - 1
+			WHERE playlist_id = %d and position > %d;
+			`,
+			playlistId,
+			position,
+		)
+		r.logger.Debug(query)
+
+		_, err = tx.ExecContext(ctx, query)
+		return itemId, mediaId, mediaType, err
+	})
+}
+
+// UpdatePlaylistItemPositions numbers the given items 1..n in slice order.
+func (r *sqliteUserRepository) UpdatePlaylistItemPositions(ctx context.Context, playlistId int, version int, userId int, itemIds []int) error {
+	return r.withPlaylistChange(ctx, playlistId, version, userId, userDomain.PlaylistActionMove, func(tx *sql.Tx) (int, int, string, error) {
+		for i, itemId := range itemIds {
+			query := fmt.Sprintf(`
+				UPDATE PlaylistItem
+				SET position = %d
+				WHERE playlist_id = %d and id = %d;
+				`,
+				i+1,
+				playlistId,
+				itemId,
+			)
+			r.logger.Debug(query)
+
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return 0, 0, "", err
+			}
+		}
+		return 0, 0, "", nil
+	})
+}
+
+func (r *sqliteUserRepository) FindPlaylistChanges(ctx context.Context, playlistId int, limit int) ([]userDomain.PlaylistChange, error) {
+	query := fmt.Sprintf(`
+		SELECT id, playlist_id, user_id, action, item_id, media_id, type, version, created_at
+		FROM PlaylistChange
+		WHERE playlist_id = %d
+		ORDER BY id DESC
+		LIMIT %d;
+		`,
+		playlistId,
+		limit,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var changes []userDomain.PlaylistChange
+	for rows.Next() {
+		var change userDomain.PlaylistChange
+		err = rows.Scan(&change.Id, &change.PlaylistId, &change.UserId, &change.Action, &change.ItemId, &change.MediaId,
+			&change.Type, &change.Version, &change.CreatedAt)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	r.logger.Debug(query)
+	return changes, nil
+}
+
+func (r *sqliteUserRepository) FindPlaylistMembers(ctx context.Context, playlistId int) ([]userDomain.PlaylistMember, error) {
+	query := fmt.Sprintf(`
+		SELECT pm.playlist_id, pm.user_id, u.nickname, pm.role, pm.invited_by, pm.added_at
+		FROM PlaylistMember pm
+		JOIN User u ON u.id = pm.user_id
+		WHERE pm.playlist_id = %d
+		ORDER BY pm.added_at;
+		`,
+		playlistId,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var members []userDomain.PlaylistMember
+	for rows.Next() {
+		var member userDomain.PlaylistMember
+		err = rows.Scan(&member.PlaylistId, &member.UserId, &member.Nickname, &member.Role, &member.InvitedBy, &member.AddedAt)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	r.logger.Debug(query)
+	return members, nil
+}
+
+func (r *sqliteUserRepository) FindPlaylistRole(ctx context.Context, playlistId int, userId int) (string, error) {
+	query := fmt.Sprintf(`
+		SELECT role
+		FROM PlaylistMember
+		WHERE playlist_id = %d and user_id = %d;
+		`,
+		playlistId,
+		userId,
+	)
+
+	var role string
+	r.logger.Debug(query)
+	row := r.Conn.QueryRowContext(ctx, query)
+	err := row.Scan(&role)
+
+	if err != nil {
+		return "", err
+	}
+	return role, nil
+}
+
+func (r *sqliteUserRepository) UpsertPlaylistMember(ctx context.Context, playlistId int, userId int, role string, invitedBy int) error {
+	query := fmt.Sprintf(`
+		INSERT INTO PlaylistMember (playlist_id, user_id, role, invited_by, added_at)
+		VALUES (%d, %d, '%s', %d, CURRENT_TIMESTAMP)
+		ON CONFLICT (playlist_id, user_id) DO UPDATE SET role = excluded.role;
+		`,
+		playlistId,
+		userId,
+		role,
+		invitedBy,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) DeletePlaylistMember(ctx context.Context, playlistId int, userId int) error {
+	query := fmt.Sprintf(`
+		DELETE FROM PlaylistMember
+		WHERE playlist_id = %d and user_id = %d;
+		`,
+		playlistId,
+		userId,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) InsertFollow(ctx context.Context, followerId int, followeeId int) error {
+	query := fmt.Sprintf(`
+		INSERT OR IGNORE INTO Follow (follower_id, followee_id, created_at) VALUES (%d, %d, CURRENT_TIMESTAMP);
+		`,
+		followerId,
+		followeeId,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) DeleteFollow(ctx context.Context, followerId int, followeeId int) error {
+	query := fmt.Sprintf(`
+		DELETE FROM Follow
+		WHERE follower_id = %d and followee_id = %d;
+		`,
+		followerId,
+		followeeId,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) FindIsFollowing(ctx context.Context, followerId int, followeeId int) (bool, error) {
+	query := fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1 FROM Follow WHERE follower_id = %d and followee_id = %d
+		);
+		`,
+		followerId,
+		followeeId,
+	)
+	r.logger.Debug(query)
+
+	var following bool
+	err := r.Conn.QueryRowContext(ctx, query).Scan(&following)
+	if err != nil {
+		r.logger.Error(err)
+		return false, err
+	}
+	return following, nil
+}
+
+func (r *sqliteUserRepository) FindFollowing(ctx context.Context, userId int) ([]userDomain.FollowUser, error) {
+	query := fmt.Sprintf(`
+		SELECT u.id, u.nickname, f.created_at
+		FROM Follow f
+		JOIN User u ON u.id = f.followee_id
+		WHERE f.follower_id = %d
+		ORDER BY f.created_at DESC;
+		`,
+		userId,
+	)
+
+	return r.queryFollowUsers(ctx, query)
+}
+
+func (r *sqliteUserRepository) FindFollowers(ctx context.Context, userId int) ([]userDomain.FollowUser, error) {
+	query := fmt.Sprintf(`
+		SELECT u.id, u.nickname, f.created_at
+		FROM Follow f
+		JOIN User u ON u.id = f.follower_id
+		WHERE f.followee_id = %d
+		ORDER BY f.created_at DESC;
+		`,
+		userId,
+	)
+
+	return r.queryFollowUsers(ctx, query)
+}
+
+func (r *sqliteUserRepository) queryFollowUsers(ctx context.Context, query string) ([]userDomain.FollowUser, error) {
+	r.logger.Debug(query)
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var users []userDomain.FollowUser
+	for rows.Next() {
+		var user userDomain.FollowUser
+		var followedAt time.Time
+		err = rows.Scan(&user.Id, &user.Nickname, &followedAt)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		user.FollowedAt = followedAt.Format(userDomain.WatchTimeLayout)
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *sqliteUserRepository) FindActivityVisibility(ctx context.Context, userId int) (string, error) {
+	query := fmt.Sprintf(`
+		SELECT activity_visibility
+		FROM User
+		WHERE id = %d;
+		`,
+		userId,
+	)
+	r.logger.Debug(query)
+
+	var visibility string
+	err := r.Conn.QueryRowContext(ctx, query).Scan(&visibility)
+	if err != nil {
+		return "", err
+	}
+	return visibility, nil
+}
+
+func (r *sqliteUserRepository) UpdateActivityVisibility(ctx context.Context, userId int, visibility string) error {
+	query := fmt.Sprintf(`
+		UPDATE User
+		SET activity_visibility = '%s'
+		WHERE id = %d;
+		`,
+		visibility,
+		userId,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) InsertActivityEvent(ctx context.Context, event userDomain.ActivityEvent) error {
+	query := fmt.Sprintf(`
+		INSERT INTO ActivityEvent (user_id, kind, media_id, type, rating, playlist_id, created_at)
+		VALUES (%d, '%s', %d, '%s', %d, %d, CURRENT_TIMESTAMP);
+		`,
+		event.UserId,
+		event.Kind,
+		event.MediaId,
+		event.Type,
+		event.Rating,
+		event.PlaylistId,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// sqliteActivitySelect is activitySelect without the schema.
+const sqliteActivitySelect = `
+		SELECT e.id, e.user_id, u.nickname, e.kind, e.media_id, e.type, e.rating, e.playlist_id, COALESCE(p.name, ''), e.created_at
+		FROM ActivityEvent e
+		JOIN User u ON u.id = e.user_id
+		LEFT JOIN Playlist p ON p.id = e.playlist_id
+		`
+
+// FindFeed builds the feed on read from the activity of everyone userId
+// follows, skipping users who keep their activity private. before is the id
+// of the oldest event already seen, 0 for the first page.
+func (r *sqliteUserRepository) FindFeed(ctx context.Context, userId int, before int, limit int) ([]userDomain.ActivityEvent, error) {
+	query := sqliteActivitySelect + fmt.Sprintf(`
+		JOIN Follow f ON f.followee_id = e.user_id
+		WHERE f.follower_id = %d and u.activity_visibility <> '%s'
+			and (e.playlist_id = 0 or p.visibility = '%s')
+			and (%d = 0 or e.id < %d)
+		ORDER BY e.id DESC
+		LIMIT %d;
+		`,
+		userId,
+		userDomain.ActivityPrivate,
+		userDomain.VisibilityPublic,
+		before,
+		before,
+		limit,
+	)
+
+	return r.queryActivity(ctx, query)
+}
+
+// FindActivityByUserId lists one user's activity regardless of their
+// visibility setting; the caller decides who may see it.
+func (r *sqliteUserRepository) FindActivityByUserId(ctx context.Context, userId int, before int, limit int) ([]userDomain.ActivityEvent, error) {
+	query := sqliteActivitySelect + fmt.Sprintf(`
+		WHERE e.user_id = %d
+			and (e.playlist_id = 0 or p.visibility = '%s')
+			and (%d = 0 or e.id < %d)
+		ORDER BY e.id DESC
+		LIMIT %d;
+		`,
+		userId,
+		userDomain.VisibilityPublic,
+		before,
+		before,
+		limit,
+	)
+
+	return r.queryActivity(ctx, query)
+}
+
+func (r *sqliteUserRepository) queryActivity(ctx context.Context, query string) ([]userDomain.ActivityEvent, error) {
+	r.logger.Debug(query)
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var events []userDomain.ActivityEvent
+	for rows.Next() {
+		var event userDomain.ActivityEvent
+		var createdAt time.Time
+		err = rows.Scan(&event.Id, &event.UserId, &event.Nickname, &event.Kind, &event.MediaId, &event.Type,
+			&event.Rating, &event.PlaylistId, &event.PlaylistName, &createdAt)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		event.CreatedAt = createdAt.Format(userDomain.WatchTimeLayout)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *sqliteUserRepository) AllBanner(ctx context.Context) ([]userDomain.Banner, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM Banner
+		ORDER BY priority DESC, id;
+		`,
+		bannerColumns,
+	)
+
+	return r.queryBanners(ctx, query)
+}
+
+// FindActiveBanners returns the banners scheduled to show right now in the
+// given locale, highest priority first. Audience is left to the caller.
+func (r *sqliteUserRepository) FindActiveBanners(ctx context.Context, locale string) ([]userDomain.Banner, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM Banner
+		WHERE (start_at IS NULL OR start_at <= CURRENT_TIMESTAMP)
+			AND (end_at IS NULL OR end_at > CURRENT_TIMESTAMP)
+			AND (locale = '' OR locale = '%s')
+		ORDER BY priority DESC, id;
+		`,
+		bannerColumns,
+		locale,
+	)
+
+	return r.queryBanners(ctx, query)
+}
+
+func (r *sqliteUserRepository) queryBanners(ctx context.Context, query string) ([]userDomain.Banner, error) {
+	r.logger.Debug(query)
+	rows, err := r.Conn.QueryContext(ctx, query)
+
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var banners []userDomain.Banner
+	for rows.Next() {
+		var banner userDomain.Banner
+		var startAt, endAt sql.NullTime
+		err = rows.Scan(&banner.Id, &banner.MovieId, &banner.Title, &banner.Type, &banner.Comment,
+			&startAt, &endAt, &banner.Priority, &banner.Audience, &banner.TargetRank, &banner.Locale,
+			&banner.Slot, &banner.Weight)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		if startAt.Valid {
+			banner.StartAt = startAt.Time.Format(userDomain.BannerTimeLayout)
+		}
+		if endAt.Valid {
+			banner.EndAt = endAt.Time.Format(userDomain.BannerTimeLayout)
+		}
+		banners = append(banners, banner)
+	}
+
+	return banners, rows.Err()
+}
+
+// UpdateBanner upserts the banner. MariaDB hands out a new id for id 0 and
+// SQLite would store 0, so a zero id is passed as NULL.
+func (r *sqliteUserRepository) UpdateBanner(ctx context.Context, banner userDomain.Banner) error {
+	query := fmt.Sprintf(`
+		INSERT INTO Banner (%s)
+		VALUES (NULLIF(%d, 0), %d, ?, '%s', ?, %s, %s, %d, '%s', ?, ?, ?, %d)
+		ON CONFLICT (id) DO UPDATE SET movie_id = excluded.movie_id, title = excluded.title, type = excluded.type, comment = excluded.comment,
+			start_at = excluded.start_at, end_at = excluded.end_at, priority = excluded.priority,
+			audience = excluded.audience, target_rank = excluded.target_rank, locale = excluded.locale,
+			slot = excluded.slot, weight = excluded.weight;
+		`,
+		bannerColumns,
+		banner.Id,
+		banner.MovieId,
+		banner.Type,
+		bannerTime(banner.StartAt),
+		bannerTime(banner.EndAt),
+		banner.Priority,
+		banner.Audience,
+		banner.Weight,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query, banner.Title, banner.Comment, banner.TargetRank, banner.Locale, banner.Slot)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) InsertBanner(ctx context.Context, banner userDomain.Banner) (int, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO Banner (movie_id, title, type, comment, start_at, end_at, priority, audience, target_rank, locale, slot, weight)
+		VALUES (%d, ?, '%s', ?, %s, %s, %d, '%s', ?, ?, ?, %d);
+		`,
+		banner.MovieId,
+		banner.Type,
+		bannerTime(banner.StartAt),
+		bannerTime(banner.EndAt),
+		banner.Priority,
+		banner.Audience,
+		banner.Weight,
+	)
+	r.logger.Debug(query)
+
+	result, err := r.Conn.ExecContext(ctx, query, banner.Title, banner.Comment, banner.TargetRank, banner.Locale, banner.Slot)
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+	return int(id), nil
+}
+
+func (r *sqliteUserRepository) DeleteBanner(ctx context.Context, id int) error {
+	query := fmt.Sprintf(`
+		DELETE FROM Banner
+		WHERE id = %d;
+		`,
+		id,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) ReadBannerById(ctx context.Context, id int) (userDomain.Banner, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM Banner
+		WHERE id = %d;
+		`,
+		bannerColumns,
+		id,
+	)
+
+	banners, err := r.queryBanners(ctx, query)
+	if err != nil {
+		return userDomain.Banner{}, err
+	}
+	if len(banners) == 0 {
+		return userDomain.Banner{}, sql.ErrNoRows
+	}
+	return banners[0], nil
+}
+
+func (r *sqliteUserRepository) FindBannerAssignment(ctx context.Context, slot string, subject string) (int, error) {
+	query := fmt.Sprintf(`
+		SELECT banner_id
+		FROM BannerAssignment
+		WHERE slot = '%s' and subject = '%s';
+		`,
+		slot,
+		subject,
+	)
+
+	var bannerId int
+	r.logger.Debug(query)
+	err := r.Conn.QueryRowContext(ctx, query).Scan(&bannerId)
+	if err != nil {
+		return 0, err
+	}
+	return bannerId, nil
+}
+
+func (r *sqliteUserRepository) UpsertBannerAssignment(ctx context.Context, slot string, subject string, bannerId int) error {
+	query := fmt.Sprintf(`
+		INSERT INTO BannerAssignment (slot, subject, banner_id, assigned_at) VALUES ('%s', '%s', %d, CURRENT_TIMESTAMP)
+		ON CONFLICT (slot, subject) DO UPDATE SET banner_id = excluded.banner_id, assigned_at = excluded.assigned_at;
+		`,
+		slot,
+		subject,
+		bannerId,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteUserRepository) InsertBannerEvent(ctx context.Context, bannerId int, subject string, kind string) error {
+	query := fmt.Sprintf(`
+		INSERT INTO BannerEvent (banner_id, subject, kind, created_at) VALUES (%d, '%s', '%s', CURRENT_TIMESTAMP);
+		`,
+		bannerId,
+		subject,
+		kind,
+	)
+	r.logger.Debug(query)
+
+	_, err := r.Conn.ExecContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// FindBannerSlotStats counts impressions and clicks for every variant in a
+// slot, oldest variant first. Rates and significance are left to the caller.
+func (r *sqliteUserRepository) FindBannerSlotStats(ctx context.Context, slot string) ([]userDomain.BannerVariantStats, error) {
+	query := fmt.Sprintf(`
+		SELECT b.id, b.title, b.weight,
+			COALESCE(SUM(e.kind = '%s'), 0),
+			COALESCE(SUM(e.kind = '%s'), 0)
+		FROM Banner b
+		LEFT JOIN BannerEvent e ON e.banner_id = b.id
+		WHERE b.slot = '%s'
+		GROUP BY b.id, b.title, b.weight
+		ORDER BY b.id;
+		`,
+		userDomain.BannerEventImpression,
+		userDomain.BannerEventClick,
+		slot,
+	)
+	r.logger.Debug(query)
+
+	rows, err := r.Conn.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+
+	var stats []userDomain.BannerVariantStats
+	for rows.Next() {
+		var variant userDomain.BannerVariantStats
+		err = rows.Scan(&variant.BannerId, &variant.Title, &variant.Weight, &variant.Impressions, &variant.Clicks)
+		if err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		stats = append(stats, variant)
+	}
+
+	return stats, rows.Err()
+}
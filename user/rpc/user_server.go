@@ -0,0 +1,496 @@
+package rpc
+
+import (
+	"context"
+	userDomain "github.com/null-like/movie-backend/domain/user"
+	"github.com/null-like/movie-backend/proto/userpb"
+	"github.com/null-like/movie-backend/request"
+	"github.com/null-like/movie-backend/user"
+	"google.golang.org/grpc"
+)
+
+const defaultPageSize = 20
+
+type userServer struct {
+	userpb.UnimplementedUserServiceServer
+	Usecase user.Usecase
+}
+
+func NewUserServer(s *grpc.Server, u user.Usecase) {
+	userpb.RegisterUserServiceServer(s, &userServer{Usecase: u})
+}
+
+// The requests below mirror the HTTP ones, so a call is checked by the same
+// rules whichever way it comes in.
+
+type emailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type signInRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type userRequest struct {
+	UserId int `json:"user_id" validate:"required,gt=0"`
+}
+
+type mediaRequest struct {
+	userRequest
+	MediaId int    `json:"media_id" validate:"required,gt=0"`
+	Type    string `json:"media_type" validate:"required,oneof=movie tv"`
+}
+
+type ratingRequest struct {
+	mediaRequest
+	Rating int `json:"rating" validate:"required,min=1,max=10"`
+}
+
+type pageRequest struct {
+	Offset int `json:"offset" validate:"gte=0"`
+	Limit  int `json:"limit" validate:"gte=0,lte=100"`
+}
+
+// page returns the offset and limit, defaulting the limit when unset.
+func (p pageRequest) page() (int, int) {
+	if p.Limit == 0 {
+		return p.Offset, defaultPageSize
+	}
+	return p.Offset, p.Limit
+}
+
+type watchlistRequest struct {
+	userRequest
+	pageRequest
+	State string `json:"state" validate:"omitempty,oneof=want watched"`
+}
+
+type historyRequest struct {
+	userRequest
+	pageRequest
+}
+
+type viewerRequest struct {
+	ViewerId int `json:"viewer_id" validate:"gte=0"`
+}
+
+type userPlaylistsRequest struct {
+	viewerRequest
+	userRequest
+}
+
+type playlistRequest struct {
+	viewerRequest
+	PlaylistId int `json:"playlist_id" validate:"required,gt=0"`
+}
+
+type feedRequest struct {
+	userRequest
+	Before int `json:"before" validate:"gte=0"`
+	Limit  int `json:"limit" validate:"gte=0,lte=100"`
+}
+
+// limit defaults the page size when unset.
+func (r feedRequest) limit() int {
+	if r.Limit == 0 {
+		return defaultPageSize
+	}
+	return r.Limit
+}
+
+type activityRequest struct {
+	viewerRequest
+	feedRequest
+}
+
+type bannersRequest struct {
+	UserId    int    `json:"user_id" validate:"gte=0"`
+	VisitorId string `json:"visitor_id" validate:"max=64"`
+	Locale    string `json:"locale" validate:"max=16"`
+}
+
+func (s *userServer) CheckEmail(ctx context.Context, in *userpb.CheckEmailRequest) (*userpb.CheckEmailResponse, error) {
+	req := emailRequest{Email: in.Email}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.Usecase.CheckUser(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	return &userpb.CheckEmailResponse{Registered: exists}, nil
+}
+
+func (s *userServer) Authenticate(ctx context.Context, in *userpb.AuthenticateRequest) (*userpb.UserInfo, error) {
+	req := signInRequest{Email: in.Email, Password: in.Password}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	info, err := s.Usecase.AuthUser(ctx, req.Email, req.Password)
+	if err != nil {
+		return nil, err
+	}
+	return &userpb.UserInfo{Id: int64(info.Id), Email: info.Email, Nickname: info.Nickname, Rank: info.Rank}, nil
+}
+
+func (s *userServer) GetProfile(ctx context.Context, in *userpb.GetProfileRequest) (*userpb.Profile, error) {
+	req := userRequest{UserId: int(in.UserId)}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	p, err := s.Usecase.GetProfile(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &userpb.Profile{
+		Id:                 int64(p.Id),
+		Nickname:           p.Nickname,
+		Avatar:             p.Avatar,
+		Bio:                p.Bio,
+		JoinDate:           p.JoinDate,
+		RatingCount:        int32(p.RatingCount),
+		FavoriteCount:      int32(p.FavoriteCount),
+		PlaylistCount:      int32(p.PlaylistCount),
+		RatingDistribution: make([]*userpb.RatingBucket, len(p.RatingDistribution)),
+		FavoriteGenres:     make([]*userpb.GenreAffinity, len(p.FavoriteGenres)),
+		WatchedRuntime:     int32(p.WatchedRuntime),
+	}
+	for i, b := range p.RatingDistribution {
+		res.RatingDistribution[i] = &userpb.RatingBucket{Rating: int32(b.Rating), Count: int32(b.Count)}
+	}
+	for i, g := range p.FavoriteGenres {
+		res.FavoriteGenres[i] = &userpb.GenreAffinity{
+			Id:            int64(g.Id),
+			Name:          g.Name,
+			RatedCount:    int32(g.RatedCount),
+			AverageRating: g.AverageRating,
+		}
+	}
+	return res, nil
+}
+
+func (s *userServer) ListFavorites(ctx context.Context, in *userpb.ListFavoritesRequest) (*userpb.ListFavoritesResponse, error) {
+	req := userRequest{UserId: int(in.UserId)}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	favorites, err := s.Usecase.GetFavorites(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &userpb.ListFavoritesResponse{Favorites: make([]*userpb.Favorite, len(favorites))}
+	for i, f := range favorites {
+		res.Favorites[i] = &userpb.Favorite{MediaId: int64(f.Id), MediaType: f.Type}
+	}
+	return res, nil
+}
+
+func (s *userServer) IsFavorite(ctx context.Context, in *userpb.MediaRequest) (*userpb.IsFavoriteResponse, error) {
+	req := mediaRequest{userRequest{int(in.UserId)}, int(in.MediaId), in.MediaType}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	favorite, err := s.Usecase.GetIsFavorite(ctx, req.UserId, req.MediaId, req.Type)
+	if err != nil {
+		return nil, err
+	}
+	return &userpb.IsFavoriteResponse{Favorite: favorite}, nil
+}
+
+func (s *userServer) SetFavorite(ctx context.Context, in *userpb.SetFavoriteRequest) (*userpb.SetFavoriteResponse, error) {
+	req := mediaRequest{userRequest{int(in.UserId)}, int(in.MediaId), in.MediaType}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	isLiked := 0
+	if in.Favorite {
+		isLiked = 1
+	}
+	if err := s.Usecase.ChangeIsLiked(ctx, req.UserId, req.MediaId, isLiked, req.Type); err != nil {
+		return nil, err
+	}
+	return &userpb.SetFavoriteResponse{}, nil
+}
+
+func (s *userServer) GetRating(ctx context.Context, in *userpb.MediaRequest) (*userpb.Rating, error) {
+	req := mediaRequest{userRequest{int(in.UserId)}, int(in.MediaId), in.MediaType}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	rating, err := s.Usecase.GetRating(ctx, req.UserId, req.MediaId, req.Type)
+	if err != nil {
+		return nil, err
+	}
+	return &userpb.Rating{MediaId: int64(req.MediaId), MediaType: req.Type, Rating: int32(rating)}, nil
+}
+
+func (s *userServer) ListRatings(ctx context.Context, in *userpb.ListRatingsRequest) (*userpb.ListRatingsResponse, error) {
+	req := userRequest{UserId: int(in.UserId)}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	ratings, err := s.Usecase.GetRatingList(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return toRatings(ratings), nil
+}
+
+func (s *userServer) RateTitle(ctx context.Context, in *userpb.RateTitleRequest) (*userpb.ListRatingsResponse, error) {
+	req := ratingRequest{mediaRequest{userRequest{int(in.UserId)}, int(in.MediaId), in.MediaType}, int(in.Rating)}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	ratings, err := s.Usecase.GetChangedRatingList(ctx, req.UserId, req.MediaId, req.Rating, req.Type, in.MarkWatched)
+	if err != nil {
+		return nil, err
+	}
+	return toRatings(ratings), nil
+}
+
+func (s *userServer) ListWatchlist(ctx context.Context, in *userpb.ListWatchlistRequest) (*userpb.ListWatchlistResponse, error) {
+	req := watchlistRequest{userRequest{int(in.UserId)}, pageRequest{int(in.Offset), int(in.Limit)}, in.State}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	offset, limit := req.page()
+	entries, err := s.Usecase.GetWatchlist(ctx, req.UserId, req.State, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &userpb.ListWatchlistResponse{Entries: make([]*userpb.WatchEntry, len(entries))}
+	for i, e := range entries {
+		res.Entries[i] = &userpb.WatchEntry{
+			MediaId:       int64(e.MediaId),
+			MediaType:     e.Type,
+			State:         e.State,
+			WatchCount:    int32(e.WatchCount),
+			RewatchCount:  int32(e.RewatchCount),
+			LastWatchedAt: e.LastWatchedAt,
+			AddedAt:       e.AddedAt,
+		}
+	}
+	return res, nil
+}
+
+func (s *userServer) ListWatchHistory(ctx context.Context, in *userpb.ListWatchHistoryRequest) (*userpb.ListWatchHistoryResponse, error) {
+	req := historyRequest{userRequest{int(in.UserId)}, pageRequest{int(in.Offset), int(in.Limit)}}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	offset, limit := req.page()
+	events, err := s.Usecase.GetWatchHistory(ctx, req.UserId, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &userpb.ListWatchHistoryResponse{Events: make([]*userpb.WatchEvent, len(events))}
+	for i, e := range events {
+		res.Events[i] = &userpb.WatchEvent{Id: int64(e.Id), MediaId: int64(e.MediaId), MediaType: e.Type, WatchedAt: e.WatchedAt}
+	}
+	return res, nil
+}
+
+func (s *userServer) ListPublicPlaylists(ctx context.Context, in *userpb.ListPublicPlaylistsRequest) (*userpb.ListPlaylistsResponse, error) {
+	playlists, err := s.Usecase.GetPublicPlaylists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toPlaylists(playlists), nil
+}
+
+func (s *userServer) ListUserPlaylists(ctx context.Context, in *userpb.ListUserPlaylistsRequest) (*userpb.ListPlaylistsResponse, error) {
+	req := userPlaylistsRequest{viewerRequest{int(in.ViewerId)}, userRequest{int(in.UserId)}}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	playlists, err := s.Usecase.GetUserPlaylists(ctx, req.ViewerId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return toPlaylists(playlists), nil
+}
+
+func (s *userServer) GetPlaylist(ctx context.Context, in *userpb.GetPlaylistRequest) (*userpb.Playlist, error) {
+	req := playlistRequest{viewerRequest{int(in.ViewerId)}, int(in.PlaylistId)}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	playlist, err := s.Usecase.GetPlaylist(ctx, req.ViewerId, req.PlaylistId)
+	if err != nil {
+		return nil, err
+	}
+	return toPlaylist(playlist), nil
+}
+
+func (s *userServer) ListFollowing(ctx context.Context, in *userpb.ListFollowsRequest) (*userpb.ListFollowsResponse, error) {
+	req := userRequest{UserId: int(in.UserId)}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	users, err := s.Usecase.GetFollowing(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return toFollows(users), nil
+}
+
+func (s *userServer) ListFollowers(ctx context.Context, in *userpb.ListFollowsRequest) (*userpb.ListFollowsResponse, error) {
+	req := userRequest{UserId: int(in.UserId)}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	users, err := s.Usecase.GetFollowers(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return toFollows(users), nil
+}
+
+func (s *userServer) GetFeed(ctx context.Context, in *userpb.GetFeedRequest) (*userpb.Feed, error) {
+	req := feedRequest{userRequest{int(in.UserId)}, int(in.Before), int(in.Limit)}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	feed, err := s.Usecase.GetFeed(ctx, req.UserId, req.Before, req.limit())
+	if err != nil {
+		return nil, err
+	}
+	return toFeed(feed), nil
+}
+
+func (s *userServer) GetUserActivity(ctx context.Context, in *userpb.GetUserActivityRequest) (*userpb.Feed, error) {
+	req := activityRequest{viewerRequest{int(in.ViewerId)}, feedRequest{userRequest{int(in.UserId)}, int(in.Before), int(in.Limit)}}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	feed, err := s.Usecase.GetUserActivity(ctx, req.ViewerId, req.UserId, req.Before, req.limit())
+	if err != nil {
+		return nil, err
+	}
+	return toFeed(feed), nil
+}
+
+func (s *userServer) ListActiveBanners(ctx context.Context, in *userpb.ListActiveBannersRequest) (*userpb.ListBannersResponse, error) {
+	req := bannersRequest{int(in.UserId), in.VisitorId, in.Locale}
+	if err := request.Validate(&req); err != nil {
+		return nil, err
+	}
+
+	banners, err := s.Usecase.GetActiveBanners(ctx, req.UserId, req.VisitorId, req.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &userpb.ListBannersResponse{Banners: make([]*userpb.Banner, len(banners))}
+	for i, b := range banners {
+		res.Banners[i] = &userpb.Banner{
+			Id:         int64(b.Id),
+			MovieId:    int64(b.MovieId),
+			Title:      b.Title,
+			MediaType:  b.Type,
+			Comment:    b.Comment,
+			StartAt:    b.StartAt,
+			EndAt:      b.EndAt,
+			Priority:   int32(b.Priority),
+			Audience:   b.Audience,
+			TargetRank: b.TargetRank,
+			Locale:     b.Locale,
+			Slot:       b.Slot,
+			Weight:     int32(b.Weight),
+		}
+	}
+	return res, nil
+}
+
+func toRatings(ratings []userDomain.Rate) *userpb.ListRatingsResponse {
+	res := &userpb.ListRatingsResponse{Ratings: make([]*userpb.Rating, len(ratings))}
+	for i, r := range ratings {
+		res.Ratings[i] = &userpb.Rating{MediaId: int64(r.Id), MediaType: r.Type, Rating: int32(r.Rating), RatedAt: r.ApplyDate}
+	}
+	return res
+}
+
+func toPlaylists(playlists []userDomain.Playlist) *userpb.ListPlaylistsResponse {
+	res := &userpb.ListPlaylistsResponse{Playlists: make([]*userpb.Playlist, len(playlists))}
+	for i, p := range playlists {
+		res.Playlists[i] = toPlaylist(p)
+	}
+	return res
+}
+
+func toPlaylist(p userDomain.Playlist) *userpb.Playlist {
+	res := &userpb.Playlist{
+		Id:         int64(p.Id),
+		UserId:     int64(p.UserId),
+		Name:       p.Name,
+		MediaType:  p.Type,
+		Visibility: p.Visibility,
+		Version:    int32(p.Version),
+		ShareSlug:  p.ShareSlug,
+		ViewCount:  int32(p.ViewCount),
+		ForkedFrom: int64(p.ForkedFrom),
+		Items:      make([]*userpb.PlaylistItem, len(p.Items)),
+	}
+	for i, item := range p.Items {
+		res.Items[i] = &userpb.PlaylistItem{
+			Id:         int64(item.Id),
+			PlaylistId: int64(item.PlaylistId),
+			MediaId:    int64(item.MediaId),
+			MediaType:  item.Type,
+			Position:   int32(item.Position),
+			Note:       item.Note,
+			AddedBy:    int64(item.AddedBy),
+			AddedAt:    item.AddedAt,
+		}
+	}
+	return res
+}
+
+func toFollows(users []userDomain.FollowUser) *userpb.ListFollowsResponse {
+	res := &userpb.ListFollowsResponse{Users: make([]*userpb.FollowUser, len(users))}
+	for i, u := range users {
+		res.Users[i] = &userpb.FollowUser{Id: int64(u.Id), Nickname: u.Nickname, FollowedAt: u.FollowedAt}
+	}
+	return res
+}
+
+func toFeed(feed userDomain.Feed) *userpb.Feed {
+	res := &userpb.Feed{Events: make([]*userpb.ActivityEvent, len(feed.Events)), NextBefore: int64(feed.NextBefore)}
+	for i, e := range feed.Events {
+		res.Events[i] = &userpb.ActivityEvent{
+			Id:           int64(e.Id),
+			UserId:       int64(e.UserId),
+			Nickname:     e.Nickname,
+			Kind:         e.Kind,
+			MediaId:      int64(e.MediaId),
+			MediaType:    e.Type,
+			Rating:       int32(e.Rating),
+			PlaylistId:   int64(e.PlaylistId),
+			PlaylistName: e.PlaylistName,
+			CreatedAt:    e.CreatedAt,
+		}
+	}
+	return res
+}
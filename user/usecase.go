@@ -2,7 +2,9 @@ package user
 
 import (
 	"context"
+	"github.com/null-like/movie-backend/blob"
 	userDomain "github.com/null-like/movie-backend/domain/user"
+	"io"
 )
 
 type Usecase interface {
@@ -13,22 +15,69 @@ type Usecase interface {
 	DeleteAndGetAllUsers(ctx context.Context, id int) ([]userDomain.AllUserInfo, error)
 	UpdateAndGetAllUsers(ctx context.Context, id int, rank string) ([]userDomain.AllUserInfo, error)
 	GetNickName(ctx context.Context, id int) (string, error)
+	GetProfile(ctx context.Context, id int) (userDomain.Profile, error)
+	GetProfiles(ctx context.Context, ids []int) ([]userDomain.Profile, error)
+	ChangeBioAndGetProfile(ctx context.Context, id int, bio string) (userDomain.Profile, error)
+	ChangeAvatarAndGetProfile(ctx context.Context, id int, r io.Reader) (userDomain.Profile, error)
+	GetAvatar(ctx context.Context, userId int, size int, name string) (io.ReadCloser, blob.Info, error)
 
 	GetIsFavorite(ctx context.Context, userId int, movieId int, mediaType string) (bool, error)
 	GetFavorites(ctx context.Context, userId int) ([]userDomain.Favorite, error)
+	GetFavoritesByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Favorite, error)
 	ChangeIsLiked(ctx context.Context, userId int, movieId int, isLiked int, mediaType string) error
-	
+
 	GetRating(ctx context.Context, userId int, movieId int, mediaType string) (int, error)
 	GetRatingList(ctx context.Context, userId int) ([]userDomain.Rate, error)
-	GetChangedRatingList(ctx context.Context, userId int, movieId int, rating int, mediaType string) ([]userDomain.Rate, error)
+	GetRatingListsByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Rate, error)
+	GetChangedRatingList(ctx context.Context, userId int, movieId int, rating int, mediaType string, markWatched bool) ([]userDomain.Rate, error)
+
+	GetWatchlist(ctx context.Context, userId int, state string, offset int, limit int) ([]userDomain.WatchEntry, error)
+	WantToWatch(ctx context.Context, userId int, mediaId int, mediaType string) (userDomain.WatchEntry, error)
+	MarkWatched(ctx context.Context, userId int, mediaId int, mediaType string, watchedAt string) (userDomain.WatchEntry, error)
+	DeleteAndGetWatchlist(ctx context.Context, userId int, mediaId int, mediaType string, offset int, limit int) ([]userDomain.WatchEntry, error)
+	GetWatchHistory(ctx context.Context, userId int, offset int, limit int) ([]userDomain.WatchEvent, error)
+
+	GetPublicPlaylists(ctx context.Context) ([]userDomain.Playlist, error)
+	GetUserPlaylists(ctx context.Context, requesterId int, ownerId int) ([]userDomain.Playlist, error)
+	GetPlaylist(ctx context.Context, requesterId int, id int) (userDomain.Playlist, error)
+	ChangePlaylistAndGetUserPlaylists(ctx context.Context, userId int, id int, title string, visibility string) ([]userDomain.Playlist, error)
+	AddPlaylist(ctx context.Context, userId int, name string, mediaType string, visibility string) (userDomain.Playlist, error)
+	AddPlaylistAndGetUserPlaylists(ctx context.Context, userId int, name string, mediaType string, visibility string) ([]userDomain.Playlist, error)
+	DeletePlaylistAndGetUserPlaylists(ctx context.Context, userId int, id int) ([]userDomain.Playlist, error)
+
+	GetCollaboratingPlaylists(ctx context.Context, userId int) ([]userDomain.Playlist, error)
+
+	SharePlaylist(ctx context.Context, userId int, id int) (userDomain.Playlist, error)
+	UnsharePlaylist(ctx context.Context, userId int, id int) (userDomain.Playlist, error)
+	GetSharedPlaylist(ctx context.Context, slug string) (userDomain.SharedPlaylist, error)
+	ForkSharedPlaylist(ctx context.Context, userId int, slug string) (userDomain.Playlist, error)
+
+	GetPlaylistItems(ctx context.Context, requesterId int, playlistId int) ([]userDomain.PlaylistItem, error)
+	GetPlaylistItemsByPlaylistIds(ctx context.Context, requesterId int, playlistIds []int) (map[int][]userDomain.PlaylistItem, error)
+	AddPlaylistItemAndGetPlaylist(ctx context.Context, userId int, playlistId int, version int, mediaId int, mediaType string, note string) (userDomain.Playlist, error)
+	DeletePlaylistItemAndGetPlaylist(ctx context.Context, userId int, playlistId int, version int, itemId int) (userDomain.Playlist, error)
+	MovePlaylistItemAndGetPlaylist(ctx context.Context, userId int, playlistId int, version int, itemId int, position int) (userDomain.Playlist, error)
+	GetPlaylistChanges(ctx context.Context, requesterId int, playlistId int) ([]userDomain.PlaylistChange, error)
+
+	GetPlaylistMembers(ctx context.Context, requesterId int, playlistId int) ([]userDomain.PlaylistMember, error)
+	AddPlaylistMemberAndGetMembers(ctx context.Context, ownerId int, playlistId int, memberId int, role string) ([]userDomain.PlaylistMember, error)
+	DeletePlaylistMemberAndGetMembers(ctx context.Context, userId int, playlistId int, memberId int) ([]userDomain.PlaylistMember, error)
 
-	GetAllPlaylists(ctx context.Context) ([]userDomain.Playlist, error)
-	ChangePlaylistAndGetAllPlaylists(ctx context.Context, id int, title string, playlist string) ([]userDomain.Playlist, error)
-	AddPlaylistAndGetAllPlaylists(ctx context.Context, name string, playlist string, mediaType string) ([]userDomain.Playlist, error)
-	DeletePlaylistAndGetAllPlaylists(ctx context.Context, id int) ([]userDomain.Playlist, error)
+	FollowAndGetFollowing(ctx context.Context, userId int, followeeId int) ([]userDomain.FollowUser, error)
+	UnfollowAndGetFollowing(ctx context.Context, userId int, followeeId int) ([]userDomain.FollowUser, error)
+	GetFollowing(ctx context.Context, userId int) ([]userDomain.FollowUser, error)
+	GetFollowers(ctx context.Context, userId int) ([]userDomain.FollowUser, error)
+	GetFeed(ctx context.Context, userId int, before int, limit int) (userDomain.Feed, error)
+	GetUserActivity(ctx context.Context, viewerId int, userId int, before int, limit int) (userDomain.Feed, error)
+	ChangeActivityVisibility(ctx context.Context, userId int, visibility string) (string, error)
 
 	GetAllBanners(ctx context.Context) ([]userDomain.Banner, error)
-	UpdateAndGetAllBanners(ctx context.Context, id int, movieId int, title string, mediaType string, comment string) ([]userDomain.Banner, error)
-	AddAndGetAllBanners(ctx context.Context, movieId int, title string, mediaType string, comment string) ([]userDomain.Banner, error)
+	GetBanner(ctx context.Context, id int) (userDomain.Banner, error)
+	AddBanner(ctx context.Context, banner userDomain.Banner) (userDomain.Banner, error)
+	GetActiveBanners(ctx context.Context, userId int, visitorId string, locale string) ([]userDomain.Banner, error)
+	UpdateAndGetAllBanners(ctx context.Context, banner userDomain.Banner) ([]userDomain.Banner, error)
+	AddAndGetAllBanners(ctx context.Context, banner userDomain.Banner) ([]userDomain.Banner, error)
 	DeleteAndGetAllBanners(ctx context.Context, id int) ([]userDomain.Banner, error)
+	RecordBannerEvent(ctx context.Context, bannerId int, userId int, visitorId string, kind string) error
+	GetBannerReport(ctx context.Context, slot string) (userDomain.BannerReport, error)
 }
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/null-like/movie-backend/blob"
+	userDomain "github.com/null-like/movie-backend/domain/user"
+	"golang.org/x/image/draw"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// avatarFormats maps the sniffed content type of an upload to the extension
+// its thumbnails are stored with. GIFs become PNG thumbnails.
+var avatarFormats = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "png",
+}
+
+// ChangeAvatarAndGetProfile checks an uploaded image, stores a thumbnail in
+// every AvatarSizes and points the profile at it. The file name is derived
+// from the content, so thumbnails never change once stored and can be
+// cached for good.
+func (u *userUsecase) ChangeAvatarAndGetProfile(ctx context.Context, id int, r io.Reader) (userDomain.Profile, error) {
+	profile, err := u.GetProfile(ctx, id)
+	if err != nil {
+		return profile, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, userDomain.MaxAvatarBytes+1))
+	if err != nil {
+		u.logger.Error(err)
+		return profile, err
+	}
+	if len(data) > userDomain.MaxAvatarBytes {
+		return profile, userDomain.ErrAvatarTooLarge
+	}
+
+	ext, ok := avatarFormats[http.DetectContentType(data)]
+	if !ok {
+		return profile, userDomain.ErrUnsupportedImage
+	}
+
+	// Check the dimensions before decoding so a small file claiming a huge
+	// image can't make us allocate it.
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return profile, fmt.Errorf("%w: %v", userDomain.ErrUnsupportedImage, err)
+	}
+	if config.Width > userDomain.MaxAvatarDimension || config.Height > userDomain.MaxAvatarDimension {
+		return profile, userDomain.ErrAvatarTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return profile, fmt.Errorf("%w: %v", userDomain.ErrUnsupportedImage, err)
+	}
+
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:12]) + "." + ext
+	contentType := mime.TypeByExtension("." + ext)
+	for _, size := range userDomain.AvatarSizes {
+		var buf bytes.Buffer
+		thumb := thumbnail(src, size)
+		if ext == "jpg" {
+			err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85})
+		} else {
+			err = png.Encode(&buf, thumb)
+		}
+		if err != nil {
+			u.logger.Error(err)
+			return profile, err
+		}
+
+		err = u.avatarStore.Put(ctx, userDomain.AvatarKey(id, size, name), &buf, contentType)
+		if err != nil {
+			u.logger.Error(err)
+			return profile, err
+		}
+	}
+
+	avatar := fmt.Sprintf("%d/%s", id, name)
+	err = u.userRepo.UpdateAvatar(ctx, id, avatar)
+	if err != nil {
+		u.logger.Error(err)
+		return profile, err
+	}
+
+	if profile.Avatar != "" && profile.Avatar != avatar {
+		u.deleteAvatar(ctx, id, profile.Avatar)
+	}
+
+	return u.GetProfile(ctx, id)
+}
+
+// deleteAvatar removes the thumbnails of a replaced avatar. They are
+// unreachable once the profile moves on, so failures are only logged.
+func (u *userUsecase) deleteAvatar(ctx context.Context, id int, avatar string) {
+	name := avatar[strings.LastIndex(avatar, "/")+1:]
+	for _, size := range userDomain.AvatarSizes {
+		err := u.avatarStore.Delete(ctx, userDomain.AvatarKey(id, size, name))
+		if err != nil {
+			u.logger.Error(err)
+		}
+	}
+}
+
+// thumbnail crops the centre square of src and scales it to size x size.
+func thumbnail(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	x := b.Min.X + (b.Dx()-side)/2
+	y := b.Min.Y + (b.Dy()-side)/2
+	crop := image.Rect(x, y, x+side, y+side)
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Over, nil)
+	return dst
+}
+
+func (u *userUsecase) GetAvatar(ctx context.Context, userId int, size int, name string) (io.ReadCloser, blob.Info, error) {
+	known := false
+	for _, s := range userDomain.AvatarSizes {
+		known = known || s == size
+	}
+	if !known {
+		return nil, blob.Info{}, userDomain.ErrInvalidAvatarSize
+	}
+
+	rc, info, err := u.avatarStore.Get(ctx, userDomain.AvatarKey(userId, size, name))
+	if errors.Is(err, blob.ErrNotFound) || errors.Is(err, blob.ErrInvalidKey) {
+		return nil, blob.Info{}, userDomain.ErrAvatarNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return nil, blob.Info{}, err
+	}
+	return rc, info, nil
+}
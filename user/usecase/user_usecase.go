@@ -2,30 +2,87 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/null-like/movie-backend/blob"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	tvDomain "github.com/null-like/movie-backend/domain/tv"
 	userDomain "github.com/null-like/movie-backend/domain/user"
+	"github.com/null-like/movie-backend/movie"
+	"github.com/null-like/movie-backend/tv"
 	"github.com/null-like/movie-backend/user"
 	"github.com/sirupsen/logrus"
+	"hash/fnv"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 type userUsecase struct {
-	logger   *logrus.Logger
-	userRepo user.Repository
+	logger      *logrus.Logger
+	userRepo    user.Repository
+	movieRepo   movie.Repository
+	tvRepo      tv.Repository
+	avatarStore blob.Storage
 }
 
-func NewUserUsecase(l *logrus.Logger, r user.Repository) user.Usecase {
+func NewUserUsecase(l *logrus.Logger, r user.Repository, mr movie.Repository, tr tv.Repository, as blob.Storage) user.Usecase {
 	return &userUsecase{
-		logger:   l,
-		userRepo: r,
+		logger:      l,
+		userRepo:    r,
+		movieRepo:   mr,
+		tvRepo:      tr,
+		avatarStore: as,
 	}
 }
 
+// checkMediaExists looks the media up in the catalog matching mediaType,
+// so favorites and ratings can't point at titles we don't have.
+func (u *userUsecase) checkMediaExists(ctx context.Context, mediaId int, mediaType string) error {
+	var exists bool
+	var err error
+
+	switch mediaType {
+	case movieDomain.MediaType:
+		exists, err = u.movieRepo.ExistMovieById(ctx, mediaId)
+	case tvDomain.MediaType:
+		exists, err = u.tvRepo.ExistSeriesById(ctx, mediaId)
+	default:
+		return fmt.Errorf("%w: %q", userDomain.ErrUnknownMediaType, mediaType)
+	}
+
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s %d", userDomain.ErrMediaNotFound, mediaType, mediaId)
+	}
+	return nil
+}
+
 func (u *userUsecase) RegisterUser(ctx context.Context, user userDomain.User) error {
 	hash := sha256.New()
 	hash.Write([]byte(user.Password))
 	user.Password = hex.EncodeToString(hash.Sum(nil))
-	err := u.userRepo.InsertUser(ctx, user)
+
+	exists, err := u.userRepo.FindIdByEmail(ctx, user.Email)
+	if err != nil {
+		u.logger.Error(err)
+		return err
+	}
+	if exists {
+		return userDomain.ErrEmailTaken
+	}
+
+	err = u.userRepo.InsertUser(ctx, user)
 	if err != nil {
 		u.logger.Error(err)
 		return err
@@ -45,31 +102,23 @@ func (u *userUsecase) AuthUser(ctx context.Context, email string, password strin
 	hash.Write([]byte(password))
 	hashPassword = hex.EncodeToString(hash.Sum(nil))
 	id, email, dbPassword, nickname, rank, err := u.userRepo.FindIdAndPasswdByEmail(ctx, email)
-
-	var userInfo userDomain.UserInfo
-
+	if errors.Is(err, sql.ErrNoRows) {
+		return userDomain.UserInfo{}, userDomain.ErrInvalidCredentials
+	}
 	if err != nil {
 		u.logger.Error(err)
-		userInfo.Id = -1
-		userInfo.Email = ""
-		userInfo.Nickname = ""
-		userInfo.Rank = ""
-		return userInfo, err
+		return userDomain.UserInfo{}, err
 	}
-
-	if dbPassword == hashPassword {
-		userInfo.Id = id
-		userInfo.Email = email
-		userInfo.Nickname = nickname
-		userInfo.Rank = rank
-		return userInfo, nil
-	} else {
-		userInfo.Id = -1
-		userInfo.Email = ""
-		userInfo.Nickname = ""
-		userInfo.Rank = ""
-		return userInfo, nil
+	if dbPassword != hashPassword {
+		return userDomain.UserInfo{}, userDomain.ErrInvalidCredentials
 	}
+
+	return userDomain.UserInfo{
+		Id:       id,
+		Email:    email,
+		Nickname: nickname,
+		Rank:     rank,
+	}, nil
 }
 
 func (u *userUsecase) GetAllUsers(ctx context.Context) ([]userDomain.AllUserInfo, error) {
@@ -113,6 +162,9 @@ func (u *userUsecase) UpdateAndGetAllUsers(ctx context.Context, id int, rank str
 
 func (u *userUsecase) GetNickName(ctx context.Context, id int) (string, error) {
 	nickname, err := u.userRepo.FindNicknameByUserId(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", userDomain.ErrUserNotFound
+	}
 	if err != nil {
 		u.logger.Error(err)
 		return nickname, err
@@ -120,6 +172,64 @@ func (u *userUsecase) GetNickName(ctx context.Context, id int) (string, error) {
 	return nickname, nil
 }
 
+// profileGenreCount is how many favorite genres a profile lists.
+const profileGenreCount = 5
+
+func (u *userUsecase) GetProfile(ctx context.Context, id int) (userDomain.Profile, error) {
+	profile, err := u.userRepo.FindProfileById(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return userDomain.Profile{}, userDomain.ErrUserNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.Profile{}, err
+	}
+
+	profile.RatingDistribution, err = u.userRepo.FindRatingDistribution(ctx, id)
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.Profile{}, err
+	}
+	profile.FavoriteGenres, err = u.userRepo.FindRatedGenres(ctx, id, profileGenreCount)
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.Profile{}, err
+	}
+	profile.WatchedRuntime, err = u.userRepo.FindWatchedRuntime(ctx, id)
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.Profile{}, err
+	}
+
+	return profile, nil
+}
+
+// GetProfiles reads the profiles of the users among ids that exist, with
+// their counts but without the rating statistics GetProfile adds.
+func (u *userUsecase) GetProfiles(ctx context.Context, ids []int) ([]userDomain.Profile, error) {
+	profiles, err := u.userRepo.FindProfilesByIds(ctx, ids)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	return profiles, nil
+}
+
+func (u *userUsecase) ChangeBioAndGetProfile(ctx context.Context, id int, bio string) (userDomain.Profile, error) {
+	bio = strings.TrimSpace(bio)
+	if utf8.RuneCountInString(bio) > userDomain.MaxBioLength {
+		return userDomain.Profile{}, userDomain.ErrBioTooLong
+	}
+
+	err := u.userRepo.UpdateBio(ctx, id, bio)
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.Profile{}, err
+	}
+
+	return u.GetProfile(ctx, id)
+}
+
 func (u *userUsecase) GetIsFavorite(ctx context.Context, userId int, movieId int, mediaType string) (bool, error) {
 	isFavorite, err := u.userRepo.FindIsFavorite(ctx, userId, movieId, mediaType)
 	return isFavorite, err
@@ -134,11 +244,30 @@ func (u *userUsecase) GetFavorites(ctx context.Context, userId int) ([]userDomai
 	return movieId, nil
 }
 
+func (u *userUsecase) GetFavoritesByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Favorite, error) {
+	favorites, err := u.userRepo.FindFavoritesByUserIds(ctx, userIds)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	return favorites, nil
+}
+
 func (u *userUsecase) ChangeIsLiked(ctx context.Context, userId int, movieId int, isLiked int, mediaType string) error {
 	var err error
 
 	if isLiked == 1 {
+		err = u.checkMediaExists(ctx, movieId, mediaType)
+		if err != nil {
+			u.logger.Error(err)
+			return err
+		}
 		err = u.userRepo.InsertFavorite(ctx, userId, movieId, mediaType)
+		if err == nil {
+			u.recordActivity(ctx, userDomain.ActivityEvent{
+				UserId: userId, Kind: userDomain.ActivityFavorite, MediaId: movieId, Type: mediaType,
+			})
+		}
 	} else {
 		err = u.userRepo.DeleteFavorite(ctx, userId, movieId, mediaType)
 	}
@@ -152,6 +281,9 @@ func (u *userUsecase) ChangeIsLiked(ctx context.Context, userId int, movieId int
 
 func (u *userUsecase) GetRating(ctx context.Context, userId int, movieId int, mediaType string) (int, error) {
 	rating, err := u.userRepo.FindRatingByMovieId(ctx, userId, movieId, mediaType)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, userDomain.ErrRatingNotFound
+	}
 	if err != nil {
 		u.logger.Error(err)
 		return 0, err
@@ -168,12 +300,48 @@ func (u *userUsecase) GetRatingList(ctx context.Context, userId int) ([]userDoma
 	return movieRatings, nil
 }
 
-func (u *userUsecase) GetChangedRatingList(ctx context.Context, userId int, movieId int, rating int, mediaType string) ([]userDomain.Rate, error) {
-	err := u.userRepo.InsertRating(ctx, userId, movieId, rating, mediaType)
+func (u *userUsecase) GetRatingListsByUserIds(ctx context.Context, userIds []int) (map[int][]userDomain.Rate, error) {
+	ratings, err := u.userRepo.FindRatingsByUserIds(ctx, userIds)
 	if err != nil {
 		u.logger.Error(err)
 		return nil, err
 	}
+	return ratings, nil
+}
+
+// GetChangedRatingList stores a rating. With markWatched set, a title that
+// isn't already marked watched gets a viewing recorded now; re-rating a
+// watched title doesn't count as a rewatch.
+func (u *userUsecase) GetChangedRatingList(ctx context.Context, userId int, movieId int, rating int, mediaType string, markWatched bool) ([]userDomain.Rate, error) {
+	err := u.checkMediaExists(ctx, movieId, mediaType)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+
+	err = u.userRepo.InsertRating(ctx, userId, movieId, rating, mediaType)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	u.recordActivity(ctx, userDomain.ActivityEvent{
+		UserId: userId, Kind: userDomain.ActivityRating, MediaId: movieId, Type: mediaType, Rating: rating,
+	})
+
+	if markWatched {
+		entry, err := u.userRepo.FindWatchEntry(ctx, userId, movieId, mediaType)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			u.logger.Error(err)
+			return nil, err
+		}
+		if entry.State != userDomain.WatchStateWatched {
+			err = u.userRepo.InsertWatch(ctx, userId, movieId, mediaType, "")
+			if err != nil {
+				u.logger.Error(err)
+				return nil, err
+			}
+		}
+	}
 
 	movieRatings, err := u.userRepo.FindRatingsByUserId(ctx, userId)
 	if err != nil {
@@ -183,40 +351,119 @@ func (u *userUsecase) GetChangedRatingList(ctx context.Context, userId int, movi
 	return movieRatings, nil
 }
 
-func (u *userUsecase) GetAllPlaylists(ctx context.Context) ([]userDomain.Playlist, error) {
-	playlists, err := u.userRepo.AllPlaylist(ctx)
+func (u *userUsecase) GetWatchlist(ctx context.Context, userId int, state string, offset int, limit int) ([]userDomain.WatchEntry, error) {
+	if state != "" && state != userDomain.WatchStateWant && state != userDomain.WatchStateWatched {
+		return nil, userDomain.ErrInvalidWatchState
+	}
+
+	entries, err := u.userRepo.FindWatchlist(ctx, userId, state, offset, limit)
 	if err != nil {
 		u.logger.Error(err)
 		return nil, err
 	}
+	return entries, nil
+}
+
+func (u *userUsecase) WantToWatch(ctx context.Context, userId int, mediaId int, mediaType string) (userDomain.WatchEntry, error) {
+	err := u.checkMediaExists(ctx, mediaId, mediaType)
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.WatchEntry{}, err
+	}
 
-	return playlists, nil
+	err = u.userRepo.UpsertWantToWatch(ctx, userId, mediaId, mediaType)
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.WatchEntry{}, err
+	}
+
+	return u.watchEntry(ctx, userId, mediaId, mediaType)
+}
+
+// MarkWatched records a viewing. watchedAt is optional and may be a date, a
+// WatchTimeLayout time or RFC 3339; it can't be in the future.
+func (u *userUsecase) MarkWatched(ctx context.Context, userId int, mediaId int, mediaType string, watchedAt string) (userDomain.WatchEntry, error) {
+	if watchedAt != "" {
+		when, err := parseWatchTime(watchedAt)
+		if err != nil {
+			return userDomain.WatchEntry{}, err
+		}
+		watchedAt = when.Format(userDomain.WatchTimeLayout)
+	}
+
+	err := u.checkMediaExists(ctx, mediaId, mediaType)
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.WatchEntry{}, err
+	}
+
+	err = u.userRepo.InsertWatch(ctx, userId, mediaId, mediaType, watchedAt)
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.WatchEntry{}, err
+	}
+
+	return u.watchEntry(ctx, userId, mediaId, mediaType)
+}
+
+func parseWatchTime(value string) (time.Time, error) {
+	var when time.Time
+	var err error
+	for _, layout := range []string{userDomain.WatchTimeLayout, "2006-01-02"} {
+		if when, err = time.ParseInLocation(layout, value, time.Local); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		if when, err = time.Parse(time.RFC3339, value); err != nil {
+			return time.Time{}, fmt.Errorf("%w: %q", userDomain.ErrInvalidWatchDate, value)
+		}
+		when = when.In(time.Local)
+	}
+	if when.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("%w: %q is in the future", userDomain.ErrInvalidWatchDate, value)
+	}
+	return when, nil
 }
 
-func (u *userUsecase) ChangePlaylistAndGetAllPlaylists(ctx context.Context, id int, name string, playlist string) ([]userDomain.Playlist, error) {
-	err := u.userRepo.InsertPlaylist(ctx, id, name, playlist)
+func (u *userUsecase) watchEntry(ctx context.Context, userId int, mediaId int, mediaType string) (userDomain.WatchEntry, error) {
+	entry, err := u.userRepo.FindWatchEntry(ctx, userId, mediaId, mediaType)
+	if errors.Is(err, sql.ErrNoRows) {
+		return userDomain.WatchEntry{}, userDomain.ErrWatchEntryNotFound
+	}
 	if err != nil {
 		u.logger.Error(err)
+		return userDomain.WatchEntry{}, err
+	}
+	return entry, nil
+}
+
+func (u *userUsecase) DeleteAndGetWatchlist(ctx context.Context, userId int, mediaId int, mediaType string, offset int, limit int) ([]userDomain.WatchEntry, error) {
+	_, err := u.watchEntry(ctx, userId, mediaId, mediaType)
+	if err != nil {
 		return nil, err
 	}
 
-	playlists, err := u.userRepo.AllPlaylist(ctx)
+	err = u.userRepo.DeleteWatchEntry(ctx, userId, mediaId, mediaType)
 	if err != nil {
 		u.logger.Error(err)
 		return nil, err
 	}
 
-	return playlists, nil
+	return u.GetWatchlist(ctx, userId, "", offset, limit)
 }
 
-func (u *userUsecase) AddPlaylistAndGetAllPlaylists(ctx context.Context, name string, playlist string, mediaType string) ([]userDomain.Playlist, error) {
-	err := u.userRepo.InsertPlaylist2(ctx, name, playlist, mediaType)
+func (u *userUsecase) GetWatchHistory(ctx context.Context, userId int, offset int, limit int) ([]userDomain.WatchEvent, error) {
+	events, err := u.userRepo.FindWatchHistory(ctx, userId, offset, limit)
 	if err != nil {
 		u.logger.Error(err)
 		return nil, err
 	}
+	return events, nil
+}
 
-	playlists, err := u.userRepo.AllPlaylist(ctx)
+func (u *userUsecase) GetPublicPlaylists(ctx context.Context) ([]userDomain.Playlist, error) {
+	playlists, err := u.userRepo.FindPublicPlaylists(ctx)
 	if err != nil {
 		u.logger.Error(err)
 		return nil, err
@@ -225,52 +472,1103 @@ func (u *userUsecase) AddPlaylistAndGetAllPlaylists(ctx context.Context, name st
 	return playlists, nil
 }
 
-func (u *userUsecase) DeletePlaylistAndGetAllPlaylists(ctx context.Context, id int) ([]userDomain.Playlist, error) {
-	err := u.userRepo.DeletePlaylist(ctx, id)
+// GetUserPlaylists lists ownerId's playlists. Owners see all of their own
+// playlists, everybody else only the public ones.
+func (u *userUsecase) GetUserPlaylists(ctx context.Context, requesterId int, ownerId int) ([]userDomain.Playlist, error) {
+	playlists, err := u.userRepo.FindPlaylistsByUserId(ctx, ownerId)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+
+	if requesterId == ownerId {
+		return playlists, nil
+	}
+
+	var visible []userDomain.Playlist
+	for _, playlist := range playlists {
+		if playlist.Visibility == userDomain.VisibilityPublic {
+			visible = append(visible, playlist)
+		}
+	}
+	return visible, nil
+}
+
+func (u *userUsecase) GetPlaylist(ctx context.Context, requesterId int, id int) (userDomain.Playlist, error) {
+	playlist, err := u.userRepo.ReadPlaylistById(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return playlist, userDomain.ErrPlaylistNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+
+	if !playlist.VisibleTo(requesterId) {
+		_, err = u.userRepo.FindPlaylistRole(ctx, id, requesterId)
+		if errors.Is(err, sql.ErrNoRows) {
+			return userDomain.Playlist{}, userDomain.ErrPlaylistNotFound
+		}
+		if err != nil {
+			u.logger.Error(err)
+			return userDomain.Playlist{}, err
+		}
+	}
+
+	playlist.Items, err = u.userRepo.FindPlaylistItems(ctx, id)
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+	return playlist, nil
+}
+
+// ownedPlaylist loads the playlist and checks that userId owns it.
+func (u *userUsecase) ownedPlaylist(ctx context.Context, userId int, id int) (userDomain.Playlist, error) {
+	playlist, err := u.GetPlaylist(ctx, userId, id)
+	if err != nil {
+		return playlist, err
+	}
+
+	if playlist.UserId == 0 || playlist.UserId != userId {
+		return playlist, userDomain.ErrNotPlaylistOwner
+	}
+	return playlist, nil
+}
+
+func (u *userUsecase) ChangePlaylistAndGetUserPlaylists(ctx context.Context, userId int, id int, name string, visibility string) ([]userDomain.Playlist, error) {
+	current, err := u.ownedPlaylist(ctx, userId, id)
 	if err != nil {
 		u.logger.Error(err)
 		return nil, err
 	}
 
-	playlists, err := u.userRepo.AllPlaylist(ctx)
+	if name == "" {
+		name = current.Name
+	}
+	if visibility == "" {
+		visibility = current.Visibility
+	}
+	if !userDomain.ValidVisibility(visibility) {
+		return nil, userDomain.ErrInvalidVisibility
+	}
+
+	err = u.userRepo.UpdatePlaylist(ctx, id, name, visibility)
 	if err != nil {
 		u.logger.Error(err)
 		return nil, err
 	}
+	if current.Visibility != userDomain.VisibilityPublic && visibility == userDomain.VisibilityPublic {
+		u.recordActivity(ctx, userDomain.ActivityEvent{
+			UserId: userId, Kind: userDomain.ActivityPlaylistPublished, PlaylistId: id,
+		})
+	}
 
-	return playlists, nil
+	return u.GetUserPlaylists(ctx, userId, userId)
 }
 
-func (u *userUsecase) GetAllBanners(ctx context.Context) ([]userDomain.Banner, error) {
-	banners, err := u.userRepo.AllBanner(ctx)
+func (u *userUsecase) AddPlaylist(ctx context.Context, userId int, name string, mediaType string, visibility string) (userDomain.Playlist, error) {
+	if visibility == "" {
+		visibility = userDomain.VisibilityPrivate
+	}
+	if !userDomain.ValidVisibility(visibility) {
+		return userDomain.Playlist{}, userDomain.ErrInvalidVisibility
+	}
+
+	id, err := u.userRepo.InsertPlaylist(ctx, userId, name, mediaType, visibility)
 	if err != nil {
 		u.logger.Error(err)
+		return userDomain.Playlist{}, err
+	}
+
+	return u.GetPlaylist(ctx, userId, id)
+}
+
+func (u *userUsecase) AddPlaylistAndGetUserPlaylists(ctx context.Context, userId int, name string, mediaType string, visibility string) ([]userDomain.Playlist, error) {
+	_, err := u.AddPlaylist(ctx, userId, name, mediaType, visibility)
+	if err != nil {
 		return nil, err
 	}
 
-	return banners, nil
+	return u.GetUserPlaylists(ctx, userId, userId)
 }
 
-func (u *userUsecase) UpdateAndGetAllBanners(ctx context.Context, id int, movieId int, title string, mediaType string, comment string) ([]userDomain.Banner, error) {
-	err := u.userRepo.UpdateBanner(ctx, id, movieId, title, mediaType, comment)
+func (u *userUsecase) DeletePlaylistAndGetUserPlaylists(ctx context.Context, userId int, id int) ([]userDomain.Playlist, error) {
+	_, err := u.ownedPlaylist(ctx, userId, id)
 	if err != nil {
 		u.logger.Error(err)
 		return nil, err
 	}
 
-	banners, err := u.userRepo.AllBanner(ctx)
+	err = u.userRepo.DeletePlaylist(ctx, id)
 	if err != nil {
 		u.logger.Error(err)
 		return nil, err
 	}
 
-	return banners, nil
+	return u.GetUserPlaylists(ctx, userId, userId)
+}
+
+// editablePlaylist loads the playlist and checks that userId is its owner or
+// an editor.
+func (u *userUsecase) editablePlaylist(ctx context.Context, userId int, id int) (userDomain.Playlist, error) {
+	playlist, err := u.GetPlaylist(ctx, userId, id)
+	if err != nil {
+		return playlist, err
+	}
+
+	if playlist.UserId != 0 && playlist.UserId == userId {
+		return playlist, nil
+	}
+
+	role, err := u.userRepo.FindPlaylistRole(ctx, id, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return playlist, userDomain.ErrNotPlaylistEditor
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+	if role != userDomain.PlaylistRoleEditor {
+		return playlist, userDomain.ErrNotPlaylistEditor
+	}
+	return playlist, nil
+}
+
+// expectedVersion is the version an item change must apply to. Clients send
+// the version they last saw; without one the change is checked against the
+// version loaded for this request.
+func expectedVersion(playlist userDomain.Playlist, version int) (int, error) {
+	if version == 0 {
+		return playlist.Version, nil
+	}
+	if version != playlist.Version {
+		return 0, userDomain.ErrPlaylistVersionConflict
+	}
+	return version, nil
+}
+
+func (u *userUsecase) GetCollaboratingPlaylists(ctx context.Context, userId int) ([]userDomain.Playlist, error) {
+	playlists, err := u.userRepo.FindPlaylistsByMemberId(ctx, userId)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	return playlists, nil
+}
+
+const shareSlugBytes = 16
+
+func newShareSlug() (string, error) {
+	b := make([]byte, shareSlugBytes)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func validShareSlug(slug string) bool {
+	if len(slug) != base64.RawURLEncoding.EncodedLen(shareSlugBytes) {
+		return false
+	}
+	_, err := base64.RawURLEncoding.DecodeString(slug)
+	return err == nil
+}
+
+// SharePlaylist gives the playlist a share link. Anyone holding the link can
+// read the playlist, whatever its visibility, until the owner unshares it.
+func (u *userUsecase) SharePlaylist(ctx context.Context, userId int, id int) (userDomain.Playlist, error) {
+	playlist, err := u.ownedPlaylist(ctx, userId, id)
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+
+	if playlist.ShareSlug != "" {
+		return playlist, nil
+	}
+
+	slug, err := newShareSlug()
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+
+	err = u.userRepo.UpdatePlaylistShareSlug(ctx, id, slug)
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+
+	return u.GetPlaylist(ctx, userId, id)
+}
+
+func (u *userUsecase) UnsharePlaylist(ctx context.Context, userId int, id int) (userDomain.Playlist, error) {
+	playlist, err := u.ownedPlaylist(ctx, userId, id)
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+
+	err = u.userRepo.UpdatePlaylistShareSlug(ctx, id, "")
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+
+	return u.GetPlaylist(ctx, userId, id)
+}
+
+func (u *userUsecase) readSharedPlaylist(ctx context.Context, slug string) (userDomain.Playlist, error) {
+	if !validShareSlug(slug) {
+		return userDomain.Playlist{}, userDomain.ErrInvalidShareSlug
+	}
+
+	playlist, err := u.userRepo.ReadPlaylistByShareSlug(ctx, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return playlist, userDomain.ErrPlaylistNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+	return playlist, nil
+}
+
+// GetSharedPlaylist renders a shared playlist with every item resolved to its
+// movie or series, and counts the view.
+func (u *userUsecase) GetSharedPlaylist(ctx context.Context, slug string) (userDomain.SharedPlaylist, error) {
+	var shared userDomain.SharedPlaylist
+
+	playlist, err := u.readSharedPlaylist(ctx, slug)
+	if err != nil {
+		return shared, err
+	}
+
+	err = u.userRepo.IncrementPlaylistViewCount(ctx, playlist.Id)
+	if err != nil {
+		u.logger.Error(err)
+		return shared, err
+	}
+
+	nickname, err := u.userRepo.FindNicknameByUserId(ctx, playlist.UserId)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		u.logger.Error(err)
+		return shared, err
+	}
+
+	items, err := u.userRepo.FindPlaylistItems(ctx, playlist.Id)
+	if err != nil {
+		u.logger.Error(err)
+		return shared, err
+	}
+
+	shared = userDomain.SharedPlaylist{
+		Id:            playlist.Id,
+		Name:          playlist.Name,
+		Type:          playlist.Type,
+		OwnerId:       playlist.UserId,
+		OwnerNickname: nickname,
+		ViewCount:     playlist.ViewCount + 1,
+		ForkedFrom:    playlist.ForkedFrom,
+	}
+	for _, item := range items {
+		sharedItem := userDomain.SharedPlaylistItem{
+			Position: item.Position,
+			Note:     item.Note,
+			AddedAt:  item.AddedAt,
+			Type:     item.Type,
+		}
+
+		switch item.Type {
+		case movieDomain.MediaType:
+			movieInfo, err := u.movieRepo.ReadMovieById(ctx, item.MediaId)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				u.logger.Error(err)
+				return shared, err
+			}
+			sharedItem.Movie = &movieInfo
+		case tvDomain.MediaType:
+			series, err := u.tvRepo.ReadSeriesById(ctx, item.MediaId)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				u.logger.Error(err)
+				return shared, err
+			}
+			sharedItem.Series = &series
+		default:
+			continue
+		}
+
+		shared.Items = append(shared.Items, sharedItem)
+	}
+
+	return shared, nil
 }
 
-func (u *userUsecase) AddAndGetAllBanners(ctx context.Context, movieId int, title string, mediaType string, comment string) ([]userDomain.Banner, error) {
-	err := u.userRepo.InsertBanner(ctx, movieId, title, mediaType, comment)
+// ForkSharedPlaylist copies a shared playlist into userId's account as a new
+// private playlist.
+func (u *userUsecase) ForkSharedPlaylist(ctx context.Context, userId int, slug string) (userDomain.Playlist, error) {
+	source, err := u.readSharedPlaylist(ctx, slug)
+	if err != nil {
+		return source, err
+	}
+
+	_, err = u.userRepo.FindNicknameByUserId(ctx, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return userDomain.Playlist{}, userDomain.ErrUserNotFound
+	}
 	if err != nil {
 		u.logger.Error(err)
+		return userDomain.Playlist{}, err
+	}
+
+	id, err := u.userRepo.ForkPlaylist(ctx, source.Id, userId, source.Name)
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.Playlist{}, err
+	}
+
+	return u.GetPlaylist(ctx, userId, id)
+}
+
+func (u *userUsecase) GetPlaylistItems(ctx context.Context, requesterId int, playlistId int) ([]userDomain.PlaylistItem, error) {
+	playlist, err := u.GetPlaylist(ctx, requesterId, playlistId)
+	if err != nil {
+		return nil, err
+	}
+	return playlist.Items, nil
+}
+
+// GetPlaylistItemsByPlaylistIds reads the items of the playlists among
+// playlistIds that requesterId may see, keyed by playlist id. Playlists that
+// don't exist or are hidden from the requester are left out, as GetPlaylist
+// would report them not found.
+func (u *userUsecase) GetPlaylistItemsByPlaylistIds(ctx context.Context, requesterId int, playlistIds []int) (map[int][]userDomain.PlaylistItem, error) {
+	playlists, err := u.userRepo.FindPlaylistsByIds(ctx, playlistIds)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+
+	var visible []int
+	var memberOf map[int]bool
+	for _, playlist := range playlists {
+		if !playlist.VisibleTo(requesterId) {
+			if memberOf == nil {
+				memberOf = map[int]bool{}
+				collaborating, err := u.userRepo.FindPlaylistsByMemberId(ctx, requesterId)
+				if err != nil {
+					u.logger.Error(err)
+					return nil, err
+				}
+				for _, p := range collaborating {
+					memberOf[p.Id] = true
+				}
+			}
+			if !memberOf[playlist.Id] {
+				continue
+			}
+		}
+		visible = append(visible, playlist.Id)
+	}
+
+	items, err := u.userRepo.FindPlaylistItemsByPlaylistIds(ctx, visible)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	for _, id := range visible {
+		if _, ok := items[id]; !ok {
+			items[id] = []userDomain.PlaylistItem{}
+		}
+	}
+	return items, nil
+}
+
+func (u *userUsecase) AddPlaylistItemAndGetPlaylist(ctx context.Context, userId int, playlistId int, version int, mediaId int, mediaType string, note string) (userDomain.Playlist, error) {
+	playlist, err := u.editablePlaylist(ctx, userId, playlistId)
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+
+	version, err = expectedVersion(playlist, version)
+	if err != nil {
+		return playlist, err
+	}
+
+	err = u.checkMediaExists(ctx, mediaId, mediaType)
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+
+	for _, item := range playlist.Items {
+		if item.MediaId == mediaId && item.Type == mediaType {
+			return playlist, userDomain.ErrPlaylistItemExists
+		}
+	}
+
+	err = u.userRepo.InsertPlaylistItem(ctx, playlistId, version, userId, mediaId, mediaType, note)
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+	if playlist.Visibility == userDomain.VisibilityPublic {
+		u.recordActivity(ctx, userDomain.ActivityEvent{
+			UserId: userId, Kind: userDomain.ActivityPlaylistItem, MediaId: mediaId, Type: mediaType, PlaylistId: playlistId,
+		})
+	}
+
+	return u.GetPlaylist(ctx, userId, playlistId)
+}
+
+func (u *userUsecase) DeletePlaylistItemAndGetPlaylist(ctx context.Context, userId int, playlistId int, version int, itemId int) (userDomain.Playlist, error) {
+	playlist, err := u.editablePlaylist(ctx, userId, playlistId)
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+
+	version, err = expectedVersion(playlist, version)
+	if err != nil {
+		return playlist, err
+	}
+
+	err = u.userRepo.DeletePlaylistItem(ctx, playlistId, version, userId, itemId)
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+
+	return u.GetPlaylist(ctx, userId, playlistId)
+}
+
+// MovePlaylistItemAndGetPlaylist moves the item to position (1-based),
+// shifting the items in between. The new order is only written if nobody
+// changed the playlist since version.
+func (u *userUsecase) MovePlaylistItemAndGetPlaylist(ctx context.Context, userId int, playlistId int, version int, itemId int, position int) (userDomain.Playlist, error) {
+	playlist, err := u.editablePlaylist(ctx, userId, playlistId)
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+
+	version, err = expectedVersion(playlist, version)
+	if err != nil {
+		return playlist, err
+	}
+
+	order, err := moveItem(playlist.Items, itemId, position)
+	if err != nil {
+		return playlist, err
+	}
+
+	err = u.userRepo.UpdatePlaylistItemPositions(ctx, playlistId, version, userId, order)
+	if err != nil {
+		u.logger.Error(err)
+		return playlist, err
+	}
+
+	return u.GetPlaylist(ctx, userId, playlistId)
+}
+
+func (u *userUsecase) GetPlaylistChanges(ctx context.Context, requesterId int, playlistId int) ([]userDomain.PlaylistChange, error) {
+	_, err := u.GetPlaylist(ctx, requesterId, playlistId)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := u.userRepo.FindPlaylistChanges(ctx, playlistId, 100)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (u *userUsecase) GetPlaylistMembers(ctx context.Context, requesterId int, playlistId int) ([]userDomain.PlaylistMember, error) {
+	_, err := u.GetPlaylist(ctx, requesterId, playlistId)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := u.userRepo.FindPlaylistMembers(ctx, playlistId)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	return members, nil
+}
+
+// AddPlaylistMemberAndGetMembers invites memberId, or changes their role if
+// they already collaborate on the playlist.
+func (u *userUsecase) AddPlaylistMemberAndGetMembers(ctx context.Context, ownerId int, playlistId int, memberId int, role string) ([]userDomain.PlaylistMember, error) {
+	_, err := u.ownedPlaylist(ctx, ownerId, playlistId)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+
+	if !userDomain.ValidPlaylistRole(role) {
+		return nil, userDomain.ErrInvalidPlaylistRole
+	}
+	if memberId == ownerId {
+		return nil, userDomain.ErrInvalidPlaylistRole
+	}
+
+	_, err = u.userRepo.FindNicknameByUserId(ctx, memberId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, userDomain.ErrUserNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+
+	err = u.userRepo.UpsertPlaylistMember(ctx, playlistId, memberId, role, ownerId)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+
+	return u.userRepo.FindPlaylistMembers(ctx, playlistId)
+}
+
+// DeletePlaylistMemberAndGetMembers removes a collaborator. The owner can
+// remove anyone, members can only leave.
+func (u *userUsecase) DeletePlaylistMemberAndGetMembers(ctx context.Context, userId int, playlistId int, memberId int) ([]userDomain.PlaylistMember, error) {
+	if userId != memberId {
+		_, err := u.ownedPlaylist(ctx, userId, playlistId)
+		if err != nil {
+			u.logger.Error(err)
+			return nil, err
+		}
+	}
+
+	_, err := u.userRepo.FindPlaylistRole(ctx, playlistId, memberId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, userDomain.ErrPlaylistMemberNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+
+	err = u.userRepo.DeletePlaylistMember(ctx, playlistId, memberId)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+
+	return u.userRepo.FindPlaylistMembers(ctx, playlistId)
+}
+
+// moveItem returns the item ids in their new order after moving itemId to
+// the 1-based position.
+func moveItem(items []userDomain.PlaylistItem, itemId int, position int) ([]int, error) {
+	if position < 1 || position > len(items) {
+		return nil, userDomain.ErrInvalidPosition
+	}
+
+	var order []int
+	found := false
+	for _, item := range items {
+		if item.Id == itemId {
+			found = true
+			continue
+		}
+		order = append(order, item.Id)
+	}
+	if !found {
+		return nil, userDomain.ErrPlaylistItemNotFound
+	}
+
+	order = append(order, 0)
+	copy(order[position:], order[position-1:])
+	order[position-1] = itemId
+	return order, nil
+}
+
+// recordActivity adds an event to the user's activity. The feed is a side
+// effect of the change that caused it, so a failure here is logged rather
+// than failing that change.
+func (u *userUsecase) recordActivity(ctx context.Context, event userDomain.ActivityEvent) {
+	err := u.userRepo.InsertActivityEvent(ctx, event)
+	if err != nil {
+		u.logger.Error(err)
+	}
+}
+
+func (u *userUsecase) FollowAndGetFollowing(ctx context.Context, userId int, followeeId int) ([]userDomain.FollowUser, error) {
+	if userId == followeeId {
+		return nil, userDomain.ErrCannotFollowSelf
+	}
+
+	_, err := u.userRepo.FindUserById(ctx, followeeId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, userDomain.ErrUserNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+
+	err = u.userRepo.InsertFollow(ctx, userId, followeeId)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+
+	return u.GetFollowing(ctx, userId)
+}
+
+func (u *userUsecase) UnfollowAndGetFollowing(ctx context.Context, userId int, followeeId int) ([]userDomain.FollowUser, error) {
+	err := u.userRepo.DeleteFollow(ctx, userId, followeeId)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+
+	return u.GetFollowing(ctx, userId)
+}
+
+func (u *userUsecase) GetFollowing(ctx context.Context, userId int) ([]userDomain.FollowUser, error) {
+	users, err := u.userRepo.FindFollowing(ctx, userId)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	return users, nil
+}
+
+func (u *userUsecase) GetFollowers(ctx context.Context, userId int) ([]userDomain.FollowUser, error) {
+	users, err := u.userRepo.FindFollowers(ctx, userId)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+	return users, nil
+}
+
+func (u *userUsecase) GetFeed(ctx context.Context, userId int, before int, limit int) (userDomain.Feed, error) {
+	events, err := u.userRepo.FindFeed(ctx, userId, before, limit)
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.Feed{}, err
+	}
+	return newFeed(events, limit), nil
+}
+
+// GetUserActivity lists a user's own activity as viewerId is allowed to see
+// it under the user's visibility setting.
+func (u *userUsecase) GetUserActivity(ctx context.Context, viewerId int, userId int, before int, limit int) (userDomain.Feed, error) {
+	visibility, err := u.userRepo.FindActivityVisibility(ctx, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return userDomain.Feed{}, userDomain.ErrUserNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.Feed{}, err
+	}
+
+	if viewerId != userId {
+		switch visibility {
+		case userDomain.ActivityPrivate:
+			return userDomain.Feed{}, userDomain.ErrActivityHidden
+		case userDomain.ActivityFollowers:
+			following, err := u.userRepo.FindIsFollowing(ctx, viewerId, userId)
+			if err != nil {
+				u.logger.Error(err)
+				return userDomain.Feed{}, err
+			}
+			if viewerId == 0 || !following {
+				return userDomain.Feed{}, userDomain.ErrActivityHidden
+			}
+		}
+	}
+
+	events, err := u.userRepo.FindActivityByUserId(ctx, userId, before, limit)
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.Feed{}, err
+	}
+	return newFeed(events, limit), nil
+}
+
+func newFeed(events []userDomain.ActivityEvent, limit int) userDomain.Feed {
+	feed := userDomain.Feed{Events: events}
+	if len(events) == limit && limit > 0 {
+		feed.NextBefore = events[len(events)-1].Id
+	}
+	return feed
+}
+
+func (u *userUsecase) ChangeActivityVisibility(ctx context.Context, userId int, visibility string) (string, error) {
+	if !userDomain.ValidActivityVisibility(visibility) {
+		return "", userDomain.ErrInvalidActivityVisibility
+	}
+
+	err := u.userRepo.UpdateActivityVisibility(ctx, userId, visibility)
+	if err != nil {
+		u.logger.Error(err)
+		return "", err
+	}
+
+	current, err := u.userRepo.FindActivityVisibility(ctx, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", userDomain.ErrUserNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return "", err
+	}
+	return current, nil
+}
+
+func (u *userUsecase) GetAllBanners(ctx context.Context) ([]userDomain.Banner, error) {
+	banners, err := u.userRepo.AllBanner(ctx)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+
+	return banners, nil
+}
+
+// GetActiveBanners returns the banners that should be on the home page right
+// now for the viewer. userId 0 is an anonymous viewer; an unknown id is
+// treated the same way rather than failing the home page.
+func (u *userUsecase) GetActiveBanners(ctx context.Context, userId int, visitorId string, locale string) ([]userDomain.Banner, error) {
+	if !localePattern.MatchString(locale) {
+		return nil, userDomain.ErrInvalidLocale
+	}
+
+	var rank string
+	if userId != 0 {
+		viewer, err := u.userRepo.FindUserById(ctx, userId)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			userId = 0
+		case err != nil:
+			u.logger.Error(err)
+			return nil, err
+		default:
+			rank = viewer.Rank
+		}
+	}
+
+	banners, err := u.userRepo.FindActiveBanners(ctx, locale)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+
+	active := make([]userDomain.Banner, 0, len(banners))
+	for _, banner := range banners {
+		if banner.ShownTo(userId, rank) {
+			active = append(active, banner)
+		}
+	}
+
+	// Without a subject there is nothing to keep an assignment sticky to, so
+	// such viewers just get each slot's control.
+	subject, _ := userDomain.BannerSubject(userId, visitorId)
+	return u.pickVariants(ctx, subject, active)
+}
+
+// pickVariants keeps one banner per experiment slot. The chosen variant stays
+// at its own place in the list, so priority ordering still applies.
+func (u *userUsecase) pickVariants(ctx context.Context, subject string, banners []userDomain.Banner) ([]userDomain.Banner, error) {
+	slots := make(map[string][]userDomain.Banner)
+	for _, banner := range banners {
+		if banner.Slot != "" && banner.Weight > 0 {
+			slots[banner.Slot] = append(slots[banner.Slot], banner)
+		}
+	}
+
+	picked := make(map[string]int, len(slots))
+	for slot, variants := range slots {
+		bannerId, err := u.assignVariant(ctx, slot, subject, variants)
+		if err != nil {
+			return nil, err
+		}
+		picked[slot] = bannerId
+	}
+
+	shown := make([]userDomain.Banner, 0, len(banners))
+	for _, banner := range banners {
+		if banner.Slot == "" || picked[banner.Slot] == banner.Id {
+			shown = append(shown, banner)
+		}
+	}
+	return shown, nil
+}
+
+// assignVariant returns the subject's stored variant for a slot, drawing and
+// storing a new one when there is none or the stored one is no longer live.
+func (u *userUsecase) assignVariant(ctx context.Context, slot string, subject string, variants []userDomain.Banner) (int, error) {
+	sort.Slice(variants, func(i, j int) bool { return variants[i].Id < variants[j].Id })
+	if subject == "" {
+		return variants[0].Id, nil
+	}
+
+	bannerId, err := u.userRepo.FindBannerAssignment(ctx, slot, subject)
+	switch {
+	case err == nil:
+		for _, variant := range variants {
+			if variant.Id == bannerId {
+				return bannerId, nil
+			}
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		u.logger.Error(err)
+		return 0, err
+	}
+
+	bannerId = weightedVariant(slot+"/"+subject, variants)
+	err = u.userRepo.UpsertBannerAssignment(ctx, slot, subject, bannerId)
+	if err != nil {
+		u.logger.Error(err)
+		return 0, err
+	}
+	return bannerId, nil
+}
+
+// weightedVariant hashes key onto the variants in proportion to their
+// weights, so a lost assignment row lands the subject on the same variant.
+func weightedVariant(key string, variants []userDomain.Banner) int {
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	n := int(h.Sum64() % uint64(total))
+	for _, variant := range variants {
+		if n < variant.Weight {
+			return variant.Id
+		}
+		n -= variant.Weight
+	}
+	return variants[len(variants)-1].Id
+}
+
+func (u *userUsecase) RecordBannerEvent(ctx context.Context, bannerId int, userId int, visitorId string, kind string) error {
+	if kind != userDomain.BannerEventImpression && kind != userDomain.BannerEventClick {
+		return userDomain.ErrInvalidBannerEvent
+	}
+	subject, err := userDomain.BannerSubject(userId, visitorId)
+	if err != nil {
+		return err
+	}
+
+	_, err = u.userRepo.ReadBannerById(ctx, bannerId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return userDomain.ErrBannerNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return err
+	}
+
+	err = u.userRepo.InsertBannerEvent(ctx, bannerId, subject, kind)
+	if err != nil {
+		u.logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// significanceLevel is the p-value below which a variant's CTR is reported as
+// different from the control's.
+const significanceLevel = 0.05
+
+// GetBannerReport returns CTR per variant of a slot. Every variant after the
+// first (the control) carries a two-proportion z-test against the control.
+func (u *userUsecase) GetBannerReport(ctx context.Context, slot string) (userDomain.BannerReport, error) {
+	if slot == "" || !slotPattern.MatchString(slot) {
+		return userDomain.BannerReport{}, userDomain.ErrInvalidSlot
+	}
+
+	variants, err := u.userRepo.FindBannerSlotStats(ctx, slot)
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.BannerReport{}, err
+	}
+	if len(variants) == 0 {
+		return userDomain.BannerReport{}, userDomain.ErrBannerNotFound
+	}
+
+	for i := range variants {
+		if variants[i].Impressions > 0 {
+			variants[i].CTR = float64(variants[i].Clicks) / float64(variants[i].Impressions)
+		}
+	}
+	control := variants[0]
+	for i := 1; i < len(variants); i++ {
+		z, ok := twoProportionZ(control.Clicks, control.Impressions, variants[i].Clicks, variants[i].Impressions)
+		if !ok {
+			continue
+		}
+		variants[i].ZScore = z
+		variants[i].PValue = twoSidedP(z)
+		variants[i].Significant = variants[i].PValue < significanceLevel
+	}
+
+	return userDomain.BannerReport{Slot: slot, Variants: variants}, nil
+}
+
+// twoProportionZ is the pooled z statistic for the difference between two
+// click-through rates. ok is false when either side has no impressions or
+// the pooled rate leaves no variance to test against.
+func twoProportionZ(clicksA int, viewsA int, clicksB int, viewsB int) (z float64, ok bool) {
+	if viewsA == 0 || viewsB == 0 {
+		return 0, false
+	}
+	pA := float64(clicksA) / float64(viewsA)
+	pB := float64(clicksB) / float64(viewsB)
+	pooled := float64(clicksA+clicksB) / float64(viewsA+viewsB)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(viewsA) + 1/float64(viewsB)))
+	if se == 0 {
+		return 0, false
+	}
+	return (pB - pA) / se, true
+}
+
+// twoSidedP is the probability of a standard normal statistic at least as far
+// from zero as z.
+func twoSidedP(z float64) float64 {
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// localePattern accepts an empty locale or a BCP 47 style tag like "ko" or
+// "en-US".
+var localePattern = regexp.MustCompile(`^([A-Za-z]{2,3}([-_][A-Za-z0-9]{2,8})*)?$`)
+
+// slotPattern accepts an empty slot, meaning the banner is not in an
+// experiment, or a short identifier.
+var slotPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{0,32}$`)
+
+// normalizeBanner checks the schedule and targeting of a banner written by an
+// admin and rewrites its times into BannerTimeLayout.
+func normalizeBanner(banner userDomain.Banner) (userDomain.Banner, error) {
+	if banner.Audience == "" {
+		banner.Audience = userDomain.AudienceAll
+	}
+	switch banner.Audience {
+	case userDomain.AudienceAll, userDomain.AudienceLoggedIn:
+		banner.TargetRank = ""
+	case userDomain.AudienceRank:
+		if banner.TargetRank == "" {
+			return banner, userDomain.ErrMissingRank
+		}
+	default:
+		return banner, userDomain.ErrInvalidAudience
+	}
+
+	if !localePattern.MatchString(banner.Locale) {
+		return banner, userDomain.ErrInvalidLocale
+	}
+	if !slotPattern.MatchString(banner.Slot) {
+		return banner, userDomain.ErrInvalidSlot
+	}
+	if banner.Weight < 0 {
+		return banner, userDomain.ErrInvalidWeight
+	}
+
+	var start, end time.Time
+	var err error
+	if banner.StartAt != "" {
+		if start, err = parseBannerTime(banner.StartAt); err != nil {
+			return banner, err
+		}
+		banner.StartAt = start.Format(userDomain.BannerTimeLayout)
+	}
+	if banner.EndAt != "" {
+		if end, err = parseBannerTime(banner.EndAt); err != nil {
+			return banner, err
+		}
+		banner.EndAt = end.Format(userDomain.BannerTimeLayout)
+	}
+	if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+		return banner, userDomain.ErrInvalidSchedule
+	}
+
+	return banner, nil
+}
+
+// parseBannerTime accepts BannerTimeLayout in server time or RFC 3339.
+func parseBannerTime(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation(userDomain.BannerTimeLayout, value, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q is not a valid time", userDomain.ErrInvalidSchedule, value)
+	}
+	return t.In(time.Local), nil
+}
+
+func (u *userUsecase) UpdateAndGetAllBanners(ctx context.Context, banner userDomain.Banner) ([]userDomain.Banner, error) {
+	banner, err := normalizeBanner(banner)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.userRepo.UpdateBanner(ctx, banner)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+
+	banners, err := u.userRepo.AllBanner(ctx)
+	if err != nil {
+		u.logger.Error(err)
+		return nil, err
+	}
+
+	return banners, nil
+}
+
+func (u *userUsecase) GetBanner(ctx context.Context, id int) (userDomain.Banner, error) {
+	banner, err := u.userRepo.ReadBannerById(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return userDomain.Banner{}, userDomain.ErrBannerNotFound
+	}
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.Banner{}, err
+	}
+	return banner, nil
+}
+
+func (u *userUsecase) AddBanner(ctx context.Context, banner userDomain.Banner) (userDomain.Banner, error) {
+	banner, err := normalizeBanner(banner)
+	if err != nil {
+		return userDomain.Banner{}, err
+	}
+
+	id, err := u.userRepo.InsertBanner(ctx, banner)
+	if err != nil {
+		u.logger.Error(err)
+		return userDomain.Banner{}, err
+	}
+
+	return u.GetBanner(ctx, id)
+}
+
+func (u *userUsecase) AddAndGetAllBanners(ctx context.Context, banner userDomain.Banner) ([]userDomain.Banner, error) {
+	_, err := u.AddBanner(ctx, banner)
+	if err != nil {
 		return nil, err
 	}
 
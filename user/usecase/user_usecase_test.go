@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	movieDomain "github.com/null-like/movie-backend/domain/movie"
+	userDomain "github.com/null-like/movie-backend/domain/user"
+	_movieRepo "github.com/null-like/movie-backend/movie/repository"
+	_userRepo "github.com/null-like/movie-backend/user/repository"
+	"github.com/sirupsen/logrus"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestMoveItem(t *testing.T) {
+	items := []userDomain.PlaylistItem{{Id: 11}, {Id: 12}, {Id: 13}, {Id: 14}}
+	for _, tc := range []struct {
+		name     string
+		itemId   int
+		position int
+		want     []int
+		wantErr  error
+	}{
+		{"to first", 13, 1, []int{13, 11, 12, 14}, nil},
+		{"to last", 11, 4, []int{12, 13, 14, 11}, nil},
+		{"last to first", 14, 1, []int{14, 11, 12, 13}, nil},
+		{"down", 12, 3, []int{11, 13, 12, 14}, nil},
+		{"in place", 12, 2, []int{11, 12, 13, 14}, nil},
+		{"position zero", 12, 0, nil, userDomain.ErrInvalidPosition},
+		{"negative position", 12, -1, nil, userDomain.ErrInvalidPosition},
+		{"past the end", 12, 5, nil, userDomain.ErrInvalidPosition},
+		{"missing item", 15, 1, nil, userDomain.ErrPlaylistItemNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := moveItem(items, tc.itemId, tc.position)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMovePlaylistItemVersion(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	mr := _movieRepo.NewMemoryMovieRepository()
+	ur := _userRepo.NewMemoryUserRepository(mr)
+	u := NewUserUsecase(logger, ur, mr, nil, nil)
+
+	ctx := context.Background()
+	id, err := ur.InsertPlaylist(ctx, 1, "List", movieDomain.MediaType, userDomain.VisibilityPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mediaId := range []int{10, 20, 30} {
+		err = ur.InsertPlaylistItem(ctx, id, 0, 1, mediaId, movieDomain.MediaType, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	p, err := u.GetPlaylist(ctx, 1, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := p.Version
+	last := p.Items[2].Id
+
+	p, err = u.MovePlaylistItemAndGetPlaylist(ctx, 1, id, stale, last, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Items[0].Id != last {
+		t.Errorf("got first item %d, want %d", p.Items[0].Id, last)
+	}
+
+	_, err = u.MovePlaylistItemAndGetPlaylist(ctx, 1, id, stale, last, 3)
+	if !errors.Is(err, userDomain.ErrPlaylistVersionConflict) {
+		t.Errorf("stale version: got error %v, want a version conflict", err)
+	}
+	p, err = u.GetPlaylist(ctx, 1, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Items[0].Id != last || p.Version != stale+1 {
+		t.Errorf("stale move changed the playlist: first item %d, version %d", p.Items[0].Id, p.Version)
+	}
+}
+
+func TestWeightedVariant(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		weights []int
+	}{
+		{"single", []int{5}},
+		{"even", []int{1, 1}},
+		{"uneven", []int{1, 2, 7}},
+		{"large", []int{900, 100}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var variants []userDomain.Banner
+			total := 0
+			for i, w := range tc.weights {
+				variants = append(variants, userDomain.Banner{Id: i + 1, Weight: w})
+				total += w
+			}
+
+			const visitors = 20000
+			counts := map[int]int{}
+			for i := 0; i < visitors; i++ {
+				key := "hero/visitor:" + strconv.Itoa(i)
+				id := weightedVariant(key, variants)
+				if again := weightedVariant(key, variants); again != id {
+					t.Fatalf("%s: got %d, then %d", key, id, again)
+				}
+				counts[id]++
+			}
+
+			for _, variant := range variants {
+				got := float64(counts[variant.Id]) / visitors
+				want := float64(variant.Weight) / float64(total)
+				if math.Abs(got-want) > 0.02 {
+					t.Errorf("variant %d: got share %.3f, want %.3f", variant.Id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTwoProportionZ(t *testing.T) {
+	for _, tc := range []struct {
+		name                             string
+		clicksA, viewsA, clicksB, viewsB int
+		wantOk                           bool
+		wantZ, wantP                     float64
+	}{
+		{"better", 100, 1000, 130, 1000, true, 2.1027, 0.0355},
+		{"worse", 50, 1000, 40, 1000, true, -1.0786, 0.2807},
+		{"equal", 30, 1000, 30, 1000, true, 0, 1},
+		{"uneven traffic", 10, 200, 25, 300, true, 1.4311, 0.1524},
+		{"control without traffic", 0, 0, 5, 100, false, 0, 0},
+		{"variant without traffic", 5, 100, 0, 0, false, 0, 0},
+		{"no clicks", 0, 100, 0, 100, false, 0, 0},
+		{"every view clicked", 100, 100, 50, 50, false, 0, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			z, ok := twoProportionZ(tc.clicksA, tc.viewsA, tc.clicksB, tc.viewsB)
+			if ok != tc.wantOk {
+				t.Fatalf("got ok %v, want %v", ok, tc.wantOk)
+			}
+			if math.IsNaN(z) || math.IsInf(z, 0) {
+				t.Fatalf("got z %v", z)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(z-tc.wantZ) > 1e-4 {
+				t.Errorf("got z %.4f, want %.4f", z, tc.wantZ)
+			}
+			if p := twoSidedP(z); math.Abs(p-tc.wantP) > 1e-4 {
+				t.Errorf("got p %.4f, want %.4f", p, tc.wantP)
+			}
+		})
+	}
+}
+
+func TestGetPlaylistItemsByPlaylistIds(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	mr := _movieRepo.NewMemoryMovieRepository()
+	ur := _userRepo.NewMemoryUserRepository(mr)
+	u := NewUserUsecase(logger, ur, mr, nil, nil)
+
+	ctx := context.Background()
+	const owner, member, stranger = 1, 2, 3
+	ids := map[string]int{}
+	for _, visibility := range []string{userDomain.VisibilityPublic, userDomain.VisibilityUnlisted, userDomain.VisibilityPrivate} {
+		id, err := ur.InsertPlaylist(ctx, owner, visibility, movieDomain.MediaType, visibility)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ur.InsertPlaylistItem(ctx, id, 0, owner, 10, movieDomain.MediaType, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[visibility] = id
+	}
+	shared, err := ur.InsertPlaylist(ctx, owner, "shared", movieDomain.MediaType, userDomain.VisibilityPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ur.UpsertPlaylistMember(ctx, shared, member, userDomain.PlaylistRoleEditor, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := []int{ids[userDomain.VisibilityPublic], ids[userDomain.VisibilityUnlisted], ids[userDomain.VisibilityPrivate], shared, shared + 100}
+
+	for _, tc := range []struct {
+		name        string
+		requesterId int
+		want        []int
+	}{
+		{"owner", owner, []int{ids[userDomain.VisibilityPublic], ids[userDomain.VisibilityUnlisted], ids[userDomain.VisibilityPrivate], shared}},
+		{"member", member, []int{ids[userDomain.VisibilityPublic], ids[userDomain.VisibilityUnlisted], shared}},
+		{"stranger", stranger, []int{ids[userDomain.VisibilityPublic], ids[userDomain.VisibilityUnlisted]}},
+		{"anonymous", 0, []int{ids[userDomain.VisibilityPublic], ids[userDomain.VisibilityUnlisted]}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			items, err := u.GetPlaylistItemsByPlaylistIds(ctx, tc.requesterId, all)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for id := range items {
+				got = append(got, id)
+			}
+			sort.Ints(got)
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("got playlists %v, want %v", got, tc.want)
+			}
+			if list, ok := items[shared]; ok && len(list) != 0 {
+				t.Errorf("empty shared playlist: got %v", list)
+			}
+		})
+	}
+}